@@ -86,7 +86,7 @@ const clickhouseTimeStringFormat = "2006-01-02 15:04:05"
 // cpu-max-all-1
 // cpu-max-all-8
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	interval := d.MustRandWindow(duration)
 	metrics := devops.GetAllCPUMetrics()
 	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
 
@@ -125,7 +125,7 @@ func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 
 	selectClauses := make([]string, numMetrics)
 	meanClauses := make([]string, numMetrics)
@@ -186,7 +186,7 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // Resultsets:
 // groupby-orderby-limit
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
+	interval := d.MustRandWindow(time.Hour)
 
 	sql := fmt.Sprintf(`
         SELECT
@@ -224,7 +224,7 @@ func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
 	} else {
 		hostWhereClause = fmt.Sprintf("AND (%s)", d.getHostWhereString(nHosts))
 	}
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 
 	sql := fmt.Sprintf(`
         SELECT *
@@ -304,7 +304,7 @@ func (d *Devops) LastPointPerHost(qi query.Query) {
 // single-groupby-5-1-1
 // single-groupby-5-8-1
 func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
@@ -45,7 +45,7 @@ func (d *Devops) getSelectAggClauses(aggFunc string, idents []string) []string {
 // cpu-max-all-1
 // cpu-max-all-8
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int) {
-	interval := d.Interval.MustRandWindow(devops.MaxAllDuration)
+	interval := d.MustRandWindow(devops.MaxAllDuration)
 	selectClauses := d.getSelectAggClauses("max", devops.GetAllCPUMetrics())
 	hosts, err := d.GetRandomHosts(nHosts)
 	panicIfErr(err)
@@ -81,7 +81,7 @@ func (d *Devops) MaxAllCPU(qi query.Query, nHosts int) {
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 	selectClauses := d.getSelectAggClauses("mean", metrics)
 
 	sql := fmt.Sprintf(`
@@ -109,7 +109,7 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // Queries:
 // groupby-orderby-limit
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
+	interval := d.MustRandWindow(time.Hour)
 	sql := fmt.Sprintf(`
 		SELECT
 			date_trunc('minute', ts) as minute,
@@ -152,7 +152,7 @@ func (d *Devops) LastPointPerHost(qi query.Query) {
 // high-cpu-1
 // high-cpu-all
 func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 	hosts, err := d.GetRandomHosts(nHosts)
 	panicIfErr(err)
 
@@ -185,7 +185,7 @@ func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
 // single-groupby-5-1-1
 // single-groupby-5-8-1
 func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	selectClauses := d.getSelectAggClauses("max", metrics)
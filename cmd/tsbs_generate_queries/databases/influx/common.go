@@ -3,6 +3,7 @@ package influx
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
@@ -11,8 +12,16 @@ import (
 	"github.com/timescale/tsbs/pkg/query"
 )
 
+// fluxQueryPath is the path of the InfluxDB v2 query API.
+const fluxQueryPath = "/api/v2/query"
+
 // BaseGenerator contains settings specific for Influx database.
 type BaseGenerator struct {
+	// UseFlux generates Flux queries for the InfluxDB v2 API instead of
+	// InfluxQL queries.
+	UseFlux bool
+	// Bucket is the bucket Flux queries read from.
+	Bucket string
 }
 
 // GenerateEmptyQuery returns an empty query.HTTP.
@@ -33,6 +42,34 @@ func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, influ
 	q.Body = nil
 }
 
+// fillInFluxQuery fills the query struct with a Flux query, sent as the body
+// of a request to the v2 query API.
+func (g *BaseGenerator) fillInFluxQuery(qi query.Query, humanLabel, humanDesc, flux string) {
+	q := qi.(*query.HTTP)
+	q.HumanLabel = []byte(humanLabel)
+	q.RawQuery = []byte(flux)
+	q.HumanDescription = []byte(humanDesc)
+	q.Method = []byte("POST")
+	q.Path = []byte(fluxQueryPath)
+	q.Body = []byte(flux)
+}
+
+// fromBucket returns the beginning of a Flux query reading the bucket of g
+// between start and end (both RFC3339 timestamps).
+func (g *BaseGenerator) fromBucket(start, end string) string {
+	return fmt.Sprintf("from(bucket: %q)\n  |> range(start: %s, stop: %s)", g.Bucket, start, end)
+}
+
+// fluxOr returns a Flux predicate matching records whose column is one of
+// values, e.g. (r.hostname == "host_1" or r.hostname == "host_2").
+func fluxOr(column string, values []string) string {
+	clauses := make([]string, len(values))
+	for i, v := range values {
+		clauses[i] = fmt.Sprintf("r.%s == %q", column, v)
+	}
+	return "(" + strings.Join(clauses, " or ") + ")"
+}
+
 // NewDevops creates a new devops use case query generator.
 func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryGenerator, error) {
 	core, err := devops.NewCore(start, end, scale)
@@ -41,6 +78,10 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 		return nil, err
 	}
 
+	if g.UseFlux {
+		return &FluxDevops{BaseGenerator: g, Core: core}, nil
+	}
+
 	devops := &Devops{
 		BaseGenerator: g,
 		Core:          core,
@@ -57,6 +98,10 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 		return nil, err
 	}
 
+	if g.UseFlux {
+		return &FluxIoT{BaseGenerator: g, Core: core}, nil
+	}
+
 	devops := &IoT{
 		BaseGenerator: g,
 		Core:          core,
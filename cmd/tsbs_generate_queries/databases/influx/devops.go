@@ -51,7 +51,7 @@ func (d *Devops) getSelectClausesAggMetrics(agg string, metrics []string) []stri
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY minute ORDER BY minute ASC
 func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	databases.PanicIfErr(err)
 	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
@@ -69,7 +69,7 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 // GROUP BY t ORDER BY t DESC
 // LIMIT $LIMIT
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
+	interval := d.MustRandWindow(time.Hour)
 	where := fmt.Sprintf("WHERE time < '%s'", interval.EndString())
 
 	humanLabel := "Influx max cpu over last 5 min-intervals (random end)"
@@ -88,7 +88,7 @@ func (d *Devops) GroupByOrderByLimit(qi query.Query) {
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	databases.PanicIfErr(err)
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 	selectClauses := d.getSelectClausesAggMetrics("mean", metrics)
 
 	humanLabel := devops.GetDoubleGroupByLabel("Influx", numMetrics)
@@ -105,7 +105,7 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour ORDER BY hour
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	interval := d.MustRandWindow(duration)
 	whereHosts := d.getHostWhereString(nHosts)
 	selectClauses := d.getSelectClausesAggMetrics("max", devops.GetAllCPUMetrics())
 
@@ -132,7 +132,7 @@ func (d *Devops) LastPointPerHost(qi query.Query) {
 // AND time >= '$TIME_START' AND time < '$TIME_END'
 // AND (hostname = '$HOST' OR hostname = '$HOST2'...)
 func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 
 	var hostWhereClause string
 	if nHosts == 0 {
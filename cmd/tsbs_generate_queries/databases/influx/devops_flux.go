@@ -0,0 +1,166 @@
+package influx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// fluxLabel prefixes the human labels of Flux queries.
+	fluxLabel = "Influx Flux"
+	// fluxEpoch starts ranges which read all the data up to a point.
+	fluxEpoch = "1970-01-01T00:00:00Z"
+)
+
+// FluxDevops produces Flux queries for all the devops query types.
+type FluxDevops struct {
+	*BaseGenerator
+	*devops.Core
+}
+
+func (d *FluxDevops) getHostFilter(nHosts int) string {
+	hostnames, err := d.GetRandomHosts(nHosts)
+	databases.PanicIfErr(err)
+	return fluxOr("hostname", hostnames)
+}
+
+// GroupByTime selects the MAX for numMetrics metrics under 'cpu',
+// per minute for nhosts hosts,
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $HOUR_START, stop: $HOUR_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and (r._field == "metric1" or ...) and (r.hostname == "$HOSTNAME_1" or ...))
+//	  |> group(columns: ["_field"])
+//	  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+func (d *FluxDevops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
+	interval := d.MustRandWindow(timeRange)
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+
+	humanLabel := fmt.Sprintf("%s %d cpu metric(s), random %4d hosts, random %s by 1m", fluxLabel, numMetrics, nHosts, timeRange)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "cpu" and %s and %s)
+  |> group(columns: ["_field"])
+  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)`,
+		d.fromBucket(interval.StartString(), interval.EndString()),
+		fluxOr("_field", metrics),
+		d.getHostFilter(nHosts))
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// GroupByOrderByLimit benchmarks a query that has a time WHERE clause, that groups by a truncated date, orders by that date, and takes a limit:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: 1970-01-01T00:00:00Z, stop: $TIME)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_user")
+//	  |> group()
+//	  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+//	  |> sort(columns: ["_time"], desc: true)
+//	  |> limit(n: 5)
+func (d *FluxDevops) GroupByOrderByLimit(qi query.Query) {
+	interval := d.MustRandWindow(time.Hour)
+
+	humanLabel := fluxLabel + " max cpu over last 5 min-intervals (random end)"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "cpu" and r._field == "usage_user")
+  |> group()
+  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)
+  |> sort(columns: ["_time"], desc: true)
+  |> limit(n: 5)`,
+		d.fromBucket(fluxEpoch, interval.EndString()))
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// GroupByTimeAndPrimaryTag selects the AVG of numMetrics metrics under 'cpu' per device per hour for a day,
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $HOUR_START, stop: $HOUR_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and (r._field == "metric1" or ...))
+//	  |> group(columns: ["_field", "hostname"])
+//	  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)
+func (d *FluxDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	databases.PanicIfErr(err)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
+
+	humanLabel := devops.GetDoubleGroupByLabel(fluxLabel, numMetrics)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "cpu" and %s)
+  |> group(columns: ["_field", "hostname"])
+  |> aggregateWindow(every: 1h, fn: mean, createEmpty: false)`,
+		d.fromBucket(interval.StartString(), interval.EndString()),
+		fluxOr("_field", metrics))
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $HOUR_START, stop: $HOUR_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and (r.hostname == "$HOSTNAME_1" or ...))
+//	  |> group(columns: ["_field"])
+//	  |> aggregateWindow(every: 1h, fn: max, createEmpty: false)
+func (d *FluxDevops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.MustRandWindow(duration)
+
+	humanLabel := devops.GetMaxAllLabel(fluxLabel, nHosts)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "cpu" and %s)
+  |> group(columns: ["_field"])
+  |> aggregateWindow(every: 1h, fn: max, createEmpty: false)`,
+		d.fromBucket(interval.StartString(), interval.EndString()),
+		d.getHostFilter(nHosts))
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// LastPointPerHost finds the last row for every host in the dataset
+func (d *FluxDevops) LastPointPerHost(qi query.Query) {
+	humanLabel := fluxLabel + " last row per host"
+	humanDesc := humanLabel + ": cpu"
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "cpu")
+  |> group(columns: ["hostname", "_field"])
+  |> last()`,
+		d.fromBucket(fluxEpoch, d.Interval.End().Format(time.RFC3339)))
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// HighCPUForHosts populates a query that gets CPU metrics when the CPU has high
+// usage between a time period for a number of hosts (if 0, it will search all hosts),
+// e.g. in Flux:
+//
+//	from(bucket: "benchmark")
+//	  |> range(start: $TIME_START, stop: $TIME_END)
+//	  |> filter(fn: (r) => r._measurement == "cpu" and (r.hostname == "$HOST" or ...))
+//	  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+//	  |> filter(fn: (r) => r.usage_user > 90.0)
+func (d *FluxDevops) HighCPUForHosts(qi query.Query, nHosts int) {
+	interval := d.MustRandWindow(devops.HighCPUDuration)
+
+	filter := `r._measurement == "cpu"`
+	if nHosts > 0 {
+		filter += " and " + d.getHostFilter(nHosts)
+	}
+
+	humanLabel, err := devops.GetHighCPULabel(fluxLabel, nHosts)
+	databases.PanicIfErr(err)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => %s)
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+  |> filter(fn: (r) => r.usage_user > 90.0)`,
+		d.fromBucket(interval.StartString(), interval.EndString()),
+		filter)
+	d.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
@@ -0,0 +1,111 @@
+package influx
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestFluxOr(t *testing.T) {
+	cases := []struct {
+		desc   string
+		values []string
+		want   string
+	}{
+		{
+			desc:   "single value",
+			values: []string{"host_1"},
+			want:   `(r.hostname == "host_1")`,
+		},
+		{
+			desc:   "multiple values",
+			values: []string{"host_1", "host_2"},
+			want:   `(r.hostname == "host_1" or r.hostname == "host_2")`,
+		},
+	}
+
+	for _, c := range cases {
+		if got := fluxOr("hostname", c.values); got != c.want {
+			t.Errorf("%s: incorrect output: got %s want %s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestFluxDevopsGroupByTime(t *testing.T) {
+	expectedHumanLabel := "Influx Flux 1 cpu metric(s), random    1 hosts, random 1s by 1m"
+	expectedHumanDesc := "Influx Flux 1 cpu metric(s), random    1 hosts, random 1s by 1m: 1970-01-01T00:05:58Z"
+	expectedQuery := `from(bucket: "benchmark")
+  |> range(start: 1970-01-01T00:05:58Z, stop: 1970-01-01T00:05:59Z)
+  |> filter(fn: (r) => r._measurement == "cpu" and (r._field == "usage_user") and (r.hostname == "host_9"))
+  |> group(columns: ["_field"])
+  |> aggregateWindow(every: 1m, fn: max, createEmpty: false)`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(time.Hour)
+	b := BaseGenerator{UseFlux: true, Bucket: "benchmark"}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	d := dq.(*FluxDevops)
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 1, time.Second)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestFluxIoTStationaryTrucks(t *testing.T) {
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(time.Hour)
+	b := BaseGenerator{UseFlux: true, Bucket: "benchmark"}
+	g, err := b.NewIoT(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating iot generator")
+	}
+	i := g.(*FluxIoT)
+
+	expectedHumanLabel := "Influx Flux stationary trucks"
+	expectedHumanDesc := "Influx Flux stationary trucks: with low avg velocity in last 10 minutes"
+	expectedQuery := `from(bucket: "benchmark")
+  |> range(start: 1970-01-01T00:36:22Z, stop: 1970-01-01T00:46:22Z)
+  |> filter(fn: (r) => r._measurement == "readings" and r._field == "velocity" and r.fleet == "West")
+  |> group(columns: ["name", "driver"])
+  |> mean()
+  |> filter(fn: (r) => r._value < 1.0)`
+
+	q := i.GenerateEmptyQuery()
+	i.StationaryTrucks(q)
+
+	verifyFluxQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func verifyFluxQuery(t *testing.T, q query.Query, humanLabel, humanDesc, flux string) {
+	fluxQuery, ok := q.(*query.HTTP)
+	if !ok {
+		t.Fatal("Filled query is not *query.HTTP type")
+	}
+
+	if got := string(fluxQuery.HumanLabel); got != humanLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got, humanLabel)
+	}
+	if got := string(fluxQuery.HumanDescription); got != humanDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got, humanDesc)
+	}
+	if got := string(fluxQuery.Method); got != "POST" {
+		t.Errorf("incorrect method:\ngot\n%s\nwant POST", got)
+	}
+	if got := string(fluxQuery.Path); got != fluxQueryPath {
+		t.Errorf("incorrect path:\ngot\n%s\nwant\n%s", got, fluxQueryPath)
+	}
+	if got := string(fluxQuery.Body); got != flux {
+		t.Errorf("incorrect body:\ngot\n%s\nwant\n%s", got, flux)
+	}
+	if got := string(fluxQuery.RawQuery); got != flux {
+		t.Errorf("incorrect raw query:\ngot\n%s\nwant\n%s", got, flux)
+	}
+}
@@ -113,7 +113,7 @@ func (i *IoT) TrucksWithHighLoad(qi query.Query) {
 
 // StationaryTrucks finds all trucks that have low average velocity in a time window.
 func (i *IoT) StationaryTrucks(qi query.Query) {
-	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	interval := i.MustRandWindow(iot.StationaryDuration)
 	influxql := fmt.Sprintf(`SELECT "name", "driver" 
 		FROM(SELECT mean("velocity") as mean_velocity 
 		 FROM "readings" 
@@ -134,7 +134,7 @@ func (i *IoT) StationaryTrucks(qi query.Query) {
 
 // TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
 func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
-	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	interval := i.MustRandWindow(iot.LongDrivingSessionDuration)
 	influxql := fmt.Sprintf(`SELECT "name","driver" 
 		FROM(SELECT count(*) AS ten_min 
 		 FROM(SELECT mean("velocity") AS mean_velocity 
@@ -158,7 +158,7 @@ func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
 
 // TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
 func (i *IoT) TrucksWithLongDailySessions(qi query.Query) {
-	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	interval := i.MustRandWindow(iot.DailyDrivingDuration)
 	influxql := fmt.Sprintf(`SELECT "name","driver" 
 		FROM(SELECT count(*) AS ten_min 
 		 FROM(SELECT mean("velocity") AS mean_velocity 
@@ -0,0 +1,267 @@
+package influx
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// FluxIoT produces Flux queries for all the iot query types.
+//
+// The truck attributes (load_capacity, nominal_fuel_consumption, ...) are
+// tags, so they are strings in Flux and are converted with float() where
+// they take part in arithmetic.
+type FluxIoT struct {
+	*BaseGenerator
+	*iot.Core
+}
+
+func (i *FluxIoT) getTruckFilter(nTrucks int) string {
+	names, err := i.GetRandomTrucks(nTrucks)
+	databases.PanicIfErr(err)
+	return fluxOr("name", names)
+}
+
+// fromDataset reads the bucket over the whole dataset interval.
+func (i *FluxIoT) fromDataset() string {
+	return i.fromBucket(i.Interval.Start().Format(time.RFC3339), i.Interval.End().Format(time.RFC3339))
+}
+
+// fromWindow reads the bucket over a random window of the given duration.
+func (i *FluxIoT) fromWindow(d time.Duration) string {
+	interval := i.MustRandWindow(d)
+	return i.fromBucket(interval.Start().Format(time.RFC3339), interval.End().Format(time.RFC3339))
+}
+
+// LastLocByTruck finds the truck location for nTrucks.
+func (i *FluxIoT) LastLocByTruck(qi query.Query, nTrucks int) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and (r._field == "latitude" or r._field == "longitude") and %s)
+  |> group(columns: ["name", "driver", "_field"])
+  |> last()
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`,
+		i.fromDataset(),
+		i.getTruckFilter(nTrucks))
+
+	humanLabel := fluxLabel + " last location by specific truck"
+	humanDesc := fmt.Sprintf("%s: random %4d trucks", humanLabel, nTrucks)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// LastLocPerTruck finds all the truck locations along with truck and driver names.
+func (i *FluxIoT) LastLocPerTruck(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and (r._field == "latitude" or r._field == "longitude") and r.fleet == %q)
+  |> group(columns: ["name", "driver", "_field"])
+  |> last()
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")`,
+		i.fromDataset(),
+		i.GetRandomFleet())
+
+	humanLabel := fluxLabel + " last location per truck"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TrucksWithLowFuel finds all trucks with low fuel (less than 10%).
+func (i *FluxIoT) TrucksWithLowFuel(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "diagnostics" and r._field == "fuel_state" and r.fleet == %q)
+  |> group(columns: ["name", "driver"])
+  |> last()
+  |> filter(fn: (r) => r._value <= 0.1)`,
+		i.fromDataset(),
+		i.GetRandomFleet())
+
+	humanLabel := fluxLabel + " trucks with low fuel"
+	humanDesc := fmt.Sprintf("%s: under 10 percent", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TrucksWithHighLoad finds all trucks that have load over 90%.
+func (i *FluxIoT) TrucksWithHighLoad(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "diagnostics" and r._field == "current_load" and r.fleet == %q)
+  |> group(columns: ["name", "driver"])
+  |> last()
+  |> filter(fn: (r) => r._value >= 0.9 * float(v: r.load_capacity))`,
+		i.fromDataset(),
+		i.GetRandomFleet())
+
+	humanLabel := fluxLabel + " trucks with high load"
+	humanDesc := fmt.Sprintf("%s: over 90 percent", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// StationaryTrucks finds all trucks that have low average velocity in a time window.
+func (i *FluxIoT) StationaryTrucks(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and r._field == "velocity" and r.fleet == %q)
+  |> group(columns: ["name", "driver"])
+  |> mean()
+  |> filter(fn: (r) => r._value < 1.0)`,
+		i.fromWindow(iot.StationaryDuration),
+		i.GetRandomFleet())
+
+	humanLabel := fluxLabel + " stationary trucks"
+	humanDesc := fmt.Sprintf("%s: with low avg velocity in last 10 minutes", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// drivingSessions returns a Flux query counting, per truck of a random fleet,
+// the 10 minute periods of a random window of the given duration in which the
+// truck was driving, and keeping the trucks driving more than maxPeriods.
+func (i *FluxIoT) drivingSessions(d time.Duration, maxPeriods int) string {
+	return fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and r._field == "velocity" and r.fleet == %q)
+  |> group(columns: ["name", "driver"])
+  |> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+  |> filter(fn: (r) => r._value > 1.0)
+  |> count()
+  |> filter(fn: (r) => r._value > %d)`,
+		i.fromWindow(d),
+		i.GetRandomFleet(),
+		maxPeriods)
+}
+
+// TrucksWithLongDrivingSessions finds all trucks that have not stopped at least 20 mins in the last 4 hours.
+func (i *FluxIoT) TrucksWithLongDrivingSessions(qi query.Query) {
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 5 mins per hour.
+	flux := i.drivingSessions(iot.LongDrivingSessionDuration, tenMinutePeriods(5, iot.LongDrivingSessionDuration))
+
+	humanLabel := fluxLabel + " trucks with longer driving sessions"
+	humanDesc := fmt.Sprintf("%s: stopped less than 20 mins in 4 hour period", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TrucksWithLongDailySessions finds all trucks that have driven more than 10 hours in the last 24 hours.
+func (i *FluxIoT) TrucksWithLongDailySessions(qi query.Query) {
+	// Calculate number of 10 min intervals that is the max driving duration for the session if we rest 35 mins per hour.
+	flux := i.drivingSessions(iot.DailyDrivingDuration, tenMinutePeriods(35, iot.DailyDrivingDuration))
+
+	humanLabel := fluxLabel + " trucks with longer daily sessions"
+	humanDesc := fmt.Sprintf("%s: drove more than 10 hours in the last 24 hours", humanLabel)
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgVsProjectedFuelConsumption calculates average and projected fuel consumption per fleet.
+func (i *FluxIoT) AvgVsProjectedFuelConsumption(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and (r._field == "velocity" or r._field == "fuel_consumption"))
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+  |> filter(fn: (r) => r.velocity > 1.0)
+  |> map(fn: (r) => ({r with nominal_fuel_consumption: float(v: r.nominal_fuel_consumption)}))
+  |> group(columns: ["fleet"])
+  |> reduce(
+      identity: {count: 0.0, fuel: 0.0, nominal: 0.0},
+      fn: (r, accumulator) => ({
+        count: accumulator.count + 1.0,
+        fuel: accumulator.fuel + r.fuel_consumption,
+        nominal: accumulator.nominal + r.nominal_fuel_consumption,
+      }))
+  |> map(fn: (r) => ({fleet: r.fleet, mean_fuel_consumption: r.fuel / r.count, nominal_fuel_consumption: r.nominal / r.count}))`,
+		i.fromDataset())
+
+	humanLabel := fluxLabel + " average vs projected fuel consumption per fleet"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgDailyDrivingDuration finds the average driving duration per driver.
+func (i *FluxIoT) AvgDailyDrivingDuration(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and r._field == "velocity")
+  |> group(columns: ["fleet", "name", "driver"])
+  |> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+  |> aggregateWindow(every: 1d, fn: count, createEmpty: false)
+  |> map(fn: (r) => ({r with _value: float(v: r._value) / 6.0}))`,
+		i.fromDataset())
+
+	humanLabel := fluxLabel + " average driver driving duration per day"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgDailyDrivingSession finds the average driving session without stopping per driver per day.
+func (i *FluxIoT) AvgDailyDrivingSession(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "readings" and r._field == "velocity" and r.name != "")
+  |> group(columns: ["name"])
+  |> aggregateWindow(every: 10m, fn: mean, createEmpty: true)
+  |> map(fn: (r) => ({r with _value: if exists r._value and r._value > 1.0 then 1.0 else 0.0}))
+  |> difference()
+  |> filter(fn: (r) => r._value != 0.0)
+  |> elapsed(unit: 1m)
+  |> filter(fn: (r) => r._value == -1.0)
+  |> map(fn: (r) => ({r with _value: float(v: r.elapsed)}))
+  |> aggregateWindow(every: 1d, fn: mean, createEmpty: false)`,
+		i.fromDataset())
+
+	humanLabel := fluxLabel + " average driver driving session without stopping per day"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// AvgLoad finds the average load per truck model per fleet.
+func (i *FluxIoT) AvgLoad(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "diagnostics" and r._field == "current_load")
+  |> map(fn: (r) => ({r with _value: r._value / float(v: r.load_capacity)}))
+  |> group(columns: ["fleet", "model"])
+  |> mean()`,
+		i.fromDataset())
+
+	humanLabel := fluxLabel + " average load per truck model per fleet"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// DailyTruckActivity returns the number of hours trucks has been active (not out-of-commission) per day per fleet per model.
+func (i *FluxIoT) DailyTruckActivity(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "diagnostics" and r._field == "status")
+  |> group(columns: ["fleet", "model"])
+  |> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+  |> filter(fn: (r) => r._value < 1.0)
+  |> aggregateWindow(every: 1d, fn: count, createEmpty: false)
+  |> map(fn: (r) => ({r with _value: float(v: r._value) / 144.0}))`,
+		i.fromDataset())
+
+	humanLabel := fluxLabel + " daily truck activity per fleet per model"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
+
+// TruckBreakdownFrequency calculates the amount of times a truck model broke down in the last period.
+func (i *FluxIoT) TruckBreakdownFrequency(qi query.Query) {
+	flux := fmt.Sprintf(`%s
+  |> filter(fn: (r) => r._measurement == "diagnostics" and r._field == "status")
+  |> map(fn: (r) => ({r with _value: if r._value != 0.0 then 1.0 else 0.0}))
+  |> group(columns: ["model"])
+  |> aggregateWindow(every: 10m, fn: mean, createEmpty: false)
+  |> map(fn: (r) => ({r with _value: if r._value >= 0.5 then 1.0 else 0.0}))
+  |> difference()
+  |> filter(fn: (r) => r._value == 1.0)
+  |> count()`,
+		i.fromDataset())
+
+	humanLabel := fluxLabel + " truck breakdown frequency per model"
+	humanDesc := humanLabel
+
+	i.fillInFluxQuery(qi, humanLabel, humanDesc, flux)
+}
@@ -12,6 +12,9 @@ import (
 // BaseGenerator contains settings specific for Mongo database.
 type BaseGenerator struct {
 	UseNaive bool
+	// UseTimeseries targets a native time-series collection, as created by
+	// the loader with timeseries-collection=true.
+	UseTimeseries bool
 }
 
 // GenerateEmptyQuery returns an empty query.Mongo.
@@ -32,7 +35,12 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 		Core:          core,
 	}
 
-	if g.UseNaive {
+	if g.UseTimeseries {
+		devops = &TimeseriesDevops{
+			BaseGenerator: g,
+			Core:          core,
+		}
+	} else if g.UseNaive {
 		devops = &NaiveDevops{
 			BaseGenerator: g,
 			Core:          core,
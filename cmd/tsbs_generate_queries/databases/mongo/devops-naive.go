@@ -40,7 +40,7 @@ type NaiveDevops struct {
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY minute ORDER BY minute ASC
 func (d *NaiveDevops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	hostnames, err := d.GetRandomHosts(nHosts)
 	panicIfErr(err)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
@@ -91,7 +91,7 @@ func (d *NaiveDevops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRa
 // WHERE time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour, hostname ORDER BY hour, hostname
 func (d *NaiveDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 
@@ -140,7 +140,7 @@ func (d *NaiveDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour ORDER BY hour
 func (d *NaiveDevops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	interval := d.MustRandWindow(duration)
 	hostnames, err := d.GetRandomHosts(nHosts)
 	panicIfErr(err)
 	metrics := devops.GetAllCPUMetrics()
@@ -191,7 +191,7 @@ func (d *NaiveDevops) MaxAllCPU(qi query.Query, nHosts int, duration time.Durati
 // AND time >= '$TIME_START' AND time < '$TIME_END'
 // AND (hostname = '$HOST' OR hostname = '$HOST2'...)
 func (d *NaiveDevops) HighCPUForHosts(qi query.Query, nHosts int) {
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 
 	pipelineQuery := mongo.Pipeline{}
 
@@ -267,7 +267,7 @@ func (d *NaiveDevops) LastPointPerHost(qi query.Query) {
 // GROUP BY minute ORDER BY minute DESC
 // LIMIT $LIMIT
 func (d *NaiveDevops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
+	interval := d.MustRandWindow(time.Hour)
 	interval, err := utils.NewTimeInterval(d.Interval.Start(), interval.End())
 	if err != nil {
 		panic(err.Error())
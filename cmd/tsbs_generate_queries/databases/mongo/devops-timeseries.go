@@ -0,0 +1,268 @@
+package mongo
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// tsTimeField and tsMetaField mirror the timeField and metaField the loader
+	// uses when creating a time-series collection (see tsbs_load_mongo).
+	tsTimeField = "time"
+	tsMetaField = "tags"
+)
+
+// TimeseriesDevops produces Mongo-specific queries for the devops use case
+// against a native time-series collection. Predicates on the timeField and the
+// metaField are kept in the leading $match stage so that the server can apply
+// them to bucket boundaries before unpacking any events.
+type TimeseriesDevops struct {
+	*BaseGenerator
+	*devops.Core
+}
+
+// bucketMatch returns the leading $match stage, restricted to the timeField and
+// the hostname within the metaField. If hostnames is empty no host predicate
+// is added.
+func bucketMatch(interval *utils.TimeInterval, hostnames []string) bson.D {
+	match := bson.M{
+		tsTimeField: bson.M{
+			"$gte": interval.Start(),
+			"$lt":  interval.End(),
+		},
+	}
+	if len(hostnames) > 0 {
+		match[tsMetaField+".hostname"] = bson.M{"$in": hostnames}
+	}
+	return bson.D{{"$match", match}}
+}
+
+// eventMatch returns the $match stage on the event-level fields that cannot be
+// evaluated against the bucket metadata.
+func eventMatch(extra bson.M) bson.D {
+	match := bson.M{"measurement": "cpu"}
+	for k, v := range extra {
+		match[k] = v
+	}
+	return bson.D{{"$match", match}}
+}
+
+// GroupByTime selects the MAX for numMetrics metrics under 'cpu',
+// per minute for nhosts hosts,
+// e.g. in pseudo-SQL:
+//
+// SELECT minute, max(metric1), ..., max(metricN)
+// FROM cpu
+// WHERE (hostname = '$HOSTNAME_1' OR ... OR hostname = '$HOSTNAME_N')
+// AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY minute ORDER BY minute ASC
+func (d *TimeseriesDevops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
+	interval := d.MustRandWindow(timeRange)
+	hostnames, err := d.GetRandomHosts(nHosts)
+	panicIfErr(err)
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	panicIfErr(err)
+
+	group := bson.M{
+		"_id": bson.M{
+			"$dateTrunc": bson.M{"date": "$" + tsTimeField, "unit": "minute"},
+		},
+	}
+	for _, metric := range metrics {
+		group["max_"+metric] = bson.M{"$max": "$" + metric}
+	}
+
+	pipelineQuery := mongo.Pipeline{
+		bucketMatch(interval, hostnames),
+		eventMatch(nil),
+		{{"$group", group}},
+		{{"$sort", bson.M{"_id": 1}}},
+	}
+
+	humanLabel := []byte(fmt.Sprintf("Mongo [TIMESERIES] %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange))
+	q := qi.(*query.Mongo)
+	q.HumanLabel = humanLabel
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
+}
+
+// GroupByTimeAndPrimaryTag selects the AVG of numMetrics metrics under 'cpu' per device per hour for a day,
+// e.g. in pseudo-SQL:
+//
+// SELECT AVG(metric1), ..., AVG(metricN)
+// FROM cpu
+// WHERE time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY hour, hostname ORDER BY hour, hostname
+func (d *TimeseriesDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
+	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
+	panicIfErr(err)
+
+	group := bson.M{
+		"_id": bson.M{
+			"time": bson.M{
+				"$dateTrunc": bson.M{"date": "$" + tsTimeField, "unit": "hour"},
+			},
+			"hostname": "$" + tsMetaField + ".hostname",
+		},
+	}
+	for _, metric := range metrics {
+		group["avg_"+metric] = bson.M{"$avg": "$" + metric}
+	}
+
+	pipelineQuery := mongo.Pipeline{
+		bucketMatch(interval, nil),
+		eventMatch(nil),
+		{{"$group", group}},
+		{{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}}},
+	}
+
+	humanLabel := devops.GetDoubleGroupByLabel("Mongo [TIMESERIES]", numMetrics)
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
+}
+
+// MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
+// e.g. in pseudo-SQL:
+//
+// SELECT MAX(metric1), ..., MAX(metricN)
+// FROM cpu WHERE (hostname = '$HOSTNAME_1' OR ... OR hostname = '$HOSTNAME_N')
+// AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY hour ORDER BY hour
+func (d *TimeseriesDevops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.MustRandWindow(duration)
+	hostnames, err := d.GetRandomHosts(nHosts)
+	panicIfErr(err)
+	metrics := devops.GetAllCPUMetrics()
+
+	group := bson.M{
+		"_id": bson.M{
+			"$dateTrunc": bson.M{"date": "$" + tsTimeField, "unit": "hour"},
+		},
+	}
+	for _, metric := range metrics {
+		group["max_"+metric] = bson.M{"$max": "$" + metric}
+	}
+
+	pipelineQuery := mongo.Pipeline{
+		bucketMatch(interval, hostnames),
+		eventMatch(nil),
+		{{"$group", group}},
+		{{"$sort", bson.M{"_id": 1}}},
+	}
+
+	humanLabel := devops.GetMaxAllLabel("Mongo [TIMESERIES]", nHosts)
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.StartString()))
+}
+
+// HighCPUForHosts populates a query that gets CPU metrics when the CPU has high
+// usage between a time period for a number of hosts (if 0, it will search all hosts),
+// e.g. in pseudo-SQL:
+//
+// SELECT * FROM cpu
+// WHERE usage_user > 90.0
+// AND time >= '$TIME_START' AND time < '$TIME_END'
+// AND (hostname = '$HOST' OR hostname = '$HOST2'...)
+func (d *TimeseriesDevops) HighCPUForHosts(qi query.Query, nHosts int) {
+	interval := d.MustRandWindow(devops.HighCPUDuration)
+
+	var hostnames []string
+	if nHosts > 0 {
+		var err error
+		hostnames, err = d.GetRandomHosts(nHosts)
+		panicIfErr(err)
+	}
+
+	pipelineQuery := mongo.Pipeline{
+		bucketMatch(interval, hostnames),
+		eventMatch(bson.M{"usage_user": bson.M{"$gt": 90.0}}),
+		{{"$set", bson.M{tsMetaField: "$" + tsMetaField + ".hostname"}}},
+	}
+
+	humanLabel, err := devops.GetHighCPULabel("Mongo [TIMESERIES]", nHosts)
+	panicIfErr(err)
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
+}
+
+// LastPointPerHost finds the last row for every host in the dataset, e.g. in pseudo-SQL:
+//
+// SELECT DISTINCT ON (hostname) * FROM cpu
+// ORDER BY hostname, time DESC
+//
+// The sort on (metaField.hostname, timeField) followed by $first matches the
+// index the loader creates, which lets the server answer it from the last
+// bucket of each series.
+func (d *TimeseriesDevops) LastPointPerHost(qi query.Query) {
+	pipelineQuery := mongo.Pipeline{
+		{{"$sort", bson.D{{tsMetaField + ".hostname", 1}, {tsTimeField, -1}}}},
+		{{
+			"$group", bson.M{
+				"_id":    bson.M{"hostname": "$" + tsMetaField + ".hostname"},
+				"result": bson.M{"$first": "$$ROOT"},
+			},
+		}},
+	}
+
+	humanLabel := "Mongo [TIMESERIES] last row per host"
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(humanLabel)
+}
+
+// GroupByOrderByLimit populates a query.Query that has a time WHERE clause, that groups by a
+// truncated date, orders by that date, and takes a limit, e.g. in pseudo-SQL:
+//
+// SELECT minute, MAX(usage_user) FROM cpu
+// WHERE time < '$TIME'
+// GROUP BY minute ORDER BY minute DESC
+// LIMIT $LIMIT
+func (d *TimeseriesDevops) GroupByOrderByLimit(qi query.Query) {
+	interval := d.MustRandWindow(time.Hour)
+	interval, err := utils.NewTimeInterval(d.Interval.Start(), interval.End())
+	if err != nil {
+		panic(err.Error())
+	}
+
+	pipelineQuery := mongo.Pipeline{
+		bucketMatch(interval, nil),
+		eventMatch(nil),
+		{{
+			"$group", bson.M{
+				"_id": bson.M{
+					"$dateTrunc": bson.M{"date": "$" + tsTimeField, "unit": "minute"},
+				},
+				"max_value": bson.M{"$max": "$usage_user"},
+			},
+		}},
+		{{"$sort", bson.M{"_id": -1}}},
+		{{"$limit", 5}},
+	}
+
+	humanLabel := "Mongo [TIMESERIES] max cpu over last 5 min-intervals (random end)"
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.EndString()))
+}
@@ -0,0 +1,82 @@
+package mongo
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestTimeseriesDevopsLeadingMatch(t *testing.T) {
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(48 * time.Hour)
+	b := &BaseGenerator{UseNaive: true, UseTimeseries: true}
+	dq, err := b.NewDevops(start, end, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator: %v", err)
+	}
+	d := dq.(*TimeseriesDevops)
+
+	cases := []struct {
+		desc      string
+		fill      func(q query.Query)
+		wantHosts bool
+	}{
+		{
+			desc:      "group by time",
+			fill:      func(q query.Query) { d.GroupByTime(q, 2, 1, time.Hour) },
+			wantHosts: true,
+		},
+		{
+			desc:      "max all cpu",
+			fill:      func(q query.Query) { d.MaxAllCPU(q, 8, 8*time.Hour) },
+			wantHosts: true,
+		},
+		{
+			desc: "double group by",
+			fill: func(q query.Query) { d.GroupByTimeAndPrimaryTag(q, 5) },
+		},
+		{
+			desc:      "high cpu one host",
+			fill:      func(q query.Query) { d.HighCPUForHosts(q, 1) },
+			wantHosts: true,
+		},
+		{
+			desc: "high cpu all hosts",
+			fill: func(q query.Query) { d.HighCPUForHosts(q, 0) },
+		},
+		{
+			desc: "group by order by limit",
+			fill: func(q query.Query) { d.GroupByOrderByLimit(q) },
+		},
+	}
+
+	for _, c := range cases {
+		rand.Seed(123)
+		q := b.GenerateEmptyQuery()
+		c.fill(q)
+		pipeline := q.(*query.Mongo).Pipeline
+		if len(pipeline) < 2 {
+			t.Fatalf("%s: pipeline too short: %v", c.desc, pipeline)
+		}
+		first := pipeline[0][0]
+		if first.Key != "$match" {
+			t.Fatalf("%s: first stage is %s, want $match", c.desc, first.Key)
+		}
+		match := first.Value.(bson.M)
+		for k := range match {
+			if k != tsTimeField && k != tsMetaField+".hostname" {
+				t.Errorf("%s: leading $match has non-bucket field %q", c.desc, k)
+			}
+		}
+		if _, ok := match[tsTimeField]; !ok {
+			t.Errorf("%s: leading $match missing time predicate", c.desc)
+		}
+		if _, ok := match[tsMetaField+".hostname"]; ok != c.wantHosts {
+			t.Errorf("%s: host predicate present = %v, want %v", c.desc, ok, c.wantHosts)
+		}
+	}
+}
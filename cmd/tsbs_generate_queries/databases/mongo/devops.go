@@ -102,7 +102,7 @@ func getTimeFilterDocs(interval *utils.TimeInterval) []interface{} {
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY minute ORDER BY minute ASC
 func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	hostnames, err := d.GetRandomHosts(nHosts)
 	panicIfErr(err)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
@@ -168,7 +168,7 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour ORDER BY hour
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	interval := d.MustRandWindow(duration)
 	hostnames, err := d.GetRandomHosts(nHosts)
 	panicIfErr(err)
 	docs := getTimeFilterDocs(interval)
@@ -233,7 +233,7 @@ func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
 // WHERE time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour, hostname ORDER BY hour, hostname
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	docs := getTimeFilterDocs(interval)
@@ -308,7 +308,7 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // AND time >= '$TIME_START' AND time < '$TIME_END'
 // AND (hostname = '$HOST' OR hostname = '$HOST2'...)
 func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 	docs := getTimeFilterDocs(interval)
 
 	pipelineQuery := mongo.Pipeline{}
@@ -441,7 +441,7 @@ func (d *Devops) LastPointPerHost(qi query.Query) {
 // GROUP BY t ORDER BY t DESC
 // LIMIT $LIMIT
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
+	interval := d.MustRandWindow(time.Hour)
 	interval, err := utils.NewTimeInterval(d.Interval.Start(), interval.End())
 	if err != nil {
 		panic(err.Error())
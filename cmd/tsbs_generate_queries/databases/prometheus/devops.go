@@ -1,4 +1,4 @@
-package victoriametrics
+package prometheus
 
 import (
 	"fmt"
@@ -48,11 +48,11 @@ func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
 func (d *Devops) GroupByTime(qq query.Query, nHosts, numMetrics int, timeRange time.Duration) {
 	metrics := mustGetCPUMetricsSlice(numMetrics)
 	hosts := d.mustGetRandomHosts(nHosts)
-	selectClause := getSelectClause(metrics, hosts)
+	selectClause := getSelectClause(d.metricPrefix("cpu"), metrics, hosts)
 	qi := &queryInfo{
 		query:    fmt.Sprintf("max(max_over_time(%s[1m])) by (__name__)", selectClause),
-		label:    fmt.Sprintf("VictoriaMetrics %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange),
-		interval: d.Interval.MustRandWindow(timeRange),
+		label:    fmt.Sprintf("%s %d cpu metric(s), random %4d hosts, random %s by 1m", d.labelPrefix(), numMetrics, nHosts, timeRange),
+		interval: d.MustRandWindow(timeRange),
 		step:     "60",
 	}
 	d.fillInQuery(qq, qi)
@@ -73,11 +73,11 @@ func (d *Devops) GroupByTime(qq query.Query, nHosts, numMetrics int, timeRange t
 // double-groupby-all
 func (d *Devops) GroupByTimeAndPrimaryTag(qq query.Query, numMetrics int) {
 	metrics := mustGetCPUMetricsSlice(numMetrics)
-	selectClause := getSelectClause(metrics, nil)
+	selectClause := getSelectClause(d.metricPrefix("cpu"), metrics, nil)
 	qi := &queryInfo{
 		query:    fmt.Sprintf("avg(avg_over_time(%s[1h])) by (__name__, hostname)", selectClause),
-		label:    devops.GetDoubleGroupByLabel("VictoriaMetrics", numMetrics),
-		interval: d.Interval.MustRandWindow(devops.DoubleGroupByDuration),
+		label:    devops.GetDoubleGroupByLabel(d.labelPrefix(), numMetrics),
+		interval: d.MustRandWindow(devops.DoubleGroupByDuration),
 		step:     "3600",
 	}
 	d.fillInQuery(qq, qi)
@@ -93,11 +93,11 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qq query.Query, numMetrics int) {
 // ) by (__name__)
 func (d *Devops) MaxAllCPU(qq query.Query, nHosts int, duration time.Duration) {
 	hosts := d.mustGetRandomHosts(nHosts)
-	selectClause := getSelectClause(devops.GetAllCPUMetrics(), hosts)
+	selectClause := getSelectClause(d.metricPrefix("cpu"), devops.GetAllCPUMetrics(), hosts)
 	qi := &queryInfo{
 		query:    fmt.Sprintf("max(max_over_time(%s[1h])) by (__name__)", selectClause),
-		label:    devops.GetMaxAllLabel("VictoriaMetrics", nHosts),
-		interval: d.Interval.MustRandWindow(duration),
+		label:    devops.GetMaxAllLabel(d.labelPrefix(), nHosts),
+		interval: d.MustRandWindow(duration),
 		step:     "3600",
 	}
 	d.fillInQuery(qq, qi)
@@ -113,21 +113,21 @@ func getHostClause(hostnames []string) string {
 	return fmt.Sprintf("hostname=~'%s'", strings.Join(hostnames, "|"))
 }
 
-func getSelectClause(metrics, hosts []string) string {
+func getSelectClause(metricPrefix string, metrics, hosts []string) string {
 	if len(metrics) == 0 {
 		panic("BUG: must be at least one metric name in clause")
 	}
 
 	hostsClause := getHostClause(hosts)
 	if len(metrics) == 1 {
-		return fmt.Sprintf("cpu_%s{%s}", metrics[0], hostsClause)
+		return fmt.Sprintf("%s%s{%s}", metricPrefix, metrics[0], hostsClause)
 	}
 
 	metricsClause := strings.Join(metrics, "|")
 	if len(hosts) > 0 {
-		return fmt.Sprintf("{__name__=~'cpu_(%s)', %s}", metricsClause, hostsClause)
+		return fmt.Sprintf("{__name__=~'%s(%s)', %s}", metricPrefix, metricsClause, hostsClause)
 	}
-	return fmt.Sprintf("{__name__=~'cpu_(%s)'}", metricsClause)
+	return fmt.Sprintf("{__name__=~'%s(%s)'}", metricPrefix, metricsClause)
 }
 
 // mustGetCPUMetricsSlice is the form of GetCPUMetricsSlice that cannot error; if it does error,
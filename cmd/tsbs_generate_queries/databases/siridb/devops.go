@@ -62,7 +62,7 @@ const goTimeFmt = "2006-01-02 15:04:05Z"
 //
 // select max(1m) from (`groupHost1` | ...) & (`groupMetric1` | ...) between 'time1' and 'time2'
 func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	whereMetrics := d.getMetricWhereString(metrics)
@@ -84,7 +84,7 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 //
 // select max(1m) from `usage_user` between time - 5m and 'roundedTime' merge as 'max usage user of the last 5 aggregate readings' using max(1)
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
+	interval := d.MustRandWindow(time.Hour)
 	timeStr := interval.End().Format(goTimeFmt)
 
 	timestrRounded := timeStr[:len(timeStr)-4] + ":00Z"
@@ -101,7 +101,7 @@ func (d *Devops) GroupByOrderByLimit(qi query.Query) {
 //
 // select mean(1h) from (`groupMetric1` | ...) between 'time1' and 'time2'
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	whereMetrics := d.getMetricWhereString(metrics)
@@ -121,7 +121,7 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 //
 // select max(1h) from (`groupHost1` | ...) & `cpu` between 'time1' and 'time2'
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	interval := d.MustRandWindow(duration)
 
 	whereMetrics := "`cpu`"
 	whereHosts := d.getHostWhereString(nHosts)
@@ -160,7 +160,7 @@ func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
 	} else {
 		whereHosts = "& " + d.getHostWhereString(nHosts)
 	}
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 
 	humanLabel, err := devops.GetHighCPULabel("SiriDB", nHosts)
 	panicIfErr(err)
@@ -16,6 +16,9 @@ type BaseGenerator struct {
 	UseJSON       bool
 	UseTags       bool
 	UseTimeBucket bool
+	// UseCaggs queries the continuous aggregates of cpu per minute and per
+	// hour (cpu_1m and cpu_1h) for the devops rollups.
+	UseCaggs bool
 }
 
 // GenerateEmptyQuery returns an empty query.TimescaleDB.
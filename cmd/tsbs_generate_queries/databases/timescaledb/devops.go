@@ -68,10 +68,30 @@ func (d *Devops) getTimeBucket(seconds int) string {
 	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
 }
 
+// getRollupSource returns the table the cpu metrics are aggregated per
+// bucket of seconds from, the bucket expression and the time column: the
+// cpu hypertable, or with UseCaggs its continuous aggregate per bucket,
+// whose rows are already aggregated per bucket and host.
+func (d *Devops) getRollupSource(seconds int) (string, string, string) {
+	if !d.UseCaggs {
+		return devops.TableName, d.getTimeBucket(seconds), "time"
+	}
+	suffix := "1m"
+	if seconds == oneHour {
+		suffix = "1h"
+	}
+	return fmt.Sprintf("%s_%s", devops.TableName, suffix), "bucket", "bucket"
+}
+
 func (d *Devops) getSelectClausesAggMetrics(agg string, metrics []string) []string {
 	selectClauses := make([]string, len(metrics))
 	for i, m := range metrics {
-		selectClauses[i] = fmt.Sprintf("%[1]s(%[2]s) as %[1]s_%[2]s", agg, m)
+		if d.UseCaggs {
+			// aggregate the column of the continuous aggregate, e.g. max(max_usage_user)
+			selectClauses[i] = fmt.Sprintf("%[1]s(%[1]s_%[2]s) as %[1]s_%[2]s", agg, m)
+		} else {
+			selectClauses[i] = fmt.Sprintf("%[1]s(%[2]s) as %[1]s_%[2]s", agg, m)
+		}
 	}
 
 	return selectClauses
@@ -87,7 +107,7 @@ func (d *Devops) getSelectClausesAggMetrics(agg string, metrics []string) []stri
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY minute ORDER BY minute ASC
 func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange time.Duration) {
-	interval := d.Interval.MustRandWindow(timeRange)
+	interval := d.MustRandWindow(timeRange)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
@@ -95,16 +115,18 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 		panic(fmt.Sprintf("invalid number of select clauses: got %d", len(selectClauses)))
 	}
 
+	table, bucket, timeColumn := d.getRollupSource(oneMinute)
 	sql := fmt.Sprintf(`SELECT %s AS minute,
         %s
-        FROM cpu
-        WHERE %s AND time >= '%s' AND time < '%s'
+        FROM %s
+        WHERE %s AND %s >= '%s' AND %s < '%s'
         GROUP BY minute ORDER BY minute ASC`,
-		d.getTimeBucket(oneMinute),
+		bucket,
 		strings.Join(selectClauses, ", "),
+		table,
 		d.getHostWhereString(nHosts),
-		interval.Start().Format(goTimeFmt),
-		interval.End().Format(goTimeFmt))
+		timeColumn, interval.Start().Format(goTimeFmt),
+		timeColumn, interval.End().Format(goTimeFmt))
 
 	humanLabel := fmt.Sprintf("TimescaleDB %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange)
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
@@ -117,15 +139,22 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 // GROUP BY t ORDER BY t DESC
 // LIMIT $LIMIT
 func (d *Devops) GroupByOrderByLimit(qi query.Query) {
-	interval := d.Interval.MustRandWindow(time.Hour)
-	sql := fmt.Sprintf(`SELECT %s AS minute, max(usage_user)
-        FROM cpu
-        WHERE time < '%s'
+	interval := d.MustRandWindow(time.Hour)
+	table, bucket, timeColumn := d.getRollupSource(oneMinute)
+	maxClause := "max(usage_user)"
+	if d.UseCaggs {
+		maxClause = "max(max_usage_user)"
+	}
+	sql := fmt.Sprintf(`SELECT %s AS minute, %s
+        FROM %s
+        WHERE %s < '%s'
         GROUP BY minute
         ORDER BY minute DESC
         LIMIT 5`,
-		d.getTimeBucket(oneMinute),
-		interval.End().Format(goTimeFmt))
+		bucket,
+		maxClause,
+		table,
+		timeColumn, interval.End().Format(goTimeFmt))
 
 	humanLabel := "TimescaleDB max cpu over last 5 min-intervals (random end)"
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.EndString())
@@ -142,13 +171,17 @@ func (d *Devops) GroupByOrderByLimit(qi query.Query) {
 func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
-	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	interval := d.MustRandWindow(devops.DoubleGroupByDuration)
 
 	selectClauses := make([]string, numMetrics)
 	meanClauses := make([]string, numMetrics)
 	for i, m := range metrics {
 		meanClauses[i] = "mean_" + m
-		selectClauses[i] = fmt.Sprintf("avg(%s) as %s", m, meanClauses[i])
+		if d.UseCaggs {
+			selectClauses[i] = fmt.Sprintf("avg(avg_%s) as %s", m, meanClauses[i])
+		} else {
+			selectClauses[i] = fmt.Sprintf("avg(%s) as %s", m, meanClauses[i])
+		}
 	}
 
 	hostnameField := "hostname"
@@ -164,23 +197,25 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 		partitionGrouping = "tags_id"
 	}
 
+	table, bucket, timeColumn := d.getRollupSource(oneHour)
 	sql := fmt.Sprintf(`
         WITH cpu_avg AS (
           SELECT %s as hour, %s,
           %s
-          FROM cpu
-          WHERE time >= '%s' AND time < '%s'
+          FROM %s
+          WHERE %s >= '%s' AND %s < '%s'
           GROUP BY 1, 2
         )
         SELECT hour, %s, %s
         FROM cpu_avg
         %s
         ORDER BY hour, %s`,
-		d.getTimeBucket(oneHour),
+		bucket,
 		partitionGrouping,
 		strings.Join(selectClauses, ", "),
-		interval.Start().Format(goTimeFmt),
-		interval.End().Format(goTimeFmt),
+		table,
+		timeColumn, interval.Start().Format(goTimeFmt),
+		timeColumn, interval.End().Format(goTimeFmt),
 		hostnameField, strings.Join(meanClauses, ", "),
 		joinStr, hostnameField)
 	humanLabel := devops.GetDoubleGroupByLabel("TimescaleDB", numMetrics)
@@ -196,21 +231,23 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 // AND time >= '$HOUR_START' AND time < '$HOUR_END'
 // GROUP BY hour ORDER BY hour
 func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
-	interval := d.Interval.MustRandWindow(duration)
+	interval := d.MustRandWindow(duration)
 
 	metrics := devops.GetAllCPUMetrics()
 	selectClauses := d.getSelectClausesAggMetrics("max", metrics)
 
+	table, bucket, timeColumn := d.getRollupSource(oneHour)
 	sql := fmt.Sprintf(`SELECT %s AS hour,
         %s
-        FROM cpu
-        WHERE %s AND time >= '%s' AND time < '%s'
+        FROM %s
+        WHERE %s AND %s >= '%s' AND %s < '%s'
         GROUP BY hour ORDER BY hour`,
-		d.getTimeBucket(oneHour),
+		bucket,
 		strings.Join(selectClauses, ", "),
+		table,
 		d.getHostWhereString(nHosts),
-		interval.Start().Format(goTimeFmt),
-		interval.End().Format(goTimeFmt))
+		timeColumn, interval.Start().Format(goTimeFmt),
+		timeColumn, interval.End().Format(goTimeFmt))
 
 	humanLabel := devops.GetMaxAllLabel("TimescaleDB", nHosts)
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
@@ -248,7 +285,7 @@ func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
 	} else {
 		hostWhereClause = fmt.Sprintf("AND %s", d.getHostWhereString(nHosts))
 	}
-	interval := d.Interval.MustRandWindow(devops.HighCPUDuration)
+	interval := d.MustRandWindow(devops.HighCPUDuration)
 
 	sql := fmt.Sprintf(`SELECT * FROM cpu WHERE usage_user > 90.0 and time >= '%s' AND time < '%s' %s`,
 		interval.Start().Format(goTimeFmt), interval.End().Format(goTimeFmt), hostWhereClause)
@@ -209,6 +209,35 @@ func TestDevopsGroupByTime(t *testing.T) {
 	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedHypertable, expectedSQLQuery)
 }
 
+func TestDevopsGroupByTimeCaggs(t *testing.T) {
+	expectedHumanLabel := "TimescaleDB 1 cpu metric(s), random    1 hosts, random 1s by 1m"
+	expectedHumanDesc := "TimescaleDB 1 cpu metric(s), random    1 hosts, random 1s by 1m: 1970-01-01T00:05:58Z"
+	expectedHypertable := "cpu"
+	expectedSQLQuery := `SELECT bucket AS minute,
+        max(max_usage_user) as max_usage_user
+        FROM cpu_1m
+        WHERE hostname IN ('host_9') AND bucket >= '1970-01-01 00:05:58.646325 +0000' AND bucket < '1970-01-01 00:05:59.646325 +0000'
+        GROUP BY minute ORDER BY minute ASC`
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(time.Hour)
+	b := BaseGenerator{
+		UseTimeBucket: true,
+		UseCaggs:      true,
+	}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	d := dq.(*Devops)
+
+	q := d.GenerateEmptyQuery()
+	d.GroupByTime(q, 1, 1, time.Second)
+
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedHypertable, expectedSQLQuery)
+}
+
 func TestGroupByOrderByLimit(t *testing.T) {
 	expectedHumanLabel := "TimescaleDB max cpu over last 5 min-intervals (random end)"
 	expectedHumanDesc := "TimescaleDB max cpu over last 5 min-intervals (random end): 1970-01-01T01:16:22Z"
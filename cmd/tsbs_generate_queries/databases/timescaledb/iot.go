@@ -163,7 +163,7 @@ func (i *IoT) TrucksWithHighLoad(qi query.Query) {
 func (i *IoT) StationaryTrucks(qi query.Query) {
 	name, driver, fleet := "name", "driver", "fleet"
 
-	interval := i.Interval.MustRandWindow(iot.StationaryDuration)
+	interval := i.MustRandWindow(iot.StationaryDuration)
 	sql := fmt.Sprintf(`SELECT t.%s, t.%s
 		FROM tags t 
 		INNER JOIN readings r ON r.tags_id = t.id 
@@ -190,7 +190,7 @@ func (i *IoT) StationaryTrucks(qi query.Query) {
 func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
 	name, driver, fleet := "name", "driver", "fleet"
 
-	interval := i.Interval.MustRandWindow(iot.LongDrivingSessionDuration)
+	interval := i.MustRandWindow(iot.LongDrivingSessionDuration)
 	sql := fmt.Sprintf(`SELECT t.%s, t.%s
 		FROM tags t 
 		INNER JOIN LATERAL 
@@ -224,7 +224,7 @@ func (i *IoT) TrucksWithLongDrivingSessions(qi query.Query) {
 func (i *IoT) TrucksWithLongDailySessions(qi query.Query) {
 	name, driver, fleet := "name", "driver", "fleet"
 
-	interval := i.Interval.MustRandWindow(iot.DailyDrivingDuration)
+	interval := i.MustRandWindow(iot.DailyDrivingDuration)
 	sql := fmt.Sprintf(`SELECT t.%s, t.%s
 		FROM tags t 
 		INNER JOIN LATERAL 
@@ -126,11 +126,46 @@ var useCaseMatrix = map[string]map[string]utils.QueryFillerMaker{
 	},
 }
 
+// parameterizedMatrix lists the query types a workload file can use with its
+// own parameters, instead of one of the fixed variants of the useCaseMatrix.
+var parameterizedMatrix = map[string]map[string]utils.ParameterizedQueryFillerMaker{
+	"devops": {
+		devops.LabelSingleGroupby: func(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+			if p.Metrics < 1 || p.Hosts < 1 || p.Hours < 1 {
+				return nil, fmt.Errorf("metrics, hosts and hours must be positive")
+			}
+			return devops.NewSingleGroupby(p.Metrics, p.Hosts, p.Hours), nil
+		},
+		devops.LabelMaxAll: func(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+			if p.Hosts < 1 {
+				return nil, fmt.Errorf("hosts must be positive")
+			}
+			if p.Duration == 0 {
+				p.Duration = devops.MaxAllDuration
+			}
+			return devops.NewMaxAllCPU(p.Hosts, p.Duration), nil
+		},
+		devops.LabelDoubleGroupby: func(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+			if p.Metrics < 1 || p.Metrics > devops.GetCPUMetricsLen() {
+				return nil, fmt.Errorf("metrics must be between 1 and %d", devops.GetCPUMetricsLen())
+			}
+			return devops.NewGroupBy(p.Metrics), nil
+		},
+		devops.LabelHighCPU: func(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+			if p.Hosts < 0 {
+				return nil, fmt.Errorf("hosts cannot be negative")
+			}
+			return devops.NewHighCPU(p.Hosts), nil
+		},
+	},
+}
+
 var conf = &config.QueryGeneratorConfig{}
 
 // Parse args:
 func init() {
 	useCaseMatrix["cpu-only"] = useCaseMatrix["devops"]
+	parameterizedMatrix["cpu-only"] = parameterizedMatrix["devops"]
 	// Change the Usage function to print the use case matrix of choices:
 	oldUsage := pflag.Usage
 	pflag.Usage = func() {
@@ -166,6 +201,7 @@ func init() {
 
 func main() {
 	qg := inputs.NewQueryGenerator(useCaseMatrix)
+	qg.ParameterizedMatrix = parameterizedMatrix
 	err := qg.Generate(conf)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
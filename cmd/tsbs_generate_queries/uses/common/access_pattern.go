@@ -0,0 +1,227 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	internalutils "github.com/timescale/tsbs/internal/utils"
+)
+
+// Distributions for placing the time windows of queries.
+const (
+	// WindowUniform places windows uniformly in the dataset time range.
+	WindowUniform = "uniform"
+	// WindowRecent places windows towards the end of the dataset, like
+	// dashboards querying "now minus N".
+	WindowRecent = "recent"
+	// WindowZipf places windows in window sized slots counted back from the
+	// end of the dataset, with Zipf distributed slot ranks.
+	WindowZipf = "zipf"
+)
+
+// Distributions for selecting the hosts (or other devices) of queries.
+const (
+	// DeviceUniform selects devices uniformly.
+	DeviceUniform = "uniform"
+	// DeviceZipf selects devices with Zipf distributed ranks, device 0 being the hottest.
+	DeviceZipf = "zipf"
+	// DeviceHotSet selects devices from a hot subset with a given probability.
+	DeviceHotSet = "hot-set"
+)
+
+const (
+	errUnknownWindowDistFmt = "unknown window distribution '%s', choices: %s, %s, %s"
+	errUnknownDeviceDistFmt = "unknown host distribution '%s', choices: %s, %s, %s"
+	errSkewNotPositive      = "skew must be positive"
+	errHotSetFraction       = "hot set fraction must be in (0, 1]"
+	errHotSetProbability    = "hot set probability must be in [0, 1]"
+)
+
+// AccessPattern describes how the time windows and devices of queries are
+// picked. The zero value picks both uniformly.
+type AccessPattern struct {
+	// WindowDistribution is one of WindowUniform, WindowRecent or WindowZipf.
+	WindowDistribution string
+	// WindowSkew controls how strongly windows are biased towards the end of
+	// the dataset. For WindowRecent the distance of a window to the end is
+	// the range times u^(1+WindowSkew), u being uniform in [0, 1). For
+	// WindowZipf it is the exponent of the distribution.
+	WindowSkew float64
+
+	// DeviceDistribution is one of DeviceUniform, DeviceZipf or DeviceHotSet.
+	DeviceDistribution string
+	// DeviceSkew is the exponent of the DeviceZipf distribution.
+	DeviceSkew float64
+	// HotSetFraction is the share of devices in the hot set for DeviceHotSet.
+	HotSetFraction float64
+	// HotSetProbability is the probability of picking a device of the hot set
+	// for DeviceHotSet, any device is picked otherwise.
+	HotSetProbability float64
+}
+
+// Validate checks that the AccessPattern is complete and fills in the
+// uniform defaults.
+func (a *AccessPattern) Validate() error {
+	switch a.WindowDistribution {
+	case "":
+		a.WindowDistribution = WindowUniform
+	case WindowUniform:
+	case WindowRecent, WindowZipf:
+		if a.WindowSkew <= 0 {
+			return fmt.Errorf("window %s", errSkewNotPositive)
+		}
+	default:
+		return fmt.Errorf(errUnknownWindowDistFmt, a.WindowDistribution, WindowUniform, WindowRecent, WindowZipf)
+	}
+
+	switch a.DeviceDistribution {
+	case "":
+		a.DeviceDistribution = DeviceUniform
+	case DeviceUniform:
+	case DeviceZipf:
+		if a.DeviceSkew <= 0 {
+			return fmt.Errorf("host %s", errSkewNotPositive)
+		}
+	case DeviceHotSet:
+		if a.HotSetFraction <= 0 || a.HotSetFraction > 1 {
+			return fmt.Errorf(errHotSetFraction)
+		}
+		if a.HotSetProbability < 0 || a.HotSetProbability > 1 {
+			return fmt.Errorf(errHotSetProbability)
+		}
+	default:
+		return fmt.Errorf(errUnknownDeviceDistFmt, a.DeviceDistribution, DeviceUniform, DeviceZipf, DeviceHotSet)
+	}
+	return nil
+}
+
+// AccessPatternSetter is implemented by query generators embedding a Core.
+type AccessPatternSetter interface {
+	SetAccessPattern(AccessPattern) error
+}
+
+// SetAccessPattern validates and sets the AccessPattern used by MustRandWindow
+// and GetRandomSubset.
+func (c *Core) SetAccessPattern(a AccessPattern) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	c.access = a
+	c.zipfCDFs = nil
+	return nil
+}
+
+// MustRandWindow returns a TimeInterval of duration window within the dataset,
+// placed according to the window distribution of the AccessPattern. It panics
+// if the window does not fit in the dataset.
+func (c *Core) MustRandWindow(window time.Duration) *internalutils.TimeInterval {
+	switch c.access.WindowDistribution {
+	case WindowRecent, WindowZipf:
+	default:
+		return c.Interval.MustRandWindow(window)
+	}
+
+	lower := c.Interval.StartUnixNano()
+	upper := c.Interval.End().Add(-window).UnixNano()
+	if upper <= lower {
+		// let the uniform placement report the error
+		return c.Interval.MustRandWindow(window)
+	}
+
+	var offset int64
+	if c.access.WindowDistribution == WindowRecent {
+		offset = int64(float64(upper-lower) * math.Pow(rand.Float64(), 1+c.access.WindowSkew))
+	} else {
+		slots := int((upper-lower)/window.Nanoseconds()) + 1
+		rank := c.zipfRank(slots, c.access.WindowSkew)
+		offset = int64(rank)*window.Nanoseconds() + rand.Int63n(window.Nanoseconds())
+	}
+	if offset > upper-lower {
+		offset = upper - lower
+	}
+
+	start := upper - offset
+	res, err := internalutils.NewTimeInterval(time.Unix(0, start), time.Unix(0, start+window.Nanoseconds()))
+	if err != nil {
+		panic(err.Error())
+	}
+	return res
+}
+
+// GetRandomSubset returns numItems distinct numbers from 0 to totalItems,
+// picked according to the device distribution of the AccessPattern.
+func (c *Core) GetRandomSubset(numItems int, totalItems int) ([]int, error) {
+	if numItems > totalItems {
+		return nil, fmt.Errorf(errMoreItemsThanScale)
+	}
+
+	var next func(seen map[int]bool) int
+	switch c.access.DeviceDistribution {
+	case DeviceZipf:
+		next = func(map[int]bool) int {
+			return c.zipfRank(totalItems, c.access.DeviceSkew)
+		}
+	case DeviceHotSet:
+		hot := int(math.Ceil(c.access.HotSetFraction * float64(totalItems)))
+		next = func(seen map[int]bool) int {
+			// once the hot set is used up fall back to all devices
+			if len(seen) < hot && rand.Float64() < c.access.HotSetProbability {
+				return rand.Intn(hot)
+			}
+			return rand.Intn(totalItems)
+		}
+	default:
+		return GetRandomSubsetPerm(numItems, totalItems)
+	}
+
+	seen := make(map[int]bool, numItems)
+	res := make([]int, 0, numItems)
+	for len(res) < numItems {
+		n := next(seen)
+		// a long tail makes the remaining items unlikely to be drawn, so
+		// complete the subset uniformly after too many duplicates
+		for tries := 0; seen[n]; tries++ {
+			if tries < 100 {
+				n = next(seen)
+			} else {
+				n = rand.Intn(totalItems)
+			}
+		}
+		seen[n] = true
+		res = append(res, n)
+	}
+	return res, nil
+}
+
+type zipfKey struct {
+	n int
+	s float64
+}
+
+// zipfRank returns a rank in [0, n) with probability proportional to
+// 1/(rank+1)^s. The cumulative distributions are cached per n and s.
+func (c *Core) zipfRank(n int, s float64) int {
+	if c.zipfCDFs == nil {
+		c.zipfCDFs = make(map[zipfKey][]float64)
+	}
+	key := zipfKey{n, s}
+	cdf, ok := c.zipfCDFs[key]
+	if !ok {
+		cdf = make([]float64, n)
+		sum := 0.0
+		for i := range cdf {
+			sum += 1 / math.Pow(float64(i+1), s)
+			cdf[i] = sum
+		}
+		c.zipfCDFs[key] = cdf
+	}
+	r := rand.Float64() * cdf[n-1]
+	rank := sort.SearchFloat64s(cdf, r)
+	if rank < n-1 && cdf[rank] == r {
+		rank++
+	}
+	return rank
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestAccessPatternValidate(t *testing.T) {
+	cases := []struct {
+		desc    string
+		access  AccessPattern
+		wantErr bool
+	}{
+		{desc: "zero value", access: AccessPattern{}},
+		{desc: "recent", access: AccessPattern{WindowDistribution: WindowRecent, WindowSkew: 1}},
+		{desc: "recent without skew", access: AccessPattern{WindowDistribution: WindowRecent}, wantErr: true},
+		{desc: "unknown window", access: AccessPattern{WindowDistribution: "foo"}, wantErr: true},
+		{desc: "zipf hosts", access: AccessPattern{DeviceDistribution: DeviceZipf, DeviceSkew: 1.2}},
+		{desc: "zipf hosts without skew", access: AccessPattern{DeviceDistribution: DeviceZipf}, wantErr: true},
+		{desc: "hot set", access: AccessPattern{DeviceDistribution: DeviceHotSet, HotSetFraction: 0.1, HotSetProbability: 0.9}},
+		{desc: "empty hot set", access: AccessPattern{DeviceDistribution: DeviceHotSet, HotSetProbability: 0.9}, wantErr: true},
+		{desc: "hot set probability", access: AccessPattern{DeviceDistribution: DeviceHotSet, HotSetFraction: 0.1, HotSetProbability: 2}, wantErr: true},
+		{desc: "unknown host", access: AccessPattern{DeviceDistribution: "foo"}, wantErr: true},
+	}
+	for _, c := range cases {
+		err := c.access.Validate()
+		if c.wantErr && err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if !c.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+	}
+
+	a := AccessPattern{}
+	a.Validate()
+	if a.WindowDistribution != WindowUniform || a.DeviceDistribution != DeviceUniform {
+		t.Errorf("zero value not defaulted to uniform: %+v", a)
+	}
+}
+
+func TestCoreMustRandWindow(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(30 * 24 * time.Hour)
+	window := time.Hour
+
+	// share of windows starting in the last 10% of the dataset
+	recentShare := func(access AccessPattern) float64 {
+		rand.Seed(123)
+		c, err := NewCore(start, end, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SetAccessPattern(access); err != nil {
+			t.Fatal(err)
+		}
+		threshold := end.Add(-3 * 24 * time.Hour)
+		recent := 0
+		const n = 10000
+		for i := 0; i < n; i++ {
+			w := c.MustRandWindow(window)
+			if w.Start().Before(start) || w.End().After(end) {
+				t.Fatalf("window %s - %s outside of dataset", w.StartString(), w.EndString())
+			}
+			if w.Duration() != window {
+				t.Fatalf("incorrect window duration: got %v want %v", w.Duration(), window)
+			}
+			if !w.Start().Before(threshold) {
+				recent++
+			}
+		}
+		return float64(recent) / n
+	}
+
+	uniform := recentShare(AccessPattern{})
+	if uniform < 0.05 || uniform > 0.15 {
+		t.Errorf("uniform windows not uniform: %.2f in the last 10%%", uniform)
+	}
+	recent := recentShare(AccessPattern{WindowDistribution: WindowRecent, WindowSkew: 2})
+	if recent < 0.4 {
+		t.Errorf("recent windows not biased: %.2f in the last 10%%", recent)
+	}
+	zipf := recentShare(AccessPattern{WindowDistribution: WindowZipf, WindowSkew: 1})
+	if zipf < 0.4 {
+		t.Errorf("zipf windows not biased: %.2f in the last 10%%", zipf)
+	}
+}
+
+func TestCoreGetRandomSubset(t *testing.T) {
+	const scale = 100
+	counts := func(access AccessPattern, numItems int) []int {
+		rand.Seed(123)
+		c, err := NewCore(time.Now(), time.Now(), scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.SetAccessPattern(access); err != nil {
+			t.Fatal(err)
+		}
+		res := make([]int, scale)
+		for i := 0; i < 1000; i++ {
+			subset, err := c.GetRandomSubset(numItems, scale)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(subset) != numItems {
+				t.Fatalf("incorrect subset length: got %d want %d", len(subset), numItems)
+			}
+			seen := map[int]bool{}
+			for _, n := range subset {
+				if seen[n] {
+					t.Fatalf("duplicate item %d in %v", n, subset)
+				}
+				seen[n] = true
+				res[n]++
+			}
+		}
+		return res
+	}
+
+	zipf := counts(AccessPattern{DeviceDistribution: DeviceZipf, DeviceSkew: 1.5}, 1)
+	if zipf[0] < zipf[1] || zipf[1] < zipf[10] || zipf[0] < 300 {
+		t.Errorf("zipf hosts not skewed: %v", zipf[:11])
+	}
+
+	hot := counts(AccessPattern{DeviceDistribution: DeviceHotSet, HotSetFraction: 0.1, HotSetProbability: 0.9}, 1)
+	inHotSet := 0
+	for _, c := range hot[:10] {
+		inHotSet += c
+	}
+	if inHotSet < 850 {
+		t.Errorf("hot set queried %d times out of 1000, want ~910", inHotSet)
+	}
+
+	// subsets larger than the hot set and the whole set must still complete
+	counts(AccessPattern{DeviceDistribution: DeviceHotSet, HotSetFraction: 0.1, HotSetProbability: 1}, 20)
+	counts(AccessPattern{DeviceDistribution: DeviceZipf, DeviceSkew: 3}, scale)
+
+	c, _ := NewCore(time.Now(), time.Now(), scale)
+	if _, err := c.GetRandomSubset(scale+1, scale); err == nil {
+		t.Errorf("unexpected lack of error for subset larger than scale")
+	}
+}
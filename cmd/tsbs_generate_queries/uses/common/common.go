@@ -21,6 +21,10 @@ type Core struct {
 
 	// Scale is the cardinality of the dataset in terms of devices/hosts
 	Scale int
+
+	// access is how time windows and devices are picked, uniform by default
+	access   AccessPattern
+	zipfCDFs map[zipfKey][]float64
 }
 
 // NewCore returns a new Core for the given time range and cardinality
@@ -50,9 +50,10 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 
 }
 
-// GetRandomHosts returns a random set of nHosts from a given Core
+// GetRandomHosts returns a random set of nHosts from a given Core, picked
+// according to its access pattern
 func (d *Core) GetRandomHosts(nHosts int) ([]string, error) {
-	return getRandomHosts(nHosts, d.Scale)
+	return getHosts(nHosts, d.Scale, d.GetRandomSubset)
 }
 
 // cpuMetrics is the list of metric names for CPU
@@ -143,10 +144,9 @@ func GetMaxAllLabel(dbName string, nHosts int) string {
 	return fmt.Sprintf("%s max of all CPU metrics, random %4d hosts, random %s by 1h", dbName, nHosts, MaxAllDuration)
 }
 
-// getRandomHosts returns a subset of numHosts hostnames of a permutation of hostnames,
-// numbered from 0 to totalHosts.
-// Ex.: host_12, host_7, host_25 for numHosts=3 and totalHosts=30 (3 out of 30)
-func getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
+// getHosts returns the hostnames of numHosts numbers from 0 to totalHosts
+// picked by subset.
+func getHosts(numHosts int, totalHosts int, subset func(int, int) ([]int, error)) ([]string, error) {
 	if numHosts < 1 {
 		return nil, fmt.Errorf("number of hosts cannot be < 1; got %d", numHosts)
 	}
@@ -154,7 +154,7 @@ func getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
 		return nil, fmt.Errorf("number of hosts (%d) larger than total hosts. See --scale (%d)", numHosts, totalHosts)
 	}
 
-	randomNumbers, err := common.GetRandomSubsetPerm(numHosts, totalHosts)
+	randomNumbers, err := subset(numHosts, totalHosts)
 	if err != nil {
 		return nil, err
 	}
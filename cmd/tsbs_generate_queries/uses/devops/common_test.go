@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
 	"github.com/timescale/tsbs/internal/utils"
 )
 
@@ -57,9 +58,9 @@ func TestCoreGetRandomHosts(t *testing.T) {
 	coreHosts := strings.Join(hosts, ",")
 
 	rand.Seed(100) // Resetting seed to get a deterministic output.
-	hosts, err = getRandomHosts(n, scale)
+	hosts, err = getHosts(n, scale, common.GetRandomSubsetPerm)
 	if err != nil {
-		t.Fatalf("unexpected error for getRandomHosts: %v", err)
+		t.Fatalf("unexpected error for getHosts: %v", err)
 	}
 	randomHosts := strings.Join(hosts, ",")
 
@@ -195,7 +196,7 @@ func TestGetRandomHosts(t *testing.T) {
 	for _, c := range cases {
 		rand.Seed(100) // always reset the random number generator
 		if c.shouldErr {
-			hosts, err := getRandomHosts(c.nHosts, c.scale)
+			hosts, err := getHosts(c.nHosts, c.scale, common.GetRandomSubsetPerm)
 			if hosts != nil {
 				t.Errorf("%s: errored but with non-nil return: %v", c.desc, hosts)
 			}
@@ -203,7 +204,7 @@ func TestGetRandomHosts(t *testing.T) {
 				t.Errorf("%s: incorrect error:\ngot\n%s\nwant\n%s", c.desc, got, c.errMsg)
 			}
 		} else {
-			hosts, err := getRandomHosts(c.nHosts, c.scale)
+			hosts, err := getHosts(c.nHosts, c.scale, common.GetRandomSubsetPerm)
 			if err != nil {
 				t.Fatalf("%s: unexpected error: got %v", c.desc, err)
 			} else if got := strings.Join(hosts, ","); got != c.want {
@@ -1,6 +1,10 @@
 package utils
 
-import "github.com/timescale/tsbs/pkg/query"
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
 
 // QueryGenerator is an interface that a database-specific implementation of a
 // use case implements to set basic configuration that can then be used by
@@ -18,3 +22,17 @@ type QueryFiller interface {
 
 // QueryFillerMaker is a function that takes a QueryGenerator and returns a QueryFiller
 type QueryFillerMaker func(QueryGenerator) QueryFiller
+
+// QueryParams are the parameters a workload file can set for a query type,
+// instead of using one of the fixed variants in the use case matrix. Only the
+// parameters meaningful for the query type are used.
+type QueryParams struct {
+	Metrics  int           `yaml:"metrics"`
+	Hosts    int           `yaml:"hosts"`
+	Hours    int           `yaml:"hours"`
+	Duration time.Duration `yaml:"duration"`
+}
+
+// ParameterizedQueryFillerMaker returns the QueryFillerMaker for a query type
+// with the given parameters, or an error if they are not valid for it.
+type ParameterizedQueryFillerMaker func(QueryParams) (QueryFillerMaker, error)
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/load/distributed"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+var agentCoordinatorAddr string
+
+func initAgentCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Load a part of the data of a coordinated load",
+		Long: "Load a part of the data of a coordinated load.\n" +
+			"The agent connects to a coordinator started with 'tsbs_load coordinator', and\n" +
+			"loads the part of the data it is assigned with the config of the coordinator.",
+		Run: runAgent,
+	}
+	cmd.Flags().StringVar(&agentCoordinatorAddr, "coordinator", "localhost"+defaultCoordinatorAddr, "Address of the coordinator")
+	return cmd
+}
+
+func runAgent(*cobra.Command, []string) {
+	agent, err := distributed.Dial(agentCoordinatorAddr)
+	if err != nil {
+		panic(err)
+	}
+	defer agent.Close()
+	assignment, err := agent.Register()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("agent %d of %d loading into %s\n", assignment.Shard, assignment.NumShards, assignment.Target)
+
+	v := viper.New()
+	if err := v.MergeConfigMap(agentSettings(assignment)); err != nil {
+		panic(fmt.Errorf("could not read the config of the coordinator: %v", err))
+	}
+	if assignment.Shard > 0 {
+		if err := agent.WaitDB(); err != nil {
+			panic(err)
+		}
+	}
+
+	target := initializers.GetTarget(assignment.Target)
+	bench, runnerConfig, err := parseBenchmarkConfig(target, v)
+	if err != nil {
+		panic(err)
+	}
+	runnerConfig.ReportPrefix = fmt.Sprintf("[agent %d] ", assignment.Shard)
+	runner, ok := load.GetBenchmarkRunner(*runnerConfig).(load.CoordinatedRunner)
+	if !ok {
+		panic(fmt.Errorf("the runner of %s cannot be coordinated", assignment.Target))
+	}
+	runner.SetCoordinator(agent)
+	runner.RunBenchmark(bench)
+}
+
+// agentSettings returns the settings of the coordinator restricted to the
+// part of the data of the agent. Only the agent of shard 0 creates the
+// database.
+func agentSettings(a *distributed.Assignment) map[string]interface{} {
+	settings := a.Settings
+	dataSource, _ := settings["data-source"].(map[string]interface{})
+	if dataSource["type"] == source.FileDataSourceType {
+		setSetting(settings, "data-source.file.shard", a.Shard)
+		setSetting(settings, "data-source.file.shards", a.NumShards)
+	} else {
+		setSetting(settings, "data-source.simulator.interleaved-generation-group-id", a.Shard)
+		setSetting(settings, "data-source.simulator.interleaved-generation-groups", a.NumShards)
+	}
+	if a.Shard > 0 {
+		setSetting(settings, "loader.runner.do-create-db", false)
+		setSetting(settings, "loader.runner.do-abort-on-exist", false)
+	}
+	return settings
+}
@@ -16,19 +16,40 @@ type LoaderConfig struct {
 }
 
 type RunnerConfig struct {
-	DBName          string `yaml:"db-name" mapstructure:"db-name"`
-	BatchSize       uint   `yaml:"batch-size" mapstructure:"batch-size"`
-	Workers         uint
-	Limit           uint64
-	DoLoad          bool          `yaml:"do-load" mapstructure:"do-load"`
-	DoCreateDB      bool          `yaml:"do-create-db" mapstructure:"do-create-db"`
-	DoAbortOnExist  bool          `yaml:"do-abort-on-exist" mapstructure:"do-abort-on-exist"`
-	ReportingPeriod time.Duration `yaml:"reporting-period" mapstructure:"reporting-period"`
-	Seed            int64
-	HashWorkers     bool   `yaml:"hash-workers" mapstructure:"hash-workers"`
-	InsertIntervals string `yaml:"insert-intervals" mapstructure:"insert-intervals"`
-	FlowControl     bool   `yaml:"flow-control" mapstructure:"flow-control"`
-	ChannelCapacity uint   `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	DBName             string `yaml:"db-name" mapstructure:"db-name"`
+	BatchSize          uint   `yaml:"batch-size" mapstructure:"batch-size"`
+	Workers            uint
+	Limit              uint64
+	DoLoad             bool          `yaml:"do-load" mapstructure:"do-load"`
+	DoCreateDB         bool          `yaml:"do-create-db" mapstructure:"do-create-db"`
+	DoAbortOnExist     bool          `yaml:"do-abort-on-exist" mapstructure:"do-abort-on-exist"`
+	ReportingPeriod    time.Duration `yaml:"reporting-period" mapstructure:"reporting-period"`
+	Seed               int64
+	HashWorkers        bool          `yaml:"hash-workers" mapstructure:"hash-workers"`
+	InsertIntervals    string        `yaml:"insert-intervals" mapstructure:"insert-intervals"`
+	FlowControl        bool          `yaml:"flow-control" mapstructure:"flow-control"`
+	ChannelCapacity    uint          `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	BatchBySeries      bool          `yaml:"batch-by-series" mapstructure:"batch-by-series"`
+	BatchMaxAge        time.Duration `yaml:"batch-max-age" mapstructure:"batch-max-age"`
+	BatchMaxSeries     uint          `yaml:"batch-max-series" mapstructure:"batch-max-series"`
+	IngestRate         string        `yaml:"ingest-rate" mapstructure:"ingest-rate"`
+	IngestRateUnit     string        `yaml:"ingest-rate-unit" mapstructure:"ingest-rate-unit"`
+	AutoTune           bool          `yaml:"auto-tune" mapstructure:"auto-tune"`
+	AutoTuneWorkers    string        `yaml:"auto-tune-workers" mapstructure:"auto-tune-workers"`
+	AutoTuneBatchSizes string        `yaml:"auto-tune-batch-sizes" mapstructure:"auto-tune-batch-sizes"`
+	AutoTuneRates      string        `yaml:"auto-tune-rates" mapstructure:"auto-tune-rates"`
+	AutoTuneProbe      time.Duration `yaml:"auto-tune-probe" mapstructure:"auto-tune-probe"`
+	AutoTunePlateau    float64       `yaml:"auto-tune-plateau" mapstructure:"auto-tune-plateau"`
+	AutoTuneMaxLatency time.Duration `yaml:"auto-tune-max-latency" mapstructure:"auto-tune-max-latency"`
+	CheckpointFile     string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file"`
+	CheckpointPeriod   time.Duration `yaml:"checkpoint-period" mapstructure:"checkpoint-period"`
+	ResumeFrom         string        `yaml:"resume-from" mapstructure:"resume-from"`
+	MemStats           bool          `yaml:"mem-stats" mapstructure:"mem-stats"`
+	ProfileDir         string        `yaml:"profile-dir" mapstructure:"profile-dir"`
+	Profiles           string        `yaml:"profiles" mapstructure:"profiles"`
+	ProfileWindows     string        `yaml:"profile-windows" mapstructure:"profile-windows"`
+	ClientStats        bool          `yaml:"client-stats" mapstructure:"client-stats"`
+	ServerStats        bool          `yaml:"server-stats" mapstructure:"server-stats"`
 }
 
 type DataSourceConfig struct {
@@ -39,6 +60,9 @@ type DataSourceConfig struct {
 
 type FileDataSourceConfig struct {
 	Location string `yaml:"location"`
+	// Shard and Shards select every Shards-th file of Location from Shard
+	Shard  uint `yaml:"shard,omitempty"`
+	Shards uint `yaml:"shards,omitempty"`
 }
 
 type SimulatorDataSourceConfig struct {
@@ -51,4 +75,26 @@ type SimulatorDataSourceConfig struct {
 	Limit                 uint64        `yaml:"max-data-points" mapstructure:"max-data-points"`
 	LogInterval           time.Duration `yaml:"log-interval" mapstructure:"log-interval"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	// InterleavedGroupID and InterleavedNumGroups select every
+	// InterleavedNumGroups-th point from InterleavedGroupID
+	InterleavedGroupID   uint `yaml:"interleaved-generation-group-id,omitempty" mapstructure:"interleaved-generation-group-id"`
+	InterleavedNumGroups uint `yaml:"interleaved-generation-groups,omitempty" mapstructure:"interleaved-generation-groups"`
+}
+
+// FanOutConfig lists the targets the fanout command loads into.
+type FanOutConfig struct {
+	// BufferSize is the number of points buffered per target when the data
+	// source is shared
+	BufferSize uint `yaml:"buffer-size" mapstructure:"buffer-size"`
+	Targets    []FanOutTargetConfig
+}
+
+type FanOutTargetConfig struct {
+	// Name identifies the target in the reports
+	Name   string
+	Target string
+	// DBName overrides loader.runner.db-name for this target
+	DBName      string                 `yaml:"db-name,omitempty" mapstructure:"db-name"`
+	ResultsFile string                 `yaml:"results-file,omitempty" mapstructure:"results-file"`
+	DBSpecific  map[string]interface{} `yaml:"db-specific" mapstructure:"db-specific"`
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load/distributed"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+const defaultCoordinatorAddr = ":7070"
+
+var (
+	coordinatorAgents      uint
+	coordinatorListen      string
+	coordinatorResultsFile string
+	// coordinatorLoadFlags are bound only when the coordinator command is
+	// executed, so they don't shadow the flags of the load command
+	coordinatorLoadFlags *pflag.FlagSet
+)
+
+func initCoordinatorCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "coordinator",
+		Short: "Coordinate the loads of several agents into a specified target database",
+		Long: "Coordinate the loads of several agents into a specified target database.\n" +
+			"The coordinator waits for --agents agents started with 'tsbs_load agent' and sends\n" +
+			"them its config. Each agent loads one interleaved generation group of a SIMULATOR\n" +
+			"data source, or one shard of the files of a FILE data source. The first agent\n" +
+			"creates the database, then all the agents start loading together. The coordinator\n" +
+			"reports their aggregate stats and merges their results.",
+		PersistentPreRun: initViperConfig,
+	}
+	coordinatorLoadFlags = loadCmdFlags()
+	cmd.PersistentFlags().AddFlagSet(coordinatorLoadFlags)
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+	cmd.PersistentFlags().UintVar(&coordinatorAgents, "agents", 2, "Number of agents loading the data")
+	cmd.PersistentFlags().StringVar(&coordinatorListen, "listen", defaultCoordinatorAddr, "Address to listen on for the agents")
+	cmd.PersistentFlags().StringVar(&coordinatorResultsFile, "results-file", "", "Write the merged results of the agents to this file")
+
+	allFormats := constants.SupportedFormats()
+	for _, format := range allFormats {
+		target := initializers.GetTarget(format)
+		subCmd := &cobra.Command{
+			Use:   format,
+			Short: "Coordinate the loads of several agents into " + format + " as a target db",
+			Run:   createRunCoordinator(target),
+		}
+		target.TargetSpecificFlags("loader.db-specific.", subCmd.PersistentFlags())
+		cmd.AddCommand(subCmd)
+	}
+	return cmd
+}
+
+func createRunCoordinator(target targets.ImplementedTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		if err := viper.BindPFlags(coordinatorLoadFlags); err != nil {
+			panic(fmt.Errorf("could not bind flags to configuration: %v", err))
+		}
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
+		}
+		settings, err := coordinatedSettings(viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+
+		coordinator, err := distributed.NewCoordinator(
+			coordinatorListen, coordinatorAgents, target.TargetName(), settings,
+			viper.GetDuration("loader.runner.reporting-period"),
+		)
+		if err != nil {
+			panic(err)
+		}
+		defer coordinator.Close()
+		fmt.Printf("waiting for %d agents on %s\n", coordinatorAgents, coordinator.Addr())
+
+		result, err := coordinator.Wait()
+		if err != nil {
+			panic(fmt.Errorf("coordinated load failed: %v", err))
+		}
+		if coordinatorResultsFile == "" {
+			return
+		}
+		fmt.Printf("Saving results json file to %s\n", coordinatorResultsFile)
+		file, err := json.MarshalIndent(result, "", " ")
+		if err != nil {
+			panic(err)
+		}
+		if err := ioutil.WriteFile(coordinatorResultsFile, file, 0644); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// coordinatedSettings checks the config of the coordinated load and returns
+// the settings to send to the agents.
+func coordinatedSettings(v *viper.Viper) (map[string]interface{}, error) {
+	dataSourceViper := v.Sub("data-source")
+	if dataSourceViper == nil {
+		return nil, fmt.Errorf("config file didn't have a top-level 'data-source' object")
+	}
+	dataSource, err := parseDataSourceConfig(dataSourceViper)
+	if err != nil {
+		return nil, err
+	}
+	if v.GetBool("loader.runner.auto-tune") || v.GetString("loader.runner.checkpoint-file") != "" ||
+		v.GetString("loader.runner.resume-from") != "" {
+		return nil, fmt.Errorf("auto-tune, checkpoint-file and resume-from cannot be used in a coordinated load")
+	}
+
+	settings := v.AllSettings()
+	if dataSource.Type == source.SimulatorDataSourceType && dataSource.Simulator.Seed == 0 {
+		// all the agents must generate the same points to split them
+		setSetting(settings, "data-source.simulator.seed", time.Now().Unix())
+	}
+	return settings, nil
+}
+
+// setSetting sets the value of a dotted key in nested settings maps.
+func setSetting(settings map[string]interface{}, key string, value interface{}) {
+	path := strings.Split(key, ".")
+	m := settings
+	for _, k := range path[:len(path)-1] {
+		sub, ok := m[k].(map[string]interface{})
+		if !ok {
+			sub = make(map[string]interface{})
+			m[k] = sub
+		}
+		m = sub
+	}
+	m[path[len(path)-1]] = value
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+const defaultFanOutBufferSize = 10000
+
+func initFanOutCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fanout",
+		Short: "Load the same data into several target databases in parallel",
+		Long: "Load the same data into several target databases in parallel.\n" +
+			"The targets are listed under 'fan-out.targets' in the config file, each with a\n" +
+			"name, a target type and its db-specific config. With a SIMULATOR data source the\n" +
+			"data is generated once and every point is sent to all targets.",
+		PersistentPreRun: initViperConfig,
+		Run:              runFanOut,
+	}
+	cmd.PersistentFlags().AddFlagSet(loadCmdFlags())
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+	return cmd
+}
+
+type fanOutTarget struct {
+	benchmark targets.Benchmark
+	runner    load.BenchmarkRunner
+}
+
+func runFanOut(cmd *cobra.Command, _ []string) {
+	// bind the flags only when this command is executed, so they don't
+	// shadow the flags of the load command
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		panic(fmt.Errorf("could not bind flags to configuration: %v", err))
+	}
+	fanOutTargets, err := parseFanOutConfig(viper.GetViper())
+	if err != nil {
+		panic(err)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, t := range fanOutTargets {
+		wg.Add(1)
+		go func(t *fanOutTarget) {
+			defer wg.Done()
+			t.runner.RunBenchmark(t.benchmark)
+		}(t)
+	}
+	wg.Wait()
+}
+
+// parseFanOutConfig creates a Benchmark and a BenchmarkRunner for each target
+// listed under fan-out.targets. The runners share the loader.runner config,
+// except for the db name and results file which can be set per target.
+func parseFanOutConfig(v *viper.Viper) ([]*fanOutTarget, error) {
+	dataSourceViper := v.Sub("data-source")
+	if dataSourceViper == nil {
+		return nil, fmt.Errorf("config file didn't have a top-level 'data-source' object")
+	}
+	dataSource, err := parseDataSourceConfig(dataSourceViper)
+	if err != nil {
+		return nil, err
+	}
+
+	runnerViper := v.Sub("loader.runner")
+	if runnerViper == nil {
+		return nil, fmt.Errorf("config file didn't have loader.runner specified")
+	}
+	runnerConfig, err := parseRunnerConfig(runnerViper)
+	if err != nil {
+		return nil, err
+	}
+
+	fanOutViper := v.Sub("fan-out")
+	if fanOutViper == nil {
+		return nil, fmt.Errorf("config file didn't have a top-level 'fan-out' object")
+	}
+	fanOutConfig, err := parseFanOutTargetsConfig(fanOutViper)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateFanOutDataSource(dataSource, fanOutConfig); err != nil {
+		return nil, err
+	}
+
+	var shared []common.Simulator
+	if dataSource.Type == source.SimulatorDataSourceType {
+		// the simulator doesn't depend on the format, any target will do
+		sim, err := (&inputs.DataGenerator{}).CreateSimulator(
+			convertDataSourceConfigToInternalRepresentation(fanOutConfig.Targets[0].Target, dataSource).Simulator,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shared = common.NewTeeSimulators(sim, len(fanOutConfig.Targets), int(fanOutConfig.BufferSize))
+	}
+
+	result := make([]*fanOutTarget, len(fanOutConfig.Targets))
+	for i, tc := range fanOutConfig.Targets {
+		target := initializers.GetTarget(tc.Target)
+		dataSourceInternal := convertDataSourceConfigToInternalRepresentation(target.TargetName(), dataSource)
+		if shared != nil {
+			dataSourceInternal.Simulator.SharedSimulator = shared[i]
+		}
+
+		runnerConfigInternal := convertRunnerConfigToInternalRep(runnerConfig)
+		runnerConfigInternal.ReportPrefix = "[" + tc.Name + "] "
+		runnerConfigInternal.ResultsFile = tc.ResultsFile
+		if tc.DBName != "" {
+			runnerConfigInternal.DBName = tc.DBName
+		}
+
+		dbSpecificViper, err := fanOutDBSpecificViper(target, tc.DBSpecific)
+		if err != nil {
+			return nil, fmt.Errorf("target '%s': %v", tc.Name, err)
+		}
+		benchmark, err := target.Benchmark(runnerConfigInternal.DBName, dataSourceInternal, dbSpecificViper)
+		if err != nil {
+			return nil, fmt.Errorf("target '%s': %v", tc.Name, err)
+		}
+		result[i] = &fanOutTarget{
+			benchmark: benchmark,
+			runner:    load.GetBenchmarkRunner(*runnerConfigInternal),
+		}
+	}
+	return result, nil
+}
+
+func parseFanOutTargetsConfig(v *viper.Viper) (*FanOutConfig, error) {
+	var conf FanOutConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	if len(conf.Targets) == 0 {
+		return nil, fmt.Errorf("fan-out.targets must list at least one target")
+	}
+	if conf.BufferSize == 0 {
+		conf.BufferSize = defaultFanOutBufferSize
+	}
+	names := make(map[string]bool, len(conf.Targets))
+	for i, t := range conf.Targets {
+		if t.Name == "" {
+			return nil, fmt.Errorf("fan-out target %d has no name", i)
+		}
+		if names[t.Name] {
+			return nil, fmt.Errorf("fan-out target name '%s' is used more than once", t.Name)
+		}
+		names[t.Name] = true
+		if !isSupportedFormat(t.Target) {
+			return nil, fmt.Errorf(
+				"fan-out target '%s': unknown target type '%s', supported: %v", t.Name, t.Target, constants.SupportedFormats(),
+			)
+		}
+	}
+	return &conf, nil
+}
+
+func isSupportedFormat(format string) bool {
+	for _, f := range constants.SupportedFormats() {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFanOutDataSource checks that every target can read the data source.
+// Files are in a target specific format, so they can only be shared between
+// targets of the same type.
+func validateFanOutDataSource(dataSource *DataSourceConfig, conf *FanOutConfig) error {
+	if dataSource.Type != source.FileDataSourceType {
+		return nil
+	}
+	for _, t := range conf.Targets[1:] {
+		if t.Target != conf.Targets[0].Target {
+			return fmt.Errorf(
+				"data source %s can only be fanned out to targets of the same type, got '%s' and '%s'",
+				source.FileDataSourceType, conf.Targets[0].Target, t.Target,
+			)
+		}
+	}
+	return nil
+}
+
+// fanOutDBSpecificViper returns a viper holding the db-specific config of a
+// target, using the target specific flag defaults for any missing values.
+func fanOutDBSpecificViper(target targets.ImplementedTarget, conf map[string]interface{}) (*viper.Viper, error) {
+	v := viper.New()
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	target.TargetSpecificFlags("", fs)
+	if err := v.BindPFlags(fs); err != nil {
+		return nil, err
+	}
+	if err := v.MergeConfigMap(conf); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/profiling"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/load/insertstrategy"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"strings"
 	"time"
@@ -43,7 +45,8 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 	fs.Bool(
 		"loader.runner.do-create-db",
 		true,
-		"Whether to create the database. Disable on all but one client if running on a multi client setup.",
+		"Whether to create the database. Disable on all but one client if running on a multi client setup "+
+			"without tsbs_load coordinator.",
 	)
 	fs.Uint("loader.runner.workers", 1, "Number of parallel clients inserting")
 	fs.Uint64("loader.runner.limit", 0, "Number of items to insert (0 = all of them).")
@@ -53,6 +56,120 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 		10000,
 		"Number of items to batch together in a single insert",
 	)
+	fs.Bool(
+		"loader.runner.batch-by-series",
+		false,
+		"Whether to fill a batch per series (e.g. host) instead of per worker. Used only by the targets which "+
+			"know the series of the points",
+	)
+	fs.Duration(
+		"loader.runner.batch-max-age",
+		0,
+		"Time after which a batch which is not full is sent anyway, 0 = only send full batches",
+	)
+	fs.Uint(
+		"loader.runner.batch-max-series",
+		load.DefaultBatchMaxSeries,
+		"Number of series batches filled at once with batch-by-series, after which they are all sent, 0 = no limit",
+	)
+	fs.String(
+		"loader.runner.ingest-rate",
+		"",
+		"Target rate of all workers together, default '' => no limit. '5000' = 5000/s, 'step:1000,500,1m' = "+
+			"1000/s and 500/s more every minute, 'ramp:0,10000,5m' = 0 to 10000/s in 5 minutes, 'sine:5000,2000,1m' = "+
+			"5000/s +/- 2000/s with a 1 minute period, 'schedule:FILE' = a 'time rate' line per change, e.g. '30s 20000'",
+	)
+	fs.String(
+		"loader.runner.ingest-rate-unit",
+		insertstrategy.RatePoints,
+		"What loader.runner.ingest-rate counts: points or batches",
+	)
+	fs.Bool(
+		"loader.runner.auto-tune",
+		false,
+		"Whether to search the workers and batch size of the best throughput with short probe phases instead "+
+			"of loading all the data. The probes go through the workers until the throughput plateaus or the p99 "+
+			"batch latency is past loader.runner.auto-tune-max-latency, and then through the batch sizes the same way",
+	)
+	fs.String(
+		"loader.runner.auto-tune-workers",
+		load.DefaultAutoTuneWorkers,
+		"Numbers of workers to probe in auto-tune mode",
+	)
+	fs.String(
+		"loader.runner.auto-tune-batch-sizes",
+		load.DefaultAutoTuneBatchSizes,
+		"Batch sizes to probe in auto-tune mode",
+	)
+	fs.String(
+		"loader.runner.auto-tune-rates",
+		"",
+		"Target rates, in loader.runner.ingest-rate-unit, to probe with the best configuration in auto-tune mode "+
+			"until one is not achieved, default '' => none",
+	)
+	fs.Duration(
+		"loader.runner.auto-tune-probe",
+		load.DefaultAutoTuneProbe,
+		"How long each probe phase of auto-tune mode lasts",
+	)
+	fs.Float64(
+		"loader.runner.auto-tune-plateau",
+		load.DefaultAutoTunePlateau,
+		"Relative throughput increase under which auto-tune mode stops adding workers or growing batches",
+	)
+	fs.Duration(
+		"loader.runner.auto-tune-max-latency",
+		0,
+		"p99 batch latency a configuration must stay under in auto-tune mode, 0 = no threshold",
+	)
+	fs.String(
+		"loader.runner.checkpoint-file",
+		"",
+		"File to write the offset of the data up to which all items were inserted to, every "+
+			"loader.runner.checkpoint-period, default '' => no checkpoints",
+	)
+	fs.Duration(
+		"loader.runner.checkpoint-period",
+		time.Minute,
+		"Period to write checkpoints",
+	)
+	fs.String(
+		"loader.runner.resume-from",
+		"",
+		"Checkpoint file of a previous load to resume, skipping the items it inserted and without creating the "+
+			"database. Checkpoints are written to it unless loader.runner.checkpoint-file is set",
+	)
+	fs.Bool(
+		"loader.runner.mem-stats",
+		false,
+		"Whether to add the allocations and GC pauses of each period to the periodic report",
+	)
+	fs.String(
+		"loader.runner.profile-dir",
+		"",
+		"Directory to write the profiles of the loader to, default '' => no profiles",
+	)
+	fs.String(
+		"loader.runner.profiles",
+		profiling.DefaultKinds,
+		"Profiles to take: cpu, heap, mutex, block and trace",
+	)
+	fs.String(
+		"loader.runner.profile-windows",
+		"",
+		"Windows of the run to profile as START:DURATION, e.g. '30s:1m,10m:1m', default '' => the whole run",
+	)
+	fs.Bool(
+		"loader.runner.client-stats",
+		false,
+		"Whether to add the CPU, RSS, goroutines and GC pauses of the loader of each period to the periodic report",
+	)
+	fs.Bool(
+		"loader.runner.server-stats",
+		false,
+		"Whether to sample the metrics of the database server every loader.runner.reporting-period into the results "+
+			"file, if the target collects them",
+	)
 	fs.Bool(
 		"loader.runner.flow-control",
 		false,
@@ -78,7 +195,15 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 	fs.String(
 		"data-source.file.location",
 		"./file-from-tsbs-generate-data",
-		"If data-source.type=FILE, load the data from this file location",
+		"If data-source.type=FILE, load the data from this file location. A glob pattern or a comma separated "+
+			"list loads several files in parallel, .gz and .zst files are decompressed",
+	)
+	fs.Uint("data-source.file.shard", 0, "Shard of the files of data-source.file.location to load")
+	fs.Uint(
+		"data-source.file.shards",
+		0,
+		"Number of shards the files of data-source.file.location are split in, every shards-th file is loaded "+
+			"from data-source.file.shard. 0 = load all the files",
 	)
 	fs.String("data-source.simulator.use-case", "devops-generic", fmt.Sprintf("Use case to generate."))
 	fs.String("data-source.simulator.timestamp-start", defaultTimeStart, "Beginning timestamp (RFC3339).")
@@ -101,4 +226,15 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 		defaultScale,
 		"Scaling value specific to use case (e.g., devices in 'devops', trucks in iot).")
 	fs.Duration("data-source.simulator.log-interval", defaultLogInterval, "Duration between data points")
+	fs.Uint(
+		"data-source.simulator.interleaved-generation-group-id",
+		0,
+		"Group (0-indexed) of the generated points to load when they are split in interleaved groups",
+	)
+	fs.Uint(
+		"data-source.simulator.interleaved-generation-groups",
+		1,
+		"Number of interleaved groups the generated points are split in, every groups-th point is loaded "+
+			"from data-source.simulator.interleaved-generation-group-id",
+	)
 }
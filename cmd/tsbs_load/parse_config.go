@@ -11,6 +11,16 @@ import (
 )
 
 func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
+	benchmark, runnerConfig, err := parseBenchmarkConfig(target, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return benchmark, load.GetBenchmarkRunner(*runnerConfig), nil
+}
+
+// parseBenchmarkConfig creates the Benchmark and returns the config of its
+// BenchmarkRunner
+func parseBenchmarkConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, *load.BenchmarkRunnerConfig, error) {
 	dataSourceViper := v.Sub("data-source")
 	if dataSourceViper == nil {
 		return nil, nil, fmt.Errorf("config file didn't have a top-level 'data-source' object")
@@ -48,7 +58,7 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 		return nil, nil, err
 	}
 
-	return benchmark, load.GetBenchmarkRunner(*loaderConfigInternal), nil
+	return benchmark, loaderConfigInternal, nil
 }
 
 func parseRunnerConfig(v *viper.Viper) (*RunnerConfig, error) {
@@ -61,19 +71,40 @@ func parseRunnerConfig(v *viper.Viper) (*RunnerConfig, error) {
 
 func convertRunnerConfigToInternalRep(r *RunnerConfig) *load.BenchmarkRunnerConfig {
 	return &load.BenchmarkRunnerConfig{
-		DBName:          r.DBName,
-		BatchSize:       r.BatchSize,
-		Workers:         r.Workers,
-		Limit:           r.Limit,
-		DoLoad:          r.DoLoad,
-		DoCreateDB:      r.DoCreateDB,
-		DoAbortOnExist:  r.DoAbortOnExist,
-		ReportingPeriod: r.ReportingPeriod,
-		Seed:            r.Seed,
-		HashWorkers:     r.HashWorkers,
-		InsertIntervals: r.InsertIntervals,
-		NoFlowControl:   !r.FlowControl,
-		ChannelCapacity: r.ChannelCapacity,
+		DBName:             r.DBName,
+		BatchSize:          r.BatchSize,
+		Workers:            r.Workers,
+		Limit:              r.Limit,
+		DoLoad:             r.DoLoad,
+		DoCreateDB:         r.DoCreateDB,
+		DoAbortOnExist:     r.DoAbortOnExist,
+		ReportingPeriod:    r.ReportingPeriod,
+		Seed:               r.Seed,
+		HashWorkers:        r.HashWorkers,
+		InsertIntervals:    r.InsertIntervals,
+		NoFlowControl:      !r.FlowControl,
+		ChannelCapacity:    r.ChannelCapacity,
+		BatchBySeries:      r.BatchBySeries,
+		BatchMaxAge:        r.BatchMaxAge,
+		BatchMaxSeries:     r.BatchMaxSeries,
+		IngestRate:         r.IngestRate,
+		IngestRateUnit:     r.IngestRateUnit,
+		AutoTune:           r.AutoTune,
+		AutoTuneWorkers:    r.AutoTuneWorkers,
+		AutoTuneBatchSizes: r.AutoTuneBatchSizes,
+		AutoTuneRates:      r.AutoTuneRates,
+		AutoTuneProbe:      r.AutoTuneProbe,
+		AutoTunePlateau:    r.AutoTunePlateau,
+		AutoTuneMaxLatency: r.AutoTuneMaxLatency,
+		CheckpointFile:     r.CheckpointFile,
+		CheckpointPeriod:   r.CheckpointPeriod,
+		ResumeFrom:         r.ResumeFrom,
+		MemStats:           r.MemStats,
+		ProfileDir:         r.ProfileDir,
+		Profiles:           r.Profiles,
+		ProfileWindows:     r.ProfileWindows,
+		ClientStats:        r.ClientStats,
+		ServerStats:        r.ServerStats,
 	}
 }
 
@@ -122,8 +153,14 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 	if d.Type == source.FileDataSourceType {
 		file = &source.FileDataSourceConfig{
 			Location: d.File.Location,
+			Shard:    d.File.Shard,
+			Shards:   d.File.Shards,
 		}
 	} else {
+		numGroups := d.Simulator.InterleavedNumGroups
+		if numGroups == 0 {
+			numGroups = 1
+		}
 		simulator = &common.DataGeneratorConfig{
 			BaseConfig: common.BaseConfig{
 				Format:    format,
@@ -137,7 +174,8 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 			Limit:                 d.Simulator.Limit,
 			LogInterval:           d.Simulator.LogInterval,
 			MaxMetricCountPerHost: d.Simulator.MaxMetricCountPerHost,
-			InterleavedNumGroups:  1,
+			InterleavedGroupID:    d.Simulator.InterleavedGroupID,
+			InterleavedNumGroups:  numGroups,
 		}
 	}
 	return &source.DataSourceConfig{
@@ -20,4 +20,7 @@ func init() {
 	rootCmd.AddCommand(loadCmd)
 	configCmd := initConfigCMD()
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(initFanOutCMD())
+	rootCmd.AddCommand(initCoordinatorCMD())
+	rootCmd.AddCommand(initAgentCMD())
 }
@@ -5,22 +5,22 @@ package main
 
 import (
 	"fmt"
+	"log"
+
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
-	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets/clickhouse"
 )
 
-// Global vars
-var (
-	target targets.ImplementedTarget
-)
-
 var loader load.BenchmarkRunner
 var loaderConf load.BenchmarkRunnerConfig
-var conf *clickhouse.ClickhouseConfig
+var conf clickhouse.ClickhouseConfig
+
+// allows for testing
+var fatal = log.Fatalf
 
 // Parse args:
 func init() {
@@ -39,18 +39,20 @@ func init() {
 	if err := viper.Unmarshal(&loaderConf); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
-	conf = &clickhouse.ClickhouseConfig{
-		Host:       viper.GetString("host"),
-		User:       viper.GetString("user"),
-		Password:   viper.GetString("password"),
-		LogBatches: viper.GetBool("log-batches"),
-		Debug:      viper.GetInt("debug"),
-		DbName:     loaderConf.DBName,
+	if err := viper.Unmarshal(&conf); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
 	loader = load.GetBenchmarkRunner(loaderConf)
 }
 
 func main() {
-	loader.RunBenchmark(clickhouse.NewBenchmark(loaderConf.FileName, loaderConf.HashWorkers, conf))
+	benchmark, err := clickhouse.NewBenchmark(loaderConf.DBName, &conf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: loaderConf.FileName},
+	})
+	if err != nil {
+		fatal("%v", err)
+	}
+	loader.RunBenchmark(benchmark)
 }
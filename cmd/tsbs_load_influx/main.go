@@ -5,48 +5,28 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"log"
-	"strings"
-	"sync"
-	"time"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/influx"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
 )
 
-// Program option vars:
-var (
-	daemonURLs        []string
-	replicationFactor int
-	backoff           time.Duration
-	useGzip           bool
-	doAbortOnExist    bool
-	consistency       string
-)
-
 // Global vars
 var (
-	loader  load.BenchmarkRunner
-	config  load.BenchmarkRunnerConfig
-	bufPool sync.Pool
-	target  targets.ImplementedTarget
+	loader     load.BenchmarkRunner
+	config     load.BenchmarkRunnerConfig
+	influxConf influx.SpecificConfig
+	target     targets.ImplementedTarget
 )
 
-var consistencyChoices = map[string]struct{}{
-	"any":    {},
-	"one":    {},
-	"quorum": {},
-	"all":    {},
-}
-
 // allows for testing
 var fatal = log.Fatalf
 
@@ -56,7 +36,6 @@ func init() {
 	config = load.BenchmarkRunnerConfig{}
 	config.AddToFlagSet(pflag.CommandLine)
 	target.TargetSpecificFlags("", pflag.CommandLine)
-	var csvDaemonURLs string
 
 	pflag.Parse()
 
@@ -69,53 +48,21 @@ func init() {
 	if err := viper.Unmarshal(&config); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
-
-	csvDaemonURLs = viper.GetString("urls")
-	replicationFactor = viper.GetInt("replication-factor")
-	consistency = viper.GetString("consistency")
-	backoff = viper.GetDuration("backoff")
-	useGzip = viper.GetBool("gzip")
-
-	if _, ok := consistencyChoices[consistency]; !ok {
-		log.Fatalf("invalid consistency settings")
+	if err := viper.Unmarshal(&influxConf); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	daemonURLs = strings.Split(csvDaemonURLs, ",")
-	if len(daemonURLs) == 0 {
-		log.Fatal("missing 'urls' flag")
-	}
 	config.HashWorkers = false
 	loader = load.GetBenchmarkRunner(config)
 }
 
-type benchmark struct{}
-
-func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
-}
-
-func (b *benchmark) GetBatchFactory() targets.BatchFactory {
-	return &factory{}
-}
-
-func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
-	return &targets.ConstantIndexer{}
-}
-
-func (b *benchmark) GetProcessor() targets.Processor {
-	return &processor{}
-}
-
-func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return &dbCreator{}
-}
-
 func main() {
-	bufPool = sync.Pool{
-		New: func() interface{} {
-			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
-		},
+	benchmark, err := influx.NewBenchmark(loader.DatabaseName(), &influxConf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: config.FileName},
+	})
+	if err != nil {
+		fatal("%v", err)
 	}
-
-	loader.RunBenchmark(&benchmark{})
+	loader.RunBenchmark(benchmark)
 }
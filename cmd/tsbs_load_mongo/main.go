@@ -6,49 +6,24 @@ package main
 import (
 	"fmt"
 	"log"
-	"time"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
-)
-
-const (
-	collectionName     = "point_data"
-	aggDocID           = "doc_id"
-	aggDateFmt         = "20060102_15" // see Go docs for how we arrive at this time format
-	aggKeyID           = "key_id"
-	aggInsertBatchSize = 500 // found via trial-and-error
-	timestampField     = "time"
-)
-
-// Program option vars:
-var (
-	daemonURL            string
-	documentPer          bool
-	writeTimeout         time.Duration
-	timeseriesCollection bool
-	retryableWrites      bool
-	orderedInserts       bool
-	randomFieldOrder     bool
-	batchMetaFields		 bool
-	collectionSharded    bool
-	numInitChunks        uint
-	shardKeySpec         string
-	balancerOn           bool
-	metaFieldIndex       string
-	granularity          string
+	"github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
 // Global vars
 var (
-	loader load.BenchmarkRunner
-	config load.BenchmarkRunnerConfig
-	target targets.ImplementedTarget
+	loader    load.BenchmarkRunner
+	config    load.BenchmarkRunnerConfig
+	mongoConf mongo.SpecificConfig
+	target    targets.ImplementedTarget
 )
 
 // Parse args:
@@ -69,60 +44,20 @@ func init() {
 	if err := viper.Unmarshal(&config); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
-
-	daemonURL = viper.GetString("url")
-	writeTimeout = viper.GetDuration("write-timeout")
-	documentPer = viper.GetBool("document-per-event")
-	timeseriesCollection = viper.GetBool("timeseries-collection")
-	retryableWrites = viper.GetBool("retryable-writes")
-	orderedInserts = viper.GetBool("ordered-inserts")
-	randomFieldOrder = viper.GetBool("random-field-order")
-	batchMetaFields = viper.GetBool("batch-meta-fields")
-	collectionSharded = viper.GetBool("collection-sharded")
-	numInitChunks = viper.GetUint("number-initial-chunks")
-	shardKeySpec = viper.GetString("shard-key-spec")
-	balancerOn = viper.GetBool("balancer-on")
-	metaFieldIndex = viper.GetString("meta-field-index")
-	granularity = viper.GetString("granularity")
-	
-	if documentPer  {
-		config.HashWorkers = false
-	} else {
-		config.HashWorkers = true
-	}
-
-	if batchMetaFields {
-		config.HashWorkers = true
-		config.BatchMetaFields = true
-		config.MetaFieldIndex = metaFieldIndex
-	}
-
-	if !documentPer && timeseriesCollection {
-		log.Fatal("Must set document-per-event=true in order to use timeseries-collection=true")
-	}
-
-	if !timeseriesCollection && batchMetaFields {
-		log.Fatal("Must set document-per-event=true and timeseries-collection=true in order to use batch-meta-fields=true")
-	} 
-
-	if collectionSharded && len(shardKeySpec) == 0 {
-		log.Fatal("Must specify a shard key spec in order to use a sharded collection")
-	}
-
-	if len(metaFieldIndex) == 0 {
-		log.Fatal("Must specify a field within metaField to index on")
+	if err := viper.Unmarshal(&mongoConf); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
 	loader = load.GetBenchmarkRunner(config)
 }
 
 func main() {
-	var benchmark targets.Benchmark
-	if documentPer {
-		benchmark = newNaiveBenchmark(loader, &config)
-	} else {
-		benchmark = newAggBenchmark(loader, &config)
+	benchmark, err := mongo.NewBenchmark(loader.DatabaseName(), &mongoConf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: config.FileName},
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-
 	loader.RunBenchmark(benchmark)
 }
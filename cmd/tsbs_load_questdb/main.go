@@ -5,35 +5,27 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
-)
-
-// Program option vars:
-var (
-	questdbRESTEndPoint string
-	questdbILPBindTo    string
-	doAbortOnExist      bool
+	"github.com/timescale/tsbs/pkg/targets/questdb"
 )
 
 // Global vars
 var (
-	loader  load.BenchmarkRunner
-	config  load.BenchmarkRunnerConfig
-	bufPool sync.Pool
-	target  targets.ImplementedTarget
+	loader      load.BenchmarkRunner
+	config      load.BenchmarkRunnerConfig
+	questdbConf questdb.SpecificConfig
+	target      targets.ImplementedTarget
 )
 
 // allows for testing
@@ -50,7 +42,7 @@ func init() {
 	pflag.CommandLine.Uint64("limit", 0, "Number of items to insert (0 = all of them).")
 	pflag.CommandLine.Bool("do-load", true, "Whether to write data. Set this flag to false to check input read speed.")
 	pflag.CommandLine.Duration("reporting-period", 10*time.Second, "Period to report write stats")
-	pflag.CommandLine.String("file", "", "File name to read data from")
+	pflag.CommandLine.String("file", "", "File(s) to read data from: a file, a glob pattern or a comma separated list of them. .gz and .zst files are decompressed. Empty reads STDIN")
 	pflag.CommandLine.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	pflag.CommandLine.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
 	pflag.CommandLine.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
@@ -66,41 +58,21 @@ func init() {
 	if err := viper.Unmarshal(&config); err != nil {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
+	if err := viper.Unmarshal(&questdbConf); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
 
-	questdbRESTEndPoint = viper.GetString("url")
-	questdbILPBindTo = viper.GetString("ilp-bind-to")
 	config.HashWorkers = false
 	loader = load.GetBenchmarkRunner(config)
 }
 
-type benchmark struct{}
-
-func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
-}
-
-func (b *benchmark) GetBatchFactory() targets.BatchFactory {
-	return &factory{}
-}
-
-func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
-	return &targets.ConstantIndexer{}
-}
-
-func (b *benchmark) GetProcessor() targets.Processor {
-	return &processor{}
-}
-
-func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return &dbCreator{}
-}
-
 func main() {
-	bufPool = sync.Pool{
-		New: func() interface{} {
-			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
-		},
+	benchmark, err := questdb.NewBenchmark(&questdbConf, &source.DataSourceConfig{
+		Type: source.FileDataSourceType,
+		File: &source.FileDataSourceConfig{Location: config.FileName},
+	})
+	if err != nil {
+		fatal("%v", err)
 	}
-
-	loader.RunBenchmark(&benchmark{})
+	loader.RunBenchmark(benchmark)
 }
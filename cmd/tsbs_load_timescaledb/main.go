@@ -70,6 +70,16 @@ func initProgramOptions() (*timescaledb.LoadingOptions, load.BenchmarkRunner, *l
 	opts.ReplicationStatsFile = viper.GetString("write-replication-stats")
 	opts.CreateMetricsTable = viper.GetBool("create-metrics-table")
 
+	opts.UseCompression = viper.GetBool("use-compression")
+	opts.CompressSegmentBy = viper.GetString("compress-segmentby")
+	opts.CompressOrderBy = viper.GetString("compress-orderby")
+	opts.CompressAfter = viper.GetDuration("compress-after")
+	opts.CompressAfterLoad = viper.GetBool("compress-after-load")
+	opts.CaggIntervals = viper.GetStringSlice("cagg-intervals")
+	opts.CaggTables = viper.GetStringSlice("cagg-tables")
+	opts.CaggMaterializedOnly = viper.GetBool("cagg-materialized-only")
+	opts.RetentionPeriod = viper.GetDuration("retention-period")
+
 	opts.ForceTextFormat = viper.GetBool("force-text-format")
 	opts.UseInsert = viper.GetBool("use-insert")
 
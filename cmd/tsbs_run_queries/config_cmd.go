@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+const (
+	targetDbFlag = "target"
+
+	writeConfigTo = "./config.yaml"
+)
+
+func initConfigCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Generate example config yaml file and save it to " + writeConfigTo,
+		Run:   config,
+	}
+
+	cmd.Flags().String(
+		targetDbFlag,
+		constants.FormatTimescaleDB,
+		"specify target db, valid: "+strings.Join(queryTargetNames(), ", "),
+	)
+	return cmd
+}
+
+func config(cmd *cobra.Command, _ []string) {
+	targetSelected, err := cmd.Flags().GetString(targetDbFlag)
+	if err != nil {
+		panic(fmt.Sprintf("could not read value for %s flag: %v", targetDbFlag, err))
+	}
+	var target targets.ImplementedQueryTarget
+	for _, t := range queryTargets() {
+		if t.TargetName() == targetSelected {
+			target = t
+		}
+	}
+	if target == nil {
+		panic(fmt.Sprintf("target %s can not run queries, valid: %s", targetSelected, strings.Join(queryTargetNames(), ", ")))
+	}
+
+	v, err := exampleConfigInViper(target)
+	if err != nil {
+		panic(err)
+	}
+	if err := v.WriteConfigAs(writeConfigTo); err != nil {
+		panic(fmt.Errorf("could not write sample config to file %s: %v", writeConfigTo, err))
+	}
+	fmt.Printf("Wrote example config to: %s\n", writeConfigTo)
+}
+
+// exampleConfigInViper returns a viper holding the default runner and
+// target-specific configuration.
+func exampleConfigInViper(t targets.ImplementedQueryTarget) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	if err := v.BindPFlags(runnerFlags()); err != nil {
+		return nil, fmt.Errorf("could not bind runner flags in viper: %v", err)
+	}
+
+	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
+	t.QueryTargetSpecificFlags(dbSpecificFlagPrefix, flagSet)
+	if err := v.BindPFlags(flagSet); err != nil {
+		return nil, fmt.Errorf("could not bind target specific config flags in viper: %v", err)
+	}
+	return v, nil
+}
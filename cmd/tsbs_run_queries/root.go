@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	runnerFlagPrefix     = "runner."
+	dbSpecificFlagPrefix = "db-specific."
+)
+
+var (
+	cfgFile string
+	rootCmd = &cobra.Command{
+		Use:              "tsbs_run_queries",
+		Short:            "Run queries generated by tsbs_generate_queries against a db",
+		PersistentPreRun: initViperConfig,
+	}
+)
+
+func init() {
+	rootCmd.PersistentFlags().AddFlagSet(runnerFlags())
+	// don't bind --config which specifies the file from where to read config
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	rootCmd.AddCommand(initRunSubCommands()...)
+	rootCmd.AddCommand(initConfigCMD())
+}
+
+// runnerFlags returns the flags of query.BenchmarkRunnerConfig, each name
+// prefixed with runnerFlagPrefix.
+func runnerFlags() *pflag.FlagSet {
+	var config query.BenchmarkRunnerConfig
+	unprefixed := pflag.NewFlagSet("", pflag.ContinueOnError)
+	config.AddToFlagSet(unprefixed)
+
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	unprefixed.VisitAll(func(f *pflag.Flag) {
+		f.Name = runnerFlagPrefix + f.Name
+		fs.AddFlag(f)
+	})
+	return fs
+}
+
+func initViperConfig(*cobra.Command, []string) {
+	if cfgFile != "" {
+		// Use config file from the flag.
+		viper.SetConfigFile(cfgFile)
+	} else {
+		// Search config in execution directory with name "config.yaml".
+		viper.AddConfigPath(".")
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+	}
+
+	if err := viper.ReadInConfig(); err == nil {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	}
+}
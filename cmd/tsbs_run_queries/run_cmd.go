@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+type cmdRunner func(*cobra.Command, []string)
+
+// queryTargets returns the supported targets that can run queries.
+func queryTargets() []targets.ImplementedQueryTarget {
+	var res []targets.ImplementedQueryTarget
+	for _, format := range constants.SupportedFormats() {
+		if target, ok := initializers.GetTarget(format).(targets.ImplementedQueryTarget); ok {
+			res = append(res, target)
+		}
+	}
+	return res
+}
+
+func queryTargetNames() []string {
+	var res []string
+	for _, target := range queryTargets() {
+		res = append(res, target.TargetName())
+	}
+	return res
+}
+
+func initRunSubCommands() []*cobra.Command {
+	var commands []*cobra.Command
+	for _, target := range queryTargets() {
+		cmd := &cobra.Command{
+			Use:   target.TargetName(),
+			Short: "Run queries against " + target.TargetName() + " as a target db",
+			Run:   createRunQueries(target),
+		}
+
+		target.QueryTargetSpecificFlags(dbSpecificFlagPrefix, cmd.PersistentFlags())
+		commands = append(commands, cmd)
+	}
+
+	return commands
+}
+
+func createRunQueries(target targets.ImplementedQueryTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		// bind only the flags of the executed sub-command so viper doesn't
+		// hold the db-specific flags of all targets
+		if err := viper.BindPFlags(cmd.Flags()); err != nil {
+			panic(fmt.Errorf("could not bind flags for %s: %v", target.TargetName(), err))
+		}
+		runner, benchmark, err := parseConfig(target, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		benchmark.RunQueries(runner)
+	}
+}
+
+func parseConfig(target targets.ImplementedQueryTarget, v *viper.Viper) (*query.BenchmarkRunner, *targets.QueryBenchmark, error) {
+	runnerViper := subConfig(v, "runner")
+	if runnerViper == nil {
+		return nil, nil, fmt.Errorf("config file didn't have a top-level 'runner' object")
+	}
+	var runnerConfig query.BenchmarkRunnerConfig
+	if err := runnerViper.Unmarshal(&runnerConfig); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode runner config: %v", err)
+	}
+	runner := query.NewBenchmarkRunner(runnerConfig)
+
+	dbSpecificViper := subConfig(v, "db-specific")
+	if dbSpecificViper == nil {
+		return nil, nil, fmt.Errorf("config file didn't have a top-level 'db-specific' object")
+	}
+	benchmark, err := target.QueryBenchmark(runner, dbSpecificViper)
+	if err != nil {
+		return nil, nil, err
+	}
+	return runner, benchmark, nil
+}
+
+// subConfig returns a new viper holding the settings under key. Unlike
+// v.Sub, it includes the values of bound flags which were not set in a config
+// file, so the command can run with flags only.
+func subConfig(v *viper.Viper, key string) *viper.Viper {
+	settings, ok := v.AllSettings()[key].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	sub := viper.New()
+	if err := sub.MergeConfigMap(settings); err != nil {
+		return nil
+	}
+	return sub
+}
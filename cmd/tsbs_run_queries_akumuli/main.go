@@ -7,11 +7,14 @@ package main
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/akumuli"
 )
 
 // Program option vars:
@@ -21,7 +24,8 @@ var (
 
 // Global vars:
 var (
-	runner *query.BenchmarkRunner
+	runner    *query.BenchmarkRunner
+	benchmark *targets.QueryBenchmark
 )
 
 // Parse args:
@@ -46,35 +50,13 @@ func init() {
 	endpoint = viper.GetString("endpoint")
 
 	runner = query.NewBenchmarkRunner(config)
-}
-
-func main() {
-	runner.Run(&query.HTTPPool, newProcessor)
-}
-
-type processor struct {
-	w    *HTTPClient
-	opts *HTTPClientDoOptions
-}
 
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	p.opts = &HTTPClientDoOptions{
-		Debug:          runner.DebugLevel(),
-		PrintResponses: runner.DoPrintResponses(),
+	benchmark, err = akumuli.NewQueryBenchmark(runner, &akumuli.QuerySpecificConfig{URLs: []string{endpoint}})
+	if err != nil {
+		log.Fatal(err)
 	}
-	url := endpoint
-	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
-	if err != nil {
-		return nil, err
-	}
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, nil
+func main() {
+	benchmark.RunQueries(runner)
 }
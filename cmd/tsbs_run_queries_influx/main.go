@@ -14,17 +14,22 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/influx"
 )
 
 // Program option vars:
 var (
 	daemonUrls []string
 	chunkSize  uint64
+	org        string
+	token      string
 )
 
 // Global vars:
 var (
-	runner *query.BenchmarkRunner
+	runner    *query.BenchmarkRunner
+	benchmark *targets.QueryBenchmark
 )
 
 // Parse args:
@@ -35,6 +40,8 @@ func init() {
 
 	pflag.String("urls", "http://localhost:8086", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
 	pflag.Uint64("chunk-response-size", 0, "Number of series to chunk results into. 0 means no chunking.")
+	pflag.String("org", "", "InfluxDB 2.x organization Flux queries are run in.")
+	pflag.String("token", "", "InfluxDB 2.x API token. Empty means no authentication.")
 
 	pflag.Parse()
 
@@ -50,6 +57,8 @@ func init() {
 
 	csvDaemonUrls = viper.GetString("urls")
 	chunkSize = viper.GetUint64("chunk-response-size")
+	org = viper.GetString("org")
+	token = viper.GetString("token")
 
 	daemonUrls = strings.Split(csvDaemonUrls, ",")
 	if len(daemonUrls) == 0 {
@@ -57,37 +66,18 @@ func init() {
 	}
 
 	runner = query.NewBenchmarkRunner(config)
-}
-
-func main() {
-	runner.Run(&query.HTTPPool, newProcessor)
-}
-
-type processor struct {
-	w    *HTTPClient
-	opts *HTTPClientDoOptions
-}
 
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	p.opts = &HTTPClientDoOptions{
-		Debug:                runner.DebugLevel(),
-		PrettyPrintResponses: runner.DoPrintResponses(),
-		chunkSize:            chunkSize,
-		database:             runner.DatabaseName(),
+	benchmark, err = influx.NewQueryBenchmark(runner, &influx.QuerySpecificConfig{
+		URLs:              daemonUrls,
+		ChunkResponseSize: chunkSize,
+		Org:               org,
+		Token:             token,
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-	url := daemonUrls[workerNumber%len(daemonUrls)]
-	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
-	if err != nil {
-		return nil, err
-	}
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, nil
+func main() {
+	benchmark.RunQueries(runner)
 }
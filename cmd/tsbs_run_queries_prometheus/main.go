@@ -0,0 +1,62 @@
+// tsbs_run_queries_prometheus speed tests Prometheus-compatible backends using
+// requests from stdin or file.
+//
+// It reads encoded Query objects from stdin, and makes concurrent requests
+// to the Prometheus HTTP API of the provided endpoints. This program has no
+// knowledge of the internals of the endpoint.
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/prometheus"
+)
+
+// Global vars:
+var (
+	runner    *query.BenchmarkRunner
+	benchmark *targets.QueryBenchmark
+)
+
+// Parse args:
+func init() {
+	var config query.BenchmarkRunnerConfig
+	config.AddToFlagSet(pflag.CommandLine)
+
+	pflag.String("urls", "http://localhost:9090",
+		"Comma-separated list of Prometheus HTTP API URLs (any backend serving /api/v1/query_range)")
+
+	pflag.Parse()
+
+	if err := utils.SetupConfigFile(); err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+	if err := viper.Unmarshal(&config); err != nil {
+		panic(fmt.Errorf("unable to decode config: %s", err))
+	}
+
+	urls := viper.GetString("urls")
+	if len(urls) == 0 {
+		log.Fatalf("missing `urls` flag")
+	}
+	runner = query.NewBenchmarkRunner(config)
+
+	var err error
+	benchmark, err = prometheus.NewQueryBenchmark(runner, &prometheus.QuerySpecificConfig{
+		URLs: strings.Split(urls, ","),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	benchmark.RunQueries(runner)
+}
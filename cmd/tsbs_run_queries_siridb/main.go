@@ -7,17 +7,13 @@ package main
 import (
 	"fmt"
 	"log"
-	"strconv"
-	"strings"
-	"time"
 
-	siridb "github.com/SiriDB/go-siridb-connector"
 	"github.com/blagojts/viper"
 	_ "github.com/lib/pq"
 	"github.com/spf13/pflag"
-	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/siridb"
 )
 
 // Program option vars:
@@ -36,10 +32,6 @@ var (
 	runner *query.BenchmarkRunner
 )
 
-var (
-	siridbConnector *siridb.Client
-)
-
 // Parse args:
 func init() {
 	var config query.BenchmarkRunnerConfig
@@ -74,140 +66,20 @@ func init() {
 	showExplain = viper.GetBool("show-explain")
 
 	runner = query.NewBenchmarkRunner(config)
-
-	if showExplain {
-		runner.SetLimit(1)
-	}
-
-	hostlist := [][]interface{}{}
-	listhosts := strings.Split(hosts, ",")
-
-	for _, hostport := range listhosts {
-		x := strings.Split(hostport, ":")
-		host := x[0]
-		port, err := strconv.ParseInt(x[1], 10, 0)
-		if err != nil {
-			log.Fatal(err)
-		}
-		hostlist = append(hostlist, []interface{}{host, int(port)})
-	}
-
-	siridbConnector = siridb.NewClient(
-		dbUser,                // username
-		dbPass,                // password
-		runner.DatabaseName(), // database
-		hostlist,              // siridb server(s)
-		nil,                   // optional log channel
-	)
 }
 
 func main() {
-	siridbConnector.Connect()
-	ChangeQueryLimit()
-	CreateGroups()
-
-	runner.Run(&query.SiriDBPool, newProcessor)
-	siridbConnector.Close()
-}
-
-type queryExecutorOptions struct {
-	showExplain   bool
-	debug         bool
-	printResponse bool
-}
-
-type processor struct {
-	opts *queryExecutorOptions
-}
-
-func newProcessor() query.Processor { return &processor{} }
-
-// Changes the maximum points which can be returned by a select query. The default
-// and recommended value is set to one million points. This value is chosen to
-// prevent a single query for taking to much memory and ensures SiriDB can respond
-// to almost any query in a reasonable amount of time.
-func ChangeQueryLimit() {
-	qry := fmt.Sprintf("alter database set select_points_limit %d", queryLimit)
-
-	if siridbConnector.IsConnected() {
-		if _, err := siridbConnector.Query(qry, uint16(writeTimeout)); err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		log.Fatal("not even a single server is connected...")
-	}
-}
-
-// CreateGroups makes groups representing regular expression to enhance performance
-func CreateGroups() {
-	created := true
-	metrics := devops.GetAllCPUMetrics()
-	siriql := make([]string, 0, 2048)
-	for _, m := range metrics {
-		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s$/", m, m))
-	}
-
-	var n uint64
-	for n = 0; n < scale; n++ {
-		host := fmt.Sprintf("host_%d", n)
-		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s,.*/", host, host))
-	}
-	siriql = append(siriql, fmt.Sprintf("create group `cpu` for /.*^cpu.*/"))
-	for _, qry := range siriql {
-		if siridbConnector.IsConnected() {
-			if _, err := siridbConnector.Query(qry, uint16(writeTimeout)); err != nil {
-				created = false
-			}
-		} else {
-			log.Fatal("not even a single server is connected...")
-		}
-	}
-	if created {
-		time.Sleep(6 * time.Second) // because the groups are created in a seperate thread every 2 seconds.
-	}
-}
-
-func (p *processor) Init(numWorker int) {
-	p.opts = &queryExecutorOptions{
-		showExplain:   showExplain,
-		debug:         runner.DebugLevel() > 0,
-		printResponse: runner.DoPrintResponses(),
-	}
-}
-
-func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
-
-	// No need to run again for EXPLAIN
-	if isWarm && p.opts.showExplain {
-		return nil, nil
-	}
-	tq := q.(*query.SiriDB)
-
-	start := time.Now()
-	qry := string(tq.SqlQuery)
-
-	var res interface{}
-	var err error
-
-	if siridbConnector.IsConnected() {
-		if res, err = siridbConnector.Query(qry, uint16(writeTimeout)); err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		log.Fatal("not even a single server is connected...")
-	}
-
-	if p.opts.debug {
-		fmt.Println(qry)
-	}
-
-	if p.opts.printResponse {
-		fmt.Println("\n", res)
+	benchmark, err := siridb.NewQueryBenchmark(runner, &siridb.QuerySpecificConfig{
+		DBUser:       dbUser,
+		DBPass:       dbPass,
+		Hosts:        hosts,
+		Scale:        scale,
+		QueryLimit:   queryLimit,
+		WriteTimeout: writeTimeout,
+		ShowExplain:  showExplain,
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	took := float64(time.Since(start).Nanoseconds()) / 1e6
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), took)
-
-	return []*query.Stat{stat}, err
+	benchmark.RunQueries(runner)
 }
@@ -2,17 +2,17 @@
 //
 // It reads encoded Query objects from stdin or file, and makes concurrent requests
 // to the a Timestream database encoded in the queries themselves, only the AWS region is
-// required, and valid AWS credentials to be stored in .aws/credentials.
+// required, and valid AWS credentials to be stored in .aws/credentials. The endpoint
+// can be overridden to query a local stand-in of Timestream.
 // This program has no knowledge of the internals of the endpoint.
 package main
 
 import (
-	"encoding/json"
 	"fmt"
-	"github.com/aws/aws-sdk-go/service/timestreamquery"
-	"github.com/timescale/tsbs/pkg/targets/timestream"
 	"time"
 
+	"github.com/timescale/tsbs/pkg/targets/timestream"
+
 	"github.com/blagojts/viper"
 	_ "github.com/jackc/pgx/v4/stdlib"
 	_ "github.com/lib/pq"
@@ -24,6 +24,7 @@ import (
 // Program option vars:
 var (
 	awsRegion    string
+	endpoint     string
 	queryTimeout time.Duration
 )
 
@@ -38,6 +39,7 @@ func init() {
 	config.AddToFlagSet(pflag.CommandLine)
 
 	pflag.String("aws-region", "us-east-1", "Region where the database is")
+	pflag.String("endpoint", "", "Endpoint of the Timestream query service, e.g. for a local stand-in. Empty discovers the endpoint of the aws-region")
 	pflag.Duration("query-timeout", time.Minute, "Configuration for aws sdk client to timeout after")
 	pflag.Parse()
 
@@ -51,109 +53,16 @@ func init() {
 	}
 
 	awsRegion = viper.GetString("aws-region")
+	endpoint = viper.GetString("endpoint")
 	queryTimeout = viper.GetDuration("query-timeout")
 	runner = query.NewBenchmarkRunner(config)
 }
 
 func main() {
-	runner.Run(&query.TimestreamPool, newProcessor)
-}
-
-// prettyPrintResponse prints a Query and its response in JSON format with two
-// keys: 'query' which has a value of the SQL used to generate the second key
-// 'results' which is an array of each row in the return set.
-func prettyPrintResponse(qry string, page *timestreamquery.QueryOutput, pageNum int) {
-	resp := make(map[string]interface{})
-	resp["query"] = qry
-	resp["results"] = mapRows(page)
-	resp["page"] = pageNum
-
-	line, err := json.MarshalIndent(resp, "", "  ")
-	if err != nil {
-		panic(err)
-	}
-
-	fmt.Println(string(line) + "\n")
-}
-
-func mapRows(page *timestreamquery.QueryOutput) []map[string]string {
-	var rows []map[string]string
-	cols := page.ColumnInfo
-	for _, row := range page.Rows {
-		rowAsMap := make(map[string]string)
-		for i, val := range row.Data {
-			colName := cols[i].Name
-			rowAsMap[*colName] = val.String()
-		}
-
-		rows = append(rows, rowAsMap)
-	}
-	return rows
-}
-
-type queryExecutorOptions struct {
-	showExplain   bool
-	debug         bool
-	printResponse bool
-}
-
-type processor struct {
-	_opts    *queryExecutorOptions
-	_readSvc *timestreamquery.TimestreamQuery
-}
-
-func newProcessor() query.Processor {
-	return &processor{}
-}
-
-func (p *processor) Init(_ int) {
-	awsSession, err := timestream.OpenAWSSession(&awsRegion, queryTimeout)
-	if err != nil {
-		panic("could not open aws session")
-	}
-	p._readSvc = timestreamquery.New(awsSession)
-	p._opts = &queryExecutorOptions{
-		debug:         runner.DebugLevel() > 0,
-		printResponse: runner.DoPrintResponses(),
-	}
-}
-
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	tq := q.(*query.Timestream)
-
-	start := time.Now()
-	qry := string(tq.SqlQuery)
-
-	if p._opts.debug {
-		fmt.Println(qry)
-	}
-
-	queryInput := &timestreamquery.QueryInput{
-		QueryString: &qry,
-	}
-	totalRows := 0
-	pageNum := 1
-	err := p._readSvc.QueryPages(queryInput,
-		func(page *timestreamquery.QueryOutput, lastPage bool) bool {
-			// process query response
-			// making sure all the returned data is read
-			totalRows += len(page.Rows)
-			if p._opts.printResponse {
-				prettyPrintResponse(qry, page, pageNum)
-			}
-			pageNum++
-			// return true to continue to next page
-			return true
-		})
-	if err != nil {
-		return nil, err
-	}
-	if p._opts.debug {
-		fmt.Printf("Total rows: %d\n", totalRows)
-	}
-	took := float64(time.Since(start).Nanoseconds()) / 1e6
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), took)
-
-	return []*query.Stat{stat}, err
+	benchmark := timestream.NewQueryBenchmark(runner, &timestream.QuerySpecificConfig{
+		AwsRegion:    awsRegion,
+		Endpoint:     endpoint,
+		QueryTimeout: queryTimeout,
+	})
+	benchmark.RunQueries(runner)
 }
@@ -6,7 +6,6 @@ import (
 	"io"
 	"math/rand"
 	"os"
-	"sort"
 
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/serialize"
@@ -88,17 +87,10 @@ func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.I
 }
 
 func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (common.Simulator, error) {
-	err := g.init(config)
-	if err != nil {
-		return nil, err
-	}
-	rand.Seed(g.config.Seed)
-	scfg, err := usecases.GetSimulatorConfig(g.config)
-	if err != nil {
-		return nil, err
+	if config == nil {
+		return nil, fmt.Errorf(ErrNoConfig)
 	}
-
-	return scfg.NewSimulator(g.config.LogInterval, g.config.Limit), nil
+	return usecases.NewSimulator(config)
 }
 
 func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
@@ -133,38 +125,14 @@ func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.Imple
 		fallthrough
 	case constants.FormatClickhouse:
 		fallthrough
+	case constants.FormatClickhouseNative:
+		fallthrough
 	case constants.FormatTimescaleDB:
 		g.writeHeader(sim.Headers())
 	}
 	return target.Serializer(), nil
 }
 
-//TODO should be implemented in targets package
 func (g *DataGenerator) writeHeader(headers *common.GeneratedDataHeaders) {
-	g.bufOut.WriteString("tags")
-
-	types := headers.TagTypes
-	for i, key := range headers.TagKeys {
-		g.bufOut.WriteString(",")
-		g.bufOut.Write([]byte(key))
-		g.bufOut.WriteString(" ")
-		g.bufOut.WriteString(types[i])
-	}
-	g.bufOut.WriteString("\n")
-	// sort the keys so the header is deterministic
-	keys := make([]string, 0)
-	fields := headers.FieldKeys
-	for k := range fields {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-	for _, measurementName := range keys {
-		g.bufOut.WriteString(measurementName)
-		for _, field := range fields[measurementName] {
-			g.bufOut.WriteString(",")
-			g.bufOut.Write([]byte(field))
-		}
-		g.bufOut.WriteString("\n")
-	}
-	g.bufOut.WriteString("\n")
+	headers.Write(g.bufOut)
 }
@@ -332,6 +332,7 @@ func TestGetSerializer(t *testing.T) {
 
 	checkWriteHeader(constants.FormatCassandra, false)
 	checkWriteHeader(constants.FormatClickhouse, true)
+	checkWriteHeader(constants.FormatClickhouseNative, true)
 	checkWriteHeader(constants.FormatInflux, false)
 	checkWriteHeader(constants.FormatMongo, false)
 	checkWriteHeader(constants.FormatSiriDB, false)
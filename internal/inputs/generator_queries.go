@@ -10,6 +10,7 @@ import (
 	"sort"
 	"time"
 
+	usesCommon "github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
 	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
@@ -29,6 +30,8 @@ const (
 	errUnknownUseCaseFmt        = "use case '%s' is undefined"
 	errCannotParseTimeFmt       = "cannot parse time from string '%s': %v"
 	errBadUseFmt                = "invalid use case specified: '%v'"
+
+	errAccessPatternNotSupportedFmt = "use case '%s' for format '%s' only supports uniform window and host distributions"
 )
 
 // DevopsGeneratorMaker creates a query generator for devops use case
@@ -57,6 +60,9 @@ type QueryGenerator struct {
 	// DebugOut is where non-generated messages should be written. If nil, it
 	// will be os.Stderr.
 	DebugOut io.Writer
+	// ParameterizedMatrix maps a use case and query type to a maker taking the
+	// query parameters of a workload file. Only used with a workload file.
+	ParameterizedMatrix map[string]map[string]queryUtils.ParameterizedQueryFillerMaker
 
 	conf          *config.QueryGeneratorConfig
 	useCaseMatrix map[string]map[string]queryUtils.QueryFillerMaker
@@ -91,8 +97,22 @@ func (g *QueryGenerator) Generate(config common.GeneratorConfig) error {
 	if err != nil {
 		return err
 	}
+	if err := g.setAccessPattern(useGen); err != nil {
+		return err
+	}
 
-	filler := g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+	var filler queryUtils.QueryFiller
+	if g.conf.WorkloadFile != "" {
+		w, err := readWorkload(g.conf.WorkloadFile)
+		if err != nil {
+			return err
+		}
+		if filler, err = g.newWorkloadFiller(w, useGen, g.conf.Seed); err != nil {
+			return err
+		}
+	} else {
+		filler = g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+	}
 
 	return g.runQueryGeneration(useGen, filler, g.conf)
 }
@@ -121,7 +141,8 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 		return fmt.Errorf(errBadUseFmt, g.conf.Use)
 	}
 
-	if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok {
+	// the query types of a workload are checked when it is read
+	if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok && g.conf.WorkloadFile == "" {
 		return fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, g.conf.QueryType)
 	}
 
@@ -213,6 +234,31 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 	}
 }
 
+// setAccessPattern configures how the use case generator picks time windows
+// and hosts. Generators that don't embed a common Core only support the
+// uniform default.
+func (g *QueryGenerator) setAccessPattern(useGen queryUtils.QueryGenerator) error {
+	access := usesCommon.AccessPattern{
+		WindowDistribution: g.conf.WindowDistribution,
+		WindowSkew:         g.conf.WindowSkew,
+		DeviceDistribution: g.conf.HostDistribution,
+		DeviceSkew:         g.conf.HostSkew,
+		HotSetFraction:     g.conf.HotSetFraction,
+		HotSetProbability:  g.conf.HotSetProbability,
+	}
+	if err := access.Validate(); err != nil {
+		return err
+	}
+	setter, ok := useGen.(usesCommon.AccessPatternSetter)
+	if !ok {
+		if access.WindowDistribution != usesCommon.WindowUniform || access.DeviceDistribution != usesCommon.DeviceUniform {
+			return fmt.Errorf(errAccessPatternNotSupportedFmt, g.conf.Use, g.conf.Format)
+		}
+		return nil
+	}
+	return setter.SetAccessPattern(access)
+}
+
 // queryEncoder abstracts the encoding of a query to either gob or JSONL format.
 type queryEncoder interface {
 	Encode(q query.Query) error
@@ -283,6 +329,9 @@ func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, fi
 				return fmt.Errorf(errCouldNotEncodeQueryFmt, err)
 			}
 			stats[string(q.HumanLabelName())]++
+			if wf, ok := filler.(*workloadFiller); ok {
+				wf.countLast()
+			}
 
 			if c.Debug > 0 {
 				var debugMsg string
@@ -320,5 +369,8 @@ func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, fi
 			return fmt.Errorf(errCouldNotQueryStatsFmt, err)
 		}
 	}
+	if wf, ok := filler.(*workloadFiller); ok {
+		return g.writeWorkloadCounts(wf, stats)
+	}
 	return nil
 }
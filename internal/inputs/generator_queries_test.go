@@ -70,6 +70,53 @@ func TestQueryGeneratorConfigValidate(t *testing.T) {
 	}
 	c.QueryType = "foo"
 
+	// Test WorkloadFile validation
+	c.WorkloadFile = "workload.yaml"
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for query type and workload file")
+	} else if got := err.Error(); got != config.ErrQueryTypeAndWorkload {
+		t.Errorf("incorrect error for query type and workload file: got\n%s\nwant\n%s", got, config.ErrQueryTypeAndWorkload)
+	}
+	c.QueryType = ""
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for workload file without query type: %v", err)
+	}
+	c.QueryType = "foo"
+	c.WorkloadFile = ""
+
+	// Test Mongo layout validation
+	c.MongoUseTimeseries = true
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for timeseries collection without naive")
+	} else if got := err.Error(); got != config.ErrMongoTimeseriesNotNaive {
+		t.Errorf("incorrect error for timeseries collection: got\n%s\nwant\n%s", got, config.ErrMongoTimeseriesNotNaive)
+	}
+	c.MongoUseNaive = true
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for timeseries collection with naive: %v", err)
+	}
+	c.MongoUseTimeseries = false
+
+	// Test TimescaleDB caggs validation
+	c.TimescaleUseCaggs = true
+	c.TimescaleUseTimeBucket = false
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for caggs without time bucket")
+	} else if got := err.Error(); got != config.ErrTimescaleCaggsNoBucket {
+		t.Errorf("incorrect error for caggs: got\n%s\nwant\n%s", got, config.ErrTimescaleCaggsNoBucket)
+	}
+	c.TimescaleUseTimeBucket = true
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for caggs with time bucket: %v", err)
+	}
+	c.TimescaleUseCaggs = false
+
 	// Test groups validation
 	c.InterleavedNumGroups = 0
 	err = c.Validate()
@@ -309,6 +356,15 @@ func TestGetUseCaseGenerator(t *testing.T) {
 	g.conf.MongoUseNaive = true
 	checkType(constants.FormatMongo, nmongo)
 
+	bm.UseTimeseries = true
+	tsmongo, err := bm.NewDevops(tsStart, tsEnd, scale)
+	if err != nil {
+		t.Fatalf("Error creating timeseries mongodb query generator")
+	}
+	if _, ok := tsmongo.(*mongo.TimeseriesDevops); !ok {
+		t.Errorf("mongo UseTimeseries did not produce a TimeseriesDevops: got %T", tsmongo)
+	}
+
 	bcc := clickhouse.BaseGenerator{}
 	clickh, err := bcc.NewDevops(tsStart, tsEnd, scale)
 	if err != nil {
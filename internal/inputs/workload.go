@@ -0,0 +1,197 @@
+package inputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	errWorkloadEmpty          = "workload file does not list any queries"
+	errWorkloadBadWeightFmt   = "workload query '%s' must have a positive weight"
+	errWorkloadDuplicateFmt   = "workload query name '%s' is used more than once, set a distinct name"
+	errWorkloadNoParamsFmt    = "query type '%s' for use case '%s' does not take parameters"
+	errWorkloadBadParamsFmt   = "workload query '%s': %v"
+	errCouldNotWriteCountsFmt = "could not write workload counts: %v"
+
+	workloadCountsSuffix = ".counts.json"
+)
+
+// Workload is a weighted mix of query types, read from a YAML file, e.g.:
+//
+//	queries:
+//	  - type: lastpoint
+//	    weight: 60
+//	  - type: single-groupby
+//	    weight: 30
+//	    params: {metrics: 1, hosts: 8, hours: 1}
+//	  - type: high-cpu-1
+//	    weight: 10
+type Workload struct {
+	Queries []WorkloadQuery `yaml:"queries"`
+}
+
+// WorkloadQuery is a single query type of a Workload. Type is a query type of
+// the use case matrix. If Params is set, Type must be a parameterized query
+// type and the parameters replace the ones of the matrix variants. Name is
+// used in the counts and defaults to Type.
+type WorkloadQuery struct {
+	Name   string                  `yaml:"name"`
+	Type   string                  `yaml:"type"`
+	Weight float64                 `yaml:"weight"`
+	Params *queryUtils.QueryParams `yaml:"params"`
+}
+
+// WorkloadCounts is the number of queries generated for each query type of a
+// workload and for each query label.
+type WorkloadCounts struct {
+	Seed    int64              `json:"seed"`
+	Total   int64              `json:"total"`
+	Types   map[string]int64   `json:"types"`
+	Labels  map[string]int64   `json:"labels"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+func readWorkload(filename string) (*Workload, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read workload file %s: %v", filename, err)
+	}
+	var w Workload
+	if err := yaml.UnmarshalStrict(b, &w); err != nil {
+		return nil, fmt.Errorf("cannot parse workload file %s: %v", filename, err)
+	}
+	return &w, nil
+}
+
+// workloadFiller is a QueryFiller that fills each query with one of the
+// fillers of a workload, picked at random according to their weights. It uses
+// its own PRNG so the sequence of query types only depends on the seed.
+type workloadFiller struct {
+	rand       *rand.Rand
+	names      []string
+	fillers    []queryUtils.QueryFiller
+	cumWeights []float64
+	// last is the index of the filler used for the latest query
+	last   int
+	counts map[string]int64
+}
+
+// newWorkloadFiller resolves every query of the workload to a QueryFiller
+// of the given use case.
+func (g *QueryGenerator) newWorkloadFiller(w *Workload, useGen queryUtils.QueryGenerator, seed int64) (*workloadFiller, error) {
+	if len(w.Queries) == 0 {
+		return nil, fmt.Errorf(errWorkloadEmpty)
+	}
+	use := g.conf.Use
+	f := &workloadFiller{
+		rand:   rand.New(rand.NewSource(seed)),
+		counts: make(map[string]int64),
+	}
+	total := 0.0
+	for _, wq := range w.Queries {
+		name := wq.Name
+		if name == "" {
+			name = wq.Type
+		}
+		if _, ok := f.counts[name]; ok {
+			return nil, fmt.Errorf(errWorkloadDuplicateFmt, name)
+		}
+		f.counts[name] = 0
+		if wq.Weight <= 0 {
+			return nil, fmt.Errorf(errWorkloadBadWeightFmt, name)
+		}
+
+		var maker queryUtils.QueryFillerMaker
+		if wq.Params == nil {
+			var ok bool
+			if maker, ok = g.useCaseMatrix[use][wq.Type]; !ok {
+				return nil, fmt.Errorf(errBadQueryTypeFmt, use, wq.Type)
+			}
+		} else {
+			paramMaker, ok := g.ParameterizedMatrix[use][wq.Type]
+			if !ok {
+				return nil, fmt.Errorf(errWorkloadNoParamsFmt, wq.Type, use)
+			}
+			var err error
+			if maker, err = paramMaker(*wq.Params); err != nil {
+				return nil, fmt.Errorf(errWorkloadBadParamsFmt, name, err)
+			}
+		}
+
+		total += wq.Weight
+		f.names = append(f.names, name)
+		f.fillers = append(f.fillers, maker(useGen))
+		f.cumWeights = append(f.cumWeights, total)
+	}
+	return f, nil
+}
+
+// Fill fills in the query.Query using a filler picked by weight.
+func (f *workloadFiller) Fill(q query.Query) query.Query {
+	r := f.rand.Float64() * f.cumWeights[len(f.cumWeights)-1]
+	f.last = sort.SearchFloat64s(f.cumWeights, r)
+	// a value on a boundary belongs to the next query type
+	if f.cumWeights[f.last] == r {
+		f.last++
+	}
+	return f.fillers[f.last].Fill(q)
+}
+
+// countLast records the latest query as written out.
+func (f *workloadFiller) countLast() {
+	f.counts[f.names[f.last]]++
+}
+
+func (f *workloadFiller) weights() map[string]float64 {
+	weights := make(map[string]float64, len(f.names))
+	prev := 0.0
+	for i, name := range f.names {
+		weights[name] = f.cumWeights[i] - prev
+		prev = f.cumWeights[i]
+	}
+	return weights
+}
+
+// writeWorkloadCounts writes the per type and per label counts of a workload
+// run next to the generated queries. Without an output file or an explicit
+// counts file the per type counts are written to the debug output instead.
+func (g *QueryGenerator) writeWorkloadCounts(f *workloadFiller, labels map[string]int64) error {
+	filename := g.conf.WorkloadCountsFile
+	if filename == "" && g.conf.File != "" {
+		filename = g.conf.File + workloadCountsSuffix
+	}
+	if filename == "" {
+		for _, name := range f.names {
+			if _, err := fmt.Fprintf(g.DebugOut, "workload %s: %d queries\n", name, f.counts[name]); err != nil {
+				return fmt.Errorf(errCouldNotWriteCountsFmt, err)
+			}
+		}
+		return nil
+	}
+
+	counts := WorkloadCounts{
+		Seed:    g.conf.Seed,
+		Types:   f.counts,
+		Labels:  labels,
+		Weights: f.weights(),
+	}
+	for _, c := range labels {
+		counts.Total += c
+	}
+	b, err := json.MarshalIndent(&counts, "", "  ")
+	if err != nil {
+		return fmt.Errorf(errCouldNotWriteCountsFmt, err)
+	}
+	if err := ioutil.WriteFile(filename, b, 0644); err != nil {
+		return fmt.Errorf(errCouldNotWriteCountsFmt, err)
+	}
+	return nil
+}
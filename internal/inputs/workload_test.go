@@ -0,0 +1,217 @@
+package inputs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const testWorkload = `
+queries:
+  - type: lastpoint
+    weight: 6
+  - type: single-groupby-1-1-1
+    weight: 3
+  - name: high-cpu-8
+    type: high-cpu
+    weight: 1
+    params: {hosts: 8}
+`
+
+func getTestWorkloadGenerator(t *testing.T, dir string) *QueryGenerator {
+	workloadFile := filepath.Join(dir, "workload.yaml")
+	if err := ioutil.WriteFile(workloadFile, []byte(testWorkload), 0644); err != nil {
+		t.Fatalf("could not write workload file: %v", err)
+	}
+	c, g := getTestConfigAndGenerator()
+	c.QueryType = ""
+	c.WorkloadFile = workloadFile
+	c.Limit = 1000
+	c.File = filepath.Join(dir, "queries")
+	g.useCaseMatrix[common.UseCaseCPUOnly][devops.LabelLastpoint] = devops.NewLastPointPerHost
+	g.ParameterizedMatrix = map[string]map[string]queryUtils.ParameterizedQueryFillerMaker{
+		common.UseCaseCPUOnly: {
+			devops.LabelHighCPU: func(p queryUtils.QueryParams) (queryUtils.QueryFillerMaker, error) {
+				return devops.NewHighCPU(p.Hosts), nil
+			},
+		},
+	}
+	g.DebugOut = ioutil.Discard
+	return g
+}
+
+func TestQueryGeneratorGenerateWorkload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs-workload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var counts [2]WorkloadCounts
+	var output [2][]byte
+	for i := range counts {
+		g := getTestWorkloadGenerator(t, dir)
+		if err := g.Generate(g.conf); err != nil {
+			t.Fatalf("unexpected error when generating: %v", err)
+		}
+		output[i], err = ioutil.ReadFile(g.conf.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadFile(g.conf.File + workloadCountsSuffix)
+		if err != nil {
+			t.Fatalf("counts file not written: %v", err)
+		}
+		if err := json.Unmarshal(b, &counts[i]); err != nil {
+			t.Fatalf("could not decode counts: %v", err)
+		}
+	}
+
+	if !bytes.Equal(output[0], output[1]) {
+		t.Errorf("output differs between runs with the same seed")
+	}
+	if !reflect.DeepEqual(counts[0], counts[1]) {
+		t.Errorf("counts differ between runs with the same seed: %v, %v", counts[0], counts[1])
+	}
+
+	got := counts[0]
+	if got.Total != 1000 {
+		t.Errorf("incorrect total: got %d want %d", got.Total, 1000)
+	}
+	sum := int64(0)
+	for _, c := range got.Labels {
+		sum += c
+	}
+	if sum != got.Total {
+		t.Errorf("label counts add up to %d, want %d", sum, got.Total)
+	}
+	want := map[string]int64{"lastpoint": 600, "single-groupby-1-1-1": 300, "high-cpu-8": 100}
+	for name, w := range want {
+		if c := got.Types[name]; c < w*8/10 || c > w*12/10 {
+			t.Errorf("count for %s too far from its weight: got %d want ~%d", name, c, w)
+		}
+	}
+	if got.Weights["high-cpu-8"] != 1 {
+		t.Errorf("incorrect weight recorded for high-cpu-8: got %v", got.Weights["high-cpu-8"])
+	}
+}
+
+func TestQueryGeneratorWorkloadInterleaved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs-workload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	total := int64(0)
+	for id := uint(0); id < 2; id++ {
+		g := getTestWorkloadGenerator(t, dir)
+		g.conf.InterleavedGroupID = id
+		g.conf.InterleavedNumGroups = 2
+		if err := g.Generate(g.conf); err != nil {
+			t.Fatalf("unexpected error when generating: %v", err)
+		}
+		b, err := ioutil.ReadFile(g.conf.File + workloadCountsSuffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var counts WorkloadCounts
+		if err := json.Unmarshal(b, &counts); err != nil {
+			t.Fatal(err)
+		}
+		total += counts.Total
+	}
+	if total != 1000 {
+		t.Errorf("interleaved groups wrote %d queries, want %d", total, 1000)
+	}
+}
+
+func TestNewWorkloadFillerErrors(t *testing.T) {
+	c, g := getTestConfigAndGenerator()
+	if err := g.init(c); err != nil {
+		t.Fatal(err)
+	}
+	useGen, err := g.getUseCaseGenerator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		desc     string
+		workload Workload
+		want     string
+	}{
+		{
+			desc: "empty",
+			want: errWorkloadEmpty,
+		},
+		{
+			desc:     "unknown type",
+			workload: Workload{Queries: []WorkloadQuery{{Type: "foo", Weight: 1}}},
+			want:     fmt.Sprintf(errBadQueryTypeFmt, common.UseCaseCPUOnly, "foo"),
+		},
+		{
+			desc:     "no weight",
+			workload: Workload{Queries: []WorkloadQuery{{Type: "single-groupby-1-1-1"}}},
+			want:     fmt.Sprintf(errWorkloadBadWeightFmt, "single-groupby-1-1-1"),
+		},
+		{
+			desc: "duplicate",
+			workload: Workload{Queries: []WorkloadQuery{
+				{Type: "single-groupby-1-1-1", Weight: 1},
+				{Type: "single-groupby-1-1-1", Weight: 2},
+			}},
+			want: fmt.Sprintf(errWorkloadDuplicateFmt, "single-groupby-1-1-1"),
+		},
+		{
+			desc: "params not supported",
+			workload: Workload{Queries: []WorkloadQuery{
+				{Type: "single-groupby-1-1-1", Weight: 1, Params: &queryUtils.QueryParams{Hosts: 2}},
+			}},
+			want: fmt.Sprintf(errWorkloadNoParamsFmt, "single-groupby-1-1-1", common.UseCaseCPUOnly),
+		},
+	}
+	for _, c := range cases {
+		_, err := g.newWorkloadFiller(&c.workload, useGen, 123)
+		if err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if got := err.Error(); got != c.want {
+			t.Errorf("%s: incorrect error: got\n%s\nwant\n%s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestWorkloadFillerFill(t *testing.T) {
+	c, g := getTestConfigAndGenerator()
+	if err := g.init(c); err != nil {
+		t.Fatal(err)
+	}
+	useGen, err := g.getUseCaseGenerator(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Workload{Queries: []WorkloadQuery{{Type: "single-groupby-1-1-1", Weight: 1}}}
+	f, err := g.newWorkloadFiller(w, useGen, 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := f.Fill(useGen.GenerateEmptyQuery())
+	if !strings.Contains(string(q.HumanLabelName()), "1 cpu metric(s), random    1 hosts") {
+		t.Errorf("query not filled by the only filler: %s", q.HumanLabelName())
+	}
+	f.countLast()
+	if got := f.counts["single-groupby-1-1-1"]; got != 1 {
+		t.Errorf("incorrect count: got %d want 1", got)
+	}
+	q.Release()
+}
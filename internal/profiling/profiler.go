@@ -0,0 +1,302 @@
+// Package profiling takes profiles of the benchmark clients and samples the
+// resources they use, to tell whether a run was bound by the client or by
+// the database.
+package profiling
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of profiles.
+const (
+	KindCPU   = "cpu"
+	KindHeap  = "heap"
+	KindMutex = "mutex"
+	KindBlock = "block"
+	KindTrace = "trace"
+)
+
+// DefaultKinds are the profiles taken when none are given.
+const DefaultKinds = KindCPU + "," + KindHeap
+
+const (
+	// mutexProfileFraction samples one in this many mutex contention events
+	// during the windows
+	mutexProfileFraction = 10
+	// blockProfileRate samples one blocking event per this many nanoseconds
+	// spent blocked during the windows
+	blockProfileRate = int(10 * time.Microsecond)
+)
+
+// Window is a period of a run to profile, starting Start after the start of
+// the run. A Duration of 0 lasts until the end of the run.
+type Window struct {
+	Start    time.Duration
+	Duration time.Duration
+}
+
+// ParseWindows parses the comma separated START:DURATION windows of s, e.g.
+// '30s:1m,10m:1m'. The windows must be in order and must not overlap. An
+// empty s is a single window lasting the whole run.
+func ParseWindows(s string) ([]Window, error) {
+	if s == "" {
+		return []Window{{}}, nil
+	}
+	var windows []Window
+	var prevEnd time.Duration
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ":")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid profile window '%s': expected START:DURATION", part)
+		}
+		start, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid start of profile window '%s': %v", part, err)
+		}
+		duration, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration of profile window '%s': %v", part, err)
+		}
+		if start < 0 || duration <= 0 {
+			return nil, fmt.Errorf("invalid profile window '%s': start must not be negative and duration must be positive", part)
+		}
+		if start < prevEnd {
+			return nil, fmt.Errorf("profile window '%s' overlaps the previous one", part)
+		}
+		prevEnd = start + duration
+		windows = append(windows, Window{Start: start, Duration: duration})
+	}
+	return windows, nil
+}
+
+// ParseKinds parses the comma separated kinds of profiles of s.
+func ParseKinds(s string) ([]string, error) {
+	if s == "" {
+		s = DefaultKinds
+	}
+	var kinds []string
+	seen := make(map[string]bool)
+	for _, kind := range strings.Split(s, ",") {
+		kind = strings.TrimSpace(kind)
+		switch kind {
+		case KindCPU, KindHeap, KindMutex, KindBlock, KindTrace:
+		default:
+			return nil, fmt.Errorf("unknown profile '%s': expected %s, %s, %s, %s or %s", kind, KindCPU, KindHeap, KindMutex, KindBlock, KindTrace)
+		}
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// Profiler takes the profiles of a run during its windows, writing them to
+// <dir>/<kind>-<window>.pprof, and the execution trace to
+// <dir>/trace-<window>.out.
+//
+// The CPU profile and the trace only cover their window. The heap profile is
+// taken at the end of the window, and like the mutex and block profiles,
+// which are only sampled during the windows, its counters add up from one
+// window to the next: the profile of the previous window can be given as
+// -base to go tool pprof to only keep those of the window.
+type Profiler struct {
+	dir     string
+	kinds   []string
+	windows []Window
+
+	stop chan struct{}
+	done chan struct{}
+	// open are the files of the CPU profile and the trace of the current
+	// window
+	open  map[string]*os.File
+	mu    sync.Mutex
+	files []string
+}
+
+// NewProfiler returns a Profiler writing the profiles of kinds during the
+// windows to dir, as parsed by ParseKinds and ParseWindows. The directory is
+// created if needed. An empty dir returns a nil Profiler, which does
+// nothing.
+func NewProfiler(dir, kinds, windows string) (*Profiler, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	p := &Profiler{dir: dir, open: make(map[string]*os.File)}
+	var err error
+	if p.kinds, err = ParseKinds(kinds); err != nil {
+		return nil, err
+	}
+	if p.windows, err = ParseWindows(windows); err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("could not create profile directory: %v", err)
+	}
+	return p, nil
+}
+
+// Start starts profiling the windows of a run started at start.
+func (p *Profiler) Start(start time.Time) {
+	if p == nil {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.run(start)
+}
+
+// Stop ends the current window at the end of the run, and cancels the ones
+// not started yet.
+func (p *Profiler) Stop() {
+	if p == nil || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+}
+
+// Files returns the profiles written so far.
+func (p *Profiler) Files() []string {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.files...)
+}
+
+func (p *Profiler) run(start time.Time) {
+	defer close(p.done)
+	for i, w := range p.windows {
+		if !p.waitUntil(start.Add(w.Start)) {
+			return
+		}
+		p.begin(i)
+		if w.Duration == 0 {
+			<-p.stop
+			p.end(i)
+			return
+		}
+		stopped := !p.waitUntil(start.Add(w.Start + w.Duration))
+		p.end(i)
+		if stopped {
+			return
+		}
+	}
+}
+
+// waitUntil waits until t, returning false if the profiler was stopped
+// before.
+func (p *Profiler) waitUntil(t time.Time) bool {
+	if !time.Now().Before(t) {
+		return true
+	}
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-p.stop:
+		return false
+	}
+}
+
+func (p *Profiler) fileName(kind string, window int) string {
+	if kind == KindTrace {
+		return filepath.Join(p.dir, fmt.Sprintf("%s-%d.out", kind, window))
+	}
+	return filepath.Join(p.dir, fmt.Sprintf("%s-%d.pprof", kind, window))
+}
+
+// begin starts the profiles of window i.
+func (p *Profiler) begin(i int) {
+	for _, kind := range p.kinds {
+		switch kind {
+		case KindCPU, KindTrace:
+			f, err := os.Create(p.fileName(kind, i))
+			if err != nil {
+				log.Printf("could not start %s profile: %v", kind, err)
+				continue
+			}
+			if kind == KindCPU {
+				err = pprof.StartCPUProfile(f)
+			} else {
+				err = trace.Start(f)
+			}
+			if err != nil {
+				log.Printf("could not start %s profile: %v", kind, err)
+				f.Close()
+				continue
+			}
+			p.open[kind] = f
+		case KindMutex:
+			runtime.SetMutexProfileFraction(mutexProfileFraction)
+		case KindBlock:
+			runtime.SetBlockProfileRate(blockProfileRate)
+		}
+	}
+}
+
+// end stops the profiles of window i and writes the ones taken at its end.
+func (p *Profiler) end(i int) {
+	for _, kind := range p.kinds {
+		switch kind {
+		case KindCPU, KindTrace:
+			f, ok := p.open[kind]
+			if !ok {
+				continue
+			}
+			if kind == KindCPU {
+				pprof.StopCPUProfile()
+			} else {
+				trace.Stop()
+			}
+			delete(p.open, kind)
+			p.closeProfile(f)
+		case KindHeap:
+			// the heap profile is as of the last GC
+			runtime.GC()
+			p.writeProfile(kind, i)
+		case KindMutex:
+			p.writeProfile(kind, i)
+			runtime.SetMutexProfileFraction(0)
+		case KindBlock:
+			p.writeProfile(kind, i)
+			runtime.SetBlockProfileRate(0)
+		}
+	}
+}
+
+func (p *Profiler) writeProfile(kind string, i int) {
+	f, err := os.Create(p.fileName(kind, i))
+	if err != nil {
+		log.Printf("could not write %s profile: %v", kind, err)
+		return
+	}
+	if err = pprof.Lookup(kind).WriteTo(f, 0); err != nil {
+		log.Printf("could not write %s profile: %v", kind, err)
+		f.Close()
+		return
+	}
+	p.closeProfile(f)
+}
+
+func (p *Profiler) closeProfile(f *os.File) {
+	if err := f.Close(); err != nil {
+		log.Printf("could not write profile %s: %v", f.Name(), err)
+		return
+	}
+	p.mu.Lock()
+	p.files = append(p.files, f.Name())
+	p.mu.Unlock()
+}
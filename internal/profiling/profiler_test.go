@@ -0,0 +1,119 @@
+package profiling
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWindows(t *testing.T) {
+	cases := []struct {
+		desc      string
+		in        string
+		want      []Window
+		shouldErr bool
+	}{
+		{desc: "whole run", in: "", want: []Window{{}}},
+		{desc: "one window", in: "30s:1m", want: []Window{{Start: 30 * time.Second, Duration: time.Minute}}},
+		{
+			desc: "two windows",
+			in:   "0s:10s, 10s:5s",
+			want: []Window{{Duration: 10 * time.Second}, {Start: 10 * time.Second, Duration: 5 * time.Second}},
+		},
+		{desc: "no duration", in: "30s", shouldErr: true},
+		{desc: "bad start", in: "x:1m", shouldErr: true},
+		{desc: "bad duration", in: "1m:x", shouldErr: true},
+		{desc: "zero duration", in: "1m:0s", shouldErr: true},
+		{desc: "overlap", in: "0s:1m,30s:1m", shouldErr: true},
+		{desc: "out of order", in: "1m:1s,0s:1s", shouldErr: true},
+	}
+	for _, c := range cases {
+		got, err := ParseWindows(c.in)
+		if c.shouldErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.desc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: incorrect windows: got %v want %v", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestParseKinds(t *testing.T) {
+	got, err := ParseKinds("")
+	if err != nil || !reflect.DeepEqual(got, []string{KindCPU, KindHeap}) {
+		t.Errorf("incorrect default kinds: got %v, %v", got, err)
+	}
+	got, err = ParseKinds("trace, mutex,block,mutex")
+	if err != nil || !reflect.DeepEqual(got, []string{KindTrace, KindMutex, KindBlock}) {
+		t.Errorf("incorrect kinds: got %v, %v", got, err)
+	}
+	if _, err = ParseKinds("cpu,goroutine"); err == nil {
+		t.Errorf("expected an error for an unknown kind")
+	}
+}
+
+func TestProfiler(t *testing.T) {
+	if p, err := NewProfiler("", "", ""); p != nil || err != nil {
+		t.Fatalf("empty directory should return a nil profiler: got %v, %v", p, err)
+	}
+	var nilProfiler *Profiler
+	nilProfiler.Start(time.Now())
+	nilProfiler.Stop()
+
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir = filepath.Join(dir, "run")
+	p, err := NewProfiler(dir, "cpu,heap,mutex,block,trace", "0s:10ms,20ms:10ms,1h:1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Start(time.Now())
+	time.Sleep(100 * time.Millisecond)
+	p.Stop()
+	var want []string
+	for _, i := range []string{"0", "1"} {
+		want = append(want,
+			filepath.Join(dir, "cpu-"+i+".pprof"),
+			filepath.Join(dir, "heap-"+i+".pprof"),
+			filepath.Join(dir, "mutex-"+i+".pprof"),
+			filepath.Join(dir, "block-"+i+".pprof"),
+			filepath.Join(dir, "trace-"+i+".out"),
+		)
+	}
+	if got := p.Files(); !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect files:\ngot  %v\nwant %v", got, want)
+	}
+	for _, f := range want {
+		if info, err := os.Stat(f); err != nil || info.Size() == 0 {
+			t.Errorf("profile %s not written: %v", f, err)
+		}
+	}
+}
+
+func TestProfilerStopEndsWindow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p, err := NewProfiler(dir, "cpu", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Start(time.Now())
+	p.Stop()
+	if got := p.Files(); len(got) != 1 || got[0] != filepath.Join(dir, "cpu-0.pprof") {
+		t.Errorf("incorrect files: got %v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
+)
+
+// Sample is the resources used by the process over a period.
+type Sample struct {
+	// CPUPercent is the CPU used, where 100 is one core, and RSS the
+	// resident memory at the end of the period. They are only valid if
+	// ProcOK, as they are read from the OS
+	CPUPercent float64
+	RSS        uint64
+	ProcOK     bool
+	Goroutines int
+	NumGC      uint32
+	GCPause    time.Duration
+}
+
+// ReportHeader is the header of the columns of Sample.ReportColumns.
+const ReportHeader = ",client CPU %,client RSS MB,goroutines,GCs,GC pause ms"
+
+// ReportColumns returns the columns of the sample for a periodic report.
+func (s Sample) ReportColumns() string {
+	cpu, rss := "-", "-"
+	if s.ProcOK {
+		cpu = fmt.Sprintf("%0.1f", s.CPUPercent)
+		rss = fmt.Sprintf("%0.1f", float64(s.RSS)/1e6)
+	}
+	return fmt.Sprintf(",%s,%s,%d,%d,%0.3f", cpu, rss, s.Goroutines, s.NumGC, float64(s.GCPause)/float64(time.Millisecond))
+}
+
+// String returns the sample as a line of text.
+func (s Sample) String() string {
+	proc := ""
+	if s.ProcOK {
+		proc = fmt.Sprintf("CPU %0.1f%%, RSS %0.1fMB, ", s.CPUPercent, float64(s.RSS)/1e6)
+	}
+	return fmt.Sprintf("%s%d goroutines, %d GCs paused %0.3fms", proc, s.Goroutines, s.NumGC, float64(s.GCPause)/float64(time.Millisecond))
+}
+
+// counters are the cumulative counters of the process at a time.
+type counters struct {
+	at      time.Time
+	cpu     time.Duration
+	numGC   uint32
+	gcPause time.Duration
+}
+
+// Sampler samples the CPU, memory, goroutines and GC pauses of the process
+// since the previous sample, and keeps the totals and maximums of the
+// samples. It can be used from several goroutines.
+type Sampler struct {
+	proc *process.Process
+
+	mu    sync.Mutex
+	start counters
+	prev  counters
+	// procOK is false once the CPU or memory of the process could not be
+	// read
+	procOK        bool
+	maxCPUPercent float64
+	maxRSS        uint64
+	maxGoroutines int
+}
+
+// NewSampler returns a Sampler whose first sample starts now.
+func NewSampler() *Sampler {
+	s := &Sampler{}
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	s.proc, s.procOK = proc, err == nil
+	c, _ := s.read()
+	s.start, s.prev = c, c
+	return s
+}
+
+// read returns the counters of the process and its RSS.
+func (s *Sampler) read() (counters, uint64) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	c := counters{at: time.Now(), numGC: m.NumGC, gcPause: time.Duration(m.PauseTotalNs)}
+	if !s.procOK {
+		return c, 0
+	}
+	times, err := s.proc.Times()
+	if err != nil {
+		s.procOK = false
+		return c, 0
+	}
+	c.cpu = time.Duration((times.User + times.System) * float64(time.Second))
+	mem, err := s.proc.MemoryInfo()
+	if err != nil {
+		s.procOK = false
+		return c, 0
+	}
+	return c, mem.RSS
+}
+
+// since returns the sample of the period from prev to c.
+func (s *Sampler) since(prev, c counters, rss uint64) Sample {
+	sample := Sample{
+		RSS:        rss,
+		ProcOK:     s.procOK,
+		Goroutines: runtime.NumGoroutine(),
+		NumGC:      c.numGC - prev.numGC,
+		GCPause:    c.gcPause - prev.gcPause,
+	}
+	if took := c.at.Sub(prev.at); took > 0 {
+		sample.CPUPercent = 100 * (c.cpu - prev.cpu).Seconds() / took.Seconds()
+	}
+	return sample
+}
+
+// Sample returns the sample of the period since the previous one.
+func (s *Sampler) Sample() Sample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, rss := s.read()
+	sample := s.since(s.prev, c, rss)
+	s.prev = c
+	if sample.CPUPercent > s.maxCPUPercent {
+		s.maxCPUPercent = sample.CPUPercent
+	}
+	if sample.RSS > s.maxRSS {
+		s.maxRSS = sample.RSS
+	}
+	if sample.Goroutines > s.maxGoroutines {
+		s.maxGoroutines = sample.Goroutines
+	}
+	return sample
+}
+
+// Total returns the sample of the run up to the last sample, and the
+// maximum CPU, RSS and goroutines of the samples.
+func (s *Sampler) Total() (total Sample, max Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total = s.since(s.start, s.prev, s.maxRSS)
+	max = Sample{CPUPercent: s.maxCPUPercent, RSS: s.maxRSS, ProcOK: s.procOK, Goroutines: s.maxGoroutines}
+	return total, max
+}
+
+// Summary returns the totals of the run up to the last sample as text.
+func (s *Sampler) Summary() string {
+	total, max := s.Total()
+	proc := ""
+	if total.ProcOK {
+		proc = fmt.Sprintf("used %0.1f%% CPU (max %0.1f%%), max RSS %0.1fMB, ", total.CPUPercent, max.CPUPercent, float64(max.RSS)/1e6)
+	}
+	return fmt.Sprintf("client %smax %d goroutines, %d GCs paused %0.3fms", proc, max.Goroutines, total.NumGC, float64(total.GCPause)/float64(time.Millisecond))
+}
+
+// Totals returns the totals of the run up to the last sample for the
+// results.
+func (s *Sampler) Totals() map[string]interface{} {
+	total, max := s.Total()
+	totals := map[string]interface{}{
+		"maxGoroutines": max.Goroutines,
+		"numGC":         total.NumGC,
+		"gcPauseMillis": float64(total.GCPause) / float64(time.Millisecond),
+	}
+	if total.ProcOK {
+		totals["meanCPUPercent"] = total.CPUPercent
+		totals["maxCPUPercent"] = max.CPUPercent
+		totals["maxRSSBytes"] = max.RSS
+	}
+	return totals
+}
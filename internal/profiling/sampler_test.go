@@ -0,0 +1,53 @@
+package profiling
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSampleReportColumns(t *testing.T) {
+	s := Sample{CPUPercent: 150, RSS: 25e6, ProcOK: true, Goroutines: 12, NumGC: 3, GCPause: 1500 * time.Microsecond}
+	if got := s.ReportColumns(); got != ",150.0,25.0,12,3,1.500" {
+		t.Errorf("incorrect columns: got %q", got)
+	}
+	if got := s.String(); got != "CPU 150.0%, RSS 25.0MB, 12 goroutines, 3 GCs paused 1.500ms" {
+		t.Errorf("incorrect string: got %q", got)
+	}
+	s.ProcOK = false
+	if got := s.ReportColumns(); got != ",-,-,12,3,1.500" {
+		t.Errorf("incorrect columns without the process stats: got %q", got)
+	}
+}
+
+func TestSampler(t *testing.T) {
+	s := NewSampler()
+	// use some CPU and collect the garbage
+	deadline := time.Now().Add(50 * time.Millisecond)
+	for time.Now().Before(deadline) {
+	}
+	runtime.GC()
+	sample := s.Sample()
+	if sample.NumGC == 0 || sample.Goroutines == 0 {
+		t.Errorf("incorrect sample: %+v", sample)
+	}
+	if sample.ProcOK && (sample.CPUPercent <= 0 || sample.RSS == 0) {
+		t.Errorf("incorrect process stats: %+v", sample)
+	}
+	runtime.GC()
+	second := s.Sample()
+	total, max := s.Total()
+	if total.NumGC != sample.NumGC+second.NumGC || total.GCPause != sample.GCPause+second.GCPause {
+		t.Errorf("incorrect total: got %+v from %+v and %+v", total, sample, second)
+	}
+	if max.CPUPercent < sample.CPUPercent || max.CPUPercent < second.CPUPercent || max.Goroutines < sample.Goroutines {
+		t.Errorf("incorrect maximums: %+v", max)
+	}
+	totals := s.Totals()
+	if totals["numGC"] != total.NumGC {
+		t.Errorf("incorrect totals: %v", totals)
+	}
+	if _, ok := totals["meanCPUPercent"]; ok != sample.ProcOK {
+		t.Errorf("process stats should be in the totals only if they could be read: %v", totals)
+	}
+}
@@ -0,0 +1,369 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/load/insertstrategy"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// Defaults of the auto-tune mode
+const (
+	DefaultAutoTuneWorkers    = "1,2,4,8,16,32"
+	DefaultAutoTuneBatchSizes = "1000,5000,10000,50000"
+	DefaultAutoTuneProbe      = 30 * time.Second
+	DefaultAutoTunePlateau    = 0.05
+)
+
+// loadFn loads the points of ds with workers workers, in batches of batchSize
+// points, and returns once they are all inserted.
+type loadFn func(b targets.Benchmark, ds targets.DataSource, workers, batchSize uint)
+
+// AutoTuneProbe is the outcome of one probe phase of the auto-tune mode.
+type AutoTuneProbe struct {
+	Workers   uint `json:"workers"`
+	BatchSize uint `json:"batchSize"`
+	// TargetRate is the ingest rate of the probe, 0 for no limit
+	TargetRate       float64 `json:"targetRate,omitempty"`
+	DurationMillis   int64   `json:"durationMillis"`
+	MetricRate       float64 `json:"metricRate"`
+	RowRate          float64 `json:"rowRate,omitempty"`
+	AchievedRate     float64 `json:"achievedRate,omitempty"`
+	Batches          uint64  `json:"batches"`
+	P50LatencyMillis float64 `json:"p50LatencyMillis"`
+	P99LatencyMillis float64 `json:"p99LatencyMillis"`
+	// Complete is false when the data ran out before the end of the probe
+	Complete bool `json:"complete"`
+}
+
+// throughput is the rate the probes are compared by: rows per second if the
+// target counts rows, metrics per second otherwise.
+func (p *AutoTuneProbe) throughput() float64 {
+	if p.RowRate > 0 {
+		return p.RowRate
+	}
+	return p.MetricRate
+}
+
+// AutoTuneResult is the best configuration found by the auto-tune mode and the
+// throughput curve of all its probes.
+type AutoTuneResult struct {
+	Best *AutoTuneProbe `json:"best,omitempty"`
+	// SustainableRate is the highest target rate of auto-tune-rates which was
+	// achieved with the best configuration
+	SustainableRate float64         `json:"sustainableRate,omitempty"`
+	RateUnit        string          `json:"rateUnit,omitempty"`
+	Probes          []AutoTuneProbe `json:"probes"`
+}
+
+// autoTuneConfig is what the search of the best configuration goes through.
+type autoTuneConfig struct {
+	workers    []uint
+	batchSizes []uint
+	rates      []float64
+	// plateau is the relative increase of throughput under which adding
+	// workers, or growing the batches, is not worth it
+	plateau    float64
+	maxLatency time.Duration
+}
+
+// probeFn runs a probe phase with workers workers, batches of batchSize
+// points and a target ingest rate, 0 for no limit.
+type probeFn func(workers, batchSize uint, rate float64) AutoTuneProbe
+
+func newAutoTuneConfig(c *BenchmarkRunnerConfig) (*autoTuneConfig, error) {
+	workers, err := parseUints(c.AutoTuneWorkers, DefaultAutoTuneWorkers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auto-tune-workers: %v", err)
+	}
+	batchSizes, err := parseUints(c.AutoTuneBatchSizes, DefaultAutoTuneBatchSizes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auto-tune-batch-sizes: %v", err)
+	}
+	var rates []float64
+	if c.AutoTuneRates != "" {
+		for _, s := range strings.Split(c.AutoTuneRates, ",") {
+			rate, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil || rate <= 0 {
+				return nil, fmt.Errorf("invalid auto-tune-rates: '%s' is not a positive rate", s)
+			}
+			rates = append(rates, rate)
+		}
+		sort.Float64s(rates)
+	}
+	if c.InsertIntervals != "" || c.IngestRate != "" {
+		return nil, fmt.Errorf("auto-tune cannot be used with insert-intervals or ingest-rate, use auto-tune-rates")
+	}
+	plateau := c.AutoTunePlateau
+	if plateau <= 0 {
+		plateau = DefaultAutoTunePlateau
+	}
+	return &autoTuneConfig{
+		workers:    workers,
+		batchSizes: batchSizes,
+		rates:      rates,
+		plateau:    plateau,
+		maxLatency: c.AutoTuneMaxLatency,
+	}, nil
+}
+
+// parseUints parses a sorted list of the positive integers of s, or of def if
+// s is empty.
+func parseUints(s, def string) ([]uint, error) {
+	if s == "" {
+		s = def
+	}
+	var values []uint
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 0)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("'%s' is not a positive integer", v)
+		}
+		values = append(values, uint(n))
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values, nil
+}
+
+// search hill-climbs the grid of workers x batch sizes: for each batch size
+// the workers are increased until the throughput plateaus or the p99 batch
+// latency goes past the threshold, and the batch sizes are increased until the
+// best throughput of a batch size plateaus. The target rates are then tried in
+// increasing order with the best configuration until one is not achieved.
+// The search stops when the data runs out.
+func (c *autoTuneConfig) search(probe probeFn) *AutoTuneResult {
+	result := &AutoTuneResult{}
+	run := func(workers, batchSize uint, rate float64) (*AutoTuneProbe, bool) {
+		p := probe(workers, batchSize, rate)
+		result.Probes = append(result.Probes, p)
+		return &result.Probes[len(result.Probes)-1], p.Complete
+	}
+	improves := func(p, than *AutoTuneProbe) bool {
+		return than == nil || p.throughput() >= than.throughput()*(1+c.plateau)
+	}
+
+	var best AutoTuneProbe
+	found := false
+	complete := true
+	for _, batchSize := range c.batchSizes {
+		var bestOfSize *AutoTuneProbe
+		for _, workers := range c.workers {
+			p, ok := run(workers, batchSize, 0)
+			if !ok {
+				complete = false
+				break
+			}
+			if c.overLatency(p) {
+				break
+			}
+			plateaued := !improves(p, bestOfSize)
+			if bestOfSize == nil || p.throughput() > bestOfSize.throughput() {
+				bestOfSize = p
+			}
+			if plateaued {
+				break
+			}
+		}
+		if bestOfSize == nil {
+			// larger batches would only take longer to insert
+			break
+		}
+		plateaued := found && !improves(bestOfSize, &best)
+		if !found || bestOfSize.throughput() > best.throughput() {
+			best, found = *bestOfSize, true
+		}
+		if plateaued || !complete {
+			break
+		}
+	}
+	if !found {
+		return result
+	}
+	result.Best = &best
+
+	for _, rate := range c.rates {
+		if !complete {
+			break
+		}
+		p, ok := run(best.Workers, best.BatchSize, rate)
+		if !ok {
+			break
+		}
+		if c.overLatency(p) || p.AchievedRate < rate*(1-c.plateau) {
+			break
+		}
+		result.SustainableRate = rate
+	}
+	return result
+}
+
+func (c *autoTuneConfig) overLatency(p *AutoTuneProbe) bool {
+	return c.maxLatency > 0 && p.P99LatencyMillis > float64(c.maxLatency)/float64(time.Millisecond)
+}
+
+// latencyRecorder collects how long the batches of a probe took to insert.
+type latencyRecorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+}
+
+func (r *latencyRecorder) record(d time.Duration) {
+	r.mu.Lock()
+	r.latencies = append(r.latencies, d)
+	r.mu.Unlock()
+}
+
+// percentile returns the latency q (0 < q <= 1) of the batches are under, in
+// milliseconds.
+func (r *latencyRecorder) percentile(q float64) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	i := int(math.Ceil(q*float64(len(r.latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return float64(r.latencies[i]) / float64(time.Millisecond)
+}
+
+func (r *latencyRecorder) count() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return uint64(len(r.latencies))
+}
+
+// probeDataSource ends the points of the underlying DataSource at a deadline,
+// so a probe phase stops there while the next one goes on with the next point.
+// It also ends them after limit points over all the probes, unless limit is 0.
+type probeDataSource struct {
+	ds        targets.DataSource
+	deadline  time.Time
+	limit     uint64
+	read      uint64
+	exhausted bool
+}
+
+func (d *probeDataSource) Headers() *common.GeneratedDataHeaders {
+	return d.ds.Headers()
+}
+
+func (d *probeDataSource) NextItem() data.LoadedPoint {
+	if d.exhausted || time.Now().After(d.deadline) {
+		return data.LoadedPoint{}
+	}
+	if d.limit > 0 && d.read >= d.limit {
+		d.exhausted = true
+		return data.LoadedPoint{}
+	}
+	p := d.ds.NextItem()
+	if p.Data == nil {
+		d.exhausted = true
+		return p
+	}
+	d.read++
+	return p
+}
+
+// autoTune runs the probe phases of the auto-tune mode with load, instead of
+// loading all the data with the configured workers and batch size.
+func (l *CommonBenchmarkRunner) autoTune(b targets.Benchmark, load loadFn) {
+	ds := &probeDataSource{ds: b.GetDataSource(), limit: l.Limit}
+	unit := l.IngestRateUnit
+	if unit == "" {
+		unit = insertstrategy.RatePoints
+	}
+	probe := func(workers, batchSize uint, rate float64) AutoTuneProbe {
+		l.latencies = &latencyRecorder{}
+		l.rateLimiter = nil
+		if rate > 0 {
+			var err error
+			if l.rateLimiter, err = insertstrategy.NewRateLimiter(strconv.FormatFloat(rate, 'f', -1, 64), unit); err != nil {
+				panic(fmt.Sprintf("could not run auto-tune probe: %v", err))
+			}
+		}
+		metrics, rows, units := atomic.LoadUint64(&l.metricCnt), atomic.LoadUint64(&l.rowCnt), atomic.LoadUint64(&l.rateUnitCnt)
+		start := time.Now()
+		ds.deadline = start.Add(l.AutoTuneProbe)
+		if l.rateLimiter != nil {
+			l.rateLimiter.Start(start)
+		}
+		load(b, ds, workers, batchSize)
+		took := time.Since(start).Seconds()
+
+		p := AutoTuneProbe{
+			Workers:          workers,
+			BatchSize:        batchSize,
+			TargetRate:       rate,
+			DurationMillis:   int64(took * 1000),
+			MetricRate:       float64(atomic.LoadUint64(&l.metricCnt)-metrics) / took,
+			RowRate:          float64(atomic.LoadUint64(&l.rowCnt)-rows) / took,
+			Batches:          l.latencies.count(),
+			P50LatencyMillis: l.latencies.percentile(0.5),
+			P99LatencyMillis: l.latencies.percentile(0.99),
+			Complete:         !ds.exhausted,
+		}
+		if rate > 0 {
+			p.AchievedRate = float64(atomic.LoadUint64(&l.rateUnitCnt)-units) / took
+		}
+		l.printProbe(&p, unit)
+		return p
+	}
+
+	printFn("%sauto-tune: probing for %v each\n", l.ReportPrefix, l.AutoTuneProbe)
+	l.autoTuneResult = l.autoTuneConfig.search(probe)
+	if len(l.autoTuneConfig.rates) > 0 {
+		l.autoTuneResult.RateUnit = unit
+	}
+	l.latencies = nil
+	l.rateLimiter = nil
+}
+
+func (l *CommonBenchmarkRunner) printProbe(p *AutoTuneProbe, unit string) {
+	rate := ""
+	if p.TargetRate > 0 {
+		rate = fmt.Sprintf(", target %0.2f %s/s achieved %0.2f", p.TargetRate, unit, p.AchievedRate)
+	}
+	partial := ""
+	if !p.Complete {
+		partial = " (out of data)"
+	}
+	printFn("%sauto-tune: %d workers, batch size %d%s: %0.2f metrics/sec, %0.2f rows/sec, p50/p99 batch latency %0.2f/%0.2fms%s\n",
+		l.ReportPrefix, p.Workers, p.BatchSize, rate, p.MetricRate, p.RowRate, p.P50LatencyMillis, p.P99LatencyMillis, partial)
+}
+
+// autoTuneSummary prints the best configuration found by the auto-tune mode,
+// and the whole result as JSON unless it goes to the results file.
+func (l *CommonBenchmarkRunner) autoTuneSummary() {
+	r := l.autoTuneResult
+	if r.Best == nil {
+		printFn("%sauto-tune: no complete probe within the latency threshold, the data may have run out too soon\n", l.ReportPrefix)
+	} else {
+		printFn("%sauto-tune: best configuration %d workers, batch size %d (%0.2f metrics/sec, %0.2f rows/sec, p99 batch latency %0.2fms)\n",
+			l.ReportPrefix, r.Best.Workers, r.Best.BatchSize, r.Best.MetricRate, r.Best.RowRate, r.Best.P99LatencyMillis)
+	}
+	if r.SustainableRate > 0 {
+		printFn("%sauto-tune: sustainable rate %0.2f %s/s\n", l.ReportPrefix, r.SustainableRate, r.RateUnit)
+	}
+	if l.ResultsFile != "" {
+		return
+	}
+	out, err := json.MarshalIndent(r, "", " ")
+	if err != nil {
+		fatal("could not marshal auto-tune result: %v", err)
+		return
+	}
+	printFn("%s\n", out)
+}
@@ -0,0 +1,203 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+)
+
+// fakeProbes returns a probeFn of the throughput and p99 latency of
+// throughput, and records the probed configurations.
+type fakeProbes struct {
+	throughput func(workers, batchSize uint) float64
+	latency    func(workers, batchSize uint) float64
+	achieved   func(rate float64) float64
+	// complete is the number of probes before the data runs out, 0 for never
+	complete int
+	probed   [][2]uint
+}
+
+func (f *fakeProbes) probe(workers, batchSize uint, rate float64) AutoTuneProbe {
+	f.probed = append(f.probed, [2]uint{workers, batchSize})
+	p := AutoTuneProbe{
+		Workers:    workers,
+		BatchSize:  batchSize,
+		TargetRate: rate,
+		RowRate:    f.throughput(workers, batchSize),
+		Complete:   f.complete == 0 || len(f.probed) < f.complete,
+	}
+	if f.latency != nil {
+		p.P99LatencyMillis = f.latency(workers, batchSize)
+	}
+	if rate > 0 {
+		p.AchievedRate = f.achieved(rate)
+	}
+	return p
+}
+
+func TestAutoTuneSearch(t *testing.T) {
+	config := &autoTuneConfig{
+		workers:    []uint{1, 2, 4, 8},
+		batchSizes: []uint{100, 1000, 10000},
+		plateau:    0.05,
+	}
+	// the throughput plateaus at 4 workers and batches of 1000
+	f := &fakeProbes{throughput: func(workers, batchSize uint) float64 {
+		if workers > 4 {
+			workers = 4
+		}
+		if batchSize > 1000 {
+			batchSize = 1000
+		}
+		return float64(workers * batchSize)
+	}}
+	r := config.search(f.probe)
+	if r.Best == nil || r.Best.Workers != 4 || r.Best.BatchSize != 1000 {
+		t.Fatalf("incorrect best configuration: %+v", r.Best)
+	}
+	want := [][2]uint{{1, 100}, {2, 100}, {4, 100}, {8, 100}, {1, 1000}, {2, 1000}, {4, 1000}, {8, 1000}, {1, 10000}, {2, 10000}, {4, 10000}, {8, 10000}}
+	if len(f.probed) != len(want) || len(r.Probes) != len(want) {
+		t.Fatalf("incorrect probes: got %v want %v", f.probed, want)
+	}
+	for i := range want {
+		if f.probed[i] != want[i] {
+			t.Errorf("incorrect probe %d: got %v want %v", i, f.probed[i], want[i])
+		}
+	}
+}
+
+func TestAutoTuneSearchLatency(t *testing.T) {
+	config := &autoTuneConfig{
+		workers:    []uint{1, 2, 4, 8},
+		batchSizes: []uint{100, 1000},
+		plateau:    0.05,
+		maxLatency: 50 * time.Millisecond,
+	}
+	f := &fakeProbes{
+		throughput: func(workers, batchSize uint) float64 { return float64(workers * batchSize) },
+		latency:    func(workers, batchSize uint) float64 { return float64(workers*batchSize) / 10 },
+	}
+	r := config.search(f.probe)
+	// more than 500 points in flight take more than 50ms
+	if r.Best == nil || r.Best.Workers != 4 || r.Best.BatchSize != 100 {
+		t.Fatalf("incorrect best configuration: %+v", r.Best)
+	}
+	if len(r.Probes) != 5 {
+		t.Errorf("incorrect number of probes: got %d want 5", len(r.Probes))
+	}
+
+	config.maxLatency = time.Millisecond
+	if r = config.search(f.probe); r.Best != nil {
+		t.Errorf("expected no best configuration, got %+v", r.Best)
+	}
+}
+
+func TestAutoTuneSearchRates(t *testing.T) {
+	config := &autoTuneConfig{
+		workers:    []uint{1},
+		batchSizes: []uint{100},
+		rates:      []float64{1000, 2000, 3000, 4000},
+		plateau:    0.05,
+	}
+	f := &fakeProbes{
+		throughput: func(workers, batchSize uint) float64 { return 2500 },
+		achieved: func(rate float64) float64 {
+			if rate > 2500 {
+				return 2500
+			}
+			return rate
+		},
+	}
+	r := config.search(f.probe)
+	if r.SustainableRate != 2000 {
+		t.Errorf("incorrect sustainable rate: got %f want 2000", r.SustainableRate)
+	}
+	if len(r.Probes) != 4 {
+		t.Errorf("incorrect number of probes: got %d want 4", len(r.Probes))
+	}
+}
+
+func TestAutoTuneSearchOutOfData(t *testing.T) {
+	config := &autoTuneConfig{
+		workers:    []uint{1, 2, 4},
+		batchSizes: []uint{100, 1000},
+		rates:      []float64{1000},
+		plateau:    0.05,
+	}
+	f := &fakeProbes{
+		throughput: func(workers, batchSize uint) float64 { return float64(workers * batchSize) },
+		complete:   3,
+	}
+	r := config.search(f.probe)
+	if r.Best == nil || r.Best.Workers != 2 {
+		t.Fatalf("incorrect best configuration: %+v", r.Best)
+	}
+	if len(r.Probes) != 3 || r.Probes[2].Complete {
+		t.Errorf("incorrect probes: %+v", r.Probes)
+	}
+}
+
+func TestNewAutoTuneConfig(t *testing.T) {
+	c, err := newAutoTuneConfig(&BenchmarkRunnerConfig{AutoTuneWorkers: "8, 2,4", AutoTuneRates: "300,100"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.workers) != 3 || c.workers[0] != 2 || c.workers[2] != 8 {
+		t.Errorf("incorrect workers: %v", c.workers)
+	}
+	if len(c.batchSizes) != 4 || c.plateau != DefaultAutoTunePlateau {
+		t.Errorf("incorrect defaults: %v %f", c.batchSizes, c.plateau)
+	}
+	if len(c.rates) != 2 || c.rates[0] != 100 {
+		t.Errorf("incorrect rates: %v", c.rates)
+	}
+
+	invalid := []BenchmarkRunnerConfig{
+		{AutoTuneWorkers: "0,1"},
+		{AutoTuneBatchSizes: "x"},
+		{AutoTuneRates: "-1"},
+		{IngestRate: "1000"},
+		{InsertIntervals: "1"},
+	}
+	for _, c := range invalid {
+		if _, err := newAutoTuneConfig(&c); err == nil {
+			t.Errorf("expected an error for %+v", c)
+		}
+	}
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	r := &latencyRecorder{}
+	if got := r.percentile(0.99); got != 0 {
+		t.Errorf("incorrect percentile without latencies: got %f", got)
+	}
+	for i := 100; i > 0; i-- {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+	if got := r.percentile(0.5); got != 50 {
+		t.Errorf("incorrect p50: got %f want 50", got)
+	}
+	if got := r.percentile(0.99); got != 99 {
+		t.Errorf("incorrect p99: got %f want 99", got)
+	}
+	if r.count() != 100 {
+		t.Errorf("incorrect count: got %d", r.count())
+	}
+}
+
+func TestProbeDataSource(t *testing.T) {
+	ds := &probeDataSource{ds: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{1, 2, 3}))}, limit: 2}
+	if p := ds.NextItem(); p.Data != nil {
+		t.Errorf("expected no point after the deadline, got %v", p.Data)
+	}
+	ds.deadline = time.Now().Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if p := ds.NextItem(); p.Data != byte(i+1) {
+			t.Errorf("incorrect point %d: got %v", i, p.Data)
+		}
+	}
+	if p := ds.NextItem(); p.Data != nil || !ds.exhausted {
+		t.Errorf("expected the limit to end the points, got %v", p.Data)
+	}
+}
@@ -0,0 +1,242 @@
+package load
+
+import (
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// scanOptions are how the scanners fill the batches, besides their size.
+type scanOptions struct {
+	// bySeries fills a batch per series instead of per channel, with an
+	// AffinityIndexer
+	bySeries bool
+	// maxAge is the time after which a batch which is not full is sent
+	// anyway, 0 for no limit. It is checked as the items are scanned.
+	maxAge time.Duration
+	// maxSeries is the number of series batches filled at once with
+	// bySeries, 0 for no limit. The batches are all sent when one more is
+	// needed, so that the memory they hold stays bounded.
+	maxSeries uint
+	// stats, if not nil, counts the batches sent and their series
+	stats *batchStats
+	// checkpoints, if not nil, numbers the items and the batches are sent as
+	// trackedBatch
+	checkpoints *checkpointTracker
+}
+
+// batchStats counts the batches sent to the workers, and the distinct series
+// they contained when the PointIndexer is an AffinityIndexer.
+type batchStats struct {
+	affinity   bool
+	batches    uint64
+	series     uint64
+	maxSeries  uint64
+	ageFlushes uint64
+	// seriesFlushes counts the batches sent because too many series batches
+	// were filled at once
+	seriesFlushes uint64
+}
+
+// meanSeries returns the mean number of distinct series per batch.
+func (s *batchStats) meanSeries() float64 {
+	if s.batches == 0 {
+		return 0
+	}
+	return float64(s.series) / float64(s.batches)
+}
+
+func hash(s string) uint {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return uint(h.Sum32())
+}
+
+// fillingBatch is a batch being filled, with the channel it is sent to.
+type fillingBatch struct {
+	batch   targets.Batch
+	channel uint
+	// started is when the first item was appended, if there is a maxAge
+	started time.Time
+	// series holds the affinity keys of the items
+	series map[string]struct{}
+	// first is the number of the first item, if there are checkpoints
+	first uint64
+}
+
+// sendFn sends batch b, ready, to channel idx.
+type sendFn func(idx uint, b targets.Batch)
+
+// batchFiller appends the scanned items to the batch of their channel, or
+// of their series, and sends the batches once they are full or too old.
+type batchFiller struct {
+	factory     targets.BatchFactory
+	indexer     targets.PointIndexer
+	affinity    targets.AffinityIndexer
+	numChannels uint
+	batchSize   uint
+	opts        scanOptions
+	send        sendFn
+
+	channels []*fillingBatch
+	series   map[string]*fillingBatch
+	// lastAgeCheck is when the age of the batches was last checked
+	lastAgeCheck time.Time
+}
+
+func newBatchFiller(
+	factory targets.BatchFactory, indexer targets.PointIndexer, numChannels, batchSize uint, opts scanOptions, send sendFn,
+) *batchFiller {
+	f := &batchFiller{
+		factory:     factory,
+		indexer:     indexer,
+		numChannels: numChannels,
+		batchSize:   batchSize,
+		opts:        opts,
+		send:        send,
+		channels:    make([]*fillingBatch, numChannels),
+		series:      make(map[string]*fillingBatch),
+	}
+	f.affinity, _ = indexer.(targets.AffinityIndexer)
+	if f.opts.stats == nil {
+		f.opts.stats = &batchStats{}
+	}
+	f.opts.stats.affinity = f.affinity != nil
+	for i := range f.channels {
+		f.channels[i] = f.newFillingBatch(uint(i))
+	}
+	if opts.maxAge > 0 {
+		f.lastAgeCheck = time.Now()
+	}
+	return f
+}
+
+func (f *batchFiller) newFillingBatch(channel uint) *fillingBatch {
+	fb := &fillingBatch{batch: f.factory.New(), channel: channel}
+	if f.affinity != nil {
+		fb.series = make(map[string]struct{})
+	}
+	return fb
+}
+
+// add appends item to its batch, and sends the batch if it is full, and
+// the batches older than the max age.
+func (f *batchFiller) add(item data.LoadedPoint) {
+	var fb *fillingBatch
+	key, hasKey := "", false
+	if f.affinity != nil {
+		key, hasKey = f.affinity.AffinityKey(item)
+	}
+	if hasKey {
+		idx := hash(key) % f.numChannels
+		if f.opts.bySeries {
+			fb = f.series[key]
+			if fb == nil {
+				if f.opts.maxSeries > 0 && uint(len(f.series)) >= f.opts.maxSeries {
+					f.sendSeries()
+				}
+				fb = f.newFillingBatch(idx)
+				f.series[key] = fb
+			}
+		} else {
+			fb = f.channels[idx]
+		}
+		fb.series[key] = struct{}{}
+	} else {
+		fb = f.channels[f.indexer.GetIndex(item)]
+	}
+
+	if f.opts.checkpoints != nil {
+		first := fb.batch.Len() == 0
+		if n := f.opts.checkpoints.scan(first); first {
+			fb.first = n
+		}
+	}
+	fb.batch.Append(item)
+	if f.opts.maxAge > 0 && fb.batch.Len() == 1 {
+		fb.started = time.Now()
+	}
+
+	if fb.batch.Len() >= f.batchSize {
+		// Batch is full (contains at least batchSize items) - ready to be sent to worker
+		f.sendBatch(fb, hasKey && f.opts.bySeries, key)
+	}
+	if f.opts.maxAge > 0 {
+		f.sendExpired()
+	}
+}
+
+// sendBatch sends fb and replaces it with a new empty batch, or forgets it
+// if it holds a single series.
+func (f *batchFiller) sendBatch(fb *fillingBatch, isSeries bool, key string) {
+	stats := f.opts.stats
+	stats.batches++
+	if n := uint64(len(fb.series)); n > 0 {
+		stats.series += n
+		if n > stats.maxSeries {
+			stats.maxSeries = n
+		}
+	}
+	if f.opts.checkpoints != nil {
+		f.send(fb.channel, &trackedBatch{Batch: fb.batch, first: fb.first})
+	} else {
+		f.send(fb.channel, fb.batch)
+	}
+	if isSeries {
+		delete(f.series, key)
+		return
+	}
+	f.channels[fb.channel] = f.newFillingBatch(fb.channel)
+}
+
+// sendExpired sends the batches filled for longer than the max age. The
+// batches are checked at most ten times per max age.
+func (f *batchFiller) sendExpired() {
+	now := time.Now()
+	if now.Sub(f.lastAgeCheck) < f.opts.maxAge/10 {
+		return
+	}
+	f.lastAgeCheck = now
+	for _, fb := range f.channels {
+		if fb.batch.Len() > 0 && now.Sub(fb.started) >= f.opts.maxAge {
+			f.opts.stats.ageFlushes++
+			f.sendBatch(fb, false, "")
+		}
+	}
+	for key, fb := range f.series {
+		if now.Sub(fb.started) >= f.opts.maxAge {
+			f.opts.stats.ageFlushes++
+			f.sendBatch(fb, true, key)
+		}
+	}
+}
+
+// sendSeries sends all the series batches, in the order of their series.
+func (f *batchFiller) sendSeries() {
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		f.opts.stats.seriesFlushes++
+		f.sendBatch(f.series[key], true, key)
+	}
+}
+
+// flush sends all the batches which are not empty, e.g. once there are no
+// more items to scan.
+func (f *batchFiller) flush() {
+	for _, fb := range f.channels {
+		// Do not enqueue empty batches (with 0 items)
+		if fb.batch.Len() > 0 {
+			f.sendBatch(fb, false, "")
+		}
+	}
+	for key, fb := range f.series {
+		f.sendBatch(fb, true, key)
+	}
+}
@@ -0,0 +1,184 @@
+package load
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+type itemsBatch struct {
+	items []byte
+}
+
+func (b *itemsBatch) Len() uint { return uint(len(b.items)) }
+
+func (b *itemsBatch) Append(p data.LoadedPoint) {
+	b.items = append(b.items, p.Data.(byte))
+}
+
+type itemsFactory struct{}
+
+func (f *itemsFactory) New() targets.Batch {
+	return &itemsBatch{}
+}
+
+// parityIndexer puts the items in the series of their parity, except the
+// items 0xff which have no series.
+type parityIndexer struct{}
+
+func (i *parityIndexer) GetIndex(data.LoadedPoint) uint {
+	return 0
+}
+
+func (i *parityIndexer) AffinityKey(p data.LoadedPoint) (string, bool) {
+	b := p.Data.(byte)
+	if b == 0xff {
+		return "", false
+	}
+	return fmt.Sprintf("series%d", b%2), true
+}
+
+type sentBatch struct {
+	channel uint
+	items   []byte
+}
+
+func fillBatches(indexer targets.PointIndexer, numChannels, batchSize uint, opts scanOptions, items []byte) []sentBatch {
+	var sent []sentBatch
+	f := newBatchFiller(&itemsFactory{}, indexer, numChannels, batchSize, opts, func(idx uint, b targets.Batch) {
+		sent = append(sent, sentBatch{channel: idx, items: b.(*itemsBatch).items})
+	})
+	for _, item := range items {
+		f.add(data.NewLoadedPoint(item))
+	}
+	f.flush()
+	return sent
+}
+
+func TestBatchFiller(t *testing.T) {
+	series0 := hash("series0") % 2
+	series1 := hash("series1") % 2
+	cases := []struct {
+		desc        string
+		indexer     targets.PointIndexer
+		numChannels uint
+		batchSize   uint
+		bySeries    bool
+		maxSeries   uint
+		items       []byte
+		want        []sentBatch
+		wantStats   batchStats
+	}{
+		{
+			desc:        "without affinity",
+			indexer:     &modIndexer{mod: 2},
+			numChannels: 2,
+			batchSize:   2,
+			items:       []byte{0, 1, 2, 3, 4},
+			want:        []sentBatch{{0, []byte{0, 2}}, {1, []byte{1, 3}}, {0, []byte{4}}},
+			wantStats:   batchStats{batches: 3},
+		},
+		{
+			desc:        "with affinity",
+			indexer:     &parityIndexer{},
+			numChannels: 1,
+			batchSize:   2,
+			items:       []byte{0, 1, 2, 4, 0xff},
+			want:        []sentBatch{{0, []byte{0, 1}}, {0, []byte{2, 4}}, {0, []byte{0xff}}},
+			wantStats:   batchStats{affinity: true, batches: 3, series: 3, maxSeries: 2},
+		},
+		{
+			desc:        "with affinity, series on their channel",
+			indexer:     &parityIndexer{},
+			numChannels: 2,
+			batchSize:   2,
+			items:       []byte{0, 2, 1},
+			want:        []sentBatch{{series0, []byte{0, 2}}, {series1, []byte{1}}},
+			wantStats:   batchStats{affinity: true, batches: 2, series: 2, maxSeries: 1},
+		},
+		{
+			desc:        "by series",
+			indexer:     &parityIndexer{},
+			numChannels: 1,
+			batchSize:   2,
+			bySeries:    true,
+			items:       []byte{0, 1, 2, 3, 4},
+			want:        []sentBatch{{0, []byte{0, 2}}, {0, []byte{1, 3}}, {0, []byte{4}}},
+			wantStats:   batchStats{affinity: true, batches: 3, series: 3, maxSeries: 1},
+		},
+		{
+			desc:        "by series, max series",
+			indexer:     &parityIndexer{},
+			numChannels: 1,
+			batchSize:   2,
+			bySeries:    true,
+			maxSeries:   1,
+			items:       []byte{0, 1, 2, 3},
+			want:        []sentBatch{{0, []byte{0}}, {0, []byte{1}}, {0, []byte{2}}, {0, []byte{3}}},
+			wantStats:   batchStats{affinity: true, batches: 4, series: 4, maxSeries: 1, seriesFlushes: 3},
+		},
+		{
+			desc:        "by series, without affinity",
+			indexer:     &modIndexer{mod: 1},
+			numChannels: 1,
+			batchSize:   2,
+			bySeries:    true,
+			items:       []byte{0, 1, 2},
+			want:        []sentBatch{{0, []byte{0, 1}}, {0, []byte{2}}},
+			wantStats:   batchStats{batches: 2},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			stats := &batchStats{}
+			opts := scanOptions{bySeries: c.bySeries, maxSeries: c.maxSeries, stats: stats}
+			got := fillBatches(c.indexer, c.numChannels, c.batchSize, opts, c.items)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("incorrect batches: got %v want %v", got, c.want)
+			}
+			if *stats != c.wantStats {
+				t.Errorf("incorrect stats: got %+v want %+v", *stats, c.wantStats)
+			}
+		})
+	}
+}
+
+func TestBatchFillerMaxAge(t *testing.T) {
+	stats := &batchStats{}
+	var sent []targets.Batch
+	opts := scanOptions{maxAge: 10 * time.Millisecond, stats: stats}
+	f := newBatchFiller(&itemsFactory{}, &modIndexer{mod: 1}, 1, 10, opts, func(idx uint, b targets.Batch) {
+		sent = append(sent, b)
+	})
+	f.add(data.NewLoadedPoint(byte(0)))
+	if len(sent) != 0 {
+		t.Fatalf("batch sent before max age: %d batches", len(sent))
+	}
+	time.Sleep(20 * time.Millisecond)
+	f.add(data.NewLoadedPoint(byte(1)))
+	if len(sent) != 1 || sent[0].Len() != 2 {
+		t.Fatalf("batch not sent after max age: %v", sent)
+	}
+	if stats.ageFlushes != 1 {
+		t.Errorf("incorrect age flushes: got %d want 1", stats.ageFlushes)
+	}
+	f.flush()
+	if len(sent) != 1 {
+		t.Errorf("empty batch sent on flush")
+	}
+}
+
+func TestBatchStatsMeanSeries(t *testing.T) {
+	s := batchStats{}
+	if got := s.meanSeries(); got != 0 {
+		t.Errorf("incorrect mean without batches: got %f", got)
+	}
+	s = batchStats{batches: 4, series: 6}
+	if got := s.meanSeries(); got != 1.5 {
+		t.Errorf("incorrect mean: got %f want 1.5", got)
+	}
+}
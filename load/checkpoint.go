@@ -0,0 +1,404 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const defaultCheckpointPeriod = time.Minute
+
+// Checkpoint records how far the items of the data source were inserted, so
+// that a load which stopped can be resumed from there with resume-from.
+type Checkpoint struct {
+	DBName string `json:"dbName"`
+	// Offset is the number of items from the start of the data source which
+	// were all inserted. Some items after it may have been inserted too
+	Offset uint64 `json:"offset"`
+	// Files are the positions in the data files up to which all the items
+	// were inserted, when the data source reads files. A resumed load seeks
+	// to them instead of reading past Offset items
+	Files []CheckpointFile `json:"files,omitempty"`
+	// Complete is true once all the items were inserted
+	Complete bool `json:"complete"`
+	// Segments are the runs of the load, one more per resume
+	Segments []CheckpointSegment `json:"segments"`
+}
+
+// CheckpointSegment is what one run of a resumed load inserted.
+type CheckpointSegment struct {
+	StartTime      int64  `json:"startTime"`
+	DurationMillis int64  `json:"durationMillis"`
+	StartOffset    uint64 `json:"startOffset"`
+	Offset         uint64 `json:"offset"`
+	Metrics        uint64 `json:"metrics"`
+	Rows           uint64 `json:"rows"`
+}
+
+// CheckpointFile is the position in a data file up to which all the items
+// were inserted.
+type CheckpointFile struct {
+	Name     string `json:"name"`
+	Position int64  `json:"position"`
+}
+
+// totals returns what all the segments inserted and how long they took.
+func (c *Checkpoint) totals() (metrics, rows uint64, took time.Duration) {
+	for _, s := range c.Segments {
+		metrics += s.Metrics
+		rows += s.Rows
+		took += time.Duration(s.DurationMillis) * time.Millisecond
+	}
+	return metrics, rows, took
+}
+
+// readCheckpoint reads the checkpoint of a previous load of dbName.
+func readCheckpoint(fileName, dbName string) (*Checkpoint, error) {
+	b, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint: %v", err)
+	}
+	c := &Checkpoint{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint %s: %v", fileName, err)
+	}
+	if c.DBName != dbName {
+		return nil, fmt.Errorf("checkpoint %s is of database '%s', not '%s'", fileName, c.DBName, dbName)
+	}
+	return c, nil
+}
+
+// writeCheckpoint replaces the checkpoint file with c, through a temporary
+// file so that it is never left half written.
+func writeCheckpoint(fileName string, c *Checkpoint) error {
+	b, err := json.MarshalIndent(c, "", " ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(fileName), filepath.Base(fileName)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fileName)
+}
+
+// checkpointTracker follows which scanned items were inserted. Items are
+// numbered as they are scanned, and each batch is tracked by the number of its
+// first item from when it starts being filled until it is inserted. All the
+// items before the first item of the oldest batch not yet inserted are then
+// inserted. When the data source reads files, the positions in the files
+// before the first item of each batch are tracked as well.
+type checkpointTracker struct {
+	mu sync.Mutex
+	// origin is the offset of the first item scanned in the data source
+	origin  uint64
+	scanned uint64
+	// pending are the positions in the files before the first item of the
+	// batches not yet inserted, nil without files
+	pending map[uint64][]int64
+	// ds is the data source of the files, if any, and positions the
+	// positions in them after the last item scanned
+	ds        targets.SeekableDataSource
+	positions []int64
+}
+
+func newCheckpointTracker(origin uint64) *checkpointTracker {
+	return &checkpointTracker{origin: origin, pending: make(map[uint64][]int64)}
+}
+
+// track tracks the positions in the files of ds, from where it is now.
+func (t *checkpointTracker) track(ds targets.SeekableDataSource) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ds = ds
+	t.positions = ds.Positions(nil)
+}
+
+// copyPositions returns a copy of the positions after the last item scanned.
+func (t *checkpointTracker) copyPositions() []int64 {
+	if t.ds == nil {
+		return nil
+	}
+	return append([]int64(nil), t.positions...)
+}
+
+// scan numbers the next scanned item, which is the first item of its batch
+// if first.
+func (t *checkpointTracker) scan(first bool) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := t.scanned
+	t.scanned++
+	if first {
+		t.pending[n] = t.copyPositions()
+	}
+	if t.ds != nil {
+		t.positions = t.ds.Positions(t.positions[:0])
+	}
+	return n
+}
+
+// done records that the batch starting at item first was inserted.
+func (t *checkpointTracker) done(first uint64) {
+	t.mu.Lock()
+	delete(t.pending, first)
+	t.mu.Unlock()
+}
+
+// offset returns the offset in the data source up to which all the items
+// were inserted, and the positions in the files up to which they were, if
+// any.
+func (t *checkpointTracker) offset() (uint64, []int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	low := t.scanned
+	for n := range t.pending {
+		if n < low {
+			low = n
+		}
+	}
+	if low == t.scanned {
+		return t.origin + low, t.copyPositions()
+	}
+	return t.origin + low, t.pending[low]
+}
+
+// files returns the names of the data files tracked, if any.
+func (t *checkpointTracker) files() []string {
+	if t.ds == nil {
+		return nil
+	}
+	return t.ds.Files()
+}
+
+// trackedBatch is a batch sent with the number of its first item when there
+// are checkpoints. Workers insert the batch it wraps.
+type trackedBatch struct {
+	targets.Batch
+	first uint64
+}
+
+// untrackBatch returns the batch to insert and a function to call once it is
+// inserted.
+func (l *CommonBenchmarkRunner) untrackBatch(batch targets.Batch) (targets.Batch, func()) {
+	tb, ok := batch.(*trackedBatch)
+	if !ok {
+		return batch, func() {}
+	}
+	return tb.Batch, func() { l.checkpoints.done(tb.first) }
+}
+
+// initCheckpoints reads the checkpoint to resume from, if any, and sets up
+// the checkpoints of this run.
+func (l *CommonBenchmarkRunner) initCheckpoints() error {
+	if l.ResumeFrom == "" && l.CheckpointFile == "" {
+		return nil
+	}
+	if l.AutoTune {
+		return fmt.Errorf("checkpoint-file and resume-from cannot be used with auto-tune")
+	}
+	l.checkpoint = &Checkpoint{DBName: l.DBName}
+	if l.ResumeFrom != "" {
+		c, err := readCheckpoint(l.ResumeFrom, l.DBName)
+		if err != nil {
+			return err
+		}
+		l.checkpoint = c
+		// the database holds the data of the previous segments
+		l.DoCreateDB = false
+		l.DoAbortOnExist = false
+		if l.CheckpointFile == "" {
+			l.CheckpointFile = l.ResumeFrom
+		}
+	}
+	if l.CheckpointPeriod <= 0 {
+		l.CheckpointPeriod = defaultCheckpointPeriod
+	}
+	l.checkpoints = newCheckpointTracker(l.checkpoint.Offset)
+	return nil
+}
+
+// checkOrdered returns an error if checkpoints are written or resumed from
+// while the items of ds may come in a different order on every run, as the
+// offset of a checkpoint would then skip other items than those inserted.
+// Data sources which seek to the positions of the checkpoint in their files
+// are resumed whatever the order of their items.
+func (l *CommonBenchmarkRunner) checkOrdered(ds targets.DataSource) error {
+	if l.checkpoints == nil {
+		return nil
+	}
+	if _, ok := ds.(targets.SeekableDataSource); ok {
+		return nil
+	}
+	if u, ok := ds.(targets.UnorderedDataSource); ok && u.Unordered() {
+		return fmt.Errorf("checkpoint-file and resume-from cannot be used with a data source read in no particular order, e.g. several data files this target cannot seek in")
+	}
+	return nil
+}
+
+// resumeOffset is the number of items of the data source inserted by the
+// previous segments.
+func (l *CommonBenchmarkRunner) resumeOffset() uint64 {
+	if l.checkpoint == nil {
+		return 0
+	}
+	return l.checkpoint.Offset
+}
+
+// skipInserted skips the items of ds inserted by the previous segments,
+// seeking to the positions of the checkpoint in the data files or else
+// reading past the items, e.g. of the simulator. It returns false if there is
+// nothing left to insert.
+func (l *CommonBenchmarkRunner) skipInserted(ds targets.DataSource) bool {
+	offset := l.resumeOffset()
+	if l.checkpoint != nil && l.checkpoint.Complete || l.Limit > 0 && offset >= l.Limit {
+		printFn("%sall the items were already inserted according to %s\n", l.ReportPrefix, l.ResumeFrom)
+		return false
+	}
+	if sds, ok := ds.(targets.SeekableDataSource); ok && l.checkpoint != nil && len(l.checkpoint.Files) > 0 {
+		return l.seekInserted(sds)
+	}
+	if offset == 0 {
+		return true
+	}
+	if u, ok := ds.(targets.UnorderedDataSource); ok && u.Unordered() {
+		fatal("checkpoint %s has no positions in the data files to resume from", l.ResumeFrom)
+		return false
+	}
+	printFn("%sresuming after %d items\n", l.ReportPrefix, offset)
+	for i := uint64(0); i < offset; i++ {
+		if ds.NextItem().Data == nil {
+			log.Printf("the data source ended after %d items, before the offset %d of the checkpoint", i, offset)
+			return false
+		}
+	}
+	return true
+}
+
+// seekInserted seeks to the positions of the checkpoint in the files of ds.
+func (l *CommonBenchmarkRunner) seekInserted(ds targets.SeekableDataSource) bool {
+	files := ds.Files()
+	if len(files) != len(l.checkpoint.Files) {
+		fatal("the checkpoint has the positions of %d data files, not %d", len(l.checkpoint.Files), len(files))
+		return false
+	}
+	positions := make([]int64, len(files))
+	for i, f := range l.checkpoint.Files {
+		if f.Name != files[i] {
+			fatal("the checkpoint has the position of data file %s, not %s", f.Name, files[i])
+			return false
+		}
+		positions[i] = f.Position
+	}
+	printFn("%sresuming after %d items, at their positions in %d data files\n", l.ReportPrefix, l.resumeOffset(), len(files))
+	if err := ds.Seek(positions); err != nil {
+		fatal("cannot resume: %v", err)
+		return false
+	}
+	return true
+}
+
+// loadWithCheckpoints skips the items inserted before a resume, and loads the
+// others with load while writing checkpoints.
+func (l *CommonBenchmarkRunner) loadWithCheckpoints(b targets.Benchmark, load loadFn, start *time.Time) {
+	ds := b.GetDataSource()
+	if !l.skipInserted(ds) {
+		return
+	}
+	if sds, ok := ds.(targets.SeekableDataSource); ok {
+		l.checkpoints.track(sds)
+	}
+	// the segment starts once the inserted items are skipped
+	*start = time.Now()
+	if l.rateLimiter != nil {
+		l.rateLimiter.Start(*start)
+	}
+	stopCheckpoints := l.startCheckpoints(*start)
+	load(b, ds, l.Workers, l.BatchSize)
+	stopCheckpoints()
+	l.saveCheckpoint(*start, time.Now(), true)
+}
+
+// startCheckpoints adds the segment of this run and writes a checkpoint every
+// checkpoint period, until the returned function is called.
+func (l *CommonBenchmarkRunner) startCheckpoints(start time.Time) func() {
+	if l.checkpoints == nil {
+		return func() {}
+	}
+	offset := l.resumeOffset()
+	l.checkpoint.Segments = append(l.checkpoint.Segments, CheckpointSegment{
+		StartTime:   start.Unix(),
+		StartOffset: offset,
+		Offset:      offset,
+	})
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(l.CheckpointPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				l.saveCheckpoint(start, now, false)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// saveCheckpoint updates the segment of this run and writes the checkpoint.
+func (l *CommonBenchmarkRunner) saveCheckpoint(start, now time.Time, complete bool) {
+	c := l.checkpoint
+	s := &c.Segments[len(c.Segments)-1]
+	var positions []int64
+	c.Offset, positions = l.checkpoints.offset()
+	c.Files = c.Files[:0]
+	for i, name := range l.checkpoints.files() {
+		c.Files = append(c.Files, CheckpointFile{Name: name, Position: positions[i]})
+	}
+	c.Complete = complete
+	s.Offset = c.Offset
+	s.DurationMillis = now.Sub(start).Milliseconds()
+	s.Metrics = atomic.LoadUint64(&l.metricCnt)
+	s.Rows = atomic.LoadUint64(&l.rowCnt)
+	if err := writeCheckpoint(l.CheckpointFile, c); err != nil {
+		log.Printf("could not write checkpoint %s: %v", l.CheckpointFile, err)
+	}
+}
+
+// resumedSummary prints what all the segments of a resumed load inserted.
+func (l *CommonBenchmarkRunner) resumedSummary() {
+	if l.checkpoint == nil || len(l.checkpoint.Segments) < 2 {
+		return
+	}
+	metrics, rows, took := l.checkpoint.totals()
+	printFn("%sover %d segments: loaded %d metrics in %0.3fsec (mean rate %0.2f metrics/sec)\n",
+		l.ReportPrefix, len(l.checkpoint.Segments), metrics, took.Seconds(), float64(metrics)/took.Seconds())
+	if rows > 0 {
+		printFn("%sover %d segments: loaded %d rows in %0.3fsec (mean rate %0.2f rows/sec)\n",
+			l.ReportPrefix, len(l.checkpoint.Segments), rows, took.Seconds(), float64(rows)/took.Seconds())
+	}
+}
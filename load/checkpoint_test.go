@@ -0,0 +1,314 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestCheckpointTracker(t *testing.T) {
+	tracker := newCheckpointTracker(100)
+	if got, _ := tracker.offset(); got != 100 {
+		t.Errorf("incorrect offset before scanning: got %d want 100", got)
+	}
+	// three batches of items 0-1, 2-3 and 4
+	for i, first := range []bool{true, false, true, false, true} {
+		if n := tracker.scan(first); n != uint64(i) {
+			t.Errorf("incorrect item number: got %d want %d", n, i)
+		}
+	}
+	steps := []struct {
+		done uint64
+		want uint64
+	}{
+		{done: 2, want: 100},
+		{done: 0, want: 104},
+		{done: 4, want: 105},
+	}
+	for _, s := range steps {
+		tracker.done(s.done)
+		if got, _ := tracker.offset(); got != s.want {
+			t.Errorf("incorrect offset after batch %d: got %d want %d", s.done, got, s.want)
+		}
+	}
+}
+
+// filesDataSource returns the items of two files in turn, and counts the
+// items read from each file as its position.
+type filesDataSource struct {
+	testDataSource
+	positions []int64
+	seeked    []int64
+}
+
+func newFilesDataSource(items ...byte) *filesDataSource {
+	return &filesDataSource{testDataSource: testDataSource{br: bufio.NewReader(bytes.NewReader(items))}, positions: make([]int64, 2)}
+}
+
+func (d *filesDataSource) NextItem() data.LoadedPoint {
+	p := d.testDataSource.NextItem()
+	if p.Data != nil {
+		d.positions[(d.called-1)%2]++
+	}
+	return p
+}
+
+func (d *filesDataSource) Unordered() bool { return true }
+
+func (d *filesDataSource) Files() []string { return []string{"a", "b"} }
+
+func (d *filesDataSource) Positions(positions []int64) []int64 {
+	return append(positions, d.positions...)
+}
+
+func (d *filesDataSource) Seek(positions []int64) error {
+	d.seeked = positions
+	return nil
+}
+
+func TestCheckpointTrackerPositions(t *testing.T) {
+	ds := newFilesDataSource(0, 1, 2, 3, 4)
+	tracker := newCheckpointTracker(10)
+	tracker.track(ds)
+	// three batches of items 0-1, 2-3 and 4
+	for _, first := range []bool{true, false, true, false, true} {
+		ds.NextItem()
+		tracker.scan(first)
+	}
+	steps := []struct {
+		done      uint64
+		want      uint64
+		positions []int64
+	}{
+		{done: 2, want: 10, positions: []int64{0, 0}},
+		{done: 0, want: 14, positions: []int64{2, 2}},
+		{done: 4, want: 15, positions: []int64{3, 2}},
+	}
+	for _, s := range steps {
+		tracker.done(s.done)
+		got, positions := tracker.offset()
+		if got != s.want || !reflect.DeepEqual(positions, s.positions) {
+			t.Errorf("incorrect offset after batch %d: got %d %v want %d %v", s.done, got, positions, s.want, s.positions)
+		}
+	}
+}
+
+func TestSaveCheckpointFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ds := newFilesDataSource(0, 1, 2)
+	br := &CommonBenchmarkRunner{
+		checkpoint:  &Checkpoint{DBName: "benchmark", Segments: []CheckpointSegment{{}}},
+		checkpoints: newCheckpointTracker(0),
+	}
+	br.CheckpointFile = filepath.Join(dir, "checkpoint.json")
+	br.checkpoints.track(ds)
+	for i := 0; i < 3; i++ {
+		ds.NextItem()
+		br.checkpoints.scan(true)
+	}
+	br.checkpoints.done(0)
+	br.checkpoints.done(1)
+	// saved twice to check that the files are replaced
+	for i := 0; i < 2; i++ {
+		br.saveCheckpoint(time.Now(), time.Now(), false)
+	}
+	got, err := readCheckpoint(br.CheckpointFile, "benchmark")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []CheckpointFile{{Name: "a", Position: 1}, {Name: "b", Position: 1}}
+	if got.Offset != 2 || !reflect.DeepEqual(got.Files, want) {
+		t.Errorf("incorrect checkpoint: got %d %v want 2 %v", got.Offset, got.Files, want)
+	}
+}
+
+func TestBatchFillerCheckpoints(t *testing.T) {
+	tracker := newCheckpointTracker(0)
+	var sent []*trackedBatch
+	f := newBatchFiller(&itemsFactory{}, &parityIndexer{}, 2, 2, scanOptions{bySeries: true, checkpoints: tracker}, func(idx uint, b targets.Batch) {
+		sent = append(sent, b.(*trackedBatch))
+	})
+	// the items are their own number
+	for _, item := range []byte{0, 1, 2, 3, 4} {
+		f.add(data.NewLoadedPoint(item))
+	}
+	if got, _ := tracker.offset(); got != 0 {
+		t.Errorf("incorrect offset before inserting: got %d want 0", got)
+	}
+	f.flush()
+	if len(sent) != 3 {
+		t.Fatalf("incorrect number of batches: got %d want 3", len(sent))
+	}
+	for _, tb := range sent {
+		if items := tb.Batch.(*itemsBatch).items; uint64(items[0]) != tb.first {
+			t.Errorf("incorrect first item of batch %v: got %d", items, tb.first)
+		}
+	}
+
+	br := &CommonBenchmarkRunner{checkpoints: tracker}
+	// the batch of items 1 and 3 holds back the offset
+	for _, tb := range sent {
+		if tb.first == 1 {
+			continue
+		}
+		batch, inserted := br.untrackBatch(tb)
+		if _, ok := batch.(*itemsBatch); !ok {
+			t.Errorf("batch not unwrapped: %T", batch)
+		}
+		inserted()
+	}
+	if got, _ := tracker.offset(); got != 1 {
+		t.Errorf("incorrect offset: got %d want 1", got)
+	}
+}
+
+func TestCheckpointFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "checkpoint.json")
+
+	c := &Checkpoint{DBName: "benchmark", Offset: 42, Segments: []CheckpointSegment{{StartOffset: 0, Offset: 42, Metrics: 420}}}
+	if err := writeCheckpoint(fileName, c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := readCheckpoint(fileName, "benchmark")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Offset != 42 || len(got.Segments) != 1 || got.Segments[0].Metrics != 420 {
+		t.Errorf("incorrect checkpoint: %+v", got)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Errorf("temporary checkpoint file left behind: %d files", len(files))
+	}
+
+	if _, err := readCheckpoint(fileName, "other"); err == nil {
+		t.Errorf("expected an error for the checkpoint of another database")
+	}
+	if _, err := readCheckpoint(filepath.Join(dir, "missing"), "benchmark"); err == nil {
+		t.Errorf("expected an error for a missing checkpoint")
+	}
+}
+
+func TestCheckpointTotals(t *testing.T) {
+	c := &Checkpoint{Segments: []CheckpointSegment{
+		{DurationMillis: 1000, Metrics: 100, Rows: 10},
+		{DurationMillis: 3000, Metrics: 300, Rows: 30},
+	}}
+	metrics, rows, took := c.totals()
+	if metrics != 400 || rows != 40 || took != 4*time.Second {
+		t.Errorf("incorrect totals: got %d %d %v", metrics, rows, took)
+	}
+}
+
+func TestSkipInserted(t *testing.T) {
+	oldPrintFn := printFn
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	defer func() { printFn = oldPrintFn }()
+
+	newDS := func() *testDataSource {
+		return &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{0, 1, 2, 3}))}
+	}
+	cases := []struct {
+		desc       string
+		checkpoint *Checkpoint
+		limit      uint64
+		want       bool
+		next       interface{}
+	}{
+		{desc: "no checkpoint", want: true, next: byte(0)},
+		{desc: "offset", checkpoint: &Checkpoint{Offset: 2}, want: true, next: byte(2)},
+		{desc: "complete", checkpoint: &Checkpoint{Offset: 4, Complete: true}},
+		{desc: "past the limit", checkpoint: &Checkpoint{Offset: 2}, limit: 2},
+		{desc: "past the data", checkpoint: &Checkpoint{Offset: 5}},
+	}
+	for _, c := range cases {
+		br := &CommonBenchmarkRunner{checkpoint: c.checkpoint}
+		br.Limit = c.limit
+		ds := newDS()
+		if got := br.skipInserted(ds); got != c.want {
+			t.Errorf("%s: incorrect result: got %v want %v", c.desc, got, c.want)
+		}
+		if c.want {
+			if next := ds.NextItem().Data; next != c.next {
+				t.Errorf("%s: incorrect next item: got %v want %v", c.desc, next, c.next)
+			}
+		}
+	}
+}
+
+func TestSkipInsertedSeek(t *testing.T) {
+	oldPrintFn, oldFatal := printFn, fatal
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	var fatalCalled bool
+	fatal = func(string, ...interface{}) { fatalCalled = true }
+	defer func() { printFn, fatal = oldPrintFn, oldFatal }()
+
+	cases := []struct {
+		desc       string
+		checkpoint *Checkpoint
+		want       bool
+		seeked     []int64
+	}{
+		{
+			desc:       "positions",
+			checkpoint: &Checkpoint{Offset: 3, Files: []CheckpointFile{{"a", 2}, {"b", 1}}},
+			want:       true,
+			seeked:     []int64{2, 1},
+		},
+		{desc: "no checkpoint", want: true},
+		{desc: "no positions", checkpoint: &Checkpoint{Offset: 3}},
+		{desc: "other files", checkpoint: &Checkpoint{Offset: 3, Files: []CheckpointFile{{"a", 2}, {"c", 1}}}},
+		{desc: "fewer files", checkpoint: &Checkpoint{Offset: 3, Files: []CheckpointFile{{"a", 2}}}},
+	}
+	for _, c := range cases {
+		fatalCalled = false
+		br := &CommonBenchmarkRunner{checkpoint: c.checkpoint}
+		ds := newFilesDataSource(0, 1, 2, 3)
+		if got := br.skipInserted(ds); got != c.want || fatalCalled == c.want {
+			t.Errorf("%s: incorrect result: got %v (fatal %v) want %v", c.desc, got, fatalCalled, c.want)
+		}
+		if !reflect.DeepEqual(ds.seeked, c.seeked) || ds.called != 0 {
+			t.Errorf("%s: incorrect seek: got %v after %d items want %v", c.desc, ds.seeked, ds.called, c.seeked)
+		}
+	}
+}
+
+type unorderedDataSource struct {
+	testDataSource
+}
+
+func (d *unorderedDataSource) Unordered() bool { return true }
+
+func TestCheckOrdered(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	if err := br.checkOrdered(&unorderedDataSource{}); err != nil {
+		t.Errorf("unexpected error without checkpoints: %v", err)
+	}
+	br.checkpoints = newCheckpointTracker(0)
+	if err := br.checkOrdered(&testDataSource{}); err != nil {
+		t.Errorf("unexpected error with an ordered data source: %v", err)
+	}
+	if err := br.checkOrdered(&unorderedDataSource{}); err == nil {
+		t.Errorf("expected an error with an unordered data source")
+	}
+	if err := br.checkOrdered(newFilesDataSource()); err != nil {
+		t.Errorf("unexpected error with a seekable data source: %v", err)
+	}
+}
@@ -0,0 +1,26 @@
+package load
+
+// RunCoordinator is told by a BenchmarkRunner about the steps of its run, to
+// coordinate the runners of several processes loading the same database.
+type RunCoordinator interface {
+	// DBReady is called once the database is created, if the runner creates
+	// it, and loading starts when it returns
+	DBReady() error
+	// Progress is called every reporting period with the numbers of metrics
+	// and rows loaded so far
+	Progress(metrics, rows uint64) error
+	// Done is called with the result of the run once loading is over
+	Done(result *LoaderTestResult, metrics, rows uint64) error
+}
+
+// CoordinatedRunner is a BenchmarkRunner whose run can be coordinated with
+// the ones of other runners.
+type CoordinatedRunner interface {
+	BenchmarkRunner
+	SetCoordinator(c RunCoordinator)
+}
+
+// SetCoordinator sets the RunCoordinator of the next run.
+func (l *CommonBenchmarkRunner) SetCoordinator(c RunCoordinator) {
+	l.coordinator = c
+}
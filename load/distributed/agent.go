@@ -0,0 +1,62 @@
+package distributed
+
+import (
+	"fmt"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+
+	"github.com/timescale/tsbs/load"
+)
+
+// Agent is the client of a coordinator. It tells the coordinator about the
+// run of its BenchmarkRunner as a load.RunCoordinator.
+type Agent struct {
+	client *rpc.Client
+}
+
+// Dial connects to the coordinator listening on addr.
+func Dial(addr string) (*Agent, error) {
+	client, err := jsonrpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the coordinator on %s: %v", addr, err)
+	}
+	return &Agent{client: client}, nil
+}
+
+// Register returns the assignment of the agent, once all the agents
+// registered.
+func (a *Agent) Register() (*Assignment, error) {
+	var assignment Assignment
+	if err := a.client.Call(serviceName+".Register", Empty{}, &assignment); err != nil {
+		return nil, fmt.Errorf("could not register with the coordinator: %v", err)
+	}
+	return &assignment, nil
+}
+
+// WaitDB waits for the agent of shard 0 to create the database.
+func (a *Agent) WaitDB() error {
+	if err := a.client.Call(serviceName+".WaitDB", Empty{}, &Empty{}); err != nil {
+		return fmt.Errorf("could not wait for the database: %v", err)
+	}
+	return nil
+}
+
+// DBReady waits for all the agents to be ready to load.
+func (a *Agent) DBReady() error {
+	return a.client.Call(serviceName+".Ready", Empty{}, &Empty{})
+}
+
+// Progress sends the numbers of metrics and rows loaded so far.
+func (a *Agent) Progress(metrics, rows uint64) error {
+	return a.client.Call(serviceName+".Progress", ProgressArgs{Metrics: metrics, Rows: rows}, &Empty{})
+}
+
+// Done sends the result of the agent.
+func (a *Agent) Done(result *load.LoaderTestResult, metrics, rows uint64) error {
+	return a.client.Call(serviceName+".Done", DoneArgs{Result: result, Metrics: metrics, Rows: rows}, &Empty{})
+}
+
+// Close disconnects from the coordinator.
+func (a *Agent) Close() error {
+	return a.client.Close()
+}
@@ -0,0 +1,458 @@
+// Package distributed coordinates the loads of several tsbs_load agent
+// processes into the same database. The coordinator assigns each agent a
+// shard of the data, starts the agents in sync once the database is created,
+// collects their periodic stats and merges their results into one
+// load.LoaderTestResult.
+//
+// Agents talk to the coordinator with JSON-RPC over TCP. One connection is
+// one agent, which must call in order Register, WaitDB unless it has shard 0,
+// Ready, Progress every reporting period and finally Done.
+package distributed
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/load"
+)
+
+// serviceName is the name of the RPC service of the coordinator.
+const serviceName = "Coordinator"
+
+var printFn = fmt.Printf
+
+// Assignment is what the coordinator assigns to an agent when it registers.
+type Assignment struct {
+	// Shard is the number of the agent, from 0 to NumShards-1. The agent of
+	// shard 0 creates the database
+	Shard     uint
+	NumShards uint
+	// Target is the name of the target database
+	Target string
+	// Settings is the configuration the agents load with, as read by the
+	// coordinator
+	Settings map[string]interface{}
+}
+
+// ProgressArgs are the numbers of metrics and rows an agent loaded so far.
+type ProgressArgs struct {
+	Metrics uint64
+	Rows    uint64
+}
+
+// DoneArgs is the result of an agent once its load is over.
+type DoneArgs struct {
+	Result  *load.LoaderTestResult
+	Metrics uint64
+	Rows    uint64
+}
+
+// Empty is the argument or reply of the calls without any.
+type Empty struct{}
+
+// agentState is what the coordinator knows about a registered agent.
+type agentState struct {
+	metrics uint64
+	rows    uint64
+	result  *load.LoaderTestResult
+	ready   bool
+	done    bool
+}
+
+// Coordinator waits for a number of agents, assigns them their shards and
+// merges their results.
+type Coordinator struct {
+	numAgents       uint
+	target          string
+	settings        map[string]interface{}
+	reportingPeriod time.Duration
+	listener        net.Listener
+
+	mu        sync.Mutex
+	agents    []*agentState
+	numReady  uint
+	numDone   uint
+	start     time.Time
+	end       time.Time
+	failure   error
+	failed    chan struct{}
+	allJoined chan struct{}
+	dbReady   chan struct{}
+	started   chan struct{}
+	allDone   chan struct{}
+	// reported is closed once the periodic reports stop
+	reported chan struct{}
+}
+
+// NewCoordinator listens on addr for numAgents agents which will load into
+// target with settings. The aggregate stats are reported every
+// reportingPeriod if it is positive.
+func NewCoordinator(addr string, numAgents uint, target string, settings map[string]interface{}, reportingPeriod time.Duration) (*Coordinator, error) {
+	if numAgents == 0 {
+		return nil, fmt.Errorf("the number of agents must be positive")
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("coordinator could not listen on %s: %v", addr, err)
+	}
+	c := &Coordinator{
+		numAgents:       numAgents,
+		target:          target,
+		settings:        settings,
+		reportingPeriod: reportingPeriod,
+		listener:        listener,
+		failed:          make(chan struct{}),
+		allJoined:       make(chan struct{}),
+		dbReady:         make(chan struct{}),
+		started:         make(chan struct{}),
+		allDone:         make(chan struct{}),
+	}
+	go c.serve()
+	return c, nil
+}
+
+// Addr returns the address the coordinator listens on.
+func (c *Coordinator) Addr() string {
+	return c.listener.Addr().String()
+}
+
+// Close stops listening for agents.
+func (c *Coordinator) Close() error {
+	return c.listener.Close()
+}
+
+func (c *Coordinator) serve() {
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			// the listener was closed
+			return
+		}
+		s := &agentService{c: c}
+		server := rpc.NewServer()
+		if err := server.RegisterName(serviceName, s); err != nil {
+			panic(fmt.Sprintf("could not register the coordinator service: %v", err))
+		}
+		go func() {
+			server.ServeCodec(jsonrpc.NewServerCodec(conn))
+			s.disconnected()
+		}()
+	}
+}
+
+// fail stops the coordinated load, the first failure being the one reported.
+func (c *Coordinator) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failure != nil {
+		return
+	}
+	c.failure = err
+	close(c.failed)
+}
+
+// wait blocks until ch is closed or the coordinated load failed.
+func (c *Coordinator) wait(ch chan struct{}) error {
+	select {
+	case <-ch:
+		return nil
+	case <-c.failed:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.failure
+	}
+}
+
+// join assigns the next shard to an agent.
+func (c *Coordinator) join() (uint, error) {
+	c.mu.Lock()
+	if uint(len(c.agents)) == c.numAgents {
+		c.mu.Unlock()
+		return 0, fmt.Errorf("all the %d agents are already registered", c.numAgents)
+	}
+	shard := uint(len(c.agents))
+	c.agents = append(c.agents, &agentState{})
+	if uint(len(c.agents)) == c.numAgents {
+		close(c.allJoined)
+	}
+	c.mu.Unlock()
+	printFn("agent %d registered\n", shard)
+	return shard, nil
+}
+
+// ready waits for all the agents to be ready to load. The database is ready
+// once the agent of shard 0 is.
+func (c *Coordinator) ready(shard uint) error {
+	c.mu.Lock()
+	if c.agents[shard].ready {
+		c.mu.Unlock()
+		return fmt.Errorf("agent %d is already ready", shard)
+	}
+	c.agents[shard].ready = true
+	if shard == 0 {
+		close(c.dbReady)
+	}
+	c.numReady++
+	if c.numReady == c.numAgents {
+		c.start = time.Now()
+		close(c.started)
+		printFn("all %d agents are ready, loading\n", c.numAgents)
+		if c.reportingPeriod > 0 {
+			c.reported = make(chan struct{})
+			go c.report(c.reportingPeriod)
+		}
+	}
+	c.mu.Unlock()
+	return c.wait(c.started)
+}
+
+func (c *Coordinator) progress(shard uint, metrics, rows uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a := c.agents[shard]
+	if !a.done {
+		a.metrics, a.rows = metrics, rows
+	}
+}
+
+func (c *Coordinator) done(shard uint, args *DoneArgs) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	a := c.agents[shard]
+	if a.done {
+		return fmt.Errorf("agent %d is already done", shard)
+	}
+	a.metrics, a.rows, a.result, a.done = args.Metrics, args.Rows, args.Result, true
+	c.numDone++
+	printFn("agent %d is done: loaded %d metrics and %d rows\n", shard, args.Metrics, args.Rows)
+	if c.numDone == c.numAgents {
+		c.end = time.Now()
+		close(c.allDone)
+	}
+	return nil
+}
+
+// totals returns the numbers of metrics and rows all the agents loaded so far.
+func (c *Coordinator) totals() (metrics, rows uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, a := range c.agents {
+		metrics += a.metrics
+		rows += a.rows
+	}
+	return metrics, rows
+}
+
+// report prints the aggregate stats of the agents every period until they
+// are all done. The agents send their progress every reporting period, so the
+// stats lag up to one period behind.
+func (c *Coordinator) report(period time.Duration) {
+	defer close(c.reported)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	prevTime := c.start
+	prevColCount := uint64(0)
+	prevRowCount := uint64(0)
+	printFn("[all agents] time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s\n")
+	for {
+		var now time.Time
+		select {
+		case <-c.allDone:
+			return
+		case <-c.failed:
+			return
+		case now = <-ticker.C:
+		}
+		cCount, rCount := c.totals()
+		sinceStart := now.Sub(c.start)
+		took := now.Sub(prevTime)
+		colrate := float64(cCount-prevColCount) / took.Seconds()
+		overallColRate := float64(cCount) / sinceStart.Seconds()
+		if rCount > 0 {
+			rowrate := float64(rCount-prevRowCount) / took.Seconds()
+			overallRowRate := float64(rCount) / sinceStart.Seconds()
+			printFn("[all agents] %d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate)
+		} else {
+			printFn("[all agents] %d,%0.2f,%E,%0.2f,-,-,-\n", now.Unix(), colrate, float64(cCount), overallColRate)
+		}
+		prevColCount = cCount
+		prevRowCount = rCount
+		prevTime = now
+	}
+}
+
+// Wait blocks until all the agents are done, prints the summary of the
+// coordinated load and returns its merged result. It fails if an agent fails
+// or disconnects before it is done.
+func (c *Coordinator) Wait() (*load.LoaderTestResult, error) {
+	if err := c.wait(c.allDone); err != nil {
+		return nil, err
+	}
+	if c.reported != nil {
+		<-c.reported
+	}
+	result := c.mergedResult()
+	c.summary(result)
+	return result, nil
+}
+
+// mergedResult merges the results of the agents. The run lasts from the
+// moment the agents were all ready to the moment they were all done. The
+// config and target report are the ones of the agent of shard 0.
+func (c *Coordinator) mergedResult() *load.LoaderTestResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	took := c.end.Sub(c.start)
+	var metrics, rows uint64
+	agentTotals := make([]map[string]interface{}, len(c.agents))
+	for i, a := range c.agents {
+		metrics += a.metrics
+		rows += a.rows
+		agentTotals[i] = map[string]interface{}{
+			"shard":   i,
+			"metrics": a.metrics,
+			"rows":    a.rows,
+		}
+		if a.result != nil {
+			for k, v := range a.result.Totals {
+				agentTotals[i][k] = v
+			}
+		}
+	}
+	totals := map[string]interface{}{
+		"agents":      len(c.agents),
+		"metrics":     metrics,
+		"metricRate":  float64(metrics) / took.Seconds(),
+		"agentTotals": agentTotals,
+	}
+	if rows > 0 {
+		totals["rows"] = rows
+		totals["rowRate"] = float64(rows) / took.Seconds()
+	}
+	result := &load.LoaderTestResult{
+		ResultFormatVersion: load.LoaderTestResultVersion,
+		StartTime:           c.start.Unix(),
+		EndTime:             c.end.Unix(),
+		DurationMillis:      took.Milliseconds(),
+		Totals:              totals,
+	}
+	if first := c.agents[0].result; first != nil {
+		result.RunnerConfig = first.RunnerConfig
+		result.TargetReport = first.TargetReport
+	}
+	return result
+}
+
+func (c *Coordinator) summary(result *load.LoaderTestResult) {
+	took := time.Duration(result.DurationMillis) * time.Millisecond
+	printFn("\n[all agents] Summary:\n")
+	printFn("[all agents] loaded %d metrics in %0.3fsec with %d agents (mean rate %0.2f metrics/sec)\n",
+		result.Totals["metrics"], took.Seconds(), c.numAgents, result.Totals["metricRate"])
+	if rows, ok := result.Totals["rows"]; ok {
+		printFn("[all agents] loaded %d rows in %0.3fsec with %d agents (mean rate %0.2f rows/sec)\n",
+			rows, took.Seconds(), c.numAgents, result.Totals["rowRate"])
+	}
+}
+
+// agentService serves the calls of the agent of one connection.
+type agentService struct {
+	c     *Coordinator
+	mu    sync.Mutex
+	shard uint
+	// registered is true once the agent got its shard
+	registered bool
+	done       bool
+}
+
+// Register assigns the agent its shard once all the agents registered.
+func (s *agentService) Register(_ Empty, reply *Assignment) error {
+	s.mu.Lock()
+	if s.registered {
+		s.mu.Unlock()
+		return fmt.Errorf("agent %d is already registered", s.shard)
+	}
+	shard, err := s.c.join()
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.shard, s.registered = shard, true
+	s.mu.Unlock()
+	if err := s.c.wait(s.c.allJoined); err != nil {
+		return err
+	}
+	*reply = Assignment{
+		Shard:     shard,
+		NumShards: s.c.numAgents,
+		Target:    s.c.target,
+		Settings:  s.c.settings,
+	}
+	return nil
+}
+
+// WaitDB waits for the agent of shard 0 to create the database.
+func (s *agentService) WaitDB(_ Empty, _ *Empty) error {
+	if _, err := s.registeredShard(); err != nil {
+		return err
+	}
+	return s.c.wait(s.c.dbReady)
+}
+
+// Ready waits for all the agents to be ready to load.
+func (s *agentService) Ready(_ Empty, _ *Empty) error {
+	shard, err := s.registeredShard()
+	if err != nil {
+		return err
+	}
+	return s.c.ready(shard)
+}
+
+// Progress records how much the agent loaded so far.
+func (s *agentService) Progress(args ProgressArgs, _ *Empty) error {
+	shard, err := s.registeredShard()
+	if err != nil {
+		return err
+	}
+	s.c.progress(shard, args.Metrics, args.Rows)
+	return nil
+}
+
+// Done records the result of the agent.
+func (s *agentService) Done(args DoneArgs, _ *Empty) error {
+	shard, err := s.registeredShard()
+	if err != nil {
+		return err
+	}
+	if err := s.c.done(shard, &args); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.done = true
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *agentService) registeredShard() (uint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.registered {
+		return 0, fmt.Errorf("the agent is not registered")
+	}
+	return s.shard, nil
+}
+
+// disconnected fails the coordinated load if the agent was not done.
+func (s *agentService) disconnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.registered || s.done {
+		return
+	}
+	log.Printf("agent %d disconnected before it was done", s.shard)
+	s.c.fail(fmt.Errorf("agent %d disconnected before it was done", s.shard))
+}
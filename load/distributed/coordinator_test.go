@@ -0,0 +1,158 @@
+package distributed
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/load"
+)
+
+func mutePrint(t *testing.T) {
+	oldPrintFn := printFn
+	printFn = func(string, ...interface{}) (int, error) { return 0, nil }
+	t.Cleanup(func() { printFn = oldPrintFn })
+}
+
+// runAgent goes through the steps of an agent loading metrics and rows.
+func runAgent(addr string, metrics, rows uint64) (*Assignment, error) {
+	agent, err := Dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	defer agent.Close()
+	assignment, err := agent.Register()
+	if err != nil {
+		return nil, err
+	}
+	if assignment.Shard > 0 {
+		if err := agent.WaitDB(); err != nil {
+			return nil, err
+		}
+	}
+	var rc load.RunCoordinator = agent
+	if err := rc.DBReady(); err != nil {
+		return nil, err
+	}
+	if err := rc.Progress(metrics/2, rows/2); err != nil {
+		return nil, err
+	}
+	result := &load.LoaderTestResult{
+		RunnerConfig: load.BenchmarkRunnerConfig{DBName: "benchmark"},
+		Totals:       map[string]interface{}{"metricRate": 1.0},
+	}
+	return assignment, rc.Done(result, metrics, rows)
+}
+
+func TestCoordinator(t *testing.T) {
+	mutePrint(t)
+	settings := map[string]interface{}{"loader": map[string]interface{}{"target": "influx"}}
+	c, err := NewCoordinator("127.0.0.1:0", 3, "influx", settings, time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	wg := sync.WaitGroup{}
+	shards := make([]bool, 3)
+	mu := sync.Mutex{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, err := runAgent(c.Addr(), 100, 10)
+			if err != nil {
+				t.Errorf("unexpected agent error: %v", err)
+				return
+			}
+			if a.NumShards != 3 || a.Target != "influx" || a.Settings["loader"] == nil {
+				t.Errorf("incorrect assignment: %+v", a)
+			}
+			mu.Lock()
+			shards[a.Shard] = true
+			mu.Unlock()
+		}()
+	}
+	result, err := c.Wait()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wg.Wait()
+	for i, assigned := range shards {
+		if !assigned {
+			t.Errorf("shard %d not assigned", i)
+		}
+	}
+	if result.Totals["metrics"] != uint64(300) || result.Totals["rows"] != uint64(30) || result.Totals["agents"] != 3 {
+		t.Errorf("incorrect totals: %v", result.Totals)
+	}
+	if n := len(result.Totals["agentTotals"].([]map[string]interface{})); n != 3 {
+		t.Errorf("incorrect number of agent totals: got %d want 3", n)
+	}
+	if result.RunnerConfig.DBName != "benchmark" {
+		t.Errorf("incorrect runner config: %+v", result.RunnerConfig)
+	}
+}
+
+func TestCoordinatorAgentDisconnected(t *testing.T) {
+	mutePrint(t)
+	c, err := NewCoordinator("127.0.0.1:0", 2, "influx", nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	agent, err := Dial(c.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	registered := make(chan error)
+	go func() {
+		_, err := agent.Register()
+		registered <- err
+	}()
+	other, err := Dial(c.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := other.Register(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-registered; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other.Close()
+
+	if _, err := c.Wait(); err == nil {
+		t.Errorf("expected an error when an agent disconnects")
+	}
+	if err := agent.DBReady(); err == nil {
+		t.Errorf("expected the other agents to fail")
+	}
+	agent.Close()
+}
+
+func TestCoordinatorTooManyAgents(t *testing.T) {
+	mutePrint(t)
+	c, err := NewCoordinator("127.0.0.1:0", 1, "influx", nil, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+	first, err := Dial(c.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer first.Close()
+	if _, err := first.Register(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := Dial(c.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer second.Close()
+	if _, err := second.Register(); err == nil {
+		t.Errorf("expected an error for an agent past the number of agents")
+	}
+}
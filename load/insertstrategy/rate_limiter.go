@@ -0,0 +1,147 @@
+package insertstrategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// RatePoints counts the points, the items of the batches, e.g. rows
+	RatePoints = "points"
+	// RateBatches counts the batches
+	RateBatches = "batches"
+
+	// rateStep is the time resolution of the rate profiles
+	rateStep = 10 * time.Millisecond
+)
+
+type sleepFn func(time.Duration)
+
+// RateLimiter paces the inserts of all the workers together to the target
+// rate of a RateProfile, in points or batches per second. Each batch is given
+// the time it may be inserted at, right after the previous batch of any
+// worker, so that the rate is smooth. The target rate is not caught up on:
+// when the workers cannot keep up, the inserts go as fast as they can.
+type RateLimiter struct {
+	profile RateProfile
+	unit    string
+	nowFn   nowProviderFn
+	sleepFn sleepFn
+
+	mu    sync.Mutex
+	start time.Time
+	// next is when the next batch may be inserted
+	next time.Time
+}
+
+// NewRateLimiter returns a RateLimiter of the profile described by profile,
+// see ParseRateProfile, in unit RatePoints or RateBatches per second.
+func NewRateLimiter(profile, unit string) (*RateLimiter, error) {
+	if unit != RatePoints && unit != RateBatches {
+		return nil, fmt.Errorf("invalid rate unit '%s', valid: %s, %s", unit, RatePoints, RateBatches)
+	}
+	p, err := ParseRateProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimiter{profile: p, unit: unit, nowFn: time.Now, sleepFn: time.Sleep}, nil
+}
+
+// Unit returns the unit of the rates, RatePoints or RateBatches.
+func (r *RateLimiter) Unit() string {
+	return r.unit
+}
+
+// Start sets when loading started, the time the profile starts from. It is
+// otherwise the time of the first Wait.
+func (r *RateLimiter) Start(at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start = at
+	r.next = at
+}
+
+// MeanTargetRate returns the mean target rate between from and to, times
+// before the start counting as the start.
+func (r *RateLimiter) MeanTargetRate(from, to time.Time) float64 {
+	r.mu.Lock()
+	start := r.start
+	r.mu.Unlock()
+	if start.IsZero() {
+		return r.rate(0)
+	}
+	begin, end := from.Sub(start), to.Sub(start)
+	if begin < 0 {
+		begin = 0
+	}
+	if end <= begin {
+		return r.rate(begin)
+	}
+	var sum float64
+	for at := begin; at < end; at += rateStep {
+		step := rateStep
+		if at+step > end {
+			step = end - at
+		}
+		sum += r.rate(at) * step.Seconds()
+	}
+	return sum / (end - begin).Seconds()
+}
+
+// Wait makes the worker sleep until its batch of points may be inserted.
+func (r *RateLimiter) Wait(points uint) {
+	units := float64(points)
+	if r.unit == RateBatches {
+		units = 1
+	}
+	r.mu.Lock()
+	now := r.nowFn()
+	if r.start.IsZero() {
+		r.start = now
+		r.next = now
+	}
+	at := r.next
+	if at.Before(now) {
+		at = now
+	}
+	at = r.firstPositive(at)
+	r.next = r.reserve(at, units)
+	r.mu.Unlock()
+
+	if d := at.Sub(now); d > 0 {
+		r.sleepFn(d)
+	}
+}
+
+func (r *RateLimiter) rate(elapsed time.Duration) float64 {
+	rate := r.profile.Rate(elapsed)
+	if rate < 0 {
+		return 0
+	}
+	return rate
+}
+
+// firstPositive returns the first time from at when the rate is positive.
+func (r *RateLimiter) firstPositive(at time.Time) time.Time {
+	for r.rate(at.Sub(r.start)) == 0 {
+		at = at.Add(rateStep)
+	}
+	return at
+}
+
+// reserve returns the time the insert of units from at is over at the target
+// rate, i.e. the rate integrated from at to it is units.
+func (r *RateLimiter) reserve(at time.Time, units float64) time.Time {
+	for units > 0 {
+		rate := r.rate(at.Sub(r.start))
+		if rate > 0 {
+			if d := time.Duration(units / rate * float64(time.Second)); d <= rateStep {
+				return at.Add(d)
+			}
+			units -= rate * rateStep.Seconds()
+		}
+		at = at.Add(rateStep)
+	}
+	return at
+}
@@ -0,0 +1,130 @@
+package insertstrategy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeClock is the time of a RateLimiter, which only moves when it sleeps.
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.slept = append(c.slept, d)
+	c.now = c.now.Add(d)
+}
+
+func newTestRateLimiter(t *testing.T, profile, unit string) (*RateLimiter, *fakeClock) {
+	r, err := NewRateLimiter(profile, unit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r.nowFn = clock.Now
+	r.sleepFn = clock.Sleep
+	r.Start(clock.now)
+	return r, clock
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	if _, err := NewRateLimiter("100", "rows"); err == nil {
+		t.Errorf("expected an error for an invalid unit")
+	}
+	if _, err := NewRateLimiter("x", RatePoints); err == nil {
+		t.Errorf("expected an error for an invalid profile")
+	}
+	r, err := NewRateLimiter("100", RateBatches)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Unit() != RateBatches {
+		t.Errorf("incorrect unit: got %s", r.Unit())
+	}
+}
+
+func TestRateLimiterWaitPoints(t *testing.T) {
+	r, clock := newTestRateLimiter(t, "1000", RatePoints)
+	start := clock.now
+	// 10 batches of 100 points at 1000 points/s take 1s, the first one
+	// does not wait
+	for i := 0; i < 10; i++ {
+		r.Wait(100)
+	}
+	if got := clock.now.Sub(start); math.Abs(got.Seconds()-0.9) > 1e-6 {
+		t.Errorf("incorrect time to insert: got %v want %v", got, 900*time.Millisecond)
+	}
+	if len(clock.slept) != 9 {
+		t.Errorf("incorrect sleeps: got %d want 9", len(clock.slept))
+	}
+}
+
+func TestRateLimiterWaitBatches(t *testing.T) {
+	r, clock := newTestRateLimiter(t, "4", RateBatches)
+	start := clock.now
+	for i := 0; i < 5; i++ {
+		r.Wait(10000)
+	}
+	if got := clock.now.Sub(start); math.Abs(got.Seconds()-1) > 1e-6 {
+		t.Errorf("incorrect time to insert: got %v want %v", got, time.Second)
+	}
+}
+
+func TestRateLimiterNoCatchUp(t *testing.T) {
+	r, clock := newTestRateLimiter(t, "100", RateBatches)
+	r.Wait(1)
+	// the workers were slower than the target rate
+	clock.now = clock.now.Add(time.Second)
+	r.Wait(1)
+	r.Wait(1)
+	if len(clock.slept) != 1 || clock.slept[0] != 10*time.Millisecond {
+		t.Errorf("incorrect sleeps after falling behind: got %v", clock.slept)
+	}
+}
+
+func TestRateLimiterRamp(t *testing.T) {
+	// the rate is integrated over the ramp: 50 points from 0 to 100/s take 1s
+	r, clock := newTestRateLimiter(t, "ramp:0,100,1s", RatePoints)
+	start := clock.now
+	r.Wait(50)
+	r.Wait(1)
+	if got := clock.now.Sub(start); math.Abs(got.Seconds()-1) > 0.02 {
+		t.Errorf("incorrect time to insert on a ramp: got %v want 1s", got)
+	}
+}
+
+func TestRateLimiterPause(t *testing.T) {
+	r, err := NewRateLimiter("constant:1", RateBatches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.profile = &scheduleRate{steps: []scheduleStep{{at: time.Second, rate: 10}}}
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	r.nowFn = clock.Now
+	r.sleepFn = clock.Sleep
+	r.Start(clock.now)
+	r.Wait(1)
+	if got := clock.now.Sub(time.Unix(1000, 0)); got != time.Second {
+		t.Errorf("incorrect pause: got %v want 1s", got)
+	}
+}
+
+func TestRateLimiterMeanTargetRate(t *testing.T) {
+	r, clock := newTestRateLimiter(t, "ramp:0,100,10s", RatePoints)
+	start := clock.now
+	if got := r.MeanTargetRate(start, start.Add(10*time.Second)); math.Abs(got-50) > 0.1 {
+		t.Errorf("incorrect mean rate of the ramp: got %f want 50", got)
+	}
+	if got := r.MeanTargetRate(start.Add(20*time.Second), start.Add(30*time.Second)); got != 100 {
+		t.Errorf("incorrect mean rate after the ramp: got %f want 100", got)
+	}
+	if got := r.MeanTargetRate(start.Add(-time.Second), start); got != 0 {
+		t.Errorf("incorrect mean rate before the start: got %f want 0", got)
+	}
+}
@@ -0,0 +1,251 @@
+package insertstrategy
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	profileSeparator      = ":"
+	profileArgsSeparator  = ","
+	constantProfile       = "constant"
+	stepProfile           = "step"
+	rampProfile           = "ramp"
+	sineProfile           = "sine"
+	scheduleProfile       = "schedule"
+	rateProfileFormatHelp = "required: 'RATE', 'constant:RATE', 'step:START,INCREMENT,INTERVAL', " +
+		"'ramp:FROM,TO,DURATION', 'sine:MEAN,AMPLITUDE,PERIOD' or 'schedule:FILE'"
+)
+
+// RateProfile is the target rate of a RateLimiter, in points or batches per
+// second, over the time since loading started.
+type RateProfile interface {
+	// Rate returns the target rate once elapsed has passed since loading
+	// started. Negative rates are the same as 0, loading pauses.
+	Rate(elapsed time.Duration) float64
+}
+
+// ParseRateProfile parses the description of a rate profile, which goes like this:
+// '5000' or 'constant:5000' => 5000 per second
+// 'step:1000,500,1m' => 1000 per second, 500 more every minute
+// 'ramp:0,10000,5m' => from 0 to 10000 per second in 5 minutes, then 10000 per second
+// 'sine:5000,2000,1m' => 5000 per second +/- 2000, with a period of one minute
+// 'schedule:bursts.txt' => the rates of a schedule file, see parseSchedule
+func ParseRateProfile(profile string) (RateProfile, error) {
+	kind, argsStr := constantProfile, profile
+	if parts := strings.SplitN(profile, profileSeparator, 2); len(parts) == 2 {
+		kind, argsStr = parts[0], parts[1]
+	}
+	if kind == scheduleProfile {
+		return parseScheduleFile(argsStr)
+	}
+
+	args := strings.Split(argsStr, profileArgsSeparator)
+	var p RateProfile
+	var err error
+	switch kind {
+	case constantProfile:
+		var rate []float64
+		if rate, _, err = parseProfileArgs(args, 1, 0); err == nil {
+			p = constantRate(rate[0])
+		}
+	case stepProfile:
+		var rates []float64
+		var durations []time.Duration
+		if rates, durations, err = parseProfileArgs(args, 2, 1); err == nil {
+			p = &stepRate{start: rates[0], increment: rates[1], every: durations[0]}
+		}
+	case rampProfile:
+		var rates []float64
+		var durations []time.Duration
+		if rates, durations, err = parseProfileArgs(args, 2, 1); err == nil {
+			p = &rampRate{from: rates[0], to: rates[1], over: durations[0]}
+		}
+	case sineProfile:
+		var rates []float64
+		var durations []time.Duration
+		if rates, durations, err = parseProfileArgs(args, 2, 1); err == nil {
+			p = &sineRate{mean: rates[0], amplitude: rates[1], period: durations[0]}
+		}
+	default:
+		return nil, fmt.Errorf("unknown rate profile '%s', %s", kind, rateProfileFormatHelp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rate profile '%s' could not be parsed: %v, %s", profile, err, rateProfileFormatHelp)
+	}
+	if err := validateRateProfile(p); err != nil {
+		return nil, fmt.Errorf("rate profile '%s': %v", profile, err)
+	}
+	return p, nil
+}
+
+// parseProfileArgs parses numRates rates followed by numDurations positive
+// durations.
+func parseProfileArgs(args []string, numRates, numDurations int) ([]float64, []time.Duration, error) {
+	if len(args) != numRates+numDurations {
+		return nil, nil, fmt.Errorf("expected %d arguments, got %d", numRates+numDurations, len(args))
+	}
+	rates := make([]float64, numRates)
+	for i := range rates {
+		rate, err := strconv.ParseFloat(strings.TrimSpace(args[i]), 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid rate '%s'", args[i])
+		}
+		rates[i] = rate
+	}
+	durations := make([]time.Duration, numDurations)
+	for i := range durations {
+		d, err := time.ParseDuration(strings.TrimSpace(args[numRates+i]))
+		if err != nil || d <= 0 {
+			return nil, nil, fmt.Errorf("invalid duration '%s'", args[numRates+i])
+		}
+		durations[i] = d
+	}
+	return rates, durations, nil
+}
+
+// validateRateProfile checks that the rate of p does not stay 0 forever, which
+// would stop loading.
+func validateRateProfile(p RateProfile) error {
+	ok := false
+	switch x := p.(type) {
+	case constantRate:
+		ok = x > 0
+	case *stepRate:
+		ok = x.increment > 0 || (x.increment == 0 && x.start > 0)
+	case *rampRate:
+		ok = x.to > 0
+	case *sineRate:
+		ok = x.mean > 0
+	case *scheduleRate:
+		ok = x.steps[len(x.steps)-1].rate > 0
+	}
+	if !ok {
+		return fmt.Errorf("the rate must not end up at 0 or less")
+	}
+	return nil
+}
+
+type constantRate float64
+
+func (r constantRate) Rate(time.Duration) float64 {
+	return float64(r)
+}
+
+// stepRate increases the rate by increment every interval.
+type stepRate struct {
+	start     float64
+	increment float64
+	every     time.Duration
+}
+
+func (r *stepRate) Rate(elapsed time.Duration) float64 {
+	return r.start + r.increment*float64(elapsed/r.every)
+}
+
+// rampRate changes the rate linearly from from to to, and then stays at to.
+type rampRate struct {
+	from float64
+	to   float64
+	over time.Duration
+}
+
+func (r *rampRate) Rate(elapsed time.Duration) float64 {
+	if elapsed >= r.over {
+		return r.to
+	}
+	return r.from + (r.to-r.from)*elapsed.Seconds()/r.over.Seconds()
+}
+
+// sineRate varies the rate around mean, starting at mean and going up.
+type sineRate struct {
+	mean      float64
+	amplitude float64
+	period    time.Duration
+}
+
+func (r *sineRate) Rate(elapsed time.Duration) float64 {
+	return r.mean + r.amplitude*math.Sin(2*math.Pi*elapsed.Seconds()/r.period.Seconds())
+}
+
+type scheduleStep struct {
+	at   time.Duration
+	rate float64
+}
+
+// scheduleRate holds each rate from its time until the time of the next one,
+// and the last one until the end.
+type scheduleRate struct {
+	steps []scheduleStep
+}
+
+func (r *scheduleRate) Rate(elapsed time.Duration) float64 {
+	i := sort.Search(len(r.steps), func(i int) bool { return r.steps[i].at > elapsed })
+	if i == 0 {
+		// loading pauses until the first step
+		return 0
+	}
+	return r.steps[i-1].rate
+}
+
+func parseScheduleFile(fileName string) (RateProfile, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open rate schedule file: %v", err)
+	}
+	defer f.Close()
+	return parseSchedule(bufio.NewScanner(f))
+}
+
+// parseSchedule parses a rate schedule, with a line per change of the rate,
+// made of the time since loading started and the rate from then on, e.g. for
+// a burst of 30s every minute:
+//
+//	0s  1000
+//	30s 20000
+//	1m  1000
+//	1m30s 20000
+//
+// Empty lines and lines starting with # are skipped.
+func parseSchedule(scanner *bufio.Scanner) (RateProfile, error) {
+	var steps []scheduleStep
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("rate schedule line %d: expected a time and a rate", line)
+		}
+		at, err := time.ParseDuration(fields[0])
+		if err != nil || at < 0 {
+			return nil, fmt.Errorf("rate schedule line %d: invalid time '%s'", line, fields[0])
+		}
+		rate, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("rate schedule line %d: invalid rate '%s'", line, fields[1])
+		}
+		if len(steps) > 0 && at <= steps[len(steps)-1].at {
+			return nil, fmt.Errorf("rate schedule line %d: times must increase", line)
+		}
+		steps = append(steps, scheduleStep{at: at, rate: rate})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read rate schedule: %v", err)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("empty rate schedule")
+	}
+	p := &scheduleRate{steps: steps}
+	if err := validateRateProfile(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
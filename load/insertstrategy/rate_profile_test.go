@@ -0,0 +1,144 @@
+package insertstrategy
+
+import (
+	"bufio"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRateProfile(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		profile string
+		rates   map[time.Duration]float64
+	}{
+		{
+			desc:    "plain rate",
+			profile: "5000",
+			rates:   map[time.Duration]float64{0: 5000, time.Hour: 5000},
+		}, {
+			desc:    "constant",
+			profile: "constant:100.5",
+			rates:   map[time.Duration]float64{0: 100.5, time.Minute: 100.5},
+		}, {
+			desc:    "step",
+			profile: "step:1000,500,1m",
+			rates:   map[time.Duration]float64{0: 1000, 59 * time.Second: 1000, time.Minute: 1500, 150 * time.Second: 2000},
+		}, {
+			desc:    "ramp",
+			profile: "ramp:0,10000,10s",
+			rates:   map[time.Duration]float64{0: 0, 5 * time.Second: 5000, 10 * time.Second: 10000, time.Hour: 10000},
+		}, {
+			desc:    "ramp down",
+			profile: "ramp:10000,5000,10s",
+			rates:   map[time.Duration]float64{0: 10000, 5 * time.Second: 7500, time.Minute: 5000},
+		}, {
+			desc:    "sine",
+			profile: "sine:5000,2000,1m",
+			rates:   map[time.Duration]float64{0: 5000, 15 * time.Second: 7000, 30 * time.Second: 5000, 45 * time.Second: 3000},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			p, err := ParseRateProfile(tc.profile)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for elapsed, want := range tc.rates {
+				if got := p.Rate(elapsed); math.Abs(got-want) > 1e-6 {
+					t.Errorf("incorrect rate at %v: got %f want %f", elapsed, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRateProfileErrors(t *testing.T) {
+	profiles := []string{
+		"",
+		"abc",
+		"0",
+		"-5",
+		"unknown:1",
+		"constant:1,2",
+		"step:1000,500",
+		"step:1000,-500,1m",
+		"step:1000,500,0s",
+		"ramp:0,0,1m",
+		"ramp:0,100,x",
+		"sine:0,100,1m",
+		"schedule:/does/not/exist",
+	}
+	for _, profile := range profiles {
+		if _, err := ParseRateProfile(profile); err == nil {
+			t.Errorf("expected an error for profile '%s'", profile)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	schedule := `
+# a burst of 10s every 30s
+0s   1000
+20s  20000
+30s  1000
+`
+	p, err := parseSchedule(bufio.NewScanner(strings.NewReader(schedule)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[time.Duration]float64{0: 1000, 19 * time.Second: 1000, 20 * time.Second: 20000, 30 * time.Second: 1000, time.Hour: 1000}
+	for elapsed, rate := range want {
+		if got := p.Rate(elapsed); got != rate {
+			t.Errorf("incorrect rate at %v: got %f want %f", elapsed, got, rate)
+		}
+	}
+
+	p, err = parseSchedule(bufio.NewScanner(strings.NewReader("5s 100")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p.Rate(time.Second); got != 0 {
+		t.Errorf("rate before the first step not 0: got %f", got)
+	}
+
+	invalid := []string{
+		"",
+		"0s",
+		"x 100",
+		"0s x",
+		"-1s 100",
+		"10s 100\n5s 100",
+		"0s 100\n10s 0",
+	}
+	for _, schedule := range invalid {
+		if _, err := parseSchedule(bufio.NewScanner(strings.NewReader(schedule))); err == nil {
+			t.Errorf("expected an error for schedule '%s'", schedule)
+		}
+	}
+}
+
+func TestParseRateProfileScheduleFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "schedule.txt")
+	if err := ioutil.WriteFile(fileName, []byte("0s 10\n1m 20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParseRateProfile("schedule:" + fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p.Rate(time.Minute); got != 20 {
+		t.Errorf("incorrect rate: got %f want 20", got)
+	}
+}
@@ -12,27 +12,43 @@ type noFlowBenchmarkRunner struct {
 }
 
 func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
-	wg, start, cleanupFn := l.preRun(b)
+	l.run(b, l.load)
+}
 
+// load sends the batches to the workers without flow control
+func (l *noFlowBenchmarkRunner) load(b targets.Benchmark, ds targets.DataSource, workers, batchSize uint) {
+	wg := &sync.WaitGroup{}
+	wg.Add(int(workers))
 	var numChannels uint
 	if l.HashWorkers {
-		numChannels = l.Workers
+		numChannels = workers
 	} else {
 		numChannels = 1
 	}
-	channels := l.createChannels(numChannels, l.ChannelCapacity)
+	channels := l.createChannels(numChannels, l.channelCapacity(workers))
 
 	// Launch all worker processes in background
-	for i := uint(0); i < l.Workers; i++ {
+	for i := uint(0); i < workers; i++ {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 	// Start scan process - actual data read process
-	scanWithoutFlowControl(b.GetDataSource(), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit, l.BenchmarkRunnerConfig.BatchMetaFields, l.BenchmarkRunnerConfig.MetaFieldIndex)
+	scanWithoutFlowControl(ds, b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, batchSize, l.scanLimit(), l.scanOptions())
 	for _, c := range channels {
 		close(c)
 	}
-	cleanupFn()
-	l.postRun(wg, start)
+	wg.Wait()
+}
+
+// channelCapacity returns the capacity of each channel, by default
+// defaultChannelCapacityPerWorker for each worker reading from it
+func (l *noFlowBenchmarkRunner) channelCapacity(workers uint) uint {
+	if l.ChannelCapacity != DefaultChannelCapacityFlagVal {
+		return l.ChannelCapacity
+	}
+	if l.HashWorkers {
+		return defaultChannelCapacityPerWorker
+	}
+	return workers * defaultChannelCapacityPerWorker
 }
 
 // createChannels create channels from which workers would receive tasks
@@ -54,10 +70,16 @@ func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 
 	// Process batches coming from the incoming queue (c)
 	for batch := range c {
+		batch, inserted := l.untrackBatch(batch)
+		rateUnits := l.waitForRate(batch)
 		startedWorkAt := time.Now()
 		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		releaseBatch(batch)
+		inserted()
+		l.recordLatency(startedWorkAt)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
+		atomic.AddUint64(&l.rateUnitCnt, rateUnits)
 		l.timeToSleep(workerNum, startedWorkAt)
 	}
 
@@ -7,11 +7,13 @@ import (
 	"io/ioutil"
 	"log"
 	"math/rand"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/profiling"
 	"github.com/timescale/tsbs/load/insertstrategy"
 )
 
@@ -21,6 +23,8 @@ const (
 	DefaultChannelCapacityFlagVal   = 0
 	defaultChannelCapacityPerWorker = 5
 	errDBExistsFmt                  = "database \"%s\" exists: aborting."
+	// DefaultBatchMaxSeries - default number of series batches filled at once
+	DefaultBatchMaxSeries = 1000
 )
 
 // change for more useful testing
@@ -47,8 +51,62 @@ type BenchmarkRunnerConfig struct {
 	// deprecated, should not be used in other places other than tsbs_load_xx commands
 	FileName string `yaml:"file" mapstructure:"file" json:"file"`
 	Seed     int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
-	BatchMetaFields  bool   `mapstructure:"batch-meta-fields"`
-	MetaFieldIndex 	 string `mapstructure:"meta-field-index"`
+	// BatchBySeries fills a batch per series instead of per channel, when the
+	// PointIndexer of the target knows the series of the points
+	BatchBySeries bool `yaml:"batch-by-series" mapstructure:"batch-by-series" json:"batch-by-series"`
+	// BatchMaxAge is the time after which a batch which is not full is sent
+	// anyway, 0 to only send full batches
+	BatchMaxAge time.Duration `yaml:"batch-max-age" mapstructure:"batch-max-age" json:"batch-max-age"`
+	// BatchMaxSeries is the number of series batches filled at once with
+	// BatchBySeries, after which they are all sent. 0 for no limit
+	BatchMaxSeries uint `yaml:"batch-max-series" mapstructure:"batch-max-series" json:"batch-max-series"`
+	// IngestRate is the profile of the target rate of all the workers
+	// together, see insertstrategy.ParseRateProfile. Empty for no limit
+	IngestRate string `yaml:"ingest-rate" mapstructure:"ingest-rate" json:"ingest-rate,omitempty"`
+	// IngestRateUnit is what IngestRate counts, points or batches
+	IngestRateUnit string `yaml:"ingest-rate-unit" mapstructure:"ingest-rate-unit" json:"ingest-rate-unit,omitempty"`
+	// AutoTune runs short probe phases with several numbers of workers, batch
+	// sizes and, optionally, target rates, to find the best configuration
+	AutoTune bool `yaml:"auto-tune" mapstructure:"auto-tune" json:"auto-tune,omitempty"`
+	// AutoTuneWorkers is the comma separated numbers of workers to probe
+	AutoTuneWorkers string `yaml:"auto-tune-workers" mapstructure:"auto-tune-workers" json:"auto-tune-workers,omitempty"`
+	// AutoTuneBatchSizes is the comma separated batch sizes to probe
+	AutoTuneBatchSizes string `yaml:"auto-tune-batch-sizes" mapstructure:"auto-tune-batch-sizes" json:"auto-tune-batch-sizes,omitempty"`
+	// AutoTuneRates is the comma separated target rates, in IngestRateUnit,
+	// to probe with the best configuration. Empty to not probe rates
+	AutoTuneRates string `yaml:"auto-tune-rates" mapstructure:"auto-tune-rates" json:"auto-tune-rates,omitempty"`
+	// AutoTuneProbe is how long each probe phase lasts
+	AutoTuneProbe time.Duration `yaml:"auto-tune-probe" mapstructure:"auto-tune-probe" json:"auto-tune-probe,omitempty"`
+	// AutoTunePlateau is the relative increase of throughput under which the
+	// throughput is considered to plateau
+	AutoTunePlateau float64 `yaml:"auto-tune-plateau" mapstructure:"auto-tune-plateau" json:"auto-tune-plateau,omitempty"`
+	// AutoTuneMaxLatency is the p99 batch latency a configuration must stay
+	// under, 0 for no threshold
+	AutoTuneMaxLatency time.Duration `yaml:"auto-tune-max-latency" mapstructure:"auto-tune-max-latency" json:"auto-tune-max-latency,omitempty"`
+	// CheckpointFile is where the offset of the data source up to which all
+	// the items were inserted is written every CheckpointPeriod
+	CheckpointFile   string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file" json:"checkpoint-file,omitempty"`
+	CheckpointPeriod time.Duration `yaml:"checkpoint-period" mapstructure:"checkpoint-period" json:"checkpoint-period,omitempty"`
+	// ResumeFrom is the checkpoint file of a load to resume, without creating
+	// the database
+	ResumeFrom string `yaml:"resume-from" mapstructure:"resume-from" json:"resume-from,omitempty"`
+	// ReportPrefix is prepended to every report and summary line, used to tell
+	// apart several runners writing to the same output
+	ReportPrefix string `yaml:"report-prefix" mapstructure:"report-prefix" json:"report-prefix,omitempty"`
+	// MemStats adds the allocations and GC pauses of each period to the
+	// periodic report
+	MemStats bool `yaml:"mem-stats" mapstructure:"mem-stats" json:"mem-stats,omitempty"`
+	// ProfileDir is where the Profiles of the loader are written during the
+	// ProfileWindows, empty to not profile
+	ProfileDir     string `yaml:"profile-dir" mapstructure:"profile-dir" json:"profile-dir,omitempty"`
+	Profiles       string `yaml:"profiles" mapstructure:"profiles" json:"profiles,omitempty"`
+	ProfileWindows string `yaml:"profile-windows" mapstructure:"profile-windows" json:"profile-windows,omitempty"`
+	// ClientStats adds the CPU, memory, goroutines and GC pauses of the
+	// loader process of each period to the periodic report
+	ClientStats bool `yaml:"client-stats" mapstructure:"client-stats" json:"client-stats,omitempty"`
+	// ServerStats samples the metrics of the database server every
+	// ReportingPeriod into the results, if the target collects them
+	ServerStats bool `yaml:"server-stats" mapstructure:"server-stats" json:"server-stats,omitempty"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -61,11 +119,34 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Bool("do-create-db", true, "Whether to create the database. Disable on all but one client if running on a multi client setup.")
 	fs.Bool("do-abort-on-exist", false, "Whether to abort if a database with the given name already exists.")
 	fs.Duration("reporting-period", 10*time.Second, "Period to report write stats")
-	fs.String("file", "", "File name to read data from")
+	fs.String("file", "", "File(s) to read data from: a file, a glob pattern or a comma separated list of them. .gz and .zst files are decompressed. Empty reads STDIN")
 	fs.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
 	fs.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.Bool("batch-by-series", false, "Whether to fill a batch per series (e.g. host) instead of per worker, if the target supports it")
+	fs.Duration("batch-max-age", 0, "Time after which a batch which is not full is sent anyway, 0 = only send full batches")
+	fs.Uint("batch-max-series", DefaultBatchMaxSeries, "Number of series batches filled at once with batch-by-series, after which they are all sent, 0 = no limit")
+	fs.String("ingest-rate", "", "Target rate of all workers together, default '' => no limit. '5000' = 5000/s, "+
+		"'step:1000,500,1m', 'ramp:0,10000,5m', 'sine:5000,2000,1m' or 'schedule:FILE' with a 'time rate' line per change")
+	fs.String("ingest-rate-unit", insertstrategy.RatePoints, "What ingest-rate counts: points or batches")
+	fs.Bool("auto-tune", false, "Whether to search the workers and batch size of the best throughput with short probe phases instead of loading all the data")
+	fs.String("auto-tune-workers", DefaultAutoTuneWorkers, "Numbers of workers to probe in auto-tune mode")
+	fs.String("auto-tune-batch-sizes", DefaultAutoTuneBatchSizes, "Batch sizes to probe in auto-tune mode")
+	fs.String("auto-tune-rates", "", "Target rates, in ingest-rate-unit, to probe with the best configuration in auto-tune mode, default '' => none")
+	fs.Duration("auto-tune-probe", DefaultAutoTuneProbe, "How long each probe phase of auto-tune mode lasts")
+	fs.Float64("auto-tune-plateau", DefaultAutoTunePlateau, "Relative throughput increase under which auto-tune mode stops adding workers or growing batches")
+	fs.Duration("auto-tune-max-latency", 0, "p99 batch latency a configuration must stay under in auto-tune mode, 0 = no threshold")
+	fs.String("checkpoint-file", "", "File to write the offset of the data up to which all items were inserted to, every checkpoint-period, default '' => no checkpoints")
+	fs.Duration("checkpoint-period", defaultCheckpointPeriod, "Period to write checkpoints")
+	fs.String("resume-from", "", "Checkpoint file of a previous load to resume, skipping the items it inserted and without creating the database. "+
+		"Checkpoints are written to it unless checkpoint-file is set")
+	fs.Bool("mem-stats", false, "Whether to add the allocations and GC pauses of each period to the periodic report")
+	fs.String("profile-dir", "", "Directory to write the profiles of the loader to, default '' => no profiles")
+	fs.String("profiles", profiling.DefaultKinds, "Profiles to take: cpu, heap, mutex, block and trace")
+	fs.String("profile-windows", "", "Windows of the run to profile as START:DURATION, e.g. '30s:1m,10m:1m', default '' => the whole run")
+	fs.Bool("client-stats", false, "Whether to add the CPU, RSS, goroutines and GC pauses of the loader of each period to the periodic report")
+	fs.Bool("server-stats", false, "Whether to sample the metrics of the database server every reporting-period into the results file, if the target collects them")
 }
 
 type BenchmarkRunner interface {
@@ -81,6 +162,26 @@ type CommonBenchmarkRunner struct {
 	rowCnt         uint64
 	initialRand    *rand.Rand
 	sleepRegulator insertstrategy.SleepRegulator
+	rateLimiter    *insertstrategy.RateLimiter
+	// rateUnitCnt counts the units of the rate limiter inserted
+	rateUnitCnt uint64
+	batchStats  batchStats
+	// latencies of the batches of the current auto-tune probe
+	latencies      *latencyRecorder
+	autoTuneConfig *autoTuneConfig
+	autoTuneResult *AutoTuneResult
+	// checkpoint is written by checkpoints as the items are inserted
+	checkpoint  *Checkpoint
+	checkpoints *checkpointTracker
+	coordinator RunCoordinator
+	// memStart are the memory stats at the start of the run, runMemStats
+	// those of the whole run
+	memStart    memStats
+	runMemStats memStats
+	profiler    *profiling.Profiler
+	// sampler samples the resources used by the loader
+	sampler     *profiling.Sampler
+	serverStats *serverStatsCollector
 }
 
 // GetBenchmarkRunnerWithBatchSize returns the singleton CommonBenchmarkRunner for use in a benchmark program
@@ -104,17 +205,35 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
 		}
 	}
-	if !c.NoFlowControl {
-		return &loader
+	if c.IngestRate != "" {
+		unit := c.IngestRateUnit
+		if unit == "" {
+			unit = insertstrategy.RatePoints
+		}
+		loader.rateLimiter, err = insertstrategy.NewRateLimiter(c.IngestRate, unit)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
 	}
-
-	if c.ChannelCapacity == DefaultChannelCapacityFlagVal {
-		if c.HashWorkers {
-			loader.ChannelCapacity = defaultChannelCapacityPerWorker
-		} else {
-			loader.ChannelCapacity = c.Workers * defaultChannelCapacityPerWorker
+	if c.AutoTune {
+		if loader.AutoTuneProbe <= 0 {
+			loader.AutoTuneProbe = DefaultAutoTuneProbe
+		}
+		loader.autoTuneConfig, err = newAutoTuneConfig(&c)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
 		}
 	}
+	if err = loader.initCheckpoints(); err != nil {
+		panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+	}
+	loader.profiler, err = profiling.NewProfiler(c.ProfileDir, c.Profiles, c.ProfileWindows)
+	if err != nil {
+		panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+	}
+	if !c.NoFlowControl {
+		return &loader
+	}
 
 	return &noFlowBenchmarkRunner{loader}
 }
@@ -124,51 +243,142 @@ func (l *CommonBenchmarkRunner) DatabaseName() string {
 	return l.DBName
 }
 
-func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *time.Time, func()) {
+func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*time.Time, func()) {
 	// Create required DB
 	var cleanupFn func()
 	if b.GetDBCreator() != nil {
 		cleanupFn = l.useDBCreator(b.GetDBCreator())
 	}
+	if l.coordinator != nil {
+		// the coordinated runners start loading together
+		if err := l.coordinator.DBReady(); err != nil {
+			panic(fmt.Sprintf("could not start the coordinated run: %v", err))
+		}
+	}
+	if b.GetDBCreator() != nil {
+		l.startServerStats(b.GetDBCreator())
+	}
 
-	if l.ReportingPeriod.Nanoseconds() > 0 {
+	l.memStart = readMemStats()
+	l.sampler = profiling.NewSampler()
+	// the probe phases of auto-tune mode are reported one by one instead
+	if l.ReportingPeriod.Nanoseconds() > 0 && !l.AutoTune {
 		go l.report(l.ReportingPeriod)
 	}
-	wg := &sync.WaitGroup{}
-	wg.Add(int(l.Workers))
 	start := time.Now()
-	return wg, &start, cleanupFn
+	if l.rateLimiter != nil {
+		l.rateLimiter.Start(start)
+	}
+	l.profiler.Start(start)
+	return &start, cleanupFn
 }
 
-func (l *CommonBenchmarkRunner) postRun(wg *sync.WaitGroup, start *time.Time) {
-	// Wait for all workers to finish
-	wg.Wait()
+func (l *CommonBenchmarkRunner) postRun(start *time.Time, dbc targets.DBCreator) {
 	end := time.Now()
 	took := end.Sub(*start)
+	l.profiler.Stop()
+	l.stopServerStats()
+	l.runMemStats = readMemStats().since(l.memStart)
+	l.sampler.Sample()
 	l.summary(took)
+	l.memSummary(took)
+	l.profileSummary()
+	l.serverStatsSummary()
+	l.resumedSummary()
+	if l.autoTuneResult != nil {
+		l.autoTuneSummary()
+	}
+	l.postLoad(dbc)
+	if l.BenchmarkRunnerConfig.ResultsFile == "" && l.coordinator == nil {
+		return
+	}
+	metricRate := float64(l.metricCnt) / took.Seconds()
+	rowRate := float64(l.rowCnt) / took.Seconds()
+	result := l.testResult(took, *start, end, metricRate, rowRate, l.targetReport(dbc))
 	if l.BenchmarkRunnerConfig.ResultsFile != "" {
-		metricRate := float64(l.metricCnt) / took.Seconds()
-		rowRate := float64(l.rowCnt) / took.Seconds()
-		l.saveTestResult(took, *start, end, metricRate, rowRate)
+		l.saveTestResult(result)
+	}
+	if l.coordinator != nil {
+		if err := l.coordinator.Done(result, l.metricCnt, l.rowCnt); err != nil {
+			log.Printf("could not send the result to the coordinator: %v", err)
+		}
+	}
+}
+
+// postLoad runs the post load steps of the DBCreator if it has any
+func (l *CommonBenchmarkRunner) postLoad(dbc targets.DBCreator) {
+	if !l.DoLoad {
+		return
+	}
+	dbcp, ok := dbc.(targets.DBCreatorPostLoad)
+	if !ok {
+		return
+	}
+	if err := dbcp.PostLoad(l.DBName); err != nil {
+		log.Printf("could not finish the post load steps: %v", err)
 	}
 }
 
-func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) {
+// targetReport collects the target specific report if the DBCreator supports it
+func (l *CommonBenchmarkRunner) targetReport(dbc targets.DBCreator) map[string]interface{} {
+	if !l.DoLoad {
+		return nil
+	}
+	dbcr, ok := dbc.(targets.DBCreatorReporter)
+	if !ok {
+		return nil
+	}
+	report, err := dbcr.Report(l.DBName)
+	if err != nil {
+		log.Printf("could not collect target report: %v", err)
+		return nil
+	}
+	return report
+}
+
+// testResult returns the LoaderTestResult of the run
+func (l *CommonBenchmarkRunner) testResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64, targetReport map[string]interface{}) *LoaderTestResult {
 	totals := make(map[string]interface{})
 	totals["metricRate"] = metricRate
 	if l.rowCnt > 0 {
 		totals["rowRate"] = rowRate
 	}
+	if l.checkpoint != nil && len(l.checkpoint.Segments) > 1 {
+		// the throughput of all the segments of a resumed load
+		metrics, rows, segmentsTook := l.checkpoint.totals()
+		took = segmentsTook
+		start = time.Unix(l.checkpoint.Segments[0].StartTime, 0)
+		totals["metricRate"] = float64(metrics) / took.Seconds()
+		if rows > 0 {
+			totals["rowRate"] = float64(rows) / took.Seconds()
+		}
+		totals["segments"] = l.checkpoint.Segments
+	}
+	if l.batchStats.affinity {
+		totals["batches"] = l.batchStats.batches
+		totals["meanSeriesPerBatch"] = l.batchStats.meanSeries()
+		totals["maxSeriesPerBatch"] = l.batchStats.maxSeries
+	}
+	totals["memStats"] = l.memTotals()
+	totals["client"] = l.sampler.Totals()
+	if profiles := l.profiler.Files(); len(profiles) > 0 {
+		totals["profiles"] = profiles
+	}
 
-	testResult := LoaderTestResult{
+	return &LoaderTestResult{
 		ResultFormatVersion: LoaderTestResultVersion,
 		RunnerConfig:        l.BenchmarkRunnerConfig,
 		StartTime:           start.Unix(),
 		EndTime:             end.Unix(),
 		DurationMillis:      took.Milliseconds(),
 		Totals:              totals,
+		TargetReport:        targetReport,
+		AutoTune:            l.autoTuneResult,
+		ServerStats:         l.serverStatsSamples(),
 	}
+}
 
+func (l *CommonBenchmarkRunner) saveTestResult(testResult *LoaderTestResult) {
 	_, _ = fmt.Printf("Saving results json file to %s\n", l.BenchmarkRunnerConfig.ResultsFile)
 	file, err := json.MarshalIndent(testResult, "", " ")
 	if err != nil {
@@ -183,25 +393,61 @@ func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Ti
 
 // RunBenchmark takes in a Benchmark b and uses it to run the load benchmark
 func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
-	wg, start, cleanupFn := l.preRun(b)
+	l.run(b, l.load)
+}
+
+// useHashedWorkers turns hash-workers on for a Benchmark which only loads
+// correctly with it
+func (l *CommonBenchmarkRunner) useHashedWorkers(b targets.Benchmark) {
+	if hb, ok := b.(targets.HashedWorkersBenchmark); ok && hb.HashWorkers() {
+		l.HashWorkers = true
+	}
+}
+
+// run loads the data of b with load, all of it or in the probe phases of
+// auto-tune mode
+func (l *CommonBenchmarkRunner) run(b targets.Benchmark, load loadFn) {
+	l.useHashedWorkers(b)
+	// checked before the database is created
+	if err := l.checkOrdered(b.GetDataSource()); err != nil {
+		fatal("%v", err)
+		return
+	}
+	start, cleanupFn := l.preRun(b)
+	switch {
+	case l.AutoTune:
+		l.autoTune(b, load)
+	case l.checkpoints != nil:
+		l.loadWithCheckpoints(b, load, start)
+	default:
+		load(b, b.GetDataSource(), l.Workers, l.BatchSize)
+	}
+	l.postRun(start, b.GetDBCreator())
+	cleanupFn()
+}
+
+// load sends the batches to the workers with flow control
+func (l *CommonBenchmarkRunner) load(b targets.Benchmark, ds targets.DataSource, workers, batchSize uint) {
+	wg := &sync.WaitGroup{}
+	wg.Add(int(workers))
 	var numChannels, capacity uint
 	if l.HashWorkers {
-		numChannels = l.Workers
+		numChannels = workers
 		capacity = 1
 	} else {
 		numChannels = 1
-		capacity = l.Workers
+		capacity = workers
 	}
 
 	channels := l.createChannels(numChannels, capacity)
 
 	// Launch all worker processes in background
-	for i := uint(0); i < l.Workers; i++ {
+	for i := uint(0); i < workers; i++ {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 
 	// Start scan process - actual data read process
-	scanWithFlowControl(channels, l.BatchSize, l.Limit, b.GetDataSource(), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))), l.BenchmarkRunnerConfig.BatchMetaFields, l.BenchmarkRunnerConfig.MetaFieldIndex)
+	scanWithFlowControl(channels, batchSize, l.scanLimit(), ds, b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))), l.scanOptions())
 	// After scan process completed (no more data to come) - begin shutdown process
 
 	// Close all communication channels to/from workers
@@ -209,8 +455,8 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 		c.close()
 	}
 
-	cleanupFn()
-	l.postRun(wg, start)
+	// Wait for all workers to finish
+	wg.Wait()
 }
 
 // useDBCreator handles a DBCreator by running it according to flags set by the
@@ -274,6 +520,13 @@ func (l *CommonBenchmarkRunner) createChannels(numChannels, capacity uint) []*du
 	return channels
 }
 
+// releaseBatch lets a batch reuse its memory once it is processed
+func releaseBatch(batch targets.Batch) {
+	if rb, ok := batch.(targets.ReleasableBatch); ok {
+		rb.Release()
+	}
+}
+
 // work is the processing function for each worker in the loader
 func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c *duplexChannel, workerNum uint) {
 
@@ -284,10 +537,16 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	// Process batches coming from duplexChannel.toWorker queue
 	// and send ACKs into duplexChannel.toScanner queue
 	for batch := range c.toWorker {
+		batch, inserted := l.untrackBatch(batch)
+		rateUnits := l.waitForRate(batch)
 		startedWorkAt := time.Now()
 		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		releaseBatch(batch)
+		inserted()
+		l.recordLatency(startedWorkAt)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
+		atomic.AddUint64(&l.rateUnitCnt, rateUnits)
 		c.sendToScanner()
 		l.timeToSleep(workerNum, startedWorkAt)
 	}
@@ -301,6 +560,38 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	wg.Done()
 }
 
+// waitForRate makes the worker wait until batch may be inserted at the
+// target ingest rate, if there is one. It returns the points, or the batch,
+// to count in the achieved rate once batch is inserted.
+func (l *CommonBenchmarkRunner) waitForRate(batch targets.Batch) uint64 {
+	if l.rateLimiter == nil {
+		return 0
+	}
+	l.rateLimiter.Wait(batch.Len())
+	if l.rateLimiter.Unit() == insertstrategy.RateBatches {
+		return 1
+	}
+	return uint64(batch.Len())
+}
+
+// recordLatency records how long a batch took to insert during an auto-tune
+// probe.
+func (l *CommonBenchmarkRunner) recordLatency(startedWorkAt time.Time) {
+	if l.latencies != nil {
+		l.latencies.record(time.Since(startedWorkAt))
+	}
+}
+
+// scanLimit is the limit of items of a scan, which is enforced over all the
+// probe phases by the DataSource in auto-tune mode, and counts the items
+// inserted before a resume.
+func (l *CommonBenchmarkRunner) scanLimit() uint64 {
+	if l.AutoTune || l.Limit == 0 {
+		return 0
+	}
+	return l.Limit - l.resumeOffset()
+}
+
 func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.Time) {
 	if l.sleepRegulator != nil {
 		l.sleepRegulator.Sleep(int(workerNum), startedWorkAt)
@@ -310,11 +601,40 @@ func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.T
 // summary prints the summary of statistics from loading
 func (l *CommonBenchmarkRunner) summary(took time.Duration) {
 	metricRate := float64(l.metricCnt) / took.Seconds()
-	printFn("\nSummary:\n")
-	printFn("loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", l.metricCnt, took.Seconds(), l.Workers, metricRate)
+	printFn("\n%sSummary:\n", l.ReportPrefix)
+	printFn("%sloaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", l.ReportPrefix, l.metricCnt, took.Seconds(), l.Workers, metricRate)
 	if l.rowCnt > 0 {
 		rowRate := float64(l.rowCnt) / float64(took.Seconds())
-		printFn("loaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", l.rowCnt, took.Seconds(), l.Workers, rowRate)
+		printFn("%sloaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", l.ReportPrefix, l.rowCnt, took.Seconds(), l.Workers, rowRate)
+	}
+	if l.batchStats.affinity {
+		printFn("%ssent %d batches with %0.2f distinct series per batch (max %d)\n", l.ReportPrefix, l.batchStats.batches, l.batchStats.meanSeries(), l.batchStats.maxSeries)
+		if l.batchStats.ageFlushes > 0 {
+			printFn("%s%d batches were sent after %v without being full\n", l.ReportPrefix, l.batchStats.ageFlushes, l.BatchMaxAge)
+		}
+		if l.batchStats.seriesFlushes > 0 {
+			printFn("%s%d batches were sent without being full as more than %d series were batched at once\n", l.ReportPrefix, l.batchStats.seriesFlushes, l.BatchMaxSeries)
+		}
+	}
+}
+
+// profileSummary prints the resources used by the loader and the profiles it
+// wrote.
+func (l *CommonBenchmarkRunner) profileSummary() {
+	printFn("%s%s\n", l.ReportPrefix, l.sampler.Summary())
+	if profiles := l.profiler.Files(); len(profiles) > 0 {
+		printFn("%swrote profiles %s\n", l.ReportPrefix, strings.Join(profiles, ", "))
+	}
+}
+
+// scanOptions returns how the scanner should fill the batches.
+func (l *CommonBenchmarkRunner) scanOptions() scanOptions {
+	return scanOptions{
+		bySeries:    l.BatchBySeries,
+		maxAge:      l.BatchMaxAge,
+		maxSeries:   l.BatchMaxSeries,
+		stats:       &l.batchStats,
+		checkpoints: l.checkpoints,
 	}
 }
 
@@ -324,26 +644,60 @@ func (l *CommonBenchmarkRunner) report(period time.Duration) {
 	prevTime := start
 	prevColCount := uint64(0)
 	prevRowCount := uint64(0)
+	prevUnitCount := uint64(0)
 
-	printFn("time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s\n")
+	rateHeader := ""
+	if l.rateLimiter != nil {
+		unit := l.rateLimiter.Unit()
+		rateHeader = fmt.Sprintf(",target %s/s,achieved %s/s", unit, unit)
+	}
+	prevMem := l.memStart
+	if l.MemStats {
+		rateHeader += memReportHeader
+	}
+	if l.ClientStats {
+		rateHeader += profiling.ReportHeader
+	}
+	printFn("%stime,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s%s\n", l.ReportPrefix, rateHeader)
 	for now := range time.NewTicker(period).C {
 		cCount := atomic.LoadUint64(&l.metricCnt)
 		rCount := atomic.LoadUint64(&l.rowCnt)
+		uCount := atomic.LoadUint64(&l.rateUnitCnt)
+		if l.coordinator != nil {
+			if err := l.coordinator.Progress(cCount, rCount); err != nil {
+				log.Printf("could not send the progress to the coordinator: %v", err)
+			}
+		}
 
 		sinceStart := now.Sub(start)
 		took := now.Sub(prevTime)
 		colrate := float64(cCount-prevColCount) / float64(took.Seconds())
 		overallColRate := float64(cCount) / float64(sinceStart.Seconds())
+		rateCols := ""
+		if l.rateLimiter != nil {
+			targetRate := l.rateLimiter.MeanTargetRate(prevTime, now)
+			achievedRate := float64(uCount-prevUnitCount) / took.Seconds()
+			rateCols = fmt.Sprintf(",%0.2f,%0.2f", targetRate, achievedRate)
+		}
+		if l.MemStats {
+			mem := readMemStats()
+			rateCols += mem.since(prevMem).memReportColumns(took)
+			prevMem = mem
+		}
+		if l.ClientStats {
+			rateCols += l.sampler.Sample().ReportColumns()
+		}
 		if rCount > 0 {
 			rowrate := float64(rCount-prevRowCount) / float64(took.Seconds())
 			overallRowRate := float64(rCount) / float64(sinceStart.Seconds())
-			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate)
+			printFn("%s%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f%s\n", l.ReportPrefix, now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate, rateCols)
 		} else {
-			printFn("%d,%0.2f,%E,%0.2f,-,-,-\n", now.Unix(), colrate, float64(cCount), overallColRate)
+			printFn("%s%d,%0.2f,%E,%0.2f,-,-,-%s\n", l.ReportPrefix, now.Unix(), colrate, float64(cCount), overallColRate, rateCols)
 		}
 
 		prevColCount = cCount
 		prevRowCount = rCount
+		prevUnitCount = uCount
 		prevTime = now
 	}
 }
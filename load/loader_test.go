@@ -100,6 +100,14 @@ func (b *testBenchmark) GetDBCreator() targets.DBCreator {
 	return nil
 }
 
+// hashedWorkersBenchmark is a testBenchmark which may need hashed workers.
+type hashedWorkersBenchmark struct {
+	testBenchmark
+	hashWorkers bool
+}
+
+func (b *hashedWorkersBenchmark) HashWorkers() bool { return b.hashWorkers }
+
 type testSleepRegulator struct {
 	calledTimes int
 	lock        sync.Mutex
@@ -305,6 +313,28 @@ func TestCreateChannelsAndPartitions(t *testing.T) {
 	}
 }
 
+func TestUseHashedWorkers(t *testing.T) {
+	cases := []struct {
+		desc   string
+		config bool
+		b      targets.Benchmark
+		want   bool
+	}{
+		{desc: "plain benchmark", b: &testBenchmark{}},
+		{desc: "plain benchmark, config", config: true, b: &testBenchmark{}, want: true},
+		{desc: "not needed", b: &hashedWorkersBenchmark{}},
+		{desc: "not needed, config", config: true, b: &hashedWorkersBenchmark{}, want: true},
+		{desc: "needed", b: &hashedWorkersBenchmark{hashWorkers: true}, want: true},
+	}
+	for _, c := range cases {
+		br := &CommonBenchmarkRunner{BenchmarkRunnerConfig: BenchmarkRunnerConfig{HashWorkers: c.config}}
+		br.useHashedWorkers(c.b)
+		if br.HashWorkers != c.want {
+			t.Errorf("%s: incorrect hash workers: got %v want %v", c.desc, br.HashWorkers, c.want)
+		}
+	}
+}
+
 func TestWork(t *testing.T) {
 	br := &CommonBenchmarkRunner{}
 	b := &testBenchmark{}
@@ -431,6 +461,28 @@ func TestSummary(t *testing.T) {
 	}
 }
 
+func TestMemSummary(t *testing.T) {
+	oldPrintFn := printFn
+	defer func() { printFn = oldPrintFn }()
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+
+	br := &CommonBenchmarkRunner{}
+	br.metricCnt = 1000
+	br.runMemStats = memStats{totalAlloc: 4000000, mallocs: 500, numGC: 4, gcPause: 20 * time.Millisecond, gcCPUFraction: 0.05}
+	br.memSummary(2 * time.Second)
+	want := "allocated 4.00MB in 500 allocations (2.00MB/sec, 4000.0 bytes and 0.50 allocations per metric)\n" +
+		"4 GCs paused 20.000ms (1.00% of the run), GC used 5.00% of the CPU\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect summary: got\n%s\nwant\n%s", got, want)
+	}
+	if totals := br.memTotals(); totals["numGC"] != uint32(4) || totals["gcPauseMillis"] != 20.0 {
+		t.Errorf("incorrect totals: %v", totals)
+	}
+}
+
 func TestReport(t *testing.T) {
 	var b bytes.Buffer
 	counter := int64(0)
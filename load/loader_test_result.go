@@ -17,4 +17,14 @@ type LoaderTestResult struct {
 
 	// Totals
 	Totals map[string]interface{} `json:"Totals"`
+
+	// Target specific details, see targets.DBCreatorReporter
+	TargetReport map[string]interface{} `json:"TargetReport,omitempty"`
+
+	// Probes and best configuration of the auto-tune mode
+	AutoTune *AutoTuneResult `json:"AutoTune,omitempty"`
+
+	// Metrics of the database server sampled during the load, see
+	// targets.StatsCollector
+	ServerStats []ServerStatsSample `json:"ServerStats,omitempty"`
 }
@@ -0,0 +1,79 @@
+package load
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// memStats are the allocation and garbage collection counters of the loader
+// process. A run whose GC pauses take a noticeable part of its duration, or
+// whose GC uses a noticeable part of the CPU, is bound by the client rather
+// than by the database.
+type memStats struct {
+	totalAlloc uint64
+	mallocs    uint64
+	numGC      uint32
+	gcPause    time.Duration
+	// gcCPUFraction is the fraction of the CPU used by the GC since the
+	// process started
+	gcCPUFraction float64
+}
+
+func readMemStats() memStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return memStats{
+		totalAlloc:    m.TotalAlloc,
+		mallocs:       m.Mallocs,
+		numGC:         m.NumGC,
+		gcPause:       time.Duration(m.PauseTotalNs),
+		gcCPUFraction: m.GCCPUFraction,
+	}
+}
+
+// since returns the counters from prev to s.
+func (s memStats) since(prev memStats) memStats {
+	return memStats{
+		totalAlloc:    s.totalAlloc - prev.totalAlloc,
+		mallocs:       s.mallocs - prev.mallocs,
+		numGC:         s.numGC - prev.numGC,
+		gcPause:       s.gcPause - prev.gcPause,
+		gcCPUFraction: s.gcCPUFraction,
+	}
+}
+
+// memReportHeader is the header of the columns of memReportColumns.
+const memReportHeader = ",alloc MB/s,GCs,GC pause ms"
+
+// memReportColumns returns the periodic report columns of the counters of a
+// period of length took.
+func (s memStats) memReportColumns(took time.Duration) string {
+	return fmt.Sprintf(",%0.2f,%d,%0.3f", float64(s.totalAlloc)/1e6/took.Seconds(), s.numGC, float64(s.gcPause)/float64(time.Millisecond))
+}
+
+// memSummary prints the allocations and GC pauses of the run.
+func (l *CommonBenchmarkRunner) memSummary(took time.Duration) {
+	s := l.runMemStats
+	perMetric := ""
+	if l.metricCnt > 0 {
+		perMetric = fmt.Sprintf(", %0.1f bytes and %0.2f allocations per metric",
+			float64(s.totalAlloc)/float64(l.metricCnt), float64(s.mallocs)/float64(l.metricCnt))
+	}
+	printFn("%sallocated %0.2fMB in %d allocations (%0.2fMB/sec%s)\n",
+		l.ReportPrefix, float64(s.totalAlloc)/1e6, s.mallocs, float64(s.totalAlloc)/1e6/took.Seconds(), perMetric)
+	printFn("%s%d GCs paused %0.3fms (%0.2f%% of the run), GC used %0.2f%% of the CPU\n",
+		l.ReportPrefix, s.numGC, float64(s.gcPause)/float64(time.Millisecond), 100*s.gcPause.Seconds()/took.Seconds(), 100*s.gcCPUFraction)
+}
+
+// memTotals returns the allocations and GC pauses of the run for the results.
+func (l *CommonBenchmarkRunner) memTotals() map[string]interface{} {
+	s := l.runMemStats
+	return map[string]interface{}{
+		"allocBytes":    s.totalAlloc,
+		"allocs":        s.mallocs,
+		"numGC":         s.numGC,
+		"gcPauseMillis": float64(s.gcPause) / float64(time.Millisecond),
+		"gcCPUFraction": s.gcCPUFraction,
+	}
+}
@@ -0,0 +1,19 @@
+package load
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemStatsSince(t *testing.T) {
+	prev := memStats{totalAlloc: 100, mallocs: 10, numGC: 1, gcPause: time.Millisecond}
+	s := memStats{totalAlloc: 2000100, mallocs: 30, numGC: 3, gcPause: 4 * time.Millisecond, gcCPUFraction: 0.5}
+	got := s.since(prev)
+	want := memStats{totalAlloc: 2000000, mallocs: 20, numGC: 2, gcPause: 3 * time.Millisecond, gcCPUFraction: 0.5}
+	if got != want {
+		t.Errorf("incorrect stats: got %+v want %+v", got, want)
+	}
+	if cols := got.memReportColumns(2 * time.Second); cols != ",1.00,2,3.000" {
+		t.Errorf("incorrect report columns: got %q", cols)
+	}
+}
@@ -2,26 +2,23 @@ package load
 
 import (
 	"github.com/timescale/tsbs/pkg/targets"
-	tsbsMongo "github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
 // scanWithoutFlowControl reads data from the DataSource ds until a limit is reached (if -1, all items are read).
-// Data is then placed into appropriate batches, using the supplied PointIndexer,
+// Data is then placed into appropriate batches, using the supplied PointIndexer and opts,
 // which are then dispatched to workers (channel idx chosen by PointIndexer).
 // readDs does no flow control, if the capacity of a channel is reached, scanning stops for all
 // workers. (should only happen if channel-capacity is low and one worker is unreasonable slower than the rest)
 // in that case just set hash-workers to false and use 1 channel for all workers.
 func scanWithoutFlowControl(
 	ds targets.DataSource, indexer targets.PointIndexer, factory targets.BatchFactory, channels []chan targets.Batch,
-	batchSize uint, limit uint64, batchedInserts bool, metaFieldIndex string) uint64 {
+	batchSize uint, limit uint64, opts scanOptions) uint64 {
 	if batchSize == 0 {
 		panic("batch size can't be 0")
 	}
-	numChannels := len(channels)
-	batches := make([]targets.Batch, numChannels)
-	for i := 0; i < numChannels; i++ {
-		batches[i] = factory.New()
-	}
+	filler := newBatchFiller(factory, indexer, uint(len(channels)), batchSize, opts, func(idx uint, b targets.Batch) {
+		channels[idx] <- b
+	})
 	var itemsRead uint64
 	for {
 		if limit > 0 && itemsRead >= limit {
@@ -35,37 +32,9 @@ func scanWithoutFlowControl(
 		}
 		itemsRead++
 
-		idx := indexer.GetIndex(item)
-		metaIndexVal := ""
-		metaIndexValExists := false
-		if batchedInserts { 
-			t := &tsbsMongo.MongoTag{}
-			p := item.Data.(*tsbsMongo.MongoPoint)
-			for j := 0; j < p.TagsLength(); j++ {  
-				p.Tags(t, j)  
-				if string(t.Key()) == metaFieldIndex {
-					metaIndexVal = string(t.Value())
-					metaIndexValExists = true
-				}
-			}
-			// Only assign the channel based on the metaIndexVal if we find a metaIndexVal
-			if metaIndexValExists {
-				idx = hash(metaIndexVal) % uint(numChannels) 
-			} 
-		}
-
-		batches[idx].Append(item)
-
-		if batches[idx].Len() >= batchSize {
-			channels[idx] <- batches[idx]
-			batches[idx] = factory.New()
-		}
+		filler.add(item)
 	}
 
-	for idx, unfilledBatch := range batches {
-		if unfilledBatch.Len() > 0 {
-			channels[idx] <- unfilledBatch
-		}
-	}
+	filler.flush()
 	return itemsRead
 }
@@ -87,7 +87,7 @@ func TestScanWithoutFlowControl(t *testing.T) {
 							t.Errorf("%s: did not panic when should", c.desc)
 						}
 					}()
-					scanWithoutFlowControl(testDataSource, indexer, &testFactory{}, channels, c.batchSize, c.limit, false)
+					scanWithoutFlowControl(testDataSource, indexer, &testFactory{}, channels, c.batchSize, c.limit, scanOptions{})
 				}()
 				return
 			} else {
@@ -96,7 +96,7 @@ func TestScanWithoutFlowControl(t *testing.T) {
 				for i := uint(0); i < c.numChannels; i++ {
 					go _boringWorkerSingleChannel(channels[i], &channelCalls[i], wg)
 				}
-				read := scanWithoutFlowControl(testDataSource, indexer, &testFactory{}, channels, c.batchSize, c.limit, false, "")
+				read := scanWithoutFlowControl(testDataSource, indexer, &testFactory{}, channels, c.batchSize, c.limit, scanOptions{})
 				for i := uint(0); i < c.numChannels; i++ {
 					close(channels[i])
 				}
@@ -2,18 +2,10 @@ package load
 
 import (
 	"reflect"
-	"hash/fnv"
 
 	"github.com/timescale/tsbs/pkg/targets"
-	tsbsMongo "github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
-func hash(s string) uint {
-	h := fnv.New32a()
-	h.Write([]byte(s))
-	return uint(h.Sum32())
-}
-
 // ackAndMaybeSend adjust the unsent batches count
 // and sends one batch (if any available) to the worker via ch.
 // Returns the updated state of unsent
@@ -46,13 +38,13 @@ func sendOrQueueBatch(ch *duplexChannel, count *int, batch targets.Batch, unsent
 }
 
 // scanWithFlowControl reads data from the DataSource ds until a limit is reached (if -1, all items are read).
-// Data is then placed into appropriate batches, using the supplied PointIndexer,
+// Data is then placed into appropriate batches, using the supplied PointIndexer and opts,
 // which are then dispatched to workers (duplexChannel chosen by PointIndexer).
 // Scan does flow control to make sure workers are not left idle for too long
 // and also that the scanning process does not starve them of CPU.
 func scanWithFlowControl(
 	channels []*duplexChannel, batchSize uint, limit uint64,
-	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer, batchedInserts bool, metaFieldIndex string,
+	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer, opts scanOptions,
 ) uint64 {
 	var itemsRead uint64
 	numChannels := len(channels)
@@ -62,23 +54,29 @@ func scanWithFlowControl(
 	}
 
 	// Batches details
-	// 1. fillingBatches contains batches that are being filled with items from scanner.
-	//    As soon a batch has batchSize items in it, or there is no more items to come, batch moves to unsentBatches.
+	// 1. The batchFiller holds the batches that are being filled with items from scanner.
+	//    As soon a batch has batchSize items in it, is too old, or there is no more items to come,
+	//    batch moves to unsentBatches.
 	// 2. unsentBatches contains batches ready to be sent to a worker.
 	//    As soon as a worker's chan is available (i.e., not blocking), the batch is placed onto that worker's chan.
 
-	// Current batches (per channel) that are being filled with items from scanner
-	fillingBatches := make([]targets.Batch, numChannels)
-	for i := range fillingBatches {
-		fillingBatches[i] = factory.New()
-	}
-
 	// Batches that are ready to be set when space on a channel opens
 	unsentBatches := make([][]targets.Batch, numChannels)
 	for i := range unsentBatches {
 		unsentBatches[i] = []targets.Batch{}
 	}
 
+	// Keep track of how many batches are outstanding (ocnt),
+	// so we don't go over a limit (olimit), in order to slow down the scanner so it doesn't starve the workers
+	ocnt := 0
+	olimit := numChannels * cap(channels[0].toWorker) * 3
+
+	// Full batches are ready to be sent to worker,
+	// or moved to outstanding, in case no workers available atm.
+	filler := newBatchFiller(factory, indexer, uint(numChannels), batchSize, opts, func(idx uint, b targets.Batch) {
+		unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, b, unsentBatches[idx])
+	})
+
 	// We use Select via reflection to either select an acknowledged channel so
 	// that we can potentially send another batch, or if none are ready to continue
 	// on scanning. However, when we reach a limit of outstanding (unsent) batches,
@@ -96,10 +94,6 @@ func scanWithFlowControl(
 		Dir: reflect.SelectDefault,
 	}
 
-	// Keep track of how many batches are outstanding (ocnt),
-	// so we don't go over a limit (olimit), in order to slow down the scanner so it doesn't starve the workers
-	ocnt := 0
-	olimit := numChannels * cap(channels[0].toWorker) * 3
 	for {
 
 		// Check whether incoming items limit reached.
@@ -130,43 +124,12 @@ func scanWithFlowControl(
 		itemsRead++
 
 		// Append new item to batch
-		idx := indexer.GetIndex(item)
-		metaIndexVal := ""
-		metaIndexValExists := false
-		if batchedInserts { 
-			t := &tsbsMongo.MongoTag{}
-			p := item.Data.(*tsbsMongo.MongoPoint)
-			for j := 0; j < p.TagsLength(); j++ {  
-				p.Tags(t, j)  
-				if string(t.Key()) == metaFieldIndex {
-					metaIndexVal = string(t.Value())
-					metaIndexValExists = true
-				}
-			}
-			// Only assign the channel based on the metaIndexVal if we find a metaIndexVal
-			if metaIndexValExists {
-				idx = hash(metaIndexVal) % uint(numChannels) 
-			} 
-		}
-		fillingBatches[idx].Append(item)
-
-		if fillingBatches[idx].Len() >= batchSize {
-			// Batch is full (contains at least batchSize items) - ready to be sent to worker,
-			// or moved to outstanding, in case no workers available atm.
-			unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, fillingBatches[idx], unsentBatches[idx])
-			// Place new empty batch
-			fillingBatches[idx] = factory.New()
-		}
+		filler.add(item)
 	}
 
 	// Finished reading input - no more items to come
-	// Make sure last batch goes out - it may be smaller than batchSize requested - there is not more items
-	for idx, b := range fillingBatches {
-		// Do not enqueue empty batches (with 0 items)
-		if b.Len() > 0 {
-			unsentBatches[idx] = sendOrQueueBatch(channels[idx], &ocnt, fillingBatches[idx], unsentBatches[idx])
-		}
-	}
+	// Make sure last batches go out - they may be smaller than batchSize requested - there is not more items
+	filler.flush()
 
 	// Wait until all the outstanding batches get acknowledged,
 	// so we don't prematurely close the acknowledge channels
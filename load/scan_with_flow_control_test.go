@@ -262,12 +262,12 @@ func TestScanWithIndexer(t *testing.T) {
 						t.Errorf("%s: did not panic when should", c.desc)
 					}
 				}()
-				scanWithFlowControl(channels, c.batchSize, c.limit, testDataSource, &testFactory{}, indexer, false, "")
+				scanWithFlowControl(channels, c.batchSize, c.limit, testDataSource, &testFactory{}, indexer, scanOptions{})
 			}()
 			continue
 		} else {
 			go _boringWorker(channels[0])
-			read := scanWithFlowControl(channels, c.batchSize, c.limit, testDataSource, &testFactory{}, indexer, false, "")
+			read := scanWithFlowControl(channels, c.batchSize, c.limit, testDataSource, &testFactory{}, indexer, scanOptions{})
 			_checkScan(t, c.desc, testDataSource.called, read, c.wantCalls)
 		}
 	}
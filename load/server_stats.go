@@ -0,0 +1,138 @@
+package load
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// ServerStatsSample are the metrics of the database server at a time, with
+// the throughput of the loader over the period before, so that the behaviour
+// of the client and the server can be lined up.
+type ServerStatsSample struct {
+	// TimeMillis is the unix time of the sample in milliseconds
+	TimeMillis int64   `json:"timeMillis"`
+	MetricRate float64 `json:"metricRate"`
+	RowRate    float64 `json:"rowRate,omitempty"`
+	// Stats are the metrics of the server, see targets.StatsCollector
+	Stats map[string]float64 `json:"stats"`
+}
+
+// serverStatsCollector samples the metrics of the server every period until
+// it is stopped.
+type serverStatsCollector struct {
+	collector targets.StatsCollector
+	dbName    string
+	period    time.Duration
+	// metricCnt and rowCnt are the counters of the runner
+	metricCnt *uint64
+	rowCnt    *uint64
+
+	stop    chan struct{}
+	done    chan struct{}
+	samples []ServerStatsSample
+	// failures counts the samples which could not be collected
+	failures uint64
+
+	prevTime    time.Time
+	prevMetrics uint64
+	prevRows    uint64
+}
+
+// startServerStats starts sampling the metrics of the server if server stats
+// are on and the DBCreator of the target collects them.
+func (l *CommonBenchmarkRunner) startServerStats(dbc targets.DBCreator) {
+	if !l.ServerStats || !l.DoLoad || l.ReportingPeriod <= 0 {
+		return
+	}
+	collector, ok := dbc.(targets.StatsCollector)
+	if !ok {
+		log.Printf("the target does not collect server stats")
+		return
+	}
+	l.serverStats = &serverStatsCollector{
+		collector: collector,
+		dbName:    l.DBName,
+		period:    l.ReportingPeriod,
+		metricCnt: &l.metricCnt,
+		rowCnt:    &l.rowCnt,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	// the first sample is the baseline of the counters of the server
+	l.serverStats.sample(time.Now())
+	go l.serverStats.run()
+}
+
+func (s *serverStatsCollector) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.period)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.sample(now)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// sample collects the metrics of the server at now.
+func (s *serverStatsCollector) sample(now time.Time) {
+	metrics := atomic.LoadUint64(s.metricCnt)
+	rows := atomic.LoadUint64(s.rowCnt)
+	stats, err := s.collector.CollectStats(s.dbName)
+	if err != nil {
+		if s.failures == 0 {
+			log.Printf("could not collect server stats: %v", err)
+		}
+		s.failures++
+		return
+	}
+	sample := ServerStatsSample{TimeMillis: now.UnixNano() / int64(time.Millisecond), Stats: stats}
+	if took := now.Sub(s.prevTime); !s.prevTime.IsZero() && took > 0 {
+		sample.MetricRate = float64(metrics-s.prevMetrics) / took.Seconds()
+		sample.RowRate = float64(rows-s.prevRows) / took.Seconds()
+	}
+	s.samples = append(s.samples, sample)
+	s.prevTime, s.prevMetrics, s.prevRows = now, metrics, rows
+}
+
+// stopServerStats stops sampling the metrics of the server, taking a last
+// sample at the end of the load.
+func (l *CommonBenchmarkRunner) stopServerStats() {
+	s := l.serverStats
+	if s == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.sample(time.Now())
+}
+
+// serverStatsSamples returns the samples of the metrics of the server, if
+// they were collected.
+func (l *CommonBenchmarkRunner) serverStatsSamples() []ServerStatsSample {
+	if l.serverStats == nil {
+		return nil
+	}
+	return l.serverStats.samples
+}
+
+// serverStatsSummary prints how many samples of the metrics of the server
+// were collected.
+func (l *CommonBenchmarkRunner) serverStatsSummary() {
+	s := l.serverStats
+	if s == nil {
+		return
+	}
+	failures := ""
+	if s.failures > 0 {
+		failures = fmt.Sprintf(", %d could not be collected", s.failures)
+	}
+	printFn("%scollected %d samples of the server stats%s\n", l.ReportPrefix, len(s.samples), failures)
+}
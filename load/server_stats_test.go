@@ -0,0 +1,84 @@
+package load
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testStatsCreator struct {
+	testCreator
+	calls int
+	// failCall is the call which fails, starting at 1
+	failCall int
+}
+
+func (c *testStatsCreator) CollectStats(dbName string) (map[string]float64, error) {
+	c.calls++
+	if c.calls == c.failCall {
+		return nil, fmt.Errorf("stats error")
+	}
+	return map[string]float64{dbName + ".calls": float64(c.calls)}, nil
+}
+
+func TestServerStats(t *testing.T) {
+	r := &CommonBenchmarkRunner{}
+	r.DBName = "db"
+	r.DoLoad = true
+	r.ServerStats = true
+	r.ReportingPeriod = 10 * time.Millisecond
+
+	r.startServerStats(&testCreator{})
+	if r.serverStats != nil {
+		t.Fatalf("server stats should not be collected if the target does not collect them")
+	}
+
+	c := &testStatsCreator{failCall: 2}
+	r.startServerStats(c)
+	if r.serverStats == nil {
+		t.Fatalf("server stats should be collected")
+	}
+	atomic.AddUint64(&r.metricCnt, 100)
+	time.Sleep(35 * time.Millisecond)
+	r.stopServerStats()
+
+	samples := r.serverStatsSamples()
+	if got := len(samples); got != c.calls-1 || got < 3 {
+		t.Fatalf("incorrect number of samples: got %d with %d calls", got, c.calls)
+	}
+	if r.serverStats.failures != 1 {
+		t.Errorf("incorrect failures: got %d want 1", r.serverStats.failures)
+	}
+	if samples[0].MetricRate != 0 || samples[0].Stats["db.calls"] != 1 {
+		t.Errorf("incorrect first sample: %+v", samples[0])
+	}
+	var metrics float64
+	for i, s := range samples[1:] {
+		took := time.Duration(s.TimeMillis-samples[i].TimeMillis) * time.Millisecond
+		metrics += s.MetricRate * took.Seconds()
+		if s.Stats["db.calls"] <= samples[i].Stats["db.calls"] {
+			t.Errorf("samples out of order: %+v after %+v", s, samples[i])
+		}
+	}
+	if metrics < 90 || metrics > 110 {
+		t.Errorf("incorrect metric rates: %v metrics in total", metrics)
+	}
+}
+
+func TestServerStatsOff(t *testing.T) {
+	r := &CommonBenchmarkRunner{}
+	r.DoLoad = true
+	r.ReportingPeriod = time.Second
+	r.startServerStats(&testStatsCreator{})
+	if r.serverStats != nil || r.serverStatsSamples() != nil {
+		t.Errorf("server stats should not be collected when off")
+	}
+	r.ServerStats = true
+	r.DoLoad = false
+	r.startServerStats(&testStatsCreator{})
+	if r.serverStats != nil {
+		t.Errorf("server stats should not be collected without loading")
+	}
+	r.stopServerStats()
+}
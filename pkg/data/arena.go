@@ -0,0 +1,91 @@
+package data
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultArenaChunkSize is the size of the chunks of the arenas of the data
+// sources.
+const DefaultArenaChunkSize = 1 << 20
+
+// chunkPools holds a pool of chunks per chunk size, shared by the arenas of
+// that size.
+var chunkPools sync.Map
+
+// Arena allocates the bytes of loaded points from large chunks, so that a
+// data source does not allocate memory for every point. A chunk is reused once
+// all the points allocated from it were released, usually by the batch
+// holding them once it is processed (see targets.ReleasableBatch). A chunk
+// which is not released is garbage collected as usual.
+//
+// An Arena is used by a single goroutine, while its chunks can be released
+// from any.
+type Arena struct {
+	chunkSize int
+	pool      *sync.Pool
+	curr      *ArenaChunk
+	off       int
+}
+
+// ArenaChunk is a chunk of memory of an Arena. It counts the points
+// allocated from it which were not released yet.
+type ArenaChunk struct {
+	buf  []byte
+	refs int32
+	pool *sync.Pool
+}
+
+// NewArena returns an Arena allocating from chunks of chunkSize bytes.
+func NewArena(chunkSize int) *Arena {
+	pool, _ := chunkPools.LoadOrStore(chunkSize, &sync.Pool{})
+	return &Arena{chunkSize: chunkSize, pool: pool.(*sync.Pool)}
+}
+
+// Alloc returns n bytes and the chunk they were allocated from, which must be
+// released once they are not used anymore. More than a quarter of a chunk is
+// allocated on its own, with a chunk which is never reused.
+func (a *Arena) Alloc(n int) ([]byte, *ArenaChunk) {
+	if n > a.chunkSize/4 {
+		return make([]byte, n), &ArenaChunk{refs: 1}
+	}
+	if a.curr == nil || a.off+n > len(a.curr.buf) {
+		a.nextChunk()
+	}
+	b := a.curr.buf[a.off : a.off+n : a.off+n]
+	a.off += n
+	atomic.AddInt32(&a.curr.refs, 1)
+	return b, a.curr
+}
+
+// nextChunk releases the current chunk and takes a new one, which the arena
+// holds a reference to while it allocates from it.
+func (a *Arena) nextChunk() {
+	a.curr.Release()
+	c, ok := a.pool.Get().(*ArenaChunk)
+	if !ok {
+		c = &ArenaChunk{buf: make([]byte, a.chunkSize), pool: a.pool}
+	}
+	atomic.StoreInt32(&c.refs, 1)
+	a.curr = c
+	a.off = 0
+}
+
+// Close releases the current chunk of the arena, which must not be used
+// anymore.
+func (a *Arena) Close() {
+	a.curr.Release()
+	a.curr = nil
+}
+
+// Release releases the bytes of a point allocated from the chunk. The chunk
+// is reused once the bytes of all its points are released and the arena
+// moved on to another chunk. Releasing a nil chunk does nothing.
+func (c *ArenaChunk) Release() {
+	if c == nil {
+		return
+	}
+	if atomic.AddInt32(&c.refs, -1) == 0 && c.pool != nil {
+		c.pool.Put(c)
+	}
+}
@@ -0,0 +1,67 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestArenaAlloc(t *testing.T) {
+	a := NewArena(64)
+	b1, c1 := a.Alloc(10)
+	b2, c2 := a.Alloc(8)
+	if len(b1) != 10 || len(b2) != 8 || cap(b1) != 10 {
+		t.Fatalf("incorrect lengths: %d %d cap %d", len(b1), len(b2), cap(b1))
+	}
+	if c1 != c2 {
+		t.Errorf("small allocations should share a chunk")
+	}
+	for i := range b1 {
+		b1[i] = 1
+	}
+	if b2[0] != 0 {
+		t.Errorf("allocations overlap")
+	}
+	// past the end of the chunk
+	_, c3 := a.Alloc(16)
+	_, c4 := a.Alloc(16)
+	_, c5 := a.Alloc(16)
+	if c3 != c1 || c4 != c1 || c5 == c1 {
+		t.Errorf("incorrect chunks: a new chunk should start after 64 bytes")
+	}
+	// more than a quarter of a chunk
+	big, c6 := a.Alloc(17)
+	if len(big) != 17 || c6 == c5 || c6.pool != nil {
+		t.Errorf("large allocation should have its own chunk")
+	}
+	c6.Release()
+	var nilChunk *ArenaChunk
+	nilChunk.Release()
+}
+
+func TestArenaReuse(t *testing.T) {
+	a := NewArena(64)
+	var chunks []*ArenaChunk
+	for i := 0; i < 4; i++ {
+		_, c := a.Alloc(16)
+		chunks = append(chunks, c)
+	}
+	_, next := a.Alloc(16)
+	if chunks[3] != chunks[0] || next == chunks[0] {
+		t.Fatalf("incorrect chunks")
+	}
+	for _, c := range chunks[1:] {
+		c.Release()
+	}
+	c1 := chunks[0]
+	if got := c1.refs; got != 1 {
+		t.Errorf("incorrect references: got %d want 1", got)
+	}
+	c1.Release()
+	if got := c1.refs; got != 0 {
+		t.Errorf("chunk not released: got %d references", got)
+	}
+	next.Release()
+	a.Close()
+	if got := next.refs; got != 0 {
+		t.Errorf("closed arena holds its chunk: got %d references", got)
+	}
+}
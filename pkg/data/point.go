@@ -166,6 +166,10 @@ func (p *Point) ClearTagValue(key []byte) {
 // Instead of using interface{} as a return type, we get compile safety by using Point
 type LoadedPoint struct {
 	Data interface{}
+	// Chunk, if not nil, is the arena chunk the bytes of Data were allocated
+	// from. The batch the point is appended to releases it once it does not
+	// use them anymore
+	Chunk *ArenaChunk
 }
 
 // NewPoint creates a Point with the provided data as the internal representation
@@ -0,0 +1,113 @@
+package source
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	defaultReadSize = 4 << 20 // 4 MB
+)
+
+// OpenBufferedReader returns the buffered Reader of file fileName, or of STDIN
+// if fileName is empty. Files ending with .gz or .zst are decompressed as
+// they are read.
+func OpenBufferedReader(fileName string) (*bufio.Reader, error) {
+	br, _, err := OpenBufferedReaderAt(fileName, 0, 0)
+	return br, err
+}
+
+// OpenBufferedReaderAt returns the buffered Reader of file fileName as
+// OpenBufferedReader does, and the Closer of the file. If position is after
+// head, the Reader returns the first head bytes of the data, e.g. its headers,
+// and then the data from position on. Positions are in the decompressed data:
+// compressed files cannot seek, their data up to position is decompressed and
+// discarded.
+func OpenBufferedReaderAt(fileName string, head, position int64) (*bufio.Reader, io.Closer, error) {
+	skip := position > head
+	if len(fileName) == 0 {
+		if skip {
+			return nil, nil, fmt.Errorf("cannot seek in STDIN")
+		}
+		// Read from STDIN
+		return bufio.NewReaderSize(os.Stdin, defaultReadSize), ioutil.NopCloser(nil), nil
+	}
+	// Read from specified file
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot open file for read %s: %v", fileName, err)
+	}
+	var r io.Reader = file
+	var closer io.Closer = file
+	switch {
+	case strings.HasSuffix(fileName, ".gz"):
+		r, err = gzip.NewReader(bufio.NewReaderSize(file, defaultReadSize))
+	case strings.HasSuffix(fileName, ".zst"):
+		var dec *zstd.Decoder
+		dec, err = zstd.NewReader(bufio.NewReaderSize(file, defaultReadSize))
+		r = dec
+		closer = closerFunc(func() error {
+			dec.Close()
+			return file.Close()
+		})
+	}
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("cannot decompress file %s: %v", fileName, err)
+	}
+	switch {
+	case !skip:
+	case r == io.Reader(file):
+		if r, err = seekFile(file, head, position); err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("cannot seek in file %s: %v", fileName, err)
+		}
+	default:
+		r = io.MultiReader(io.LimitReader(r, head), &skipReader{r: r, skip: position - head})
+	}
+	return bufio.NewReaderSize(r, defaultReadSize), closer, nil
+}
+
+// seekFile returns the first head bytes of file followed by its bytes from
+// position on.
+func seekFile(file *os.File, head, position int64) (io.Reader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if position > info.Size() {
+		return nil, fmt.Errorf("position %d is after the end of the file", position)
+	}
+	return io.MultiReader(io.NewSectionReader(file, 0, head), io.NewSectionReader(file, position, math.MaxInt64-position)), nil
+}
+
+// skipReader discards the first skip bytes of r before its first read.
+type skipReader struct {
+	r    io.Reader
+	skip int64
+}
+
+func (s *skipReader) Read(p []byte) (int, error) {
+	if s.skip > 0 {
+		if n, err := io.CopyN(ioutil.Discard, s.r, s.skip); err != nil {
+			return 0, fmt.Errorf("data ends %d bytes before the position: %v", s.skip-n, err)
+		}
+		s.skip = 0
+	}
+	return s.r.Read(p)
+}
+
+// closerFunc is an io.Closer closing with itself.
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,92 @@
+package source
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const testData = "header\n\na\nb\nc\n"
+
+func writeCompressed(t *testing.T, name string) {
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	switch filepath.Ext(name) {
+	case ".gz":
+		w := gzip.NewWriter(f)
+		w.Write([]byte(testData))
+		err = w.Close()
+	case ".zst":
+		w, _ := zstd.NewWriter(f)
+		w.Write([]byte(testData))
+		err = w.Close()
+	default:
+		_, err = f.Write([]byte(testData))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenBufferedReaderAt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		desc     string
+		head     int64
+		position int64
+		want     string
+	}{
+		{desc: "start", want: testData},
+		{desc: "position in headers", head: 8, position: 8, want: testData},
+		{desc: "skip", head: 8, position: 12, want: "header\n\nc\n"},
+		{desc: "skip all", head: 8, position: 14, want: "header\n\n"},
+		{desc: "no headers", position: 10, want: "b\nc\n"},
+	}
+	for _, ext := range []string{"", ".gz", ".zst"} {
+		name := filepath.Join(dir, "data"+ext)
+		writeCompressed(t, name)
+		for _, c := range cases {
+			br, closer, err := OpenBufferedReaderAt(name, c.head, c.position)
+			if err != nil {
+				t.Errorf("%s%s: unexpected error: %v", c.desc, ext, err)
+				continue
+			}
+			got, err := ioutil.ReadAll(br)
+			if err != nil {
+				t.Errorf("%s%s: unexpected read error: %v", c.desc, ext, err)
+			}
+			if string(got) != c.want {
+				t.Errorf("%s%s: incorrect data: got %q want %q", c.desc, ext, got, c.want)
+			}
+			if err := closer.Close(); err != nil {
+				t.Errorf("%s%s: unexpected close error: %v", c.desc, ext, err)
+			}
+		}
+
+		// after the end of the data
+		br, closer, err := OpenBufferedReaderAt(name, 8, 20)
+		if err == nil {
+			_, err = ioutil.ReadAll(br)
+			closer.Close()
+		}
+		if err == nil {
+			t.Errorf("after the end%s: expected an error", ext)
+		}
+	}
+
+	if _, _, err := OpenBufferedReaderAt("", 0, 10); err == nil {
+		t.Errorf("STDIN: expected an error")
+	}
+}
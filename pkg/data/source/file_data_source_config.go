@@ -1,5 +1,67 @@
 package source
 
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
 type FileDataSourceConfig struct {
+	// Location is a file, a glob pattern of files, or a comma separated list
+	// of them. Empty means STDIN
 	Location string `yaml:"location"`
+	// Shard and Shards select every Shards-th file from the Shard-th one, so
+	// that several loaders each load a part of the files. Shards 0 means all
+	// the files
+	Shard  uint `yaml:"shard,omitempty"`
+	Shards uint `yaml:"shards,omitempty"`
+}
+
+// Files returns the names of the files at Location, with the glob patterns
+// expanded in order, or a single empty name for STDIN. Only the files of
+// Shard are returned if there are Shards.
+func (c *FileDataSourceConfig) Files() ([]string, error) {
+	if len(c.Location) == 0 {
+		if c.Shards > 1 {
+			return nil, fmt.Errorf("STDIN cannot be split in %d shards", c.Shards)
+		}
+		return []string{""}, nil
+	}
+	var files []string
+	for _, loc := range strings.Split(c.Location, ",") {
+		loc = strings.TrimSpace(loc)
+		if len(loc) == 0 {
+			continue
+		}
+		matches, err := filepath.Glob(loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid file pattern %s: %v", loc, err)
+		}
+		if len(matches) == 0 {
+			if !strings.ContainsAny(loc, "*?[\\") {
+				// not a pattern, opening it will tell why it is missing
+				files = append(files, loc)
+				continue
+			}
+			return nil, fmt.Errorf("no file matches %s", loc)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file in location %s", c.Location)
+	}
+	if c.Shards <= 1 {
+		return files, nil
+	}
+	if c.Shard >= c.Shards {
+		return nil, fmt.Errorf("invalid file shard %d of %d shards", c.Shard, c.Shards)
+	}
+	var shard []string
+	for i := c.Shard; i < uint(len(files)); i += c.Shards {
+		shard = append(shard, files[i])
+	}
+	if len(shard) == 0 {
+		return nil, fmt.Errorf("no file for shard %d of %d shards, location %s has %d files", c.Shard, c.Shards, c.Location, len(files))
+	}
+	return shard, nil
 }
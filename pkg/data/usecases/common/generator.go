@@ -40,6 +40,10 @@ type DataGeneratorConfig struct {
 	InterleavedGroupID    uint          `yaml:"interleaved-generation-group-id" mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups  uint          `yaml:"interleaved-generation-groups" mapstructure:"interleaved-generation-groups"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+
+	// SharedSimulator, if set, is returned instead of creating a new simulator
+	// from this config. It is used to feed several targets from one simulation.
+	SharedSimulator Simulator `yaml:"-" mapstructure:"-"`
 }
 
 // Validate checks that the values of the DataGeneratorConfig are reasonable.
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// NewInterleavedSimulator returns a Simulator of the points of sim in the
+// interleaved generation group groupID of numGroups, i.e. every numGroups-th
+// point it writes from the groupID-th one, as tsbs_generate_data writes them
+// with interleaved-generation-group-id and interleaved-generation-groups.
+// The simulators of all the groups together write all the points of sim.
+func NewInterleavedSimulator(sim Simulator, groupID, numGroups uint) Simulator {
+	return &interleavedSimulator{Simulator: sim, groupID: groupID, numGroups: numGroups}
+}
+
+type interleavedSimulator struct {
+	Simulator
+	groupID   uint
+	numGroups uint
+	// currGroupID is the group of the next point sim writes
+	currGroupID uint
+}
+
+// Next writes the next point of sim into p, and returns false if sim does
+// not write it or if it belongs to another group.
+func (s *interleavedSimulator) Next(p *data.Point) bool {
+	if !s.Simulator.Next(p) {
+		return false
+	}
+	group := s.currGroupID
+	s.currGroupID = (s.currGroupID + 1) % s.numGroups
+	if group != s.groupID {
+		p.Reset()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestInterleavedSimulator(t *testing.T) {
+	const numGroups = 3
+	want := 0
+	ref := testBaseConf.NewSimulator(time.Second, 0)
+	for !ref.Finished() {
+		if ref.Next(data.NewPoint()) {
+			want++
+		}
+	}
+
+	total := 0
+	for group := uint(0); group < numGroups; group++ {
+		sim := NewInterleavedSimulator(testBaseConf.NewSimulator(time.Second, 0), group, numGroups)
+		count := 0
+		p := data.NewPoint()
+		for !sim.Finished() {
+			if sim.Next(p) {
+				count++
+			}
+			p.Reset()
+		}
+		if min, max := want/numGroups, (want+numGroups-1)/numGroups; count < min || count > max {
+			t.Errorf("group %d: incorrect number of points: got %d want %d to %d", group, count, min, max)
+		}
+		total += count
+	}
+	if total != want {
+		t.Errorf("incorrect number of points of all groups: got %d want %d", total, want)
+	}
+}
@@ -1,7 +1,10 @@
 package common
 
 import (
+	"bufio"
+	"io"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/timescale/tsbs/pkg/data"
@@ -70,6 +73,39 @@ type GeneratedDataHeaders struct {
 	FieldKeys map[string][]string
 }
 
+// Write writes the headers of generated data files: a line with the tag keys
+// and types, a line per measurement with its field keys, and an empty line.
+func (h *GeneratedDataHeaders) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("tags")
+
+	types := h.TagTypes
+	for i, key := range h.TagKeys {
+		bw.WriteString(",")
+		bw.WriteString(key)
+		bw.WriteString(" ")
+		bw.WriteString(types[i])
+	}
+	bw.WriteString("\n")
+	// sort the keys so the header is deterministic
+	keys := make([]string, 0)
+	fields := h.FieldKeys
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, measurementName := range keys {
+		bw.WriteString(measurementName)
+		for _, field := range fields[measurementName] {
+			bw.WriteString(",")
+			bw.WriteString(field)
+		}
+		bw.WriteString("\n")
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
 // Simulator simulates a use case.
 type Simulator interface {
 	Finished() bool
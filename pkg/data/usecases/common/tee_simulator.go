@@ -0,0 +1,96 @@
+package common
+
+import (
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// NewTeeSimulators runs sim once and returns n Simulators that each replay
+// every point sim writes, in the same order. This lets several consumers load
+// identical data from a single simulation (the simulators use the global PRNG,
+// so running one per consumer concurrently would not be deterministic).
+//
+// Each returned Simulator buffers up to bufferSize points; the slowest consumer
+// determines how fast the underlying simulation advances. Every returned
+// Simulator must be drained, otherwise the others stall once their buffer is full.
+func NewTeeSimulators(sim Simulator, n int, bufferSize int) []Simulator {
+	headers := sim.Headers()
+	chans := make([]chan *data.Point, n)
+	tees := make([]Simulator, n)
+	for i := range chans {
+		chans[i] = make(chan *data.Point, bufferSize)
+		tees[i] = &teeSimulator{headers: headers, points: chans[i]}
+	}
+
+	go func() {
+		for !sim.Finished() {
+			p := data.NewPoint()
+			if !sim.Next(p) {
+				continue
+			}
+			// generators reuse their timestamp between ticks
+			if p.Timestamp() != nil {
+				ts := *p.Timestamp()
+				p.SetTimestamp(&ts)
+			}
+			for _, c := range chans {
+				c <- p
+			}
+		}
+		for _, c := range chans {
+			close(c)
+		}
+	}()
+
+	return tees
+}
+
+// teeSimulator is one of the consumers created by NewTeeSimulators. The points
+// it receives are shared with the other consumers and never modified, they are
+// copied into the Point passed to Next.
+type teeSimulator struct {
+	headers  *GeneratedDataHeaders
+	points   chan *data.Point
+	finished bool
+}
+
+// Finished tells whether all points of the underlying simulation have been consumed.
+func (s *teeSimulator) Finished() bool {
+	return s.finished
+}
+
+// Next copies the next simulated point into p. It returns false once the
+// underlying simulation has finished.
+func (s *teeSimulator) Next(p *data.Point) bool {
+	from, ok := <-s.points
+	if !ok {
+		s.finished = true
+		return false
+	}
+	p.SetMeasurementName(from.MeasurementName())
+	tagValues := from.TagValues()
+	for i, k := range from.TagKeys() {
+		p.AppendTag(k, tagValues[i])
+	}
+	fieldValues := from.FieldValues()
+	for i, k := range from.FieldKeys() {
+		p.AppendField(k, fieldValues[i])
+	}
+	p.SetTimestamp(from.Timestamp())
+	return true
+}
+
+func (s *teeSimulator) Fields() map[string][]string {
+	return s.headers.FieldKeys
+}
+
+func (s *teeSimulator) TagKeys() []string {
+	return s.headers.TagKeys
+}
+
+func (s *teeSimulator) TagTypes() []string {
+	return s.headers.TagTypes
+}
+
+func (s *teeSimulator) Headers() *GeneratedDataHeaders {
+	return s.headers
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestTeeSimulators(t *testing.T) {
+	const consumers = 3
+	sim := testBaseConf.NewSimulator(time.Second, 0)
+	want := 0
+	ref := testBaseConf.NewSimulator(time.Second, 0)
+	for !ref.Finished() {
+		if ref.Next(data.NewPoint()) {
+			want++
+		}
+	}
+
+	tees := NewTeeSimulators(sim, consumers, 2)
+	counts := make([]int, consumers)
+	wg := sync.WaitGroup{}
+	for i, tee := range tees {
+		wg.Add(1)
+		go func(i int, s Simulator) {
+			defer wg.Done()
+			p := data.NewPoint()
+			for !s.Finished() {
+				if !s.Next(p) {
+					continue
+				}
+				if !bytes.Equal(p.MeasurementName(), dummyMeasurementName) {
+					t.Errorf("consumer %d: incorrect measurement: got %s", i, p.MeasurementName())
+				}
+				if got := len(p.TagKeys()); got != 1 {
+					t.Errorf("consumer %d: incorrect number of tags: got %d want 1", i, got)
+				}
+				counts[i]++
+				p.Reset()
+			}
+		}(i, tee)
+	}
+	wg.Wait()
+
+	for i, got := range counts {
+		if got != want {
+			t.Errorf("consumer %d: incorrect number of points: got %d want %d", i, got, want)
+		}
+	}
+	if got := tees[0].Headers(); got == nil || len(got.TagKeys) != 1 {
+		t.Errorf("incorrect headers: %v", got)
+	}
+}
@@ -3,6 +3,7 @@ package usecases
 import (
 	"fmt"
 	"math"
+	"math/rand"
 
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
@@ -13,6 +14,32 @@ import (
 
 const errCannotParseTimeFmt = "cannot parse time from string '%s': %v"
 
+// NewSimulator returns the simulator of the data generator config dgc, after
+// seeding the random generator with the seed of dgc. The SharedSimulator of
+// dgc is returned if it is set.
+func NewSimulator(dgc *common.DataGeneratorConfig) (common.Simulator, error) {
+	if dgc == nil {
+		return nil, fmt.Errorf("no data generator config")
+	}
+	if dgc.SharedSimulator != nil {
+		return dgc.SharedSimulator, nil
+	}
+	if err := dgc.Validate(); err != nil {
+		return nil, err
+	}
+	rand.Seed(dgc.Seed)
+	scfg, err := GetSimulatorConfig(dgc)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := scfg.NewSimulator(dgc.LogInterval, dgc.Limit)
+	if dgc.InterleavedNumGroups > 1 {
+		sim = common.NewInterleavedSimulator(sim, dgc.InterleavedGroupID, dgc.InterleavedNumGroups)
+	}
+	return sim, nil
+}
+
 func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig, error) {
 	var ret common.SimulatorConfig
 	var err error
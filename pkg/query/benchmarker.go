@@ -8,10 +8,12 @@ import (
 	"log"
 	"os"
 	"runtime/pprof"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/profiling"
 	"golang.org/x/time/rate"
 )
 
@@ -38,6 +40,10 @@ type BenchmarkRunnerConfig struct {
 	PrintInterval    uint64 `mapstructure:"print-interval"`
 	PrewarmQueries   bool   `mapstructure:"prewarm-queries"`
 	ResultsFile      string `mapstructure:"results-file"`
+	ProfileDir       string `mapstructure:"profile-dir"`
+	Profiles         string `mapstructure:"profiles"`
+	ProfileWindows   string `mapstructure:"profile-windows"`
+	ClientStats      bool   `mapstructure:"client-stats"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -55,6 +61,10 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Int("debug", 0, "Whether to print debug messages.")
 	fs.String("file", "", "File name to read queries from")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.String("profile-dir", "", "Directory to write the profiles of the query runner to, default '' => no profiles")
+	fs.String("profiles", profiling.DefaultKinds, "Profiles to take: cpu, heap, mutex, block and trace")
+	fs.String("profile-windows", "", "Windows of the run to profile as START:DURATION, e.g. '30s:1m,10m:1m', default '' => the whole run")
+	fs.Bool("client-stats", false, "Whether to print the CPU, RSS, goroutines and GC pauses of the query runner with the timing stats")
 }
 
 // BenchmarkRunner contains the common components for running a query benchmarking
@@ -65,6 +75,10 @@ type BenchmarkRunner struct {
 	sp      statProcessor
 	scanner *scanner
 	ch      chan Query
+	// profiler takes the profiles of the run and sampler samples the
+	// resources used by the runner
+	profiler *profiling.Profiler
+	sampler  *profiling.Sampler
 }
 
 // NewBenchmarkRunner creates a new instance of BenchmarkRunner which is
@@ -79,6 +93,16 @@ func NewBenchmarkRunner(config BenchmarkRunnerConfig) *BenchmarkRunner {
 		burnIn:           runner.BurnIn,
 		hdrLatenciesFile: runner.HDRLatenciesFile,
 	}
+	if runner.ClientStats {
+		runner.sampler = profiling.NewSampler()
+		spArgs.sampler = runner.sampler
+	}
+
+	var err error
+	runner.profiler, err = profiling.NewProfiler(runner.ProfileDir, runner.Profiles, runner.ProfileWindows)
+	if err != nil {
+		panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+	}
 
 	runner.sp = newStatProcessor(spArgs)
 	return runner
@@ -163,6 +187,7 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	// Read in jobs, closing the job channel when done:
 	// Wall clock start time
 	wallStart := time.Now()
+	b.profiler.Start(wallStart)
 	b.scanner.setReader(b.GetBufferedReader()).scan(queryPool, b.ch)
 	close(b.ch)
 
@@ -173,10 +198,18 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	// Wall clock end time
 	wallEnd := time.Now()
 	wallTook := wallEnd.Sub(wallStart)
+	b.profiler.Stop()
 	_, err := fmt.Printf("wall clock time: %fsec\n", float64(wallTook.Nanoseconds())/1e9)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if b.sampler != nil {
+		b.sampler.Sample()
+		_, _ = fmt.Println(b.sampler.Summary())
+	}
+	if profiles := b.profiler.Files(); len(profiles) > 0 {
+		_, _ = fmt.Printf("wrote profiles %s\n", strings.Join(profiles, ", "))
+	}
 
 	// (Optional) create a memory profile:
 	if len(b.MemProfile) > 0 {
@@ -203,6 +236,12 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 		DurationMillis:      took.Milliseconds(),
 		Totals:              b.sp.GetTotalsMap(),
 	}
+	if b.sampler != nil {
+		testResult.Totals["client"] = b.sampler.Totals()
+	}
+	if profiles := b.profiler.Files(); len(profiles) > 0 {
+		testResult.Totals["profiles"] = profiles
+	}
 
 	_, _ = fmt.Printf("Saving results json file to %s\n", b.BenchmarkRunnerConfig.ResultsFile)
 	file, err := json.MarshalIndent(testResult, "", " ")
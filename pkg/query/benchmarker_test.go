@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 type testProcessor struct {
@@ -208,6 +209,33 @@ func TestBenchmarkRunnerRunPanicOnBurnInBiggerThanLimit(t *testing.T) {
 	t.Errorf("the code did not panic")
 }
 
+func TestNewBenchmarkRunnerProfiling(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b := NewBenchmarkRunner(BenchmarkRunnerConfig{ProfileDir: dir, Profiles: "heap", ClientStats: true})
+	if b.profiler == nil || b.sampler == nil {
+		t.Fatalf("profiler and sampler should be set")
+	}
+	if b.sp.getArgs().sampler != b.sampler {
+		t.Errorf("stat processor should print the samples of the runner")
+	}
+	b.profiler.Start(time.Now())
+	b.profiler.Stop()
+	if got := b.profiler.Files(); len(got) != 1 {
+		t.Errorf("incorrect profiles: got %v", got)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("the code did not panic on invalid profile windows")
+		}
+	}()
+	NewBenchmarkRunner(BenchmarkRunnerConfig{ProfileDir: dir, ProfileWindows: "1m"})
+}
+
 func TestBenchmarkRunnerRunNoQueries(t *testing.T) {
 	// SETUP
 	// ..empty query file
@@ -8,7 +8,13 @@ import (
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
-const ErrEmptyQueryType = "query type cannot be empty"
+const (
+	ErrEmptyQueryType          = "query type cannot be empty"
+	ErrQueryTypeAndWorkload    = "only one of query-type and workload-file can be set"
+	ErrMongoTimeseriesNotNaive = "mongo-timeseries-collection requires mongo-use-naive"
+	ErrInfluxQueryLanguage     = "influx-query-language must be one of: influxql, flux"
+	ErrTimescaleCaggsNoBucket  = "timescale-use-caggs requires timescale-use-time-bucket"
+)
 
 // QueryGeneratorConfig is the GeneratorConfig that should be used with a
 // QueryGenerator. It includes all the fields from a BaseConfig, as well as
@@ -21,6 +27,21 @@ type QueryGeneratorConfig struct {
 	InterleavedGroupID   uint   `mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups uint   `mapstructure:"interleaved-generation-groups"`
 
+	// WorkloadFile is a YAML file with a weighted mix of query types, used
+	// instead of QueryType. WorkloadCountsFile is where the number of generated
+	// queries per type and label is written.
+	WorkloadFile       string `mapstructure:"workload-file"`
+	WorkloadCountsFile string `mapstructure:"workload-counts-file"`
+
+	// Access pattern of the generated queries, see AccessPattern in
+	// cmd/tsbs_generate_queries/uses/common.
+	WindowDistribution string  `mapstructure:"window-distribution"`
+	WindowSkew         float64 `mapstructure:"window-skew"`
+	HostDistribution   string  `mapstructure:"host-distribution"`
+	HostSkew           float64 `mapstructure:"host-skew"`
+	HotSetFraction     float64 `mapstructure:"hot-set-fraction"`
+	HotSetProbability  float64 `mapstructure:"hot-set-probability"`
+
 	// OutputFormat controls the serialization format for generated queries.
 	// Supported values: "gob" (default, binary) and "jsonl" (JSON Lines, one JSON object per query).
 	OutputFormat string `mapstructure:"output-format"`
@@ -29,11 +50,15 @@ type QueryGeneratorConfig struct {
 	TimescaleUseJSON       bool `mapstructure:"timescale-use-json"`
 	TimescaleUseTags       bool `mapstructure:"timescale-use-tags"`
 	TimescaleUseTimeBucket bool `mapstructure:"timescale-use-time-bucket"`
+	TimescaleUseCaggs      bool `mapstructure:"timescale-use-caggs"`
 
 	ClickhouseUseTags bool `mapstructure:"clickhouse-use-tags"`
 
-	MongoUseNaive bool   `mapstructure:"mongo-use-naive"`
-	DbName        string `mapstructure:"db-name"`
+	InfluxQueryLanguage string `mapstructure:"influx-query-language"`
+
+	MongoUseNaive      bool   `mapstructure:"mongo-use-naive"`
+	MongoUseTimeseries bool   `mapstructure:"mongo-timeseries-collection"`
+	DbName             string `mapstructure:"db-name"`
 }
 
 // Validate checks that the values of the QueryGeneratorConfig are reasonable.
@@ -43,9 +68,12 @@ func (c *QueryGeneratorConfig) Validate() error {
 		return err
 	}
 
-	if c.QueryType == "" {
+	if c.QueryType == "" && c.WorkloadFile == "" {
 		return fmt.Errorf(ErrEmptyQueryType)
 	}
+	if c.QueryType != "" && c.WorkloadFile != "" {
+		return fmt.Errorf(ErrQueryTypeAndWorkload)
+	}
 
 	if c.OutputFormat == "" {
 		c.OutputFormat = "gob"
@@ -54,6 +82,21 @@ func (c *QueryGeneratorConfig) Validate() error {
 		return fmt.Errorf("invalid output format '%s': must be 'gob' or 'jsonl'", c.OutputFormat)
 	}
 
+	if c.MongoUseTimeseries && !c.MongoUseNaive {
+		return fmt.Errorf(ErrMongoTimeseriesNotNaive)
+	}
+
+	if c.TimescaleUseCaggs && !c.TimescaleUseTimeBucket {
+		return fmt.Errorf(ErrTimescaleCaggsNoBucket)
+	}
+
+	if c.InfluxQueryLanguage == "" {
+		c.InfluxQueryLanguage = "influxql"
+	}
+	if c.InfluxQueryLanguage != "influxql" && c.InfluxQueryLanguage != "flux" {
+		return fmt.Errorf(ErrInfluxQueryLanguage)
+	}
+
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 	return err
 }
@@ -62,19 +105,31 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	c.BaseConfig.AddToFlagSet(fs)
 	fs.Uint64("queries", 1000, "Number of queries to generate.")
 	fs.String("query-type", "", "Query type. (Choices are in the use case matrix.)")
+	fs.String("workload-file", "", "YAML file with a weighted mix of query types to generate instead of a single query-type.")
+	fs.String("workload-counts-file", "", "File to write the number of generated queries per type and label to when using workload-file. Defaults to <file>.counts.json")
 
 	fs.Uint("interleaved-generation-group-id", 0,
 		"Group (0-indexed) to perform round-robin serialization within. Use this to scale up data generation to multiple processes.")
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 
+	fs.String("window-distribution", "uniform", "Placement of query time windows. Choices: uniform, recent (biased towards the end of the dataset), zipf (Zipf distributed window slots counted back from the end)")
+	fs.Float64("window-skew", 1.0, "Skew of the recent and zipf window distributions; higher values query more recent data")
+	fs.String("host-distribution", "uniform", "Selection of queried hosts/devices. Choices: uniform, zipf (host_0 being the hottest), hot-set")
+	fs.Float64("host-skew", 1.0, "Exponent of the zipf host distribution")
+	fs.Float64("hot-set-fraction", 0.1, "Share of hosts in the hot set for the hot-set host distribution")
+	fs.Float64("hot-set-probability", 0.9, "Probability of picking a host from the hot set for the hot-set host distribution")
+
 	fs.String("output-format", "gob", "Output serialization format for generated queries. Choices: gob, jsonl")
 
 	fs.Bool("clickhouse-use-tags", true, "ClickHouse only: Use separate tags table when querying")
+	fs.String("influx-query-language", "influxql", "InfluxDB only: Query language of the generated queries. Choices: influxql, flux (InfluxDB 2.x)")
 	fs.Bool("mongo-use-naive", true, "MongoDB only: Generate queries for the 'naive' data storage format for Mongo")
+	fs.Bool("mongo-timeseries-collection", false, "MongoDB only: Generate queries for a time-series collection (loaded with timeseries-collection=true). Requires mongo-use-naive")
 	fs.Bool("timescale-use-json", false, "TimescaleDB only: Use separate JSON tags table when querying")
 	fs.Bool("timescale-use-tags", true, "TimescaleDB only: Use separate tags table when querying")
 	fs.Bool("timescale-use-time-bucket", true, "TimescaleDB only: Use time bucket. Set to false to test on native PostgreSQL")
+	fs.Bool("timescale-use-caggs", false, "TimescaleDB only: Query the cpu_1m and cpu_1h continuous aggregates (loaded with cagg-intervals=1m,1h) for the devops rollups")
 
-	fs.String("db-name", "benchmark", "Specify database name. Timestream requires it in order to generate the queries")
+	fs.String("db-name", "benchmark", "Specify database name. Timestream requires it in order to generate the queries, Flux queries read from the bucket of that name")
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/cratedb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/influx"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/mongo"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/prometheus"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/questdb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/siridb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/timescaledb"
@@ -22,19 +23,27 @@ func InitQueryFactories(config *config.QueryGeneratorConfig) map[string]interfac
 	factories[constants.FormatClickhouse] = &clickhouse.BaseGenerator{
 		UseTags: config.ClickhouseUseTags,
 	}
+	// the data of the native format is loaded into the same tables
+	factories[constants.FormatClickhouseNative] = factories[constants.FormatClickhouse]
 	factories[constants.FormatCrateDB] = &cratedb.BaseGenerator{}
-	factories[constants.FormatInflux] = &influx.BaseGenerator{}
+	factories[constants.FormatInflux] = &influx.BaseGenerator{
+		UseFlux: config.InfluxQueryLanguage == "flux",
+		Bucket:  config.DbName,
+	}
 	factories[constants.FormatTimescaleDB] = &timescaledb.BaseGenerator{
 		UseJSON:       config.TimescaleUseJSON,
 		UseTags:       config.TimescaleUseTags,
 		UseTimeBucket: config.TimescaleUseTimeBucket,
+		UseCaggs:      config.TimescaleUseCaggs,
 	}
 	factories[constants.FormatSiriDB] = &siridb.BaseGenerator{}
 	factories[constants.FormatMongo] = &mongo.BaseGenerator{
-		UseNaive: config.MongoUseNaive,
+		UseNaive:      config.MongoUseNaive,
+		UseTimeseries: config.MongoUseTimeseries,
 	}
 	factories[constants.FormatAkumuli] = &akumuli.BaseGenerator{}
 	factories[constants.FormatVictoriaMetrics] = &victoriametrics.BaseGenerator{}
+	factories[constants.FormatPrometheus] = &prometheus.BaseGenerator{}
 	factories[constants.FormatTimestream] = &timestream.BaseGenerator{
 		DBName: config.DbName,
 	}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/internal/profiling"
 	"io/ioutil"
 	"log"
 	"os"
@@ -25,11 +26,12 @@ type statProcessor interface {
 }
 
 type statProcessorArgs struct {
-	prewarmQueries   bool    // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
-	limit            *uint64 // limit is the number of statistics to analyze before stopping
-	burnIn           uint64  // burnIn is the number of statistics to ignore before analyzing
-	printInterval    uint64  // printInterval is how often print intermediate stats (number of queries)
-	hdrLatenciesFile string  // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	prewarmQueries   bool               // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
+	limit            *uint64            // limit is the number of statistics to analyze before stopping
+	burnIn           uint64             // burnIn is the number of statistics to ignore before analyzing
+	printInterval    uint64             // printInterval is how often print intermediate stats (number of queries)
+	hdrLatenciesFile string             // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	sampler          *profiling.Sampler // sampler samples the resources used by the runner at every print, if not nil
 
 }
 
@@ -152,6 +154,12 @@ func (sp *defaultStatProcessor) process(workers uint) {
 			if err != nil {
 				log.Fatal(err)
 			}
+			if sp.args.sampler != nil {
+				_, err = fmt.Fprintf(os.Stderr, "Client: %s\n", sp.args.sampler.Sample())
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
 			err = writeStatGroupMap(os.Stderr, sp.statMapping)
 			if err != nil {
 				log.Fatal(err)
@@ -1,27 +1,57 @@
 package akumuli
 
 import (
-	"github.com/timescale/tsbs/load"
-	"github.com/timescale/tsbs/pkg/targets"
+	"bufio"
+	"bytes"
 	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 )
 
-func NewBenchmark(loadFileName, endpoint string, bufPool *sync.Pool) targets.Benchmark {
-	return &benchmark{
-		loadFileName: loadFileName,
-		endpoint:     endpoint,
-		bufPool:      bufPool,
+// SpecificConfig is the configuration of the Akumuli loader.
+type SpecificConfig struct {
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
 	}
+	return &conf, nil
+}
+
+// NewBenchmark returns the Benchmark writing to the Akumuli endpoint in conf
+// the points read from a file or generated by the simulator.
+func NewBenchmark(conf *SpecificConfig, dsConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	ds, err := common.NewDataSource(dsConfig, NewAkumuliSerializer(), false, func(br *bufio.Reader) (targets.DataSource, error) {
+		return &fileDataSource{reader: br}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &benchmark{
+		ds:       ds,
+		endpoint: conf.Endpoint,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
 }
 
 type benchmark struct {
-	loadFileName string
-	endpoint     string
-	bufPool      *sync.Pool
+	ds       targets.DataSource
+	endpoint string
+	bufPool  *sync.Pool
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{reader: load.GetBufferedReader(b.loadFileName)}
+	return b.ds
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
@@ -39,3 +69,9 @@ func (b *benchmark) GetProcessor() targets.Processor {
 func (b *benchmark) GetDBCreator() targets.DBCreator {
 	return &dbCreator{}
 }
+
+// HashWorkers returns true, the series of a host must be written by the same
+// worker.
+func (b *benchmark) HashWorkers() bool {
+	return true
+}
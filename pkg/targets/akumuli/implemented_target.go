@@ -5,11 +5,12 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &akumuliTarget{}
 }
 
@@ -25,9 +26,25 @@ func (t *akumuliTarget) TargetName() string {
 }
 
 func (t *akumuliTarget) Serializer() serialize.PointSerializer {
-	return &Serializer{}
+	return NewAkumuliSerializer()
 }
 
-func (t *akumuliTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *akumuliTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(conf, dataSourceConfig)
+}
+
+func (t *akumuliTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:8181", "Comma-separated list of Akumuli API endpoints.")
+}
+
+func (t *akumuliTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
@@ -0,0 +1,63 @@
+package akumuli
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the Akumuli query runner.
+type QuerySpecificConfig struct {
+	URLs []string `yaml:"urls" mapstructure:"urls"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the Akumuli API endpoints in conf, round-robin over the workers.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if len(conf.URLs) == 0 {
+		return nil, fmt.Errorf("missing `urls` flag")
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.HTTPPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{urls: conf.URLs, runner: runner}
+		},
+	}, nil
+}
+
+type queryProcessor struct {
+	urls   []string
+	runner *query.BenchmarkRunner
+	w      *HTTPClient
+	opts   *HTTPClientDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.opts = &HTTPClientDoOptions{
+		Debug:          p.runner.DebugLevel(),
+		PrintResponses: p.runner.DoPrintResponses(),
+	}
+	url := p.urls[workerNumber%len(p.urls)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
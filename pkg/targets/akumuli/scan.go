@@ -14,6 +14,8 @@ import (
 
 type fileDataSource struct {
 	reader *bufio.Reader
+	// position is the number of bytes read
+	position int64
 }
 
 func (d *fileDataSource) NextItem() data.LoadedPoint {
@@ -23,7 +25,8 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	}
 	nbytes := binary.LittleEndian.Uint16(hdr[4:6])
 	body := make([]byte, nbytes)
-	_, err = io.ReadFull(d.reader, body)
+	n, err := io.ReadFull(d.reader, body)
+	d.position += int64(n)
 	if err == io.EOF {
 		return data.LoadedPoint{}
 	}
@@ -33,6 +36,9 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 // Cassandra doesn't serialize headers, no need to read them
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders { return nil }
 
+// Position returns the number of bytes of the data read.
+func (d *fileDataSource) Position() int64 { return d.position }
+
 type pointIndexer struct {
 	nchan uint
 }
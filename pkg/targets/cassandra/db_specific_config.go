@@ -9,7 +9,7 @@ type SpecificConfig struct {
 	Hosts             string        `yaml:"hosts" mapstructure:"hosts"`
 	ReplicationFactor int           `yaml:"replication-factor" mapstructure:"replication-factor"`
 	ConsistencyLevel  string        `yaml:"consistency" mapstructure:"consistency"`
-	WriteTimeout      time.Duration `yaml:"write-timeout" mapstructureL:"write-timeout"`
+	WriteTimeout      time.Duration `yaml:"write-timeout" mapstructure:"write-timeout"`
 }
 
 func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
@@ -19,3 +19,20 @@ func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
 	}
 	return &conf, nil
 }
+
+// QuerySpecificConfig is the configuration of the Cassandra query runner.
+type QuerySpecificConfig struct {
+	Hosts                  []string      `yaml:"hosts" mapstructure:"hosts"`
+	AggregationPlan        string        `yaml:"aggregation-plan" mapstructure:"aggregation-plan"`
+	ReadTimeout            time.Duration `yaml:"read-timeout" mapstructure:"read-timeout"`
+	ClientSideIndexTimeout time.Duration `yaml:"client-side-index-timeout" mapstructure:"client-side-index-timeout"`
+	SubQueryParallelism    int           `yaml:"sub-query-parallelism" mapstructure:"sub-query-parallelism"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
@@ -5,12 +5,13 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"time"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &cassandraTarget{}
 }
 
@@ -32,6 +33,26 @@ func (t *cassandraTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *cassandraTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *cassandraTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(conf, dataSourceConfig)
+}
+
+func (t *cassandraTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"hosts", "localhost:9042", "Comma separated list of Cassandra hostname and port combinations.")
+	flagSet.String(flagPrefix+"aggregation-plan", "", "Aggregation plan (choices: server, client)")
+	flagSet.Duration(flagPrefix+"read-timeout", 1*time.Second, "Maximum request timeout.")
+	flagSet.Duration(flagPrefix+"client-side-index-timeout", 10*time.Second, "Maximum client-side index timeout (only used at initialization).")
+	flagSet.Int(flagPrefix+"sub-query-parallelism", 8, "Maximum number of CQL sub-queries of a query in flight at once.")
+}
+
+func (t *cassandraTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
@@ -1,4 +1,4 @@
-package main
+package cassandra
 
 import (
 	"fmt"
@@ -34,15 +34,16 @@ func NewHLQueryExecutor(session *gocql.Session, csi *ClientSideIndex, debug int)
 // HLQueryExecutorDoOptions contains options used by HLQueryExecutor.
 type HLQueryExecutorDoOptions struct {
 	AggregationPlan      int
-	SubQueryParallelism  int // unused
+	SubQueryParallelism  int
 	Debug                int
 	PrettyPrintResponses bool
 }
 
 // Do takes a high-level query, constructs a query plan using the client-side
 // index contained within the query executor, executes that query plan, then
-// aggregates the results.
-func (qe *HLQueryExecutor) Do(q *HLQuery, opts HLQueryExecutorDoOptions) (qpLagMs, requestLagMs float64, err error) {
+// aggregates the results. The CQLQueries of the plan are run with at most
+// opts.SubQueryParallelism of them in flight.
+func (qe *HLQueryExecutor) Do(q *HLQuery, opts HLQueryExecutorDoOptions) (qpLagMs, requestLagMs float64, planStats *QueryPlanStats, err error) {
 	if opts.Debug >= 1 {
 		fmt.Printf("[hlqe] Do: %s\n", q)
 	}
@@ -81,12 +82,16 @@ func (qe *HLQueryExecutor) Do(q *HLQuery, opts HLQueryExecutorDoOptions) (qpLagM
 	// execute the query plan:
 	var results []CQLResult
 	execStart := time.Now()
-	results, err = qp.Execute(qe.session)
+	results, planStats, err = qp.Execute(qe.session, opts.SubQueryParallelism)
 	requestLagMs = float64(time.Now().Sub(execStart).Nanoseconds()) / 1e6
 	if err != nil {
 		return
 	}
 
+	if opts.Debug >= 1 {
+		fmt.Printf("[hlqe] %d CQL queries took %fms, merging their results %fms\n", planStats.SubQueries, planStats.FanOutMs, planStats.MergeMs)
+	}
+
 	// optionally, print reponses for query validation:
 	if opts.PrettyPrintResponses {
 		for _, r := range results {
@@ -1,4 +1,4 @@
-package main
+package cassandra
 
 import (
 	"fmt"
@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -14,10 +15,79 @@ import (
 
 // A QueryPlan is a strategy used to fulfill an HLQuery.
 type QueryPlan interface {
-	Execute(*gocql.Session) ([]CQLResult, error)
+	// Execute runs the CQLQueries of the plan on the session, with at most
+	// parallelism of them in flight, and merges their results.
+	Execute(session *gocql.Session, parallelism int) ([]CQLResult, *QueryPlanStats, error)
 	DebugQueries(int)
 }
 
+// QueryPlanStats breaks down the execution of a QueryPlan.
+type QueryPlanStats struct {
+	// SubQueries is the number of CQLQueries sent to the server.
+	SubQueries int
+	// FanOutMs is the time spent waiting for the CQLQueries.
+	FanOutMs float64
+	// MergeMs is the time spent merging their results on the client.
+	MergeMs float64
+}
+
+// runBounded calls fn for every index in [0, n) with at most parallelism
+// calls in flight. It returns the error of the lowest failing index, once
+// all calls are done.
+func runBounded(n, parallelism int, fn func(i int) error) error {
+	if parallelism > n {
+		parallelism = n
+	}
+	if parallelism <= 1 {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, n)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(parallelism)
+	for w := 0; w < parallelism; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeCQLQueries runs queries on session with at most parallelism of them
+// in flight, handing the iterator of the i-th query to scan. Calls to scan
+// are concurrent, so scan must only touch state owned by query i.
+func executeCQLQueries(session *gocql.Session, queries []CQLQuery, parallelism int, scan func(i int, iter *gocql.Iter)) error {
+	return runBounded(len(queries), parallelism, func(i int) error {
+		iter := session.Query(queries[i].PreparableQueryString, queries[i].Args...).Iter()
+		scan(i, iter)
+		return iter.Close()
+	})
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t).Nanoseconds()) / 1e6
+}
+
 // A QueryPlanWithServerAggregation fulfills an HLQuery by performing
 // aggregation on both the server and the client. This results in more
 // round-trip requests, but uses the server to aggregate over large datasets.
@@ -40,10 +110,10 @@ func NewQueryPlanWithServerAggregation(aggrLabel string, bucketedCQLQueries map[
 	return qp, nil
 }
 
-// Execute runs all CQLQueries in the QueryPlan and collects the results.
-//
-// TODO(rw): support parallel execution.
-func (qp *QueryPlanWithServerAggregation) Execute(session *gocql.Session) ([]CQLResult, error) {
+// Execute runs all CQLQueries in the QueryPlan, the queries of all buckets
+// sharing the parallelism, and merges the results of each bucket with its
+// Aggregator.
+func (qp *QueryPlanWithServerAggregation) Execute(session *gocql.Session, parallelism int) ([]CQLResult, *QueryPlanStats, error) {
 	// sort the time interval buckets we'll use:
 	sortedKeys := make([]*utils.TimeInterval, 0, len(qp.BucketedCQLQueries))
 	for k := range qp.BucketedCQLQueries {
@@ -51,34 +121,51 @@ func (qp *QueryPlanWithServerAggregation) Execute(session *gocql.Session) ([]CQL
 	}
 	sort.Sort(TimeIntervals(sortedKeys))
 
-	// for each bucket, execute its queries while aggregating its results
-	// in constant space, then append them to the result set:
+	var queries []CQLQuery
+	for _, k := range sortedKeys {
+		queries = append(queries, qp.BucketedCQLQueries[k]...)
+	}
+
+	// Execute the CQLQueries and collect their results
+	//
+	// For server-side aggregation, each will return only one row; for
+	// exclusive client-side aggregation each will return a sequence.
+	stats := &QueryPlanStats{SubQueries: len(queries)}
+	values := make([][]float64, len(queries))
+	fanOutStart := time.Now()
+	err := executeCQLQueries(session, queries, parallelism, func(i int, iter *gocql.Iter) {
+		var x float64
+		for iter.Scan(&x) {
+			values[i] = append(values[i], x)
+		}
+	})
+	stats.FanOutMs = msSince(fanOutStart)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// for each bucket, aggregate the results of its queries in constant
+	// space, then append them to the result set:
+	mergeStart := time.Now()
 	results := make([]CQLResult, 0, len(qp.BucketedCQLQueries))
+	i := 0
 	for _, k := range sortedKeys {
 		agg, err := GetAggregator(qp.AggregatorLabel)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		for _, q := range qp.BucketedCQLQueries[k] {
-			// Execute one CQLQuery and collect its result
-			//
-			// For server-side aggregation, this will return only
-			// one row; for exclusive client-side aggregation this
-			// will return a sequence.
-			iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
-			var x float64
-			for iter.Scan(&x) {
+		for range qp.BucketedCQLQueries[k] {
+			for _, x := range values[i] {
 				agg.Put(x)
 			}
-			if err := iter.Close(); err != nil {
-				return nil, err
-			}
+			i++
 		}
 		results = append(results, CQLResult{TimeInterval: k, Values: []float64{agg.Get()}})
 	}
+	stats.MergeMs = msSince(mergeStart)
 
-	return results, nil
+	return results, stats, nil
 }
 
 // DebugQueries prints debugging information.
@@ -160,39 +247,55 @@ func csiDebugQueries(cqlQueries []CQLQuery, label string, level int) {
 	}
 }
 
-// Execute runs all CQLQueries in the QueryPlan and collects the results.
-//
-// TODO(rw): support parallel execution.
-func (qp *QueryPlanWithoutServerAggregation) Execute(session *gocql.Session) ([]CQLResult, error) {
-	// for each query, execute it, then put each result row into the
-	// client-side aggregator that matches its time bucket:
-	for _, q := range qp.CQLQueries {
-		iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
+// cqlRow is a (timestamp, value) row returned by a CQLQuery.
+type cqlRow struct {
+	timestampNs int64
+	value       float64
+}
 
-		var timestampNs int64
-		var value float64
+// Execute runs all CQLQueries in the QueryPlan and merges the result rows
+// with the client-side aggregator of their time bucket.
+func (qp *QueryPlanWithoutServerAggregation) Execute(session *gocql.Session, parallelism int) ([]CQLResult, *QueryPlanStats, error) {
+	// index the buckets to aggregate by start time, as the time buckets
+	// of the result rows are rebuilt from their timestamps:
+	buckets := make(map[int64]*utils.TimeInterval, len(qp.Aggregators))
+	for ti := range qp.Aggregators {
+		buckets[ti.StartUnixNano()] = ti
+	}
 
-		for iter.Scan(&timestampNs, &value) {
-			ts := time.Unix(0, timestampNs).UTC()
-			tsTruncated := ts.Truncate(qp.GroupByDuration)
-			bucketKey, err := utils.NewTimeInterval(tsTruncated, tsTruncated.Add(qp.GroupByDuration))
-			if err != nil {
-				return nil, err
-			}
+	// execute each query, keeping its result rows until the first one
+	// outside of the buckets to aggregate:
+	stats := &QueryPlanStats{SubQueries: len(qp.CQLQueries)}
+	rows := make([][]cqlRow, len(qp.CQLQueries))
+	fanOutStart := time.Now()
+	err := executeCQLQueries(session, qp.CQLQueries, parallelism, func(i int, iter *gocql.Iter) {
+		var row cqlRow
+		for iter.Scan(&row.timestampNs, &row.value) {
+			bucketStart := time.Unix(0, row.timestampNs).Truncate(qp.GroupByDuration).UnixNano()
 
 			// Due to limits, bucket is not needed, skip
-			if _, ok := qp.Aggregators[bucketKey]; !ok {
+			if _, ok := buckets[bucketStart]; !ok {
 				break
 			}
-
-			qp.Aggregators[bucketKey][q.Field].Put(value)
+			rows[i] = append(rows[i], row)
 		}
-		if err := iter.Close(); err != nil {
-			return nil, err
+	})
+	stats.FanOutMs = msSince(fanOutStart)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// put each result row into the client-side aggregator that matches
+	// its time bucket, then perform client-side aggregation across all
+	// buckets:
+	mergeStart := time.Now()
+	for i, q := range qp.CQLQueries {
+		for _, row := range rows[i] {
+			bucketStart := time.Unix(0, row.timestampNs).Truncate(qp.GroupByDuration).UnixNano()
+			qp.Aggregators[buckets[bucketStart]][q.Field].Put(row.value)
 		}
 	}
 
-	// perform client-side aggregation across all buckets:
 	results := make([]CQLResult, 0, len(qp.TimeBuckets))
 	for _, ti := range qp.TimeBuckets {
 		if _, ok := qp.Aggregators[ti]; !ok {
@@ -205,8 +308,9 @@ func (qp *QueryPlanWithoutServerAggregation) Execute(session *gocql.Session) ([]
 		}
 		results = append(results, res)
 	}
+	stats.MergeMs = msSince(mergeStart)
 
-	return results, nil
+	return results, stats, nil
 }
 
 // DebugQueries prints debugging information.
@@ -240,8 +344,11 @@ func (a int64arr) Less(i, j int) bool { return a[i] < a[j] }
 
 // Execute runs all CQLQueries in the QueryPlan and collects the results.
 //
-// TODO(rw): support parallel execution.
-func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult, error) {
+// The queries filter each other's results, so they are always run one after
+// another, regardless of parallelism.
+func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session, _ int) ([]CQLResult, *QueryPlanStats, error) {
+	stats := &QueryPlanStats{}
+	fanOutStart := time.Now()
 	res := make(map[int64]map[string][]float64)
 	// Useful index for placing values in a row correctly
 	fieldPos := make(map[string]int)
@@ -267,6 +374,7 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 		for _, q := range qp.cqlQueries {
 			if q.Field == whereParts[0] { // only handle queries for where clause field
 				iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
+				stats.SubQueries++
 
 				var timestampNs int64
 				var value float64
@@ -287,7 +395,7 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 					res[timestampNs][key][fieldPos[q.Field]] = value
 				}
 				if err := iter.Close(); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 			}
 		}
@@ -296,6 +404,7 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 		for _, q := range qp.cqlQueries {
 			if q.Field != whereParts[0] {
 				iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
+				stats.SubQueries++
 
 				var timestampNs int64
 				var value float64
@@ -312,7 +421,7 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 					res[timestampNs][key][fieldPos[q.Field]] = value
 				}
 				if err := iter.Close(); err != nil {
-					return nil, err
+					return nil, nil, err
 				}
 			}
 		}
@@ -320,6 +429,8 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 		// TODO support no where clause?
 	}
 
+	stats.FanOutMs = msSince(fanOutStart)
+	mergeStart := time.Now()
 	keys := make(int64arr, len(res))
 	i := 0
 	for k := range res {
@@ -334,7 +445,7 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 		for _, vals := range res[ts] {
 			ti, err := utils.NewTimeInterval(tst, tst)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			temp := CQLResult{
 				TimeInterval: ti,
@@ -344,7 +455,9 @@ func (qp *QueryPlanNoAggregation) Execute(session *gocql.Session) ([]CQLResult,
 		}
 	}
 
-	return results, nil
+	stats.MergeMs = msSince(mergeStart)
+
+	return results, stats, nil
 }
 
 // DebugQueries prints debugging information.
@@ -395,8 +508,11 @@ func NewQueryPlanForEvery(fields []string, forEveryTag string, forEveryNum int64
 
 // Execute runs all CQLQueries in the QueryPlan and collects the results.
 //
-// TODO(rw): support parallel execution.
-func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error) {
+// Queries are skipped once the rows of their tag are complete, so they are
+// always run one after another, regardless of parallelism.
+func (qp *QueryPlanForEvery) Execute(session *gocql.Session, _ int) ([]CQLResult, *QueryPlanStats, error) {
+	stats := &QueryPlanStats{}
+	fanOutStart := time.Now()
 	res := make(map[string]map[int64][]float64)
 	seriesTracker := make(map[string]int)
 
@@ -408,6 +524,7 @@ func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error
 
 	for _, q := range qp.cqlQueries {
 		iter := session.Query(q.PreparableQueryString, q.Args...).Iter()
+		stats.SubQueries++
 
 		rm := r.FindSubmatch([]byte(q.Args[0].(string)))
 		key := string(rm[1])
@@ -448,10 +565,12 @@ func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error
 			}
 		}
 		if err := iter.Close(); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	stats.FanOutMs = msSince(fanOutStart)
+	mergeStart := time.Now()
 	results := make([]CQLResult, 0, len(res))
 	// TODO should print out each host
 	for _, map2 := range res {
@@ -459,7 +578,7 @@ func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error
 			tst := time.Unix(0, ts)
 			ti, err := utils.NewTimeInterval(tst, tst)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			temp := CQLResult{
 				TimeInterval: ti,
@@ -469,7 +588,9 @@ func (qp *QueryPlanForEvery) Execute(session *gocql.Session) ([]CQLResult, error
 		}
 	}
 
-	return results, nil
+	stats.MergeMs = msSince(mergeStart)
+
+	return results, stats, nil
 }
 
 // DebugQueries prints debugging information.
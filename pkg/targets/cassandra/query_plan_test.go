@@ -0,0 +1,67 @@
+package cassandra
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunBounded(t *testing.T) {
+	cases := []struct {
+		desc        string
+		n           int
+		parallelism int
+	}{
+		{desc: "serial", n: 10, parallelism: 1},
+		{desc: "parallel", n: 50, parallelism: 4},
+		{desc: "more workers than calls", n: 3, parallelism: 10},
+		{desc: "no calls", n: 0, parallelism: 4},
+	}
+	for _, c := range cases {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		done := make([]bool, c.n)
+		err := runBounded(c.n, c.parallelism, func(i int) error {
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			inFlight--
+			done[i] = true
+			mu.Unlock()
+			return nil
+		})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+		if maxInFlight > c.parallelism {
+			t.Errorf("%s: %d calls in flight, want at most %d", c.desc, maxInFlight, c.parallelism)
+		}
+		for i, d := range done {
+			if !d {
+				t.Errorf("%s: call %d not made", c.desc, i)
+			}
+		}
+	}
+}
+
+func TestRunBoundedError(t *testing.T) {
+	for _, parallelism := range []int{1, 4} {
+		err := runBounded(20, parallelism, func(i int) error {
+			if i == 7 || i == 12 {
+				return fmt.Errorf("error %d", i)
+			}
+			return nil
+		})
+		if err == nil || err.Error() != "error 7" {
+			t.Errorf("parallelism %d: incorrect error: got %v want error 7", parallelism, err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package cassandra
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	BucketDuration   = 24 * time.Hour
+	BucketTimeLayout = "2006-01-02"
+)
+
+// Blessed tables that hold benchmark data:
+var (
+	BlessedTables = []string{
+		"series_bigint",
+		"series_float",
+		"series_double",
+		"series_boolean",
+		"series_blob",
+	}
+)
+
+// Helpers for choice-like flags:
+var (
+	aggrPlanChoices = map[string]int{
+		"server": AggrPlanTypeWithServerAggregation,
+		"client": AggrPlanTypeWithoutServerAggregation,
+	}
+)
+
+// NewQueryBenchmark builds the client-side index of the series stored in the
+// Cassandra cluster of conf and returns the QueryBenchmark running the
+// queries of runner with the aggregation plan of conf.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	aggrPlan, ok := aggrPlanChoices[conf.AggregationPlan]
+	if !ok {
+		return nil, fmt.Errorf("invalid aggregation plan '%s', choices: server, client", conf.AggregationPlan)
+	}
+	if len(conf.Hosts) == 0 {
+		return nil, fmt.Errorf("missing `hosts` flag")
+	}
+	if conf.SubQueryParallelism < 1 {
+		return nil, fmt.Errorf("sub-query parallelism must be at least 1, got %d", conf.SubQueryParallelism)
+	}
+
+	// Make client-side index:
+	session := NewCassandraSession(conf.Hosts, runner.DatabaseName(), conf.ClientSideIndexTimeout)
+	csi := NewClientSideIndex(FetchSeriesCollection(session))
+	session.Close()
+
+	// Make database connection pool:
+	session = NewCassandraSession(conf.Hosts, runner.DatabaseName(), conf.ReadTimeout)
+
+	subQueries := &subQueryStats{byLabel: make(map[string]*subQueryCount)}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.CassandraPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{
+				session:     session,
+				csi:         csi,
+				aggrPlan:    aggrPlan,
+				parallelism: conf.SubQueryParallelism,
+				runner:      runner,
+				subQueries:  subQueries,
+			}
+		},
+		Close: func() {
+			session.Close()
+			if err := subQueries.write(os.Stdout); err != nil {
+				fmt.Fprintf(os.Stderr, "could not write sub-query stats: %v\n", err)
+			}
+		},
+	}, nil
+}
+
+// subQueryCount counts the CQL sub-queries of the queries of one type.
+type subQueryCount struct {
+	queries    int
+	subQueries int
+	max        int
+}
+
+// subQueryStats collects the subQueryCount of each query type, shared by
+// all workers.
+type subQueryStats struct {
+	mu      sync.Mutex
+	byLabel map[string]*subQueryCount
+}
+
+func (s *subQueryStats) add(label string, subQueries int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.byLabel[label]
+	if !ok {
+		c = &subQueryCount{}
+		s.byLabel[label] = c
+	}
+	c.queries++
+	c.subQueries += subQueries
+	if subQueries > c.max {
+		c.max = subQueries
+	}
+}
+
+// write prints the number of CQL sub-queries per query of each query type.
+func (s *subQueryStats) write(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.byLabel) == 0 {
+		return nil
+	}
+
+	labels := make([]string, 0, len(s.byLabel))
+	for l := range s.byLabel {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	if _, err := fmt.Fprintln(w, "CQL sub-queries per query:"); err != nil {
+		return err
+	}
+	for _, l := range labels {
+		c := s.byLabel[l]
+		_, err := fmt.Fprintf(w, "%s:\n\tmean: %8.2f, max: %6d, total: %d\n", l, float64(c.subQueries)/float64(c.queries), c.max, c.subQueries)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type queryProcessor struct {
+	session     *gocql.Session
+	csi         *ClientSideIndex
+	aggrPlan    int
+	parallelism int
+	runner      *query.BenchmarkRunner
+	subQueries  *subQueryStats
+
+	qe   *HLQueryExecutor
+	opts *HLQueryExecutorDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.opts = &HLQueryExecutorDoOptions{
+		AggregationPlan:      p.aggrPlan,
+		SubQueryParallelism:  p.parallelism,
+		Debug:                p.runner.DebugLevel(),
+		PrettyPrintResponses: p.runner.DoPrintResponses(),
+	}
+	p.qe = NewHLQueryExecutor(p.session, p.csi, p.runner.DebugLevel())
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	cq := q.(*query.Cassandra)
+	hlq := &HLQuery{*cq}
+	hlq.ForceUTC()
+	labels := [][]byte{
+		q.HumanLabelName(),
+		append(q.HumanLabelName(), "-qp"...),
+		append(q.HumanLabelName(), "-req"...),
+		append(q.HumanLabelName(), "-fanout"...),
+		append(q.HumanLabelName(), "-merge"...),
+	}
+	if isWarm {
+		for i, l := range labels {
+			labels[i] = append(l, " (warm)"...)
+		}
+	}
+	qpLagMs, reqLagMs, planStats, err := p.qe.Do(hlq, *p.opts)
+	if err != nil {
+		return nil, err
+	}
+	p.subQueries.add(string(labels[0]), planStats.SubQueries)
+	// total stat
+	totalMs := qpLagMs + reqLagMs
+	stats := []*query.Stat{
+		query.GetPartialStat().Init(labels[1], qpLagMs),
+		query.GetPartialStat().Init(labels[2], reqLagMs),
+		query.GetPartialStat().Init(labels[3], planStats.FanOutMs),
+		query.GetPartialStat().Init(labels[4], planStats.MergeMs),
+		query.GetStat().Init(labels[0], totalMs),
+	}
+	return stats, nil
+}
@@ -1,18 +1,18 @@
 package cassandra
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 	"log"
 	"strings"
 	"sync"
 )
 
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targetsCommon.LineScanner
 }
 
 // Reads and returns a CSV line that encodes a data point.
@@ -29,6 +29,11 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	return data.NewLoadedPoint(d.scanner.Text())
 }
 
+// Position returns the number of bytes of the data scanned.
+func (d *fileDataSource) Position() int64 {
+	return d.scanner.Position()
+}
+
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	return nil
 }
@@ -5,22 +5,64 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/timescale/tsbs/load"
+	"github.com/blagojts/viper"
 	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+	"github.com/timescale/tsbs/pkg/targets/timescaledb"
 )
 
 const dbType = "clickhouse"
 
+// defaultBlockSize is the number of rows in the blocks of the native loader,
+// the default of the block_size setting of the driver.
+const defaultBlockSize = 1000000
+
 type ClickhouseConfig struct {
-	Host     string
-	User     string
-	Password string
+	Host     string `yaml:"host" mapstructure:"host"`
+	User     string `yaml:"user" mapstructure:"user"`
+	Password string `yaml:"password" mapstructure:"password"`
+
+	LogBatches bool   `yaml:"log-batches" mapstructure:"log-batches"`
+	InTableTag bool   `yaml:"-" mapstructure:"-"`
+	Debug      int    `yaml:"debug" mapstructure:"debug"`
+	DbName     string `yaml:"-" mapstructure:"-"`
+
+	// Native loads data generated with the clickhouse-native format, and
+	// writes it in blocks of BlockSize rows over the native protocol.
+	Native      bool `yaml:"native" mapstructure:"native"`
+	BlockSize   int  `yaml:"block-size" mapstructure:"block-size"`
+	AsyncInsert bool `yaml:"async-insert" mapstructure:"async-insert"`
+	// Codecs are the compression codecs of the columns of the metric tables,
+	// as <column>=<codec>[+<codec>...] with the column * for all the fields.
+	Codecs []string `yaml:"codecs" mapstructure:"codecs"`
+
+	codecs map[string]string
+}
+
+func parseSpecificConfig(v *viper.Viper) (*ClickhouseConfig, error) {
+	var conf ClickhouseConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
 
-	LogBatches bool
-	InTableTag bool
-	Debug      int
-	DbName     string
+// Validate checks the block size and parses the column codecs.
+func (c *ClickhouseConfig) Validate() error {
+	if c.BlockSize < 0 {
+		return fmt.Errorf("invalid block-size %d", c.BlockSize)
+	}
+	if c.BlockSize == 0 {
+		c.BlockSize = defaultBlockSize
+	}
+	codecs, err := parseCodecs(c.Codecs)
+	if err != nil {
+		return err
+	}
+	c.codecs = codecs
+	return nil
 }
 
 // String values of tags and fields to insert - string representation
@@ -90,21 +132,35 @@ func (f *factory) New() targets.Batch {
 
 const tagsPrefix = "tags"
 
-func NewBenchmark(file string, hashWorkers bool, conf *ClickhouseConfig) targets.Benchmark {
-	return &benchmark{
-		ds: &fileDataSource{
-			scanner: bufio.NewScanner(load.GetBufferedReader(file)),
-		},
-		hashWorkers: hashWorkers,
-		conf:        conf,
+// NewBenchmark returns the benchmark loading ClickHouse database dbName with
+// the data of the data source, in the text format or with conf.Native in the
+// clickhouse-native format.
+func NewBenchmark(dbName string, conf *ClickhouseConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	conf.DbName = dbName
+	var ds targets.DataSource
+	var err error
+	if conf.Native {
+		ds, err = common.NewDataSource(dataSourceConfig, &RowBinarySerializer{}, true, func(br *bufio.Reader) (targets.DataSource, error) {
+			return &nativeDataSource{r: common.NewPositionReader(br)}, nil
+		})
+	} else {
+		ds, err = common.NewDataSource(dataSourceConfig, &timescaledb.Serializer{}, true, func(br *bufio.Reader) (targets.DataSource, error) {
+			return &fileDataSource{scanner: common.NewLineScanner(br)}, nil
+		})
 	}
+	if err != nil {
+		return nil, err
+	}
+	return &benchmark{ds: ds, conf: conf}, nil
 }
 
 // targets.Benchmark interface implementation
 type benchmark struct {
-	ds          targets.DataSource
-	hashWorkers bool
-	conf        *ClickhouseConfig
+	ds   targets.DataSource
+	conf *ClickhouseConfig
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
@@ -112,11 +168,14 @@ func (b *benchmark) GetDataSource() targets.DataSource {
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	if b.conf.Native {
+		return &nativeFactory{}
+	}
 	return &factory{}
 }
 
 func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
-	if b.hashWorkers {
+	if maxPartitions > 1 {
 		return &hostnameIndexer{
 			partitions: maxPartitions,
 		}
@@ -126,6 +185,9 @@ func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
 
 // loader.Benchmark interface implementation
 func (b *benchmark) GetProcessor() targets.Processor {
+	if b.conf.Native {
+		return &nativeProcessor{conf: b.conf, headers: b.ds.Headers()}
+	}
 	return &processor{conf: b.conf}
 }
 
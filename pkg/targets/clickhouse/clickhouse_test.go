@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets/common"
 )
 
 func TestGetConnectString(t *testing.T) {
@@ -95,7 +96,7 @@ func TestNextItem(t *testing.T) {
 	}
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		dataSource := &fileDataSource{scanner: bufio.NewScanner(br)}
+		dataSource := &fileDataSource{scanner: common.NewLineScanner(br)}
 		if c.shouldFatal {
 			fmt.Println(c.desc)
 			isCalled := false
@@ -126,7 +127,7 @@ func TestNextItem(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("tags,tag1text,tag2text\ncpu,140,0.0,0.0\n")
 	br := bufio.NewReader(bytes.NewReader([]byte(input)))
-	dataSource := &fileDataSource{scanner: bufio.NewScanner(br)}
+	dataSource := &fileDataSource{scanner: common.NewLineScanner(br)}
 	_ = dataSource.NextItem()
 	// nothing left, should be EOF
 	p := dataSource.NextItem()
@@ -191,7 +192,7 @@ func TestHeaders(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		dataSource := &fileDataSource{bufio.NewScanner(br), nil}
+		dataSource := &fileDataSource{common.NewLineScanner(br), nil}
 		if c.shouldFatal {
 			isCalled := false
 			fatal = func(fmt string, args ...interface{}) {
@@ -113,6 +113,18 @@ func createTagsTable(conf *ClickhouseConfig, db *sqlx.DB, tagNames, tagTypes []s
 func createMetricsTable(conf *ClickhouseConfig, db *sqlx.DB, tableName string, fieldColumns []string) {
 	tableCols[tableName] = fieldColumns
 
+	sql := generateMetricsTableQuery(conf, tableName, fieldColumns)
+	if conf.Debug > 0 {
+		fmt.Printf(sql)
+	}
+	_, err := db.Exec(sql)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// generateMetricsTableQuery builds the CREATE TABLE SQL statement of a metrics table
+func generateMetricsTableQuery(conf *ClickhouseConfig, tableName string, fieldColumns []string) string {
 	// We'll have some service columns in table to be created and columnNames contains all column names to be created
 	var columnNames []string
 
@@ -132,28 +144,26 @@ func createMetricsTable(conf *ClickhouseConfig, db *sqlx.DB, tableName string, f
 			// Skip nameless columns
 			continue
 		}
-		columnsWithType = append(columnsWithType, fmt.Sprintf("%s Nullable(Float64)", column))
+		columnsWithType = append(columnsWithType, fmt.Sprintf("%s Nullable(Float64)%s", column, columnCodec(conf.codecs, column, true)))
 	}
 
-	sql := fmt.Sprintf(`
+	return fmt.Sprintf(`
 			CREATE TABLE %s (
-				created_date    Date     DEFAULT today(),
-				created_at      DateTime DEFAULT now(),
-				time            String,
-				tags_id         UInt32,
+				created_date    Date     DEFAULT today()%s,
+				created_at      DateTime DEFAULT now()%s,
+				time            String%s,
+				tags_id         UInt32%s,
 				%s,
-				additional_tags String   DEFAULT ''
+				additional_tags String   DEFAULT ''%s
 			) ENGINE = MergeTree(created_date, (tags_id, created_at), 8192)
 			`,
 		tableName,
-		strings.Join(columnsWithType, ","))
-	if conf.Debug > 0 {
-		fmt.Printf(sql)
-	}
-	_, err := db.Exec(sql)
-	if err != nil {
-		panic(err)
-	}
+		columnCodec(conf.codecs, "created_date", false),
+		columnCodec(conf.codecs, "created_at", false),
+		columnCodec(conf.codecs, "time", false),
+		columnCodec(conf.codecs, "tags_id", false),
+		strings.Join(columnsWithType, ","),
+		columnCodec(conf.codecs, "additional_tags", false))
 }
 
 func generateTagsTableQuery(tagNames, tagTypes []string) string {
@@ -201,3 +211,51 @@ func serializedTypeToClickHouseType(serializedType string) string {
 		panic(fmt.Sprintf("unrecognized type %s", serializedType))
 	}
 }
+
+// codecNames are the compression codecs the columns of the metrics tables
+// can be created with
+var codecNames = map[string]bool{
+	"Delta":       true,
+	"DoubleDelta": true,
+	"Gorilla":     true,
+	"ZSTD":        true,
+}
+
+// parseCodecs parses the codecs of the columns, given as
+// <column>=<codec>[+<codec>...], e.g. created_at=DoubleDelta+ZSTD or
+// *=Gorilla for all the field columns. A codec can have its parameter, as
+// in ZSTD(3). It returns the CODEC clause of each column.
+func parseCodecs(specs []string) (map[string]string, error) {
+	codecs := make(map[string]string)
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if len(spec) == 0 {
+			continue
+		}
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return nil, fmt.Errorf("invalid codec %q, expected <column>=<codec>[+<codec>...]", spec)
+		}
+		chain := strings.Split(parts[1], "+")
+		for _, codec := range chain {
+			name := strings.SplitN(codec, "(", 2)[0]
+			if !codecNames[name] {
+				return nil, fmt.Errorf("unknown codec %q of column %s, choices: Delta, DoubleDelta, Gorilla, ZSTD", codec, parts[0])
+			}
+		}
+		codecs[parts[0]] = fmt.Sprintf(" CODEC(%s)", strings.Join(chain, ", "))
+	}
+	return codecs, nil
+}
+
+// columnCodec returns the CODEC clause of a column, if it has one. The field
+// columns without their own codec have the codec of *.
+func columnCodec(codecs map[string]string, column string, field bool) string {
+	if codec, ok := codecs[column]; ok {
+		return codec
+	}
+	if field {
+		return codecs["*"]
+	}
+	return ""
+}
@@ -2,6 +2,7 @@ package clickhouse
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -66,3 +67,49 @@ func TestGenerateTagsTableQueryPanicOnWrongType(t *testing.T) {
 
 	t.Fatalf("test should have stopped at this point")
 }
+
+func TestParseCodecs(t *testing.T) {
+	codecs, err := parseCodecs([]string{"created_at=DoubleDelta+ZSTD", "*=Gorilla", "usage_user=ZSTD(3)", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"created_at": " CODEC(DoubleDelta, ZSTD)",
+		"*":          " CODEC(Gorilla)",
+		"usage_user": " CODEC(ZSTD(3))",
+	}
+	for col, codec := range want {
+		if codecs[col] != codec {
+			t.Errorf("incorrect codec of %s: got %q want %q", col, codecs[col], codec)
+		}
+	}
+	if got := columnCodec(codecs, "usage_idle", true); got != want["*"] {
+		t.Errorf("field without its own codec should get the codec of *, got %q", got)
+	}
+	if got := columnCodec(codecs, "tags_id", false); got != "" {
+		t.Errorf("service column should not get the codec of *, got %q", got)
+	}
+
+	for _, spec := range []string{"created_at", "=ZSTD", "created_at=", "created_at=LZ5", "time=Delta+Foo"} {
+		if _, err := parseCodecs([]string{spec}); err == nil {
+			t.Errorf("expected an error for codec %q", spec)
+		}
+	}
+}
+
+func TestGenerateMetricsTableQueryCodecs(t *testing.T) {
+	conf := &ClickhouseConfig{Codecs: []string{"created_at=DoubleDelta", "*=Gorilla+ZSTD"}}
+	if err := conf.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sql := generateMetricsTableQuery(conf, "cpu", []string{"usage_user", "usage_system"})
+	for _, want := range []string{
+		"created_at      DateTime DEFAULT now() CODEC(DoubleDelta),",
+		"time            String,",
+		"usage_user Nullable(Float64) CODEC(Gorilla, ZSTD),usage_system Nullable(Float64) CODEC(Gorilla, ZSTD),",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("query does not contain %q:\n%s", want, sql)
+		}
+	}
+}
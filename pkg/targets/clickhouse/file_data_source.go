@@ -1,16 +1,16 @@
 package clickhouse
 
 import (
-	"bufio"
 	"strings"
 
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 )
 
 // scan.PointDecoder interface implementation
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targetsCommon.LineScanner
 	//cached headers (should be read only at start of file)
 	headers *common.GeneratedDataHeaders
 }
@@ -59,6 +59,11 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	})
 }
 
+// Position returns the number of bytes of the data scanned.
+func (d *fileDataSource) Position() int64 {
+	return d.scanner.Position()
+}
+
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	if d.headers != nil {
 		return d.headers
@@ -106,6 +111,12 @@ func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 		i++
 	}
 
+	d.headers = parseHeaders(tags, cols)
+	return d.headers
+}
+
+// parseHeaders parses the tags line and the table lines of the header.
+func parseHeaders(tags string, cols []string) *common.GeneratedDataHeaders {
 	// tags content:
 	//tags,hostname,region,datacenter,rack,os,arch,team,service,service_version,service_environment
 	//
@@ -138,12 +149,11 @@ func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 		tableName := tableSpec[0]
 		fieldKeys[tableName] = tableSpec[1:]
 	}
-	d.headers = &common.GeneratedDataHeaders{
+	return &common.GeneratedDataHeaders{
 		TagKeys:   tagNames,
 		TagTypes:  tagTypes,
 		FieldKeys: fieldKeys,
 	}
-	return d.headers
 }
 
 func extractTagNamesAndTypes(tags []string) ([]string, []string) {
@@ -5,22 +5,39 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/timescaledb"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &clickhouseTarget{}
 }
 
-type clickhouseTarget struct{}
+// NewNativeTarget returns the target of the clickhouse-native format, the
+// RowBinary encoded data loaded in blocks over the native protocol.
+func NewNativeTarget() targets.ImplementedQueryTarget {
+	return &clickhouseTarget{native: true}
+}
+
+type clickhouseTarget struct {
+	native bool
+}
 
-func (c clickhouseTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("implement me")
+func (c clickhouseTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	conf.Native = conf.Native || c.native
+	return NewBenchmark(targetDB, conf, dataSourceConfig)
 }
 
 func (c clickhouseTarget) Serializer() serialize.PointSerializer {
+	if c.native {
+		return &RowBinarySerializer{}
+	}
 	return &timescaledb.Serializer{}
 }
 
@@ -30,8 +47,32 @@ func (c clickhouseTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.
 	flagSet.String(flagPrefix+"password", "", "Password to connect to ClickHouse")
 	flagSet.Bool(flagPrefix+"log-batches", false, "Whether to time individual batches.")
 	flagSet.Int(flagPrefix+"debug", 0, "Debug printing (choices: 0, 1, 2). (default 0)")
+	if !c.native {
+		flagSet.Bool(flagPrefix+"native", false, "Whether the data was generated with the clickhouse-native format, to load it in blocks over the native protocol")
+	}
+	flagSet.Int(flagPrefix+"block-size", defaultBlockSize, "Maximum number of rows of the blocks of the native loader")
+	flagSet.Bool(flagPrefix+"async-insert", false, "Whether the native loader inserts asynchronously (async_insert=1, wait_for_async_insert=0)")
+	flagSet.StringSlice(flagPrefix+"codecs", nil, "Comma-separated compression codecs of the columns of the metrics tables, as <column>=<codec>[+<codec>...] with * for all the fields. Codecs: Delta, DoubleDelta, Gorilla, ZSTD")
 }
 
 func (c clickhouseTarget) TargetName() string {
+	if c.native {
+		return constants.FormatClickhouseNative
+	}
 	return constants.FormatClickhouse
 }
+
+func (c clickhouseTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"hosts", "localhost",
+		"Comma separated list of ClickHouse hosts (pass multiple values for sharding reads on a multi-node setup)")
+	flagSet.String(flagPrefix+"user", "default", "User to connect to ClickHouse as")
+	flagSet.String(flagPrefix+"password", "", "Password to connect to ClickHouse")
+}
+
+func (c clickhouseTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
+}
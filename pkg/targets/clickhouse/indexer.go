@@ -14,8 +14,13 @@ type hostnameIndexer struct {
 
 // scan.PointIndexer interface implementation
 func (i *hostnameIndexer) GetIndex(item data.LoadedPoint) uint {
-	p := item.Data.(*point)
-	hostname := strings.SplitN(p.row.tags, ",", 2)[0]
+	var hostname string
+	switch p := item.Data.(type) {
+	case *point:
+		hostname = strings.SplitN(p.row.tags, ",", 2)[0]
+	case *nativePoint:
+		hostname = p.tagKey()
+	}
 	h := fnv.New32a()
 	h.Write([]byte(hostname))
 	return uint(h.Sum32()) % i.partitions
@@ -0,0 +1,118 @@
+package clickhouse
+
+import (
+	"io"
+	"strings"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// nativePoint is a single row of data decoded from the clickhouse-native
+// format, with the typed values of its tags and fields (nil for NULL)
+type nativePoint struct {
+	table     string
+	timestamp int64
+	tags      []interface{}
+	fields    []interface{}
+}
+
+// tagKey returns the value of the first tag, e.g. the hostname
+func (p *nativePoint) tagKey() string {
+	if len(p.tags) == 0 {
+		return ""
+	}
+	return tagValueKey(p.tags[0])
+}
+
+// scan.Batch interface implementation
+type nativeBatch struct {
+	m   map[string][]*nativePoint
+	cnt uint
+}
+
+// scan.Batch interface implementation
+func (b *nativeBatch) Len() uint {
+	return b.cnt
+}
+
+// scan.Batch interface implementation
+func (b *nativeBatch) Append(item data.LoadedPoint) {
+	p := item.Data.(*nativePoint)
+	b.m[p.table] = append(b.m[p.table], p)
+	b.cnt++
+}
+
+// scan.BatchFactory interface implementation
+type nativeFactory struct{}
+
+// scan.BatchFactory interface implementation
+func (f *nativeFactory) New() targets.Batch {
+	return &nativeBatch{m: map[string][]*nativePoint{}}
+}
+
+// nativeDataSource reads the data generated with the clickhouse-native
+// format: the text header followed by the RowBinary encoded points.
+type nativeDataSource struct {
+	r *targetsCommon.PositionReader
+	//cached headers (should be read only at start of file)
+	headers *common.GeneratedDataHeaders
+	decoder *rowBinaryDecoder
+}
+
+// scan.PointDecoder interface implementation
+func (d *nativeDataSource) NextItem() data.LoadedPoint {
+	if d.decoder == nil && d.Headers() == nil {
+		return data.LoadedPoint{}
+	}
+	p, err := d.decoder.decode()
+	if err == io.EOF {
+		return data.LoadedPoint{}
+	} else if err != nil {
+		fatal("decode error: %v", err)
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(p)
+}
+
+// Headers reads the text header, the same as the one of the text format,
+// up to its blank line.
+func (d *nativeDataSource) Headers() *common.GeneratedDataHeaders {
+	if d.headers != nil {
+		return d.headers
+	}
+	var tags string
+	var cols []string
+	for i := 0; ; i++ {
+		line, err := d.r.ReadString('\n')
+		if err == io.EOF {
+			fatal("reached EOF, but not enough things scanned")
+			return nil
+		} else if err != nil {
+			fatal("read error: %v", err)
+			return nil
+		}
+		line = strings.TrimSpace(line)
+		if i == 0 {
+			tags = line
+			continue
+		}
+		if len(line) == 0 {
+			// empty line - end of header
+			break
+		}
+		cols = append(cols, line)
+	}
+	d.headers = parseHeaders(tags, cols)
+	if d.headers != nil {
+		d.decoder = &rowBinaryDecoder{r: d.r, tagTypes: d.headers.TagTypes}
+	}
+	return d.headers
+}
+
+// Position returns the number of bytes of the data read.
+func (d *nativeDataSource) Position() int64 {
+	return d.r.Position()
+}
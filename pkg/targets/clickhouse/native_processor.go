@@ -0,0 +1,207 @@
+package clickhouse
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kshvakov/clickhouse"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// nativeProcessor inserts the points of the clickhouse-native format in
+// blocks of columns, streamed over the native protocol. A batch is sent in
+// blocks of at most conf.BlockSize rows per table.
+type nativeProcessor struct {
+	conn    clickhouse.Clickhouse
+	db      *sqlx.DB
+	csi     *syncCSI
+	conf    *ClickhouseConfig
+	headers *common.GeneratedDataHeaders
+}
+
+// load.Processor interface implementation
+func (p *nativeProcessor) Init(workerNum int, doLoad, hashWorkers bool) {
+	if !doLoad {
+		return
+	}
+	conn, err := clickhouse.OpenDirect(getConnectString(p.conf, true))
+	if err != nil {
+		fatal("could not connect to ClickHouse: %v", err)
+		return
+	}
+	p.conn = conn
+	// the tags are inserted once per host, through database/sql
+	p.db = sqlx.MustConnect(dbType, getConnectString(p.conf, true))
+	if hashWorkers {
+		p.csi = newSyncCSI()
+	} else {
+		p.csi = globalSyncCSI
+	}
+}
+
+// load.ProcessorCloser interface implementation
+func (p *nativeProcessor) Close(doLoad bool) {
+	if doLoad {
+		p.conn.Close()
+		p.db.Close()
+	}
+}
+
+// load.Processor interface implementation
+func (p *nativeProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	batch := b.(*nativeBatch)
+	rowCnt := 0
+	metricCnt := uint64(0)
+	for tableName, rows := range batch.m {
+		rowCnt += len(rows)
+		if doLoad {
+			start := time.Now()
+			metricCnt += p.insertBlocks(tableName, rows)
+
+			if p.conf.LogBatches {
+				took := time.Since(start)
+				fmt.Printf("BATCH: batchsize %d row rate %f/sec (took %v)\n", len(rows), float64(len(rows))/took.Seconds(), took)
+			}
+		}
+	}
+	batch.m = map[string][]*nativePoint{}
+	batch.cnt = 0
+
+	return metricCnt, uint64(rowCnt)
+}
+
+// insertBlocks inserts the rows of a table in blocks, and returns the number
+// of metrics inserted.
+func (p *nativeProcessor) insertBlocks(tableName string, rows []*nativePoint) uint64 {
+	tagIDs := p.tagIDs(rows)
+	fieldCols := p.headers.FieldKeys[tableName]
+
+	cols := []string{"created_date", "created_at", "time", "tags_id", "additional_tags"}
+	if p.conf.InTableTag {
+		cols = append(cols, p.headers.TagKeys[0]) // hostname
+	}
+	cols = append(cols, fieldCols...)
+
+	if _, err := p.conn.Begin(); err != nil {
+		panic(err)
+	}
+	if _, err := p.conn.Prepare(insertQuery(tableName, cols, p.conf.AsyncInsert)); err != nil {
+		panic(err)
+	}
+	block, err := p.conn.Block()
+	if err != nil {
+		panic(err)
+	}
+
+	metricCnt := uint64(0)
+	values := make([]driver.Value, len(cols))
+	for _, row := range rows {
+		if len(row.fields) > len(fieldCols) {
+			panic(fmt.Sprintf("point of %s has %d fields, the headers describe %d", tableName, len(row.fields), len(fieldCols)))
+		}
+		metricCnt += uint64(len(row.fields))
+
+		ts := time.Unix(0, row.timestamp).UTC()
+		values[0] = ts                                            // created_date
+		values[1] = ts                                            // created_at
+		values[2] = ts.Format("2006-01-02 15:04:05.999999 -0700") // time
+		values[3] = tagIDs[row.tagKey()]                          // tags_id
+		values[4] = ""                                            // additional_tags
+		i := 5
+		if p.conf.InTableTag {
+			values[i] = row.tags[0]
+			i++
+		}
+		for j := range fieldCols {
+			// the fields the point does not have are NULL
+			values[i+j] = nil
+			if j < len(row.fields) {
+				values[i+j] = row.fields[j]
+			}
+		}
+
+		if err := block.AppendRow(values); err != nil {
+			panic(err)
+		}
+		if block.NumRows >= uint64(p.conf.BlockSize) {
+			if err := p.conn.WriteBlock(block); err != nil {
+				panic(err)
+			}
+		}
+	}
+	// Commit writes the last block and the end of the data
+	if err := p.conn.Commit(); err != nil {
+		panic(err)
+	}
+	return metricCnt
+}
+
+// tagIDs inserts the tags of the hosts which are new, and returns the ids
+// of the tags of the rows by hostname.
+func (p *nativeProcessor) tagIDs(rows []*nativePoint) map[string]int64 {
+	ids := make(map[string]int64)
+	var newTags []*nativePoint
+	p.csi.mutex.RLock()
+	for _, row := range rows {
+		key := row.tagKey()
+		if _, ok := ids[key]; ok {
+			continue
+		}
+		if id, ok := p.csi.m[key]; ok {
+			ids[key] = id
+			continue
+		}
+		ids[key] = 0
+		newTags = append(newTags, row)
+	}
+	p.csi.mutex.RUnlock()
+
+	if len(newTags) > 0 {
+		p.csi.mutex.Lock()
+		// another worker may have inserted them in the meantime
+		var toInsert [][]interface{}
+		for _, row := range newTags {
+			if id, ok := p.csi.m[row.tagKey()]; ok {
+				ids[row.tagKey()] = id
+			} else {
+				toInsert = append(toInsert, p.tagColumnValues(row.tags))
+			}
+		}
+		if len(toInsert) > 0 {
+			hostnameToTags := insertTagValues(p.conf, p.db, p.headers.TagKeys, len(p.csi.m), toInsert, true)
+			for hostName, tagsID := range hostnameToTags {
+				p.csi.m[hostName] = tagsID
+				ids[hostName] = tagsID
+			}
+		}
+		p.csi.mutex.Unlock()
+	}
+	return ids
+}
+
+// tagColumnValues returns the values of all the columns of the tags table,
+// NULL for the tags the point does not have
+func (p *nativeProcessor) tagColumnValues(tags []interface{}) []interface{} {
+	values := make([]interface{}, len(p.headers.TagKeys))
+	copy(values, tags)
+	return values
+}
+
+// insertQuery builds the INSERT statement the blocks of a table are sent
+// with. Asynchronous inserts are buffered by the server, which does not
+// wait for them to be written.
+func insertQuery(tableName string, cols []string, asyncInsert bool) string {
+	settings := ""
+	if asyncInsert {
+		settings = " SETTINGS async_insert=1, wait_for_async_insert=0"
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s)%s VALUES (%s)",
+		tableName,
+		strings.Join(cols, ","),
+		settings,
+		strings.Repeat(",?", len(cols))[1:])
+}
@@ -267,9 +267,6 @@ func (p *processor) processCSI(tableName string, rows []*insertData) uint64 {
 
 // insertTags fills tags table with values
 func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]string, returnResults bool) map[string]int64 {
-	// Map hostname to tags_id
-	ret := make(map[string]int64)
-
 	// reflect tags table structure which is
 	// CREATE TABLE tags(
 	//	 created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -286,9 +283,23 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 	// ...
 	// ( ... row N values ... ),
 
+	values := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		values[i] = make([]interface{}, len(row))
+		for j, value := range row {
+			values[i][j] = convertBasedOnType(tagColumnTypes[j], value)
+		}
+	}
+	return insertTagValues(conf, db, tableCols["tags"], startID, values, returnResults)
+}
+
+// insertTagValues fills tags table with the typed values of the tags columns cols
+func insertTagValues(conf *ClickhouseConfig, db *sqlx.DB, cols []string, startID int, rows [][]interface{}, returnResults bool) map[string]int64 {
+	// Map hostname to tags_id
+	ret := make(map[string]int64)
+
 	// Columns. Ex.:
 	// hostname,region,datacenter,rack,os,arch,team,service,service_version,service_environment
-	cols := tableCols["tags"]
 	// Add id column to prepared statement
 	sql := fmt.Sprintf(`
 		INSERT INTO tags(
@@ -328,9 +339,7 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 		// Place id at the beginning
 		variadicArgs[0] = id
 		// And all the rest of column values afterwards
-		for i, value := range row {
-			variadicArgs[i+1] = convertBasedOnType(tagColumnTypes[i], value)
-		}
+		copy(variadicArgs[1:], row)
 
 		// And now expand []interface{} with the same data as 'row' contains (plus 'id') in Exec(args ...interface{})
 		_, err := stmt.Exec(variadicArgs...)
@@ -341,7 +350,7 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 		// Fill map hostname -> id
 		if returnResults {
 			// Map hostname -> tags_id
-			ret[row[0]] = int64(id)
+			ret[tagValueKey(row[0])] = int64(id)
 		}
 	}
 
@@ -357,6 +366,19 @@ func insertTags(conf *ClickhouseConfig, db *sqlx.DB, startID int, rows [][]strin
 	return nil
 }
 
+// tagValueKey returns the string a tag value is looked up by in the tags
+// ids, e.g. the hostname
+func tagValueKey(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
 func convertBasedOnType(serializedType, value string) interface{} {
 	if value == "" {
 		return nil
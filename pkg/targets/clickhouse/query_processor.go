@@ -0,0 +1,142 @@
+package clickhouse
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/jmoiron/sqlx"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the ClickHouse query runner.
+type QuerySpecificConfig struct {
+	Hosts    []string `yaml:"hosts" mapstructure:"hosts"`
+	User     string   `yaml:"user" mapstructure:"user"`
+	Password string   `yaml:"password" mapstructure:"password"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the ClickHouse hosts in conf, round-robin over the workers.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if len(conf.Hosts) == 0 {
+		return nil, fmt.Errorf("missing `hosts` flag")
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.ClickHousePool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{conf: conf, runner: runner}
+		},
+	}, nil
+}
+
+// getQueryConnectString returns the connect string of a query worker.
+//
+// If we're running queries against multiple nodes we need to balance the queries
+// across replicas. Each worker is assigned a sequence number -- we'll use that
+// to evenly distribute hosts to worker connections
+func getQueryConnectString(conf *QuerySpecificConfig, dbName string, workerNumber int) string {
+	// Round robin the host/worker assignment by assigning a host based on workerNumber % totalNumberOfHosts
+	host := conf.Hosts[workerNumber%len(conf.Hosts)]
+
+	return fmt.Sprintf("tcp://%s:9000?username=%s&password=%s&database=%s", host, conf.User, conf.Password, dbName)
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(rows *sqlx.Rows, q *query.ClickHouse) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+
+	results := []map[string]interface{}{}
+	for rows.Next() {
+		r := make(map[string]interface{})
+		if err := rows.MapScan(r); err != nil {
+			panic(err)
+		}
+		results = append(results, r)
+		resp["results"] = results
+	}
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+}
+
+// query.Processor interface implementation
+type queryProcessor struct {
+	conf   *QuerySpecificConfig
+	runner *query.BenchmarkRunner
+	db     *sqlx.DB
+	opts   *queryExecutorOptions
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) Init(workerNumber int) {
+	p.db = sqlx.MustConnect(dbType, getQueryConnectString(p.conf, p.runner.DatabaseName(), workerNumber))
+	p.opts = &queryExecutorOptions{
+		// ClickHouse could not do EXPLAIN
+		showExplain:   false,
+		debug:         p.runner.DebugLevel() > 0,
+		printResponse: p.runner.DoPrintResponses(),
+	}
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+
+	// Ensure ClickHouse query
+	chQuery := q.(*query.ClickHouse)
+
+	start := time.Now()
+
+	// SqlQuery is []byte, so cast is needed
+	sql := string(chQuery.SqlQuery)
+
+	// Main action - run the query
+	rows, err := p.db.Queryx(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	// Print some extra info if needed
+	if p.opts.debug {
+		fmt.Println(sql)
+	}
+	if p.opts.printResponse {
+		prettyPrintResponse(rows, chQuery)
+	}
+
+	// Finalize the query
+	rows.Close()
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
@@ -0,0 +1,263 @@
+package clickhouse
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// RowBinarySerializer writes a Point in the clickhouse-native format, the
+// RowBinary encoding of ClickHouse: little endian numbers, strings prefixed
+// with their uvarint length and nullable values prefixed with a null byte.
+// The data file starts with the same text header as the text format, which
+// gives the types of the tags and the fields of each table.
+//
+// Each Point is written as
+// <measurement String><timestamp Int64 (ns)>
+// <number of tags UVarint><tag Nullable(<type of the tag>)>...
+// <number of fields UVarint><field Nullable(Float64)>...
+type RowBinarySerializer struct{}
+
+// Serialize writes Point p to the given Writer w, so it can be loaded by
+// the native ClickHouse loader.
+func (s *RowBinarySerializer) Serialize(p *data.Point, w io.Writer) error {
+	buf := make([]byte, 0, 256)
+	buf = appendString(buf, p.MeasurementName())
+	buf = appendUint64(buf, uint64(p.Timestamp().UTC().UnixNano()))
+
+	tagValues := p.TagValues()
+	buf = appendUvarint(buf, uint64(len(tagValues)))
+	for _, v := range tagValues {
+		var err error
+		if buf, err = appendTagValue(buf, v); err != nil {
+			return err
+		}
+	}
+
+	fieldValues := p.FieldValues()
+	buf = appendUvarint(buf, uint64(len(fieldValues)))
+	for _, v := range fieldValues {
+		var err error
+		if buf, err = appendFieldValue(buf, v); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func appendUint32(buf []byte, v uint32) []byte {
+	var tmp [4]byte
+	binary.LittleEndian.PutUint32(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendUint64(buf []byte, v uint64) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	return append(buf, tmp[:]...)
+}
+
+func appendString(buf, v []byte) []byte {
+	buf = appendUvarint(buf, uint64(len(v)))
+	return append(buf, v...)
+}
+
+// appendTagValue appends tag value v, encoded as the type the headers name
+// for it (the Go type of the values of the tag).
+func appendTagValue(buf []byte, v interface{}) ([]byte, error) {
+	if v == nil {
+		return append(buf, 1), nil
+	}
+	buf = append(buf, 0)
+	switch v := v.(type) {
+	case string:
+		return appendString(buf, []byte(v)), nil
+	case []byte:
+		return appendString(buf, v), nil
+	case float32:
+		return appendUint32(buf, math.Float32bits(v)), nil
+	case float64:
+		return appendUint64(buf, math.Float64bits(v)), nil
+	case int32:
+		return appendUint32(buf, uint32(v)), nil
+	case int64:
+		return appendUint64(buf, uint64(v)), nil
+	default:
+		return nil, fmt.Errorf("unsupported tag value type %T", v)
+	}
+}
+
+// appendFieldValue appends field value v as a Nullable(Float64), the type
+// of the field columns.
+func appendFieldValue(buf []byte, v interface{}) ([]byte, error) {
+	var f float64
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 1), nil
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case bool:
+		if v {
+			f = 1
+		}
+	default:
+		return nil, fmt.Errorf("unsupported field value type %T", v)
+	}
+	buf = append(buf, 0)
+	return appendUint64(buf, math.Float64bits(f)), nil
+}
+
+// rowBinaryDecoder reads the Points written by RowBinarySerializer.
+// byteReader is what a rowBinaryDecoder reads from, e.g. a bufio.Reader.
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+type rowBinaryDecoder struct {
+	r        byteReader
+	tagTypes []string
+	buf      [8]byte
+}
+
+// decode reads the next point, returning io.EOF when there are no more.
+func (d *rowBinaryDecoder) decode() (*nativePoint, error) {
+	table, err := d.string()
+	if err == io.EOF {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, err
+	}
+	p := &nativePoint{table: table}
+	ts, err := d.uint64()
+	if err != nil {
+		return nil, err
+	}
+	p.timestamp = int64(ts)
+
+	numTags, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	if numTags > len(d.tagTypes) {
+		return nil, fmt.Errorf("point of %s has %d tags, the headers describe %d", table, numTags, len(d.tagTypes))
+	}
+	p.tags = make([]interface{}, numTags)
+	for i := range p.tags {
+		if p.tags[i], err = d.tagValue(d.tagTypes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	numFields, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	p.fields = make([]interface{}, numFields)
+	for i := range p.fields {
+		isNull, err := d.nullFlag()
+		if err != nil {
+			return nil, err
+		}
+		if isNull != 0 {
+			continue
+		}
+		f, err := d.uint64()
+		if err != nil {
+			return nil, err
+		}
+		p.fields[i] = math.Float64frombits(f)
+	}
+	return p, nil
+}
+
+func (d *rowBinaryDecoder) tagValue(tagType string) (interface{}, error) {
+	isNull, err := d.nullFlag()
+	if err != nil || isNull != 0 {
+		return nil, err
+	}
+	switch tagType {
+	case "string":
+		v, err := d.string()
+		return v, unexpectedEOF(err)
+	case "float32":
+		v, err := d.uint32()
+		return math.Float32frombits(v), err
+	case "float64":
+		v, err := d.uint64()
+		return math.Float64frombits(v), err
+	case "int32":
+		v, err := d.uint32()
+		return int32(v), err
+	case "int64":
+		v, err := d.uint64()
+		return int64(v), err
+	default:
+		return nil, fmt.Errorf("unrecognized type %s", tagType)
+	}
+}
+
+func (d *rowBinaryDecoder) nullFlag() (byte, error) {
+	b, err := d.r.ReadByte()
+	return b, unexpectedEOF(err)
+}
+
+func (d *rowBinaryDecoder) count() (int, error) {
+	n, err := binary.ReadUvarint(d.r)
+	return int(n), unexpectedEOF(err)
+}
+
+func (d *rowBinaryDecoder) string() (string, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return "", err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		return "", unexpectedEOF(err)
+	}
+	return string(b), nil
+}
+
+func (d *rowBinaryDecoder) uint32() (uint32, error) {
+	if _, err := io.ReadFull(d.r, d.buf[:4]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return binary.LittleEndian.Uint32(d.buf[:4]), nil
+}
+
+func (d *rowBinaryDecoder) uint64() (uint64, error) {
+	if _, err := io.ReadFull(d.r, d.buf[:]); err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	return binary.LittleEndian.Uint64(d.buf[:]), nil
+}
+
+// unexpectedEOF reports the end of the data in the middle of a point as an
+// error, unlike the end of the data between points.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package clickhouse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+func TestRowBinaryRoundTrip(t *testing.T) {
+	ts := time.Unix(0, 1451606400000000123)
+	p := data.NewPoint()
+	p.SetMeasurementName([]byte("cpu"))
+	p.SetTimestamp(&ts)
+	p.AppendTag([]byte("hostname"), "host_0")
+	p.AppendTag([]byte("rack"), nil)
+	p.AppendTag([]byte("weight"), float32(1.5))
+	p.AppendField([]byte("usage_user"), 58.25)
+	p.AppendField([]byte("usage_system"), nil)
+	p.AppendField([]byte("usage_idle"), int64(7))
+
+	var buf bytes.Buffer
+	s := &RowBinarySerializer{}
+	for i := 0; i < 2; i++ {
+		if err := s.Serialize(p, &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	d := &rowBinaryDecoder{r: bufio.NewReader(&buf), tagTypes: []string{"string", "string", "float32"}}
+	want := &nativePoint{
+		table:     "cpu",
+		timestamp: 1451606400000000123,
+		tags:      []interface{}{"host_0", nil, float32(1.5)},
+		fields:    []interface{}{58.25, nil, float64(7)},
+	}
+	for i := 0; i < 2; i++ {
+		got, err := d.decode()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("incorrect point: got\n%+v\nwant\n%+v", got, want)
+		}
+	}
+	if _, err := d.decode(); err != io.EOF {
+		t.Errorf("expected EOF after the last point, got %v", err)
+	}
+}
+
+func TestRowBinaryDecodeErrors(t *testing.T) {
+	ts := time.Unix(0, 0)
+	p := data.NewPoint()
+	p.SetMeasurementName([]byte("cpu"))
+	p.SetTimestamp(&ts)
+	p.AppendTag([]byte("hostname"), "host_0")
+	p.AppendField([]byte("usage_user"), 1.0)
+	var buf bytes.Buffer
+	if err := (&RowBinarySerializer{}).Serialize(p, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	full := buf.Bytes()
+
+	d := &rowBinaryDecoder{r: bufio.NewReader(bytes.NewReader(full[:len(full)-3])), tagTypes: []string{"string"}}
+	if _, err := d.decode(); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected unexpected EOF for a truncated point, got %v", err)
+	}
+	d = &rowBinaryDecoder{r: bufio.NewReader(bytes.NewReader(full)), tagTypes: nil}
+	if _, err := d.decode(); err == nil {
+		t.Errorf("expected an error for more tags than the headers describe")
+	}
+}
+
+func TestRowBinarySerializeUnsupportedType(t *testing.T) {
+	ts := time.Unix(0, 0)
+	p := data.NewPoint()
+	p.SetTimestamp(&ts)
+	p.AppendTag([]byte("hostname"), struct{}{})
+	if err := (&RowBinarySerializer{}).Serialize(p, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error for an unsupported tag type")
+	}
+}
+
+func TestNativeDataSource(t *testing.T) {
+	ts := time.Unix(0, 10)
+	p := data.NewPoint()
+	p.SetMeasurementName([]byte("cpu"))
+	p.SetTimestamp(&ts)
+	p.AppendTag([]byte("hostname"), "host_0")
+	p.AppendField([]byte("usage_user"), 1.0)
+	var buf bytes.Buffer
+	buf.WriteString("tags,hostname string\ncpu,usage_user\n\n")
+	if err := (&RowBinarySerializer{}).Serialize(p, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	size := int64(buf.Len())
+	ds := &nativeDataSource{r: common.NewPositionReader(bufio.NewReader(&buf))}
+	headers := ds.Headers()
+	if got := strings.Join(headers.TagKeys, ","); got != "hostname" {
+		t.Errorf("incorrect tag keys: %s", got)
+	}
+	if got := strings.Join(headers.FieldKeys["cpu"], ","); got != "usage_user" {
+		t.Errorf("incorrect fields of cpu: %s", got)
+	}
+	item := ds.NextItem()
+	np, ok := item.Data.(*nativePoint)
+	if !ok {
+		t.Fatalf("expected a native point, got %v", item.Data)
+	}
+	if np.table != "cpu" || np.timestamp != 10 || np.tagKey() != "host_0" {
+		t.Errorf("incorrect point: %+v", np)
+	}
+	if got := ds.Position(); got != size {
+		t.Errorf("incorrect position: got %d want %d", got, size)
+	}
+	if item = ds.NextItem(); item.Data != nil {
+		t.Errorf("expected no more points, got %v", item.Data)
+	}
+}
+
+func TestInsertQuery(t *testing.T) {
+	cols := []string{"created_at", "usage_user"}
+	want := "INSERT INTO cpu (created_at,usage_user) VALUES (?,?)"
+	if got := insertQuery("cpu", cols, false); got != want {
+		t.Errorf("incorrect query: got %s want %s", got, want)
+	}
+	want = "INSERT INTO cpu (created_at,usage_user) SETTINGS async_insert=1, wait_for_async_insert=0 VALUES (?,?)"
+	if got := insertQuery("cpu", cols, true); got != want {
+		t.Errorf("incorrect async query: got %s want %s", got, want)
+	}
+}
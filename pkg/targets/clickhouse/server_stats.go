@@ -0,0 +1,28 @@
+package clickhouse
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// CollectStats returns the current metrics of system.metrics, such as the
+// running queries and merges, the background pool tasks and the memory
+// tracked, named system.metrics.<metric>.
+func (d *dbCreator) CollectStats(_ string) (map[string]float64, error) {
+	db, err := sqlx.Connect(dbType, getConnectString(d.config, false))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	var rows []struct {
+		Metric string `db:"metric"`
+		Value  int64  `db:"value"`
+	}
+	if err = db.Select(&rows, "SELECT metric, value FROM system.metrics"); err != nil {
+		return nil, err
+	}
+	stats := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		stats["system.metrics."+row.Metric] = float64(row.Value)
+	}
+	return stats, nil
+}
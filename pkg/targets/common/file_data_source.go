@@ -0,0 +1,256 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// pointsPerFile is the number of points read ahead from each file when
+// several files are read in parallel.
+const pointsPerFile = 1000
+
+// NewDataSourceFn returns the DataSource of a target reading the data from
+// br, e.g. from a file or from a SimulatorReader.
+type NewDataSourceFn func(br *bufio.Reader) (targets.DataSource, error)
+
+// NewDataSource returns the DataSource of a data source: the one of its
+// files, or the one reading the points of its simulator serialized with
+// serializer. writeHeaders should be set for the targets whose data files
+// start with headers.
+func NewDataSource(dsConfig *source.DataSourceConfig, serializer serialize.PointSerializer, writeHeaders bool, newDS NewDataSourceFn) (targets.DataSource, error) {
+	if dsConfig.Type == source.FileDataSourceType {
+		return FileDataSource(dsConfig.File, newDS)
+	}
+	br, err := DataSourceReader(dsConfig, serializer, writeHeaders)
+	if err != nil {
+		return nil, err
+	}
+	return newDS(br)
+}
+
+// FileDataSource returns the DataSource of the files of config, decompressed
+// if needed. Each file is read by its own DataSource returned by newDS. With
+// several files, each is read in its own goroutine and the points of all
+// files are returned as they are read, so the files must hold different
+// series, e.g. be generated with interleaved groups. The headers of the
+// files are assumed to be the same, the ones of the first file are returned.
+// If the DataSources of the files are targets.PositionedDataSource, the
+// returned DataSource is a targets.SeekableDataSource.
+func FileDataSource(config *source.FileDataSourceConfig, newDS NewDataSourceFn) (targets.DataSource, error) {
+	names, err := config.Files()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*dataFile, len(names))
+	positioned := true
+	for i, name := range names {
+		files[i] = &dataFile{name: name, newDS: newDS}
+		if err := files[i].open(0, 0); err != nil {
+			return nil, err
+		}
+		_, ok := files[i].ds.(targets.PositionedDataSource)
+		// STDIN cannot seek
+		positioned = positioned && ok && name != ""
+	}
+	if positioned {
+		return newFilesDataSource(files), nil
+	}
+	if len(files) == 1 {
+		return files[0].ds, nil
+	}
+	return &unseekableDataSource{newFilesDataSource(files)}, nil
+}
+
+// dataFile is a data file read by the DataSource of a target.
+type dataFile struct {
+	name   string
+	newDS  NewDataSourceFn
+	ds     targets.DataSource
+	closer io.Closer
+	// skipped is the number of bytes of the data skipped by a seek
+	skipped int64
+}
+
+// open opens the file, to read its first head bytes followed by its data
+// from position on.
+func (f *dataFile) open(head, position int64) error {
+	br, closer, err := source.OpenBufferedReaderAt(f.name, head, position)
+	if err != nil {
+		return err
+	}
+	ds, err := f.newDS(br)
+	if err != nil {
+		closer.Close()
+		return err
+	}
+	f.ds, f.closer = ds, closer
+	return nil
+}
+
+// position returns the position in the data of the file after the last item
+// read, 0 if its DataSource does not tell it.
+func (f *dataFile) position() int64 {
+	p, ok := f.ds.(targets.PositionedDataSource)
+	if !ok {
+		return 0
+	}
+	return p.Position() + f.skipped
+}
+
+// seek opens the file again to read its items from position on. The
+// headers at the start of the data are read again first.
+func (f *dataFile) seek(position int64) error {
+	f.ds.Headers()
+	head := f.position()
+	if position <= head {
+		return nil
+	}
+	f.closer.Close()
+	if err := f.open(head, position); err != nil {
+		return err
+	}
+	f.ds.Headers()
+	f.skipped = position - head
+	return nil
+}
+
+// filePoint is a point read from a file, with the position in the file
+// after it.
+type filePoint struct {
+	point    data.LoadedPoint
+	file     int
+	position int64
+}
+
+// filesDataSource reads the DataSources of files, several of them in
+// parallel.
+type filesDataSource struct {
+	files []*dataFile
+	// started is set once the first item is read
+	started bool
+	// points are the points read ahead from several files
+	points chan filePoint
+	// positions are those of the files after the last points returned,
+	// with several files
+	positions   []int64
+	headers     *common.GeneratedDataHeaders
+	headersOnce sync.Once
+	readOnce    sync.Once
+}
+
+func newFilesDataSource(files []*dataFile) *filesDataSource {
+	return &filesDataSource{
+		files:  files,
+		points: make(chan filePoint, pointsPerFile*len(files)),
+	}
+}
+
+// Headers reads the headers of all the files, which come before their
+// points, and returns the ones of the first file.
+func (d *filesDataSource) Headers() *common.GeneratedDataHeaders {
+	d.headersOnce.Do(func() {
+		for i, f := range d.files {
+			headers := f.ds.Headers()
+			if i == 0 {
+				d.headers = headers
+			}
+		}
+	})
+	return d.headers
+}
+
+// Unordered returns whether there are several files, whose points are
+// interleaved in the order they are read.
+func (d *filesDataSource) Unordered() bool {
+	return len(d.files) > 1
+}
+
+// Files returns the names of the files.
+func (d *filesDataSource) Files() []string {
+	names := make([]string, len(d.files))
+	for i, f := range d.files {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Positions appends the positions of the files after the last points
+// returned to positions.
+func (d *filesDataSource) Positions(positions []int64) []int64 {
+	if d.positions != nil {
+		return append(positions, d.positions...)
+	}
+	// the files are only read by this goroutine
+	for _, f := range d.files {
+		positions = append(positions, f.position())
+	}
+	return positions
+}
+
+// Seek opens the files again to read them from positions.
+func (d *filesDataSource) Seek(positions []int64) error {
+	if d.started {
+		return fmt.Errorf("cannot seek once the items are read")
+	}
+	if len(positions) != len(d.files) {
+		return fmt.Errorf("%d positions for %d files", len(positions), len(d.files))
+	}
+	for i, f := range d.files {
+		if err := f.seek(positions[i]); err != nil {
+			return fmt.Errorf("cannot seek in %s: %v", f.name, err)
+		}
+	}
+	return nil
+}
+
+func (d *filesDataSource) NextItem() data.LoadedPoint {
+	d.started = true
+	if len(d.files) == 1 {
+		return d.files[0].ds.NextItem()
+	}
+	d.readOnce.Do(d.read)
+	// the zero point once all the files are read
+	p := <-d.points
+	if p.point.Data != nil {
+		d.positions[p.file] = p.position
+	}
+	return p.point
+}
+
+// read starts reading the points of each file in its own goroutine.
+func (d *filesDataSource) read() {
+	d.Headers()
+	d.positions = d.Positions(nil)
+	wg := &sync.WaitGroup{}
+	wg.Add(len(d.files))
+	for i, f := range d.files {
+		go func(i int, f *dataFile) {
+			defer wg.Done()
+			for {
+				p := f.ds.NextItem()
+				if p.Data == nil {
+					return
+				}
+				d.points <- filePoint{point: p, file: i, position: f.position()}
+			}
+		}(i, f)
+	}
+	go func() {
+		wg.Wait()
+		close(d.points)
+	}()
+}
+
+// unseekableDataSource reads several files whose DataSources do not tell
+// their positions, so that they cannot seek.
+type unseekableDataSource struct {
+	targets.UnorderedDataSource
+}
@@ -0,0 +1,293 @@
+package common
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// lineDataSource returns the lines of a file after its header line, which
+// holds the tag keys.
+type lineDataSource struct {
+	scanner *LineScanner
+	headers *common.GeneratedDataHeaders
+}
+
+func newLineDataSource(br *bufio.Reader) (targets.DataSource, error) {
+	return &lineDataSource{scanner: NewLineScanner(br)}, nil
+}
+
+// newUnpositionedDataSource returns a lineDataSource which does not tell its
+// position.
+func newUnpositionedDataSource(br *bufio.Reader) (targets.DataSource, error) {
+	ds, err := newLineDataSource(br)
+	return struct{ targets.DataSource }{ds}, err
+}
+
+func (d *lineDataSource) Headers() *common.GeneratedDataHeaders {
+	if d.headers == nil && d.scanner.Scan() {
+		d.headers = &common.GeneratedDataHeaders{TagKeys: strings.Split(d.scanner.Text(), ",")}
+	}
+	return d.headers
+}
+
+func (d *lineDataSource) NextItem() data.LoadedPoint {
+	if d.headers == nil {
+		panic("headers not read before the points")
+	}
+	if !d.scanner.Scan() {
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(d.scanner.Text())
+}
+
+func (d *lineDataSource) Position() int64 {
+	return d.scanner.Position()
+}
+
+func writeTestFile(t *testing.T, name, content string) {
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		w := gzip.NewWriter(f)
+		w.Write([]byte(content))
+		err = w.Close()
+	case strings.HasSuffix(name, ".zst"):
+		w, _ := zstd.NewWriter(f)
+		w.Write([]byte(content))
+		err = w.Close()
+	default:
+		_, err = f.Write([]byte(content))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readN returns the next n items of ds, or all of them if n is negative.
+func readN(ds targets.DataSource, n int) []string {
+	var got []string
+	for ; n != 0; n-- {
+		p := ds.NextItem()
+		if p.Data == nil {
+			return got
+		}
+		got = append(got, p.Data.(string))
+	}
+	return got
+}
+
+func readAll(ds targets.DataSource) []string {
+	return readN(ds, -1)
+}
+
+func writeTestFiles(t *testing.T, dir string) {
+	writeTestFile(t, filepath.Join(dir, "data-0"), "hostname\na\nb\n")
+	writeTestFile(t, filepath.Join(dir, "data-1.gz"), "hostname\nc\nd\n")
+	writeTestFile(t, filepath.Join(dir, "data-2.zst"), "hostname\ne\n")
+}
+
+func TestFileDataSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir)
+
+	cases := []struct {
+		desc     string
+		location string
+		shard    uint
+		shards   uint
+		want     []string
+		// unordered is whether several files are read in parallel
+		unordered bool
+	}{
+		{
+			desc:     "single file",
+			location: filepath.Join(dir, "data-0"),
+			want:     []string{"a", "b"},
+		},
+		{
+			desc:     "gzip file",
+			location: filepath.Join(dir, "data-1.gz"),
+			want:     []string{"c", "d"},
+		},
+		{
+			desc:     "zstd file",
+			location: filepath.Join(dir, "data-2.zst"),
+			want:     []string{"e"},
+		},
+		{
+			desc:      "glob",
+			location:  filepath.Join(dir, "data-*"),
+			want:      []string{"a", "b", "c", "d", "e"},
+			unordered: true,
+		},
+		{
+			desc:      "list",
+			location:  filepath.Join(dir, "data-0") + "," + filepath.Join(dir, "data-2.zst"),
+			want:      []string{"a", "b", "e"},
+			unordered: true,
+		},
+		{
+			desc:      "first shard",
+			location:  filepath.Join(dir, "data-*"),
+			shard:     0,
+			shards:    2,
+			want:      []string{"a", "b", "e"},
+			unordered: true,
+		},
+		{
+			desc:     "second shard",
+			location: filepath.Join(dir, "data-*"),
+			shard:    1,
+			shards:   2,
+			want:     []string{"c", "d"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			config := &source.FileDataSourceConfig{Location: c.location, Shard: c.shard, Shards: c.shards}
+			ds, err := FileDataSource(config, newLineDataSource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if h := ds.Headers(); h == nil || h.TagKeys[0] != "hostname" {
+				t.Errorf("incorrect headers: %v", h)
+			}
+			u, ok := ds.(targets.UnorderedDataSource)
+			if unordered := ok && u.Unordered(); unordered != c.unordered {
+				t.Errorf("incorrect unordered: got %v want %v", unordered, c.unordered)
+			}
+			if _, ok := ds.(targets.SeekableDataSource); !ok {
+				t.Errorf("data source cannot seek")
+			}
+			got := readAll(ds)
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("incorrect points: got %v want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFileDataSourceSeek(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir)
+
+	cases := []struct {
+		desc     string
+		location string
+		read     int
+		want     []string
+	}{
+		{desc: "start", location: filepath.Join(dir, "data-0"), want: []string{"a", "b"}},
+		{desc: "single file", location: filepath.Join(dir, "data-0"), read: 1, want: []string{"a", "b"}},
+		{desc: "end", location: filepath.Join(dir, "data-0"), read: 2, want: []string{"a", "b"}},
+		{desc: "gzip file", location: filepath.Join(dir, "data-1.gz"), read: 1, want: []string{"c", "d"}},
+		{desc: "zstd file", location: filepath.Join(dir, "data-2.zst"), read: 1, want: []string{"e"}},
+		{desc: "glob", location: filepath.Join(dir, "data-*"), read: 3, want: []string{"a", "b", "c", "d", "e"}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			config := &source.FileDataSourceConfig{Location: c.location}
+			ds, err := FileDataSource(config, newLineDataSource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ds.Headers()
+			got := readN(ds, c.read)
+			positions := ds.(targets.SeekableDataSource).Positions(nil)
+
+			// resumed
+			ds, err = FileDataSource(config, newLineDataSource)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if h := ds.Headers(); h == nil || h.TagKeys[0] != "hostname" {
+				t.Errorf("incorrect headers: %v", h)
+			}
+			if err := ds.(targets.SeekableDataSource).Seek(positions); err != nil {
+				t.Fatalf("unexpected seek error: %v", err)
+			}
+			got = append(got, readAll(ds)...)
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(c.want, ",") {
+				t.Errorf("incorrect points: got %v want %v", got, c.want)
+			}
+			if err := ds.(targets.SeekableDataSource).Seek(positions); err == nil {
+				t.Errorf("expected an error seeking once the items are read")
+			}
+		})
+	}
+}
+
+func TestFileDataSourceUnpositioned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeTestFiles(t, dir)
+
+	for _, location := range []string{filepath.Join(dir, "data-0"), filepath.Join(dir, "data-*")} {
+		ds, err := FileDataSource(&source.FileDataSourceConfig{Location: location}, newUnpositionedDataSource)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", location, err)
+		}
+		if _, ok := ds.(targets.SeekableDataSource); ok {
+			t.Errorf("%s: data source can seek", location)
+		}
+		ds.Headers()
+		if got := readAll(ds); len(got) == 0 {
+			t.Errorf("%s: no points read", location)
+		}
+	}
+}
+
+func TestFileDataSourceErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "not-gzip.gz"), []byte("hostname\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configs := []*source.FileDataSourceConfig{
+		{Location: filepath.Join(dir, "missing")},
+		{Location: filepath.Join(dir, "missing-*")},
+		{Location: filepath.Join(dir, "not-gzip.gz")},
+		{Location: filepath.Join(dir, "not-gzip.gz"), Shard: 1, Shards: 2},
+		{Location: filepath.Join(dir, "not-gzip.gz"), Shard: 2, Shards: 2},
+		{Location: "", Shards: 2},
+	}
+	for _, config := range configs {
+		_, err := FileDataSource(config, newLineDataSource)
+		if err == nil {
+			t.Errorf("%+v: expected an error", config)
+		}
+	}
+}
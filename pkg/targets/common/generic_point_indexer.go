@@ -12,9 +12,9 @@ import (
 // used to calculate the hash
 type hashPropertySelectFn func(point *data.LoadedPoint) []byte
 
-// GenericPointIndexer implements the targets.PointIndexer
+// GenericPointIndexer implements the targets.AffinityIndexer
 // where the input for the hash function is provided
-// as an input function
+// as an input function, and is the affinity key of the point
 type GenericPointIndexer struct {
 	propertySelector hashPropertySelectFn
 	hasher           hash.Hash32
@@ -34,3 +34,8 @@ func (g *GenericPointIndexer) GetIndex(point data.LoadedPoint) uint {
 	g.hasher.Write(g.propertySelector(&point))
 	return uint(g.hasher.Sum32()) % g.maxPartitions
 }
+
+// AffinityKey returns the properties of the point the hash is calculated from
+func (g *GenericPointIndexer) AffinityKey(point data.LoadedPoint) (string, bool) {
+	return string(g.propertySelector(&point)), true
+}
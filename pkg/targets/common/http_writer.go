@@ -0,0 +1,316 @@
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/pflag"
+)
+
+// Content encodings of the body of write requests.
+const (
+	EncodingNone   = "none"
+	EncodingGzip   = "gzip"
+	EncodingSnappy = "snappy"
+	EncodingZstd   = "zstd"
+)
+
+const (
+	errUnknownEncodingFmt = "unknown content encoding '%s', choices: %s, %s, %s, %s"
+	errBackoffFmt         = "max backoff %v is smaller than backoff %v"
+
+	// maxErrorBodySize is the number of bytes of a response body read to
+	// classify it and report it in errors
+	maxErrorBodySize = 64 * 1024
+)
+
+// HTTPWriterConfig is the configuration of an HTTPWriter, usually embedded in
+// the SpecificConfig of HTTP based targets.
+type HTTPWriterConfig struct {
+	// Timeout is the timeout of each request, including reading the response.
+	Timeout time.Duration `yaml:"http-timeout" mapstructure:"http-timeout"`
+	// MaxIdleConnsPerHost is the number of keep-alive connections kept open to
+	// each host. It should be at least the number of workers.
+	MaxIdleConnsPerHost int `yaml:"max-idle-conns-per-host" mapstructure:"max-idle-conns-per-host"`
+	// IdleConnTimeout is how long an unused keep-alive connection is kept open.
+	IdleConnTimeout time.Duration `yaml:"idle-conn-timeout" mapstructure:"idle-conn-timeout"`
+	// MaxRetries is the number of times a request is retried when the server
+	// asks for backpressure or can't be reached. A negative value retries
+	// until the request succeeds.
+	MaxRetries int `yaml:"max-retries" mapstructure:"max-retries"`
+	// Backoff is the time to wait before the first retry. It doubles with
+	// each retry, up to MaxBackoff, and is jittered to spread the retries of
+	// the workers.
+	Backoff    time.Duration `yaml:"backoff" mapstructure:"backoff"`
+	MaxBackoff time.Duration `yaml:"max-backoff" mapstructure:"max-backoff"`
+	// Encoding is the content encoding of the request bodies, one of
+	// EncodingNone, EncodingGzip, EncodingSnappy or EncodingZstd.
+	Encoding string `yaml:"content-encoding" mapstructure:"content-encoding"`
+}
+
+// DefaultHTTPWriterConfig is the HTTPWriterConfig shared by the HTTP based
+// targets, so that they behave the same under backpressure.
+var DefaultHTTPWriterConfig = HTTPWriterConfig{
+	Timeout:             30 * time.Second,
+	MaxIdleConnsPerHost: 1000,
+	IdleConnTimeout:     5 * time.Minute,
+	MaxRetries:          10,
+	Backoff:             100 * time.Millisecond,
+	MaxBackoff:          10 * time.Second,
+	Encoding:            EncodingNone,
+}
+
+// AddHTTPWriterFlags adds the flags of the HTTPWriterConfig, except for the
+// content encoding, with the values of defaults as defaults.
+func AddHTTPWriterFlags(flagPrefix string, flagSet *pflag.FlagSet, defaults HTTPWriterConfig) {
+	flagSet.Duration(flagPrefix+"http-timeout", defaults.Timeout, "Timeout of each write request.")
+	flagSet.Int(flagPrefix+"max-idle-conns-per-host", defaults.MaxIdleConnsPerHost, "Number of keep-alive connections kept open to each host.")
+	flagSet.Duration(flagPrefix+"idle-conn-timeout", defaults.IdleConnTimeout, "Time after which an unused keep-alive connection is closed.")
+	flagSet.Int(flagPrefix+"max-retries", defaults.MaxRetries, "Number of times a write is retried when the server needs backpressure or is unreachable (negative = no limit).")
+	flagSet.Duration(flagPrefix+"backoff", defaults.Backoff, "Time to sleep before the first retry of a write. Doubles with each retry.")
+	flagSet.Duration(flagPrefix+"max-backoff", defaults.MaxBackoff, "Maximum time to sleep between retries of a write.")
+}
+
+// AddContentEncodingFlag adds the flag of the content encoding of the
+// HTTPWriterConfig, for targets which accept more than one encoding.
+func AddContentEncodingFlag(flagPrefix string, flagSet *pflag.FlagSet, defaultEncoding string) {
+	flagSet.String(flagPrefix+"content-encoding", defaultEncoding,
+		fmt.Sprintf("Content encoding of write requests (choices: %s, %s, %s, %s).", EncodingNone, EncodingGzip, EncodingSnappy, EncodingZstd))
+}
+
+// Validate checks the HTTPWriterConfig and fills in the defaults of unset
+// fields.
+func (c *HTTPWriterConfig) Validate() error {
+	switch c.Encoding {
+	case "":
+		c.Encoding = EncodingNone
+	case EncodingNone, EncodingGzip, EncodingSnappy, EncodingZstd:
+	default:
+		return fmt.Errorf(errUnknownEncodingFmt, c.Encoding, EncodingNone, EncodingGzip, EncodingSnappy, EncodingZstd)
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = DefaultHTTPWriterConfig.MaxIdleConnsPerHost
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultHTTPWriterConfig.Backoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultHTTPWriterConfig.MaxBackoff
+	}
+	if c.MaxBackoff < c.Backoff {
+		return fmt.Errorf(errBackoffFmt, c.MaxBackoff, c.Backoff)
+	}
+	return nil
+}
+
+// StatusClass is what a write request should lead to given its response.
+type StatusClass int
+
+const (
+	// StatusOK means the data was written.
+	StatusOK StatusClass = iota
+	// StatusRetry means the server needs backpressure, the request should be
+	// retried after backing off.
+	StatusRetry
+	// StatusFail means the request failed and retrying it will not help.
+	StatusFail
+)
+
+// StatusClassifier classifies the response of a write request from its status
+// code and (the beginning of) its body.
+type StatusClassifier func(statusCode int, body []byte) StatusClass
+
+// DefaultStatusClassifier accepts 2xx responses and retries requests on
+// 408 Request Timeout, 429 Too Many Requests and 5xx responses.
+func DefaultStatusClassifier(statusCode int, _ []byte) StatusClass {
+	switch {
+	case statusCode/100 == 2:
+		return StatusOK
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests, statusCode/100 == 5:
+		return StatusRetry
+	default:
+		return StatusFail
+	}
+}
+
+// PostStats describes how a write request went.
+type PostStats struct {
+	// Retries is the number of times the request was retried.
+	Retries int
+	// Backoff is the time spent sleeping between retries.
+	Backoff time.Duration
+}
+
+// HTTPWriter sends write requests over a pool of keep-alive connections,
+// encoding the bodies and retrying the requests with exponential backoff
+// when the server needs backpressure. It is safe for concurrent use by the
+// workers of a benchmark.
+type HTTPWriter struct {
+	conf     HTTPWriterConfig
+	client   *http.Client
+	classify StatusClassifier
+}
+
+// NewHTTPWriter creates an HTTPWriter from conf, classifying responses with
+// classify, or DefaultStatusClassifier if it is nil.
+func NewHTTPWriter(conf HTTPWriterConfig, classify StatusClassifier) (*HTTPWriter, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	if classify == nil {
+		classify = DefaultStatusClassifier
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		MaxIdleConns:          0, // no limit, only per host
+		MaxIdleConnsPerHost:   conf.MaxIdleConnsPerHost,
+		IdleConnTimeout:       conf.IdleConnTimeout,
+		DisableCompression:    true,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return &HTTPWriter{
+		conf:     conf,
+		client:   &http.Client{Transport: transport, Timeout: conf.Timeout},
+		classify: classify,
+	}, nil
+}
+
+// Post sends body to url with the headers of header, after encoding it with
+// the content encoding of the HTTPWriterConfig. Requests are retried while
+// the server needs backpressure or can't be reached, up to MaxRetries times.
+func (w *HTTPWriter) Post(url string, header http.Header, body []byte) (PostStats, error) {
+	var stats PostStats
+	encoded, release, err := encodeBody(w.conf.Encoding, body)
+	if err != nil {
+		return stats, err
+	}
+	defer release()
+
+	for {
+		class, err := w.post(url, header, encoded)
+		if class == StatusOK {
+			return stats, nil
+		}
+		if class == StatusFail {
+			return stats, err
+		}
+		if w.conf.MaxRetries >= 0 && stats.Retries >= w.conf.MaxRetries {
+			return stats, fmt.Errorf("giving up after %d retries: %v", stats.Retries, err)
+		}
+
+		d := w.backoff(stats.Retries)
+		time.Sleep(d)
+		stats.Retries++
+		stats.Backoff += d
+	}
+}
+
+func (w *HTTPWriter) post(url string, header http.Header, body []byte) (StatusClass, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return StatusFail, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if w.conf.Encoding != EncodingNone {
+		req.Header.Set("Content-Encoding", w.conf.Encoding)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// the server is unreachable or too slow to answer
+		return StatusRetry, err
+	}
+	defer func() {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	respBody, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		return StatusRetry, err
+	}
+	class := w.classify(resp.StatusCode, respBody)
+	if class == StatusOK {
+		return class, nil
+	}
+	return class, fmt.Errorf("%s returned HTTP status %d: %s", url, resp.StatusCode, respBody)
+}
+
+// backoff returns the time to sleep before the retry following retries
+// retries: the exponential backoff with "equal jitter", i.e. a random
+// duration between half of and the full backoff.
+func (w *HTTPWriter) backoff(retries int) time.Duration {
+	d := w.conf.MaxBackoff
+	if retries < 32 {
+		if exp := w.conf.Backoff << uint(retries); exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+var (
+	encodeBufPool = sync.Pool{New: func() interface{} {
+		return new(bytes.Buffer)
+	}}
+	encodeSlicePool = sync.Pool{New: func() interface{} {
+		return new([]byte)
+	}}
+	gzipWriterPool = sync.Pool{New: func() interface{} {
+		return gzip.NewWriter(nil)
+	}}
+	noRelease = func() {}
+
+	// the zstd encoder is safe for concurrent use, it is only created by
+	// targets using it
+	zstdEncoder     *zstd.Encoder
+	zstdEncoderErr  error
+	zstdEncoderOnce sync.Once
+)
+
+// encodeBody encodes body with encoding. The returned bytes are valid until
+// release is called.
+func encodeBody(encoding string, body []byte) ([]byte, func(), error) {
+	switch encoding {
+	case EncodingGzip:
+		buf := encodeBufPool.Get().(*bytes.Buffer)
+		buf.Reset()
+		zw := gzipWriterPool.Get().(*gzip.Writer)
+		zw.Reset(buf)
+		_, err := zw.Write(body)
+		if err == nil {
+			err = zw.Close()
+		}
+		gzipWriterPool.Put(zw)
+		return buf.Bytes(), func() { encodeBufPool.Put(buf) }, err
+	case EncodingSnappy:
+		bp := encodeSlicePool.Get().(*[]byte)
+		dst := snappy.Encode((*bp)[:cap(*bp)], body)
+		return dst, func() { *bp = dst; encodeSlicePool.Put(bp) }, nil
+	case EncodingZstd:
+		zstdEncoderOnce.Do(func() {
+			zstdEncoder, zstdEncoderErr = zstd.NewWriter(nil)
+		})
+		if zstdEncoderErr != nil {
+			return nil, noRelease, zstdEncoderErr
+		}
+		bp := encodeSlicePool.Get().(*[]byte)
+		dst := zstdEncoder.EncodeAll(body, (*bp)[:0])
+		return dst, func() { *bp = dst; encodeSlicePool.Put(bp) }, nil
+	default:
+		return body, noRelease, nil
+	}
+}
@@ -0,0 +1,200 @@
+package common
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+func testWriterConfig() HTTPWriterConfig {
+	conf := DefaultHTTPWriterConfig
+	conf.Backoff = time.Millisecond
+	conf.MaxBackoff = 4 * time.Millisecond
+	conf.MaxRetries = 3
+	return conf
+}
+
+func TestHTTPWriterConfigValidate(t *testing.T) {
+	cases := []struct {
+		desc    string
+		conf    HTTPWriterConfig
+		wantErr bool
+	}{
+		{desc: "zero value", conf: HTTPWriterConfig{}},
+		{desc: "defaults", conf: DefaultHTTPWriterConfig},
+		{desc: "zstd", conf: HTTPWriterConfig{Encoding: EncodingZstd}},
+		{desc: "unknown encoding", conf: HTTPWriterConfig{Encoding: "lz4"}, wantErr: true},
+		{desc: "max backoff too small", conf: HTTPWriterConfig{Backoff: time.Second, MaxBackoff: time.Millisecond}, wantErr: true},
+	}
+	for _, c := range cases {
+		err := c.conf.Validate()
+		if c.wantErr && err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if !c.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+	}
+}
+
+func TestDefaultStatusClassifier(t *testing.T) {
+	cases := map[int]StatusClass{
+		http.StatusOK:                  StatusOK,
+		http.StatusNoContent:           StatusOK,
+		http.StatusBadRequest:          StatusFail,
+		http.StatusNotFound:            StatusFail,
+		http.StatusRequestTimeout:      StatusRetry,
+		http.StatusTooManyRequests:     StatusRetry,
+		http.StatusInternalServerError: StatusRetry,
+		http.StatusServiceUnavailable:  StatusRetry,
+	}
+	for code, want := range cases {
+		if got := DefaultStatusClassifier(code, nil); got != want {
+			t.Errorf("incorrect class for status %d: got %d want %d", code, got, want)
+		}
+	}
+}
+
+func TestHTTPWriterPostRetries(t *testing.T) {
+	var requests int64
+	failures := int64(2)
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w, err := NewHTTPWriter(testWriterConfig(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// backpressure twice, then success
+	stats, err := w.Post(server.URL, nil, []byte("body"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if stats.Retries != 2 || stats.Backoff <= 0 {
+		t.Errorf("incorrect stats: got %+v want 2 retries", stats)
+	}
+
+	// more backpressure than retries
+	atomic.StoreInt64(&requests, 0)
+	failures = 10
+	stats, err = w.Post(server.URL, nil, []byte("body"))
+	if err == nil {
+		t.Errorf("unexpected lack of error after too many retries")
+	}
+	if stats.Retries != 3 {
+		t.Errorf("incorrect number of retries: got %d want 3", stats.Retries)
+	}
+
+	// failure is not retried
+	atomic.StoreInt64(&requests, 0)
+	status = http.StatusBadRequest
+	stats, err = w.Post(server.URL, nil, []byte("body"))
+	if err == nil {
+		t.Errorf("unexpected lack of error for bad request")
+	}
+	if stats.Retries != 0 || atomic.LoadInt64(&requests) != 1 {
+		t.Errorf("bad request retried: %d retries, %d requests", stats.Retries, requests)
+	}
+}
+
+func TestHTTPWriterPostEncoding(t *testing.T) {
+	body := bytes.Repeat([]byte("cpu,hostname=host_0 usage_user=58 1451606400000000000\n"), 100)
+	decoders := map[string]func([]byte) ([]byte, error){
+		EncodingNone: func(b []byte) ([]byte, error) { return b, nil },
+		EncodingGzip: func(b []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(b))
+			if err != nil {
+				return nil, err
+			}
+			return ioutil.ReadAll(r)
+		},
+		EncodingSnappy: func(b []byte) ([]byte, error) { return snappy.Decode(nil, b) },
+		EncodingZstd: func(b []byte) ([]byte, error) {
+			r, err := zstd.NewReader(nil)
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return r.DecodeAll(b, nil)
+		},
+	}
+
+	for encoding, decode := range decoders {
+		var got []byte
+		var gotEncoding, gotType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotEncoding = r.Header.Get("Content-Encoding")
+			gotType = r.Header.Get("Content-Type")
+			b, err := ioutil.ReadAll(r.Body)
+			if err == nil {
+				got, err = decode(b)
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		conf := testWriterConfig()
+		conf.Encoding = encoding
+		w, err := NewHTTPWriter(conf, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// twice to reuse the pooled buffers
+		for i := 0; i < 2; i++ {
+			got = nil
+			_, err = w.Post(server.URL, http.Header{"Content-Type": {"text/plain"}}, body)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", encoding, err)
+			}
+			if !bytes.Equal(got, body) {
+				t.Errorf("%s: incorrect decoded body of %d bytes, want %d bytes", encoding, len(got), len(body))
+			}
+		}
+		if encoding == EncodingNone && gotEncoding != "" {
+			t.Errorf("%s: unexpected Content-Encoding %s", encoding, gotEncoding)
+		} else if encoding != EncodingNone && gotEncoding != encoding {
+			t.Errorf("%s: incorrect Content-Encoding: got %s", encoding, gotEncoding)
+		}
+		if gotType != "text/plain" {
+			t.Errorf("%s: incorrect Content-Type: got %s", encoding, gotType)
+		}
+		server.Close()
+	}
+}
+
+func TestHTTPWriterBackoff(t *testing.T) {
+	conf := HTTPWriterConfig{Backoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	w, err := NewHTTPWriter(conf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for retries, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		for i := 0; i < 100; i++ {
+			if d := w.backoff(retries); d < max/2 || d > max {
+				t.Fatalf("backoff of retry %d out of [%v, %v]: %v", retries, max/2, max, d)
+			}
+		}
+	}
+	if d := w.backoff(100); d < conf.MaxBackoff/2 || d > conf.MaxBackoff {
+		t.Errorf("backoff not capped after many retries: %v", d)
+	}
+}
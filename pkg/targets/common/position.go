@@ -0,0 +1,73 @@
+package common
+
+import (
+	"bufio"
+	"io"
+)
+
+// LineScanner is a bufio.Scanner of lines which counts the bytes of the lines
+// it scanned, so that the DataSource scanning them is a
+// targets.PositionedDataSource.
+type LineScanner struct {
+	*bufio.Scanner
+	position int64
+}
+
+// NewLineScanner returns the LineScanner of the lines of r.
+func NewLineScanner(r io.Reader) *LineScanner {
+	s := &LineScanner{Scanner: bufio.NewScanner(r)}
+	s.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		s.position += int64(advance)
+		return advance, token, err
+	})
+	return s
+}
+
+// Position returns the number of bytes of the lines scanned, new lines
+// included.
+func (s *LineScanner) Position() int64 {
+	return s.position
+}
+
+// PositionReader reads from a bufio.Reader and counts the bytes read, so that
+// the DataSource reading them is a targets.PositionedDataSource.
+type PositionReader struct {
+	r        *bufio.Reader
+	position int64
+}
+
+// NewPositionReader returns the PositionReader of r.
+func NewPositionReader(r *bufio.Reader) *PositionReader {
+	return &PositionReader{r: r}
+}
+
+func (r *PositionReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.position += int64(n)
+	return n, err
+}
+
+func (r *PositionReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.position++
+	}
+	return b, err
+}
+
+func (r *PositionReader) ReadString(delim byte) (string, error) {
+	s, err := r.r.ReadString(delim)
+	r.position += int64(len(s))
+	return s, err
+}
+
+// Peek returns the next n bytes without reading them.
+func (r *PositionReader) Peek(n int) ([]byte, error) {
+	return r.r.Peek(n)
+}
+
+// Position returns the number of bytes read.
+func (r *PositionReader) Position() int64 {
+	return r.position
+}
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestLineScanner(t *testing.T) {
+	s := NewLineScanner(strings.NewReader("first\n\nthird\nlast"))
+	want := []struct {
+		line     string
+		position int64
+	}{
+		{"first", 6},
+		{"", 7},
+		{"third", 13},
+		{"last", 17},
+	}
+	for _, w := range want {
+		if !s.Scan() {
+			t.Fatalf("scan ended before %q", w.line)
+		}
+		if s.Text() != w.line || s.Position() != w.position {
+			t.Errorf("incorrect line: got %q at %d want %q at %d", s.Text(), s.Position(), w.line, w.position)
+		}
+	}
+	if s.Scan() {
+		t.Errorf("unexpected line %q", s.Text())
+	}
+}
+
+func TestPositionReader(t *testing.T) {
+	r := NewPositionReader(bufio.NewReader(strings.NewReader("header\n\x96\x01body")))
+	if line, err := r.ReadString('\n'); line != "header\n" || err != nil {
+		t.Errorf("incorrect line %q: %v", line, err)
+	}
+	if b, err := r.Peek(1); len(b) != 1 || err != nil {
+		t.Errorf("incorrect peek %v: %v", b, err)
+	}
+	if r.Position() != 7 {
+		t.Errorf("incorrect position after the line: %d", r.Position())
+	}
+	if n, err := binary.ReadUvarint(r); n != 150 || err != nil {
+		t.Errorf("incorrect uvarint %d: %v", n, err)
+	}
+	if r.Position() != 9 {
+		t.Errorf("incorrect position after the uvarint: %d", r.Position())
+	}
+	if b, err := ioutil.ReadAll(io.Reader(r)); string(b) != "body" || err != nil {
+		t.Errorf("incorrect body %q: %v", b, err)
+	}
+	if r.Position() != 13 {
+		t.Errorf("incorrect position at the end: %d", r.Position())
+	}
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorReader is an io.Reader of the points of a simulator, serialized
+// as tsbs_generate_data would write them. It allows a target to read the
+// simulator with the same code it reads its data files with.
+type SimulatorReader struct {
+	sim        common.Simulator
+	serializer serialize.PointSerializer
+	point      *data.Point
+	buf        bytes.Buffer
+	err        error
+}
+
+// NewSimulatorReader returns a SimulatorReader of sim. With writeHeaders the
+// headers of sim are written first, as data files with headers start with.
+func NewSimulatorReader(sim common.Simulator, serializer serialize.PointSerializer, writeHeaders bool) *SimulatorReader {
+	r := &SimulatorReader{
+		sim:        sim,
+		serializer: serializer,
+		point:      data.NewPoint(),
+	}
+	if writeHeaders {
+		r.err = sim.Headers().Write(&r.buf)
+	}
+	return r
+}
+
+// NewBufferedSimulatorReader is NewSimulatorReader wrapped in a bufio.Reader,
+// as returned by source.OpenBufferedReader for files.
+func NewBufferedSimulatorReader(sim common.Simulator, serializer serialize.PointSerializer, writeHeaders bool) *bufio.Reader {
+	return bufio.NewReaderSize(NewSimulatorReader(sim, serializer, writeHeaders), 4<<20)
+}
+
+// Read serializes the next points of the simulator into p.
+func (r *SimulatorReader) Read(p []byte) (int, error) {
+	for r.buf.Len() < len(p) && r.err == nil {
+		if r.sim.Finished() {
+			r.err = io.EOF
+			break
+		}
+		if r.sim.Next(r.point) {
+			r.err = r.serializer.Serialize(r.point, &r.buf)
+		}
+		r.point.Reset()
+	}
+	if r.buf.Len() > 0 {
+		return r.buf.Read(p)
+	}
+	return 0, r.err
+}
+
+// DataSourceReader returns a reader of the data of a data source: its file,
+// or the points of its simulator serialized with serializer. writeHeaders
+// should be set for the targets whose data files start with headers. Use
+// NewDataSource for the data sources of several files.
+func DataSourceReader(dsConfig *source.DataSourceConfig, serializer serialize.PointSerializer, writeHeaders bool) (*bufio.Reader, error) {
+	if dsConfig.Type == source.FileDataSourceType {
+		files, err := dsConfig.File.Files()
+		if err != nil {
+			return nil, err
+		}
+		if len(files) > 1 {
+			return nil, fmt.Errorf("a single file can be read, %s has %d", dsConfig.File.Location, len(files))
+		}
+		return source.OpenBufferedReader(files[0])
+	}
+	simulator, err := usecases.NewSimulator(dsConfig.Simulator)
+	if err != nil {
+		return nil, err
+	}
+	return NewBufferedSimulatorReader(simulator, serializer, writeHeaders), nil
+}
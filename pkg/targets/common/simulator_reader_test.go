@@ -0,0 +1,123 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+var keyIteration = []byte("iteration")
+
+type testSimulator struct {
+	limit            uint64
+	shouldWriteLimit uint64
+	iteration        uint64
+}
+
+func (s *testSimulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
+
+func (s *testSimulator) Finished() bool {
+	return s.iteration >= s.limit
+}
+
+func (s *testSimulator) Next(p *data.Point) bool {
+	p.AppendField(keyIteration, s.iteration)
+	ret := s.iteration < s.shouldWriteLimit
+	s.iteration++
+	return ret
+}
+
+func (s *testSimulator) Fields() map[string][]string {
+	return map[string][]string{"cpu": {"usage_user"}}
+}
+
+func (s *testSimulator) TagKeys() []string {
+	return []string{"hostname"}
+}
+
+func (s *testSimulator) TagTypes() []string {
+	return []string{"string"}
+}
+
+type testSerializer struct {
+	shouldError bool
+}
+
+func (s *testSerializer) Serialize(p *data.Point, w io.Writer) error {
+	if s.shouldError {
+		return fmt.Errorf("erroring")
+	}
+	_, err := fmt.Fprintf(w, "%s=%d\n", keyIteration, p.GetFieldValue(keyIteration).(uint64))
+	return err
+}
+
+func TestSimulatorReader(t *testing.T) {
+	cases := []struct {
+		desc         string
+		sim          *testSimulator
+		writeHeaders bool
+		want         string
+	}{
+		{
+			desc: "all points",
+			sim:  &testSimulator{limit: 3, shouldWriteLimit: 3},
+			want: "iteration=0\niteration=1\niteration=2\n",
+		},
+		{
+			desc: "skipped points",
+			sim:  &testSimulator{limit: 3, shouldWriteLimit: 1},
+			want: "iteration=0\n",
+		},
+		{
+			desc:         "with headers",
+			sim:          &testSimulator{limit: 1, shouldWriteLimit: 1},
+			writeHeaders: true,
+			want:         "tags,hostname string\ncpu,usage_user\n\niteration=0\n",
+		},
+	}
+	for _, c := range cases {
+		r := NewSimulatorReader(c.sim, &testSerializer{}, c.writeHeaders)
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+		if string(got) != c.want {
+			t.Errorf("%s: incorrect output: got\n%q\nwant\n%q", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestSimulatorReaderSmallReads(t *testing.T) {
+	r := NewSimulatorReader(&testSimulator{limit: 2, shouldWriteLimit: 2}, &testSerializer{}, false)
+	buf := make([]byte, 4)
+	var got []byte
+	for {
+		n, err := r.Read(buf)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if want := "iteration=0\niteration=1\n"; string(got) != want {
+		t.Errorf("incorrect output: got %q want %q", got, want)
+	}
+}
+
+func TestSimulatorReaderSerializeError(t *testing.T) {
+	r := NewSimulatorReader(&testSimulator{limit: 2, shouldWriteLimit: 2}, &testSerializer{shouldError: true}, false)
+	if _, err := ioutil.ReadAll(r); err == nil {
+		t.Errorf("expected the error of the serializer")
+	}
+}
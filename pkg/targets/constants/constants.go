@@ -2,24 +2,26 @@ package constants
 
 // Formats supported for generation
 const (
-	FormatCassandra       = "cassandra"
-	FormatClickhouse      = "clickhouse"
-	FormatInflux          = "influx"
-	FormatMongo           = "mongo"
-	FormatSiriDB          = "siridb"
-	FormatTimescaleDB     = "timescaledb"
-	FormatAkumuli         = "akumuli"
-	FormatCrateDB         = "cratedb"
-	FormatPrometheus      = "prometheus"
-	FormatVictoriaMetrics = "victoriametrics"
-	FormatTimestream      = "timestream"
-	FormatQuestDB         = "questdb"
+	FormatCassandra        = "cassandra"
+	FormatClickhouse       = "clickhouse"
+	FormatClickhouseNative = "clickhouse-native"
+	FormatInflux           = "influx"
+	FormatMongo            = "mongo"
+	FormatSiriDB           = "siridb"
+	FormatTimescaleDB      = "timescaledb"
+	FormatAkumuli          = "akumuli"
+	FormatCrateDB          = "cratedb"
+	FormatPrometheus       = "prometheus"
+	FormatVictoriaMetrics  = "victoriametrics"
+	FormatTimestream       = "timestream"
+	FormatQuestDB          = "questdb"
 )
 
 func SupportedFormats() []string {
 	return []string{
 		FormatCassandra,
 		FormatClickhouse,
+		FormatClickhouseNative,
 		FormatInflux,
 		FormatMongo,
 		FormatSiriDB,
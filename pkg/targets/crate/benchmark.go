@@ -0,0 +1,94 @@
+package crate
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+
+	"github.com/blagojts/viper"
+	"github.com/jackc/pgx/v4"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// the logger is used in implementations of interface methods that
+// do not return error on failures to allow testing such methods
+var fatal = log.Fatalf
+
+// SpecificConfig is the configuration of the CrateDB loader.
+type SpecificConfig struct {
+	Hosts    string `yaml:"hosts" mapstructure:"hosts"`
+	Port     uint   `yaml:"port" mapstructure:"port"`
+	User     string `yaml:"user" mapstructure:"user"`
+	Pass     string `yaml:"pass" mapstructure:"pass"`
+	Replicas int    `yaml:"replicas" mapstructure:"replicas"`
+	Shards   int    `yaml:"shards" mapstructure:"shards"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+type benchmark struct {
+	dbc *dbCreator
+	ds  targets.DataSource
+}
+
+// NewBenchmark returns the benchmark loading CrateDB with the data of the
+// data source. The tables are described by the headers of the data.
+func NewBenchmark(conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=doc", conf.Hosts, conf.Port, conf.User, conf.Pass)
+	connConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse connection config: %v", err)
+	}
+	ds, err := common.NewDataSource(dataSourceConfig, &Serializer{}, true, func(br *bufio.Reader) (targets.DataSource, error) {
+		return &fileDataSource{scanner: common.NewLineScanner(br)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO implement or check if anything has to be done to support WorkerPerQueue mode
+	return &benchmark{
+		dbc: &dbCreator{
+			cfg:         connConfig,
+			numReplicas: conf.Replicas,
+			numShards:   conf.Shards,
+			ds:          ds,
+		},
+		ds: ds,
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	tableDefs := make(map[string]*tableDef)
+	for _, td := range b.dbc.tableDefs {
+		tableDefs[td.name] = td
+	}
+	return &processor{
+		tableDefs: tableDefs,
+		connCfg:   b.dbc.cfg,
+	}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return b.dbc
+}
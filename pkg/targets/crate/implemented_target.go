@@ -5,11 +5,12 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &crateTarget{}
 }
 
@@ -33,6 +34,26 @@ func (t *crateTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *crateTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *crateTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(conf, dataSourceConfig)
+}
+
+func (t *crateTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"hosts", "localhost", "CrateDB hostnames")
+	flagSet.String(flagPrefix+"user", "crate", "User to connect to CrateDB")
+	flagSet.String(flagPrefix+"pass", "", "Password for user connecting to CrateDB")
+	flagSet.Int(flagPrefix+"port", 5432, "A port to connect to database instances")
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+}
+
+func (t *crateTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
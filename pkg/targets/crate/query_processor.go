@@ -0,0 +1,151 @@
+package crate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/jackc/pgx/v4"
+	"github.com/pkg/errors"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the CrateDB query runner.
+type QuerySpecificConfig struct {
+	Hosts       string `yaml:"hosts" mapstructure:"hosts"`
+	User        string `yaml:"user" mapstructure:"user"`
+	Pass        string `yaml:"pass" mapstructure:"pass"`
+	Port        int    `yaml:"port" mapstructure:"port"`
+	ShowExplain bool   `yaml:"show-explain" mapstructure:"show-explain"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to CrateDB over its PostgreSQL wire protocol. With ShowExplain set only one
+// query is run.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=%s", conf.Hosts, conf.Port, conf.User, conf.Pass, runner.DatabaseName())
+	connConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse connection config")
+	}
+	if conf.ShowExplain {
+		runner.SetLimit(1)
+	}
+	opts := &queryExecutorOptions{
+		showExplain:   conf.ShowExplain,
+		debug:         runner.DebugLevel() > 0,
+		printResponse: runner.DoPrintResponses(),
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.CrateDBPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{connCfg: connConfig, opts: opts}
+		},
+	}, nil
+}
+
+type queryProcessor struct {
+	conn    *pgx.Conn
+	connCfg *pgx.ConnConfig
+	opts    *queryExecutorOptions
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	conn, err := pgx.ConnectConfig(context.Background(), p.connCfg)
+	if err != nil {
+		panic(err)
+	}
+	p.conn = conn
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+	tq := q.(*query.CrateDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.showExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.conn.Query(context.Background(), qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+	if p.opts.showExplain {
+		fmt.Printf("Explian Query:\n")
+		prettyPrintResponse(rows, tq)
+		fmt.Printf("\n-----------\n\n")
+	} else if p.opts.printResponse {
+		prettyPrintResponse(rows, tq)
+	}
+	defer rows.Close()
+
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(rows pgx.Rows, q *query.CrateDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = mapRows(rows)
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(r pgx.Rows) []map[string]interface{} {
+	var rows []map[string]interface{}
+	cols := r.FieldDescriptions()
+	for r.Next() {
+		row := make(map[string]interface{})
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+
+		err := r.Scan(values...)
+		if err != nil {
+			panic(errors.Wrap(err, "error while reading values"))
+		}
+
+		for i, column := range cols {
+			row[string(column.Name)] = *values[i].(*interface{})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -1,7 +1,6 @@
-package main
+package crate
 
 import (
-	"bufio"
 	"strconv"
 	"strings"
 	"sync"
@@ -10,6 +9,7 @@ import (
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 )
 
 type row = []interface{}
@@ -53,14 +53,15 @@ func (f *factory) New() targets.Batch {
 
 // source.DataSource interface implementation
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targetsCommon.LineScanner
 	headers *common.GeneratedDataHeaders
 }
 
 // source.DataSource interface implementation
 //
 // Decodes a data point of a following format:
-//       <measurement_type>\t<tags>\t<timestamp>\t<metric1>\t...\t<metricN>
+//
+//	<measurement_type>\t<tags>\t<timestamp>\t<metric1>\t...\t<metricN>
 //
 // Converts metric values to double-precision floating-point number, timestamp
 // to time.Time and tags to bytes array.
@@ -100,6 +101,11 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	return data.NewLoadedPoint(&point{table: table, row: row})
 }
 
+// Position returns the number of bytes of the data scanned.
+func (d *fileDataSource) Position() int64 {
+	return d.scanner.Position()
+}
+
 // cratedb file format doesn't have headers
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	if d.headers != nil {
@@ -34,3 +34,36 @@ type DBCreatorPost interface {
 	// PostCreateDB does further initialization after the database is created
 	PostCreateDB(dbName string) error
 }
+
+// DBCreatorPostLoad is a DBCreator that also needs to do some work on the loaded
+// data once loading has finished (e.g., compressing it). The time it takes is
+// not part of the load.
+type DBCreatorPostLoad interface {
+	DBCreator
+
+	// PostLoad is called after all workers are done and before Report
+	PostLoad(dbName string) error
+}
+
+// DBCreatorReporter is a DBCreator that can describe the state of the database
+// once loading has finished (e.g., how data was distributed across a cluster).
+// The returned values are added to the results file under TargetReport.
+type DBCreatorReporter interface {
+	DBCreator
+
+	// Report is called after all workers are done and before Close
+	Report(dbName string) (map[string]interface{}, error)
+}
+
+// StatsCollector is implemented by the DBCreator of a target which can sample
+// metrics of the database server during the load (e.g., its operation
+// counters or cache usage). With server stats on, the loader collects them
+// every reporting period and adds them, with the throughput of the loader,
+// to the results file under ServerStats.
+type StatsCollector interface {
+	// CollectStats returns the current metrics of the server by name.
+	// Counters are returned as the server reports them, cumulated since it
+	// started. It is called concurrently with the workers, after Init and
+	// before Close
+	CollectStats(dbName string) (map[string]float64, error)
+}
@@ -0,0 +1,190 @@
+package influx
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// allows for testing
+var fatal = log.Fatalf
+
+var consistencyChoices = map[string]struct{}{
+	"any":    {},
+	"one":    {},
+	"quorum": {},
+	"all":    {},
+}
+
+var precisionChoices = map[string]struct{}{
+	"ns": {},
+	"us": {},
+	"ms": {},
+	"s":  {},
+}
+
+// Line protocol transports
+const (
+	TransportHTTP = "http"
+	TransportUDP  = "udp"
+	TransportTCP  = "tcp"
+)
+
+// defaultUDPPayloadSize fits a UDP datagram in the MTU of an Ethernet link.
+const defaultUDPPayloadSize = 1400
+
+// defaultHTTPWriterConfig is the common HTTPWriterConfig, except that writes
+// are retried until they succeed, as InfluxDB can stall for a long time
+// while it compacts its cache.
+var defaultHTTPWriterConfig = func() common.HTTPWriterConfig {
+	c := common.DefaultHTTPWriterConfig
+	c.MaxRetries = -1
+	return c
+}()
+
+// SpecificConfig is the configuration of the InfluxDB loader.
+type SpecificConfig struct {
+	URLs              []string `yaml:"urls" mapstructure:"urls"`
+	ReplicationFactor int      `yaml:"replication-factor" mapstructure:"replication-factor"`
+	Consistency       string   `yaml:"consistency" mapstructure:"consistency"`
+	Gzip              bool     `yaml:"gzip" mapstructure:"gzip"`
+	APIVersion        int      `yaml:"api-version" mapstructure:"api-version"`
+	Org               string   `yaml:"org" mapstructure:"org"`
+	Token             string   `yaml:"token" mapstructure:"token"`
+	Precision         string   `yaml:"precision" mapstructure:"precision"`
+
+	// Transport is the transport of the writes. The udp and tcp transports
+	// write to SocketAddresses instead of URLs, which are then only used to
+	// create the database.
+	Transport       string   `yaml:"transport" mapstructure:"transport"`
+	SocketAddresses []string `yaml:"socket-addresses" mapstructure:"socket-addresses"`
+	UDPPayloadSize  int      `yaml:"udp-payload-size" mapstructure:"udp-payload-size"`
+
+	common.HTTPWriterConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// Validate checks the choices of the config and fills in the defaults of
+// unset fields.
+func (c *SpecificConfig) Validate() error {
+	if len(c.URLs) == 0 || c.URLs[0] == "" {
+		return fmt.Errorf("missing 'urls' flag")
+	}
+	if _, ok := consistencyChoices[c.Consistency]; !ok {
+		return fmt.Errorf("invalid consistency settings")
+	}
+	if c.APIVersion != 1 && c.APIVersion != 2 {
+		return fmt.Errorf("invalid api-version %d: must be 1 or 2", c.APIVersion)
+	}
+	if _, ok := precisionChoices[c.Precision]; !ok {
+		return fmt.Errorf("invalid precision settings")
+	}
+	if c.APIVersion == 2 && c.Org == "" {
+		return fmt.Errorf("missing 'org' flag, required by api-version 2")
+	}
+	switch c.Transport {
+	case "":
+		c.Transport = TransportHTTP
+	case TransportHTTP:
+	case TransportUDP, TransportTCP:
+		if c.APIVersion != 1 {
+			return fmt.Errorf("transport %s only supports api-version 1", c.Transport)
+		}
+		if len(c.SocketAddresses) == 0 || c.SocketAddresses[0] == "" {
+			return fmt.Errorf("missing 'socket-addresses' flag, required by transport %s", c.Transport)
+		}
+		if c.UDPPayloadSize == 0 {
+			c.UDPPayloadSize = defaultUDPPayloadSize
+		}
+		if c.UDPPayloadSize < 0 {
+			return fmt.Errorf("invalid udp-payload-size %d: must be positive", c.UDPPayloadSize)
+		}
+	default:
+		return fmt.Errorf("invalid transport '%s': must be one of %s, %s, %s", c.Transport, TransportHTTP, TransportUDP, TransportTCP)
+	}
+	return nil
+}
+
+type benchmark struct {
+	dbName     string
+	conf       *SpecificConfig
+	ds         targets.DataSource
+	httpWriter *common.HTTPWriter
+	bufPool    *sync.Pool
+}
+
+// NewBenchmark returns the benchmark loading InfluxDB with the line protocol
+// of the data source into database (or bucket) dbName.
+func NewBenchmark(dbName string, conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	writerConfig := conf.HTTPWriterConfig
+	if conf.Gzip {
+		writerConfig.Encoding = common.EncodingGzip
+	}
+	httpWriter, err := common.NewHTTPWriter(writerConfig, statusClassifier)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := common.NewDataSource(dataSourceConfig, &Serializer{}, false, func(br *bufio.Reader) (targets.DataSource, error) {
+		return newFileDataSource(br), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &benchmark{
+		dbName:     dbName,
+		conf:       conf,
+		ds:         ds,
+		httpWriter: httpWriter,
+		bufPool: &sync.Pool{
+			New: func() interface{} {
+				return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+			},
+		},
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{
+		dbName:     b.dbName,
+		conf:       b.conf,
+		bufPool:    b.bufPool,
+		httpWriter: b.httpWriter,
+	}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	// pick first url since it always exists
+	if b.conf.APIVersion == 2 {
+		return &bucketCreator{daemonURL: b.conf.URLs[0], org: b.conf.Org, token: b.conf.Token}
+	}
+	return &dbCreator{daemonURL: b.conf.URLs[0], replicationFactor: b.conf.ReplicationFactor}
+}
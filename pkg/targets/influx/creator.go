@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"encoding/json"
@@ -11,12 +11,11 @@ import (
 )
 
 type dbCreator struct {
-	daemonURL string
+	daemonURL         string
+	replicationFactor int
 }
 
-func (d *dbCreator) Init() {
-	d.daemonURL = daemonURLs[0] // pick first one since it always exists
-}
+func (d *dbCreator) Init() {}
 
 func (d *dbCreator) DBExists(dbName string) bool {
 	dbs, err := d.listDatabases()
@@ -25,7 +24,7 @@ func (d *dbCreator) DBExists(dbName string) bool {
 	}
 
 	for _, db := range dbs {
-		if db == loader.DatabaseName() {
+		if db == dbName {
 			return true
 		}
 	}
@@ -95,7 +94,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	u.Path = "query"
 	v := u.Query()
 	v.Set("consistency", "all")
-	v.Set("q", fmt.Sprintf("CREATE DATABASE %s WITH REPLICATION %d", dbName, replicationFactor))
+	v.Set("q", fmt.Sprintf("CREATE DATABASE %s WITH REPLICATION %d", dbName, d.replicationFactor))
 	u.RawQuery = v.Encode()
 
 	req, err := http.NewRequest("GET", u.String(), nil)
@@ -0,0 +1,139 @@
+package influx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// bucketCreator creates the bucket points are written to through the
+// InfluxDB 2.x API.
+type bucketCreator struct {
+	daemonURL string
+	org       string
+	token     string
+	client    *http.Client
+}
+
+func (d *bucketCreator) Init() {
+	d.client = &http.Client{}
+}
+
+func (d *bucketCreator) DBExists(dbName string) bool {
+	id, err := d.bucketID(dbName)
+	if err != nil {
+		fatal("%v", err)
+	}
+	return id != ""
+}
+
+func (d *bucketCreator) RemoveOldDB(dbName string) error {
+	id, err := d.bucketID(dbName)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return nil
+	}
+	if err := d.do("DELETE", "/api/v2/buckets/"+id, nil, nil); err != nil {
+		return fmt.Errorf("drop bucket error: %v", err)
+	}
+	time.Sleep(time.Second)
+	return nil
+}
+
+func (d *bucketCreator) CreateDB(dbName string) error {
+	orgID, err := d.orgID()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"orgID": orgID, "name": dbName})
+	if err != nil {
+		return err
+	}
+	if err := d.do("POST", "/api/v2/buckets", body, nil); err != nil {
+		return fmt.Errorf("create bucket error: %v", err)
+	}
+	time.Sleep(time.Second)
+	return nil
+}
+
+// orgID returns the ID of the organization named by the org flag.
+func (d *bucketCreator) orgID() (string, error) {
+	var listing struct {
+		Orgs []struct {
+			ID string `json:"id"`
+		} `json:"orgs"`
+	}
+	if err := d.do("GET", "/api/v2/orgs?org="+url.QueryEscape(d.org), nil, &listing); err != nil {
+		return "", fmt.Errorf("list orgs error: %v", err)
+	}
+	if len(listing.Orgs) == 0 {
+		return "", fmt.Errorf("org %s not found", d.org)
+	}
+	return listing.Orgs[0].ID, nil
+}
+
+// bucketID returns the ID of the bucket named name in the org, or an empty
+// string if it does not exist.
+func (d *bucketCreator) bucketID(name string) (string, error) {
+	var listing struct {
+		Buckets []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"buckets"`
+	}
+	v := url.Values{}
+	v.Set("org", d.org)
+	v.Set("name", name)
+	if err := d.do("GET", "/api/v2/buckets?"+v.Encode(), nil, &listing); err != nil {
+		return "", fmt.Errorf("list buckets error: %v", err)
+	}
+	for _, b := range listing.Buckets {
+		if b.Name == name {
+			return b.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// do sends a request to the v2 API, decoding the JSON response into out
+// when it is not nil.
+func (d *bucketCreator) do(method, path string, body []byte, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, d.daemonURL+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if d.token != "" {
+		req.Header.Set("Authorization", "Token "+d.token)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, respBody)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, out)
+}
@@ -0,0 +1,130 @@
+package influx
+
+// This file lifted wholesale from mountainflux by Mark Rushakoff.
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+var (
+	backoffMagicWords0  = []byte("engine: cache maximum memory size exceeded")
+	backoffMagicWords1  = []byte("write failed: hinted handoff queue not empty")
+	backoffMagicWords2a = []byte("write failed: read message type: read tcp")
+	backoffMagicWords2b = []byte("i/o timeout")
+	backoffMagicWords3  = []byte("write failed: engine: cache-max-memory-size exceeded")
+	backoffMagicWords4  = []byte("timeout")
+	backoffMagicWords5  = []byte("write failed: can not exceed max connections of 500")
+)
+
+var lineProtocolHeader = http.Header{"Content-Type": {"text/plain"}}
+
+// HTTPWriterConfig is the configuration used to create an HTTPWriter.
+type HTTPWriterConfig struct {
+	// URL of the host, in form "http://example.com:8086"
+	Host string
+
+	// Name of the target database into which points will be written. With
+	// the v2 API it is the name of the bucket.
+	Database string
+
+	// APIVersion is the InfluxDB write API used: 1 for /write, 2 for
+	// /api/v2/write.
+	APIVersion int
+
+	// Org, Token and Precision are only used by the v2 API.
+	Org       string
+	Token     string
+	Precision string
+
+	// Debug label for more informative errors.
+	DebugInfo string
+}
+
+// HTTPWriter is a Writer that writes to an InfluxDB HTTP server.
+type HTTPWriter struct {
+	writer *common.HTTPWriter
+
+	c      HTTPWriterConfig
+	url    string
+	header http.Header
+}
+
+// NewHTTPWriter returns a new HTTPWriter from the supplied HTTPWriterConfig,
+// sending its requests with writer.
+func NewHTTPWriter(c HTTPWriterConfig, consistency string, writer *common.HTTPWriter) *HTTPWriter {
+	w := &HTTPWriter{
+		writer: writer,
+
+		c:      c,
+		header: lineProtocolHeader,
+	}
+	if c.APIVersion == 2 {
+		v := url.Values{}
+		v.Set("org", c.Org)
+		v.Set("bucket", c.Database)
+		v.Set("precision", c.Precision)
+		w.url = c.Host + "/api/v2/write?" + v.Encode()
+		if c.Token != "" {
+			w.header = http.Header{
+				"Content-Type":  {"text/plain"},
+				"Authorization": {"Token " + c.Token},
+			}
+		}
+	} else {
+		w.url = c.Host + "/write?consistency=" + consistency + "&db=" + url.QueryEscape(c.Database)
+	}
+	return w
+}
+
+// WriteLineProtocol writes the given byte slice to the HTTP server described in the Writer's HTTPWriterConfig.
+// The request is retried while the server indicates backpressure is needed. It returns the retries and the
+// backoff they took, and any error received while sending the data over HTTP or if the HTTP response isn't
+// as expected.
+func (w *HTTPWriter) WriteLineProtocol(body []byte) (common.PostStats, error) {
+	stats, err := w.writer.Post(w.url, w.header, body)
+	if err != nil {
+		err = fmt.Errorf("[DebugInfo: %s] %v", w.c.DebugInfo, err)
+	}
+	return stats, err
+}
+
+// statusClassifier classifies the responses of InfluxDB writes: only 204 No
+// Content means success, and a 500 only needs backpressure if its body says so.
+func statusClassifier(statusCode int, body []byte) common.StatusClass {
+	switch {
+	case statusCode == http.StatusNoContent:
+		return common.StatusOK
+	case statusCode == http.StatusInternalServerError:
+		if backpressurePred(body) {
+			return common.StatusRetry
+		}
+		return common.StatusFail
+	case statusCode/100 == 2:
+		return common.StatusFail
+	default:
+		return common.DefaultStatusClassifier(statusCode, body)
+	}
+}
+
+func backpressurePred(body []byte) bool {
+	if bytes.Contains(body, backoffMagicWords0) {
+		return true
+	} else if bytes.Contains(body, backoffMagicWords1) {
+		return true
+	} else if bytes.Contains(body, backoffMagicWords2a) && bytes.Contains(body, backoffMagicWords2b) {
+		return true
+	} else if bytes.Contains(body, backoffMagicWords3) {
+		return true
+	} else if bytes.Contains(body, backoffMagicWords4) {
+		return true
+	} else if bytes.Contains(body, backoffMagicWords5) {
+		return true
+	} else {
+		return false
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package influx
 
 import (
 	"context"
@@ -10,7 +10,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/valyala/fasthttp"
+	"github.com/timescale/tsbs/pkg/targets/common"
 )
 
 const (
@@ -72,6 +72,20 @@ func shutdownHTTPServer(c chan struct{}) {
 	<-c                   // wait for clean shutdown
 }
 
+func testHTTPWriter(t *testing.T, useGzip bool) *common.HTTPWriter {
+	conf := common.DefaultHTTPWriterConfig
+	conf.Backoff = time.Millisecond
+	conf.MaxBackoff = time.Millisecond
+	if useGzip {
+		conf.Encoding = common.EncodingGzip
+	}
+	w, err := common.NewHTTPWriter(conf, statusClassifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
 func testWriterMatchesConfig(w *HTTPWriter, conf HTTPWriterConfig, consistency string) error {
 	// Check HTTP Config is the same
 	if got := w.c.Host; got != conf.Host {
@@ -82,7 +96,7 @@ func testWriterMatchesConfig(w *HTTPWriter, conf HTTPWriterConfig, consistency s
 	}
 
 	// Check URL is accurate
-	got := string(w.url)
+	got := w.url
 	if !strings.Contains(got, conf.Host) {
 		return fmt.Errorf("url does not contain correct host: looking for %s in %s", conf.Host, got)
 	}
@@ -97,97 +111,95 @@ func testWriterMatchesConfig(w *HTTPWriter, conf HTTPWriterConfig, consistency s
 }
 
 func TestNewHTTPWriter(t *testing.T) {
-	w := NewHTTPWriter(testConf, testConsistency)
-	// Check client name
-	if got := w.client.Name; got != httpClientName {
-		t.Errorf("name of http client is incorrect: got %s want %s", got, httpClientName)
-	}
-
+	w := NewHTTPWriter(testConf, testConsistency, testHTTPWriter(t, false))
 	err := testWriterMatchesConfig(w, testConf, testConsistency)
 	if err != nil {
 		t.Error(err)
 	}
 }
 
-func TestHTTPWriterInitializeReq(t *testing.T) {
-	req := fasthttp.AcquireRequest()
-	defer fasthttp.ReleaseRequest(req)
-	w := NewHTTPWriter(testConf, testConsistency)
-	body := "this is a test body"
-	w.initializeReq(req, []byte(body), false)
+func TestNewHTTPWriterAPIv2(t *testing.T) {
+	conf := testConf
+	conf.Host = "http://localhost:8086"
+	conf.APIVersion = 2
+	conf.Org = "my org"
+	conf.Token = "secret"
+	conf.Precision = "ms"
+	w := NewHTTPWriter(conf, testConsistency, testHTTPWriter(t, false))
 
-	if got := string(req.Body()); got != body {
-		t.Errorf("non-gzip: body not correct: got '%s' want '%s'", got, body)
+	want := "http://localhost:8086/api/v2/write?bucket=test&org=my+org&precision=ms"
+	if w.url != want {
+		t.Errorf("incorrect url: got %s want %s", w.url, want)
 	}
-	if got := string(req.Header.Method()); got != string(methodPost) {
-		t.Errorf("non-gzip: method not correct: got %s want %s", got, string(methodPost))
-	}
-	if got := string(req.Header.RequestURI()); got != string(w.url) {
-		t.Errorf("non-gzip: URI is not correct: got %s want %s", got, string(w.url))
-	}
-	if got := string(req.Header.Peek(headerContentEncoding)); got != "" {
-		t.Errorf("non-gzip: Content-Encoding is not empty: got %s", got)
+	if got := w.header.Get("Authorization"); got != "Token secret" {
+		t.Errorf("incorrect Authorization header: got %s", got)
 	}
 
-	w.initializeReq(req, []byte(body), true)
-	if got := string(req.Header.Peek(headerContentEncoding)); got != headerGzip {
-		t.Errorf("gzip: Content-Encoding is not correct: got %s want %s", got, headerGzip)
+	conf.Token = ""
+	w = NewHTTPWriter(conf, testConsistency, testHTTPWriter(t, false))
+	if got := w.header.Get("Authorization"); got != "" {
+		t.Errorf("unexpected Authorization header without token: %s", got)
 	}
 }
 
-func TestHTTPWriterExecuteReq(t *testing.T) {
+func TestHTTPWriterWriteLineProtocol(t *testing.T) {
 	c := launchHTTPServer()
 
-	// Success case test, make sure no error and positive latency
-	req := fasthttp.AcquireRequest()
-	defer fasthttp.ReleaseRequest(req)
-	w := NewHTTPWriter(testConf, testConsistency)
-	body := "this is a test body"
+	// Success case test, make sure no error and no retries
+	w := NewHTTPWriter(testConf, testConsistency, testHTTPWriter(t, false))
+	body := []byte("this is a test body")
 	normalURL := w.url // save for later modification
-	w.initializeReq(req, []byte(body), false)
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseResponse(resp)
-	lat, err := w.executeReq(req, resp)
+	stats, err := w.WriteLineProtocol(body)
 	if err != nil {
 		t.Errorf("unexpected error received: %v", err)
 	}
-	if lat <= 0 {
-		t.Errorf("latency is unrealistic (<= 0): %d", lat)
-	}
-
-	// Backoff case test, make sure its a backoff error and positive latency
-	resp = fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseResponse(resp)
-	w.url = []byte(fmt.Sprintf("%s&%s=true", string(normalURL), shouldBackoffParam))
-	req = fasthttp.AcquireRequest()
-	defer fasthttp.ReleaseRequest(req)
-	w.initializeReq(req, []byte(body), false)
-	lat, err = w.executeReq(req, resp)
-	if err != errBackoff {
-		t.Errorf("unexpected error response received (not backoff error): %v", err)
-	}
-	if lat <= 0 {
-		t.Errorf("latency is unrealistic (<= 0): %d", lat)
-	}
-
-	// Unexpected response case test, make sure its an error and positive latency
-	resp = fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseResponse(resp)
-	w.url = []byte(fmt.Sprintf("%s&%s=true", string(normalURL), shouldInvalidParam))
-	req = fasthttp.AcquireRequest()
-	defer fasthttp.ReleaseRequest(req)
-	w.initializeReq(req, []byte(body), false)
-	lat, err = w.executeReq(req, resp)
+	if stats.Retries != 0 {
+		t.Errorf("unexpected retries: %d", stats.Retries)
+	}
+
+	// Backoff case test, make sure it is retried once and succeeds
+	w.url = fmt.Sprintf("%s&%s=true", normalURL, shouldBackoffParam)
+	stats, err = w.WriteLineProtocol(body)
+	if err != nil {
+		t.Errorf("unexpected error received: %v", err)
+	}
+	if stats.Retries != 1 || stats.Backoff <= 0 {
+		t.Errorf("backoff not retried once: %+v", stats)
+	}
+
+	// Unexpected response case test, make sure its an error
+	w.url = fmt.Sprintf("%s&%s=true", normalURL, shouldInvalidParam)
+	stats, err = w.WriteLineProtocol(body)
 	if err == nil {
 		t.Errorf("unexpected non-error response received")
 	}
-	if lat <= 0 {
-		t.Errorf("latency is unrealistic (<= 0): %d", lat)
+	if stats.Retries != 0 {
+		t.Errorf("invalid response retried: %d", stats.Retries)
 	}
 
 	shutdownHTTPServer(c)
 }
 
+func TestStatusClassifier(t *testing.T) {
+	cases := []struct {
+		code int
+		body string
+		want common.StatusClass
+	}{
+		{code: http.StatusNoContent, want: common.StatusOK},
+		{code: http.StatusOK, body: "success should be an empty msg", want: common.StatusFail},
+		{code: http.StatusInternalServerError, body: string(backoffMagicWords1), want: common.StatusRetry},
+		{code: http.StatusInternalServerError, body: "unknown error", want: common.StatusFail},
+		{code: http.StatusServiceUnavailable, want: common.StatusRetry},
+		{code: http.StatusBadRequest, body: "unable to parse", want: common.StatusFail},
+	}
+	for _, c := range cases {
+		if got := statusClassifier(c.code, []byte(c.body)); got != c.want {
+			t.Errorf("incorrect class for status %d '%s': got %d want %d", c.code, c.body, got, c.want)
+		}
+	}
+}
+
 func TestBackpressurePred(t *testing.T) {
 	cases := []struct {
 		body string
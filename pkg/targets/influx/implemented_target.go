@@ -5,12 +5,13 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"github.com/timescale/tsbs/pkg/targets/constants"
-	"time"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &influxTarget{}
 }
 
@@ -21,8 +22,15 @@ func (t *influxTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.Fla
 	flagSet.String(flagPrefix+"urls", "http://localhost:8086", "InfluxDB URLs, comma-separated. Will be used in a round-robin fashion.")
 	flagSet.Int(flagPrefix+"replication-factor", 1, "Cluster replication factor (only applies to clustered databases).")
 	flagSet.String(flagPrefix+"consistency", "all", "Write consistency. Must be one of: any, one, quorum, all.")
-	flagSet.Duration(flagPrefix+"backoff", time.Second, "Time to sleep between requests when server indicates backpressure is needed.")
 	flagSet.Bool(flagPrefix+"gzip", true, "Whether to gzip encode requests (default true).")
+	flagSet.Int(flagPrefix+"api-version", 1, "InfluxDB write API version. 1 writes to /write, 2 writes to /api/v2/write and uses db-name as the bucket.")
+	flagSet.String(flagPrefix+"org", "", "InfluxDB 2.x organization owning the bucket (api-version 2 only).")
+	flagSet.String(flagPrefix+"token", "", "InfluxDB 2.x API token (api-version 2 only). Empty means no authentication.")
+	flagSet.String(flagPrefix+"precision", "ns", "Timestamp precision of the written points (api-version 2 only). Must be one of: ns, us, ms, s.")
+	flagSet.String(flagPrefix+"transport", TransportHTTP, "Transport of the line protocol writes, one of: http (to urls), udp or tcp (to socket-addresses, api-version 1 only).")
+	flagSet.String(flagPrefix+"socket-addresses", "localhost:8089", "host:port addresses of the UDP or TCP listeners, comma-separated. Will be used in a round-robin fashion.")
+	flagSet.Int(flagPrefix+"udp-payload-size", defaultUDPPayloadSize, "Maximum size of a UDP datagram. Batches are split at line boundaries to fit.")
+	common.AddHTTPWriterFlags(flagPrefix, flagSet, defaultHTTPWriterConfig)
 }
 
 func (t *influxTarget) TargetName() string {
@@ -33,6 +41,25 @@ func (t *influxTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *influxTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *influxTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(targetDB, conf, dataSourceConfig)
+}
+
+func (t *influxTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:8086", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
+	flagSet.Uint64(flagPrefix+"chunk-response-size", 0, "Number of series to chunk results into. 0 means no chunking.")
+	flagSet.String(flagPrefix+"org", "", "InfluxDB 2.x organization Flux queries are run in.")
+	flagSet.String(flagPrefix+"token", "", "InfluxDB 2.x API token. Empty means no authentication.")
+}
+
+func (t *influxTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
@@ -0,0 +1,97 @@
+package influx
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// allows for testing
+var printFn = fmt.Printf
+
+type processor struct {
+	dbName     string
+	conf       *SpecificConfig
+	bufPool    *sync.Pool
+	httpWriter *common.HTTPWriter
+
+	workerID     int
+	totalBackoff time.Duration
+	writer       *HTTPWriter
+	// socketWriter writes instead of writer with the udp and tcp transports
+	socketWriter *SocketWriter
+}
+
+func (p *processor) Init(numWorker int, doLoad, _ bool) {
+	if p.conf.Transport == TransportUDP || p.conf.Transport == TransportTCP {
+		p.workerID = numWorker
+		if !doLoad {
+			return
+		}
+		addr := p.conf.SocketAddresses[numWorker%len(p.conf.SocketAddresses)]
+		w, err := NewSocketWriter(p.conf.Transport, addr, p.conf.UDPPayloadSize)
+		if err != nil {
+			fatal("[worker %d] %v", numWorker, err)
+		}
+		p.socketWriter = w
+		return
+	}
+	daemonURL := p.conf.URLs[numWorker%len(p.conf.URLs)]
+	cfg := HTTPWriterConfig{
+		DebugInfo: fmt.Sprintf("worker #%d, dest url: %s", numWorker, daemonURL),
+		Host:      daemonURL,
+		Database:  p.dbName,
+
+		APIVersion: p.conf.APIVersion,
+		Org:        p.conf.Org,
+		Token:      p.conf.Token,
+		Precision:  p.conf.Precision,
+	}
+	w := NewHTTPWriter(cfg, p.conf.Consistency, p.httpWriter)
+	p.initWithHTTPWriter(numWorker, w)
+}
+
+func (p *processor) initWithHTTPWriter(numWorker int, w *HTTPWriter) {
+	p.workerID = numWorker
+	p.writer = w
+}
+
+func (p *processor) Close(_ bool) {
+	if p.socketWriter != nil {
+		if err := p.socketWriter.Close(); err != nil {
+			fatal("[worker %d] error closing connection: %v", p.workerID, err)
+		}
+		return
+	}
+	printFn("[worker %d] backoffs took a total of %fsec of runtime\n", p.workerID, p.totalBackoff.Seconds())
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	batch := b.(*batch)
+
+	// Write the batch: the writer retries until backoff is not needed.
+	if doLoad && p.socketWriter != nil {
+		if err := p.socketWriter.WriteLineProtocol(batch.buf.Bytes()); err != nil {
+			fatal("Error writing: %s\n", err.Error())
+		}
+	} else if doLoad {
+		stats, err := p.writer.WriteLineProtocol(batch.buf.Bytes())
+		if stats.Retries > 0 {
+			printFn("[worker %d] backoff took %.02fsec\n", p.workerID, stats.Backoff.Seconds())
+			p.totalBackoff += stats.Backoff
+		}
+		if err != nil {
+			fatal("Error writing: %s\n", err.Error())
+		}
+	}
+	metricCnt := batch.metrics
+	rowCnt := batch.rows
+
+	// Return the batch buffer to the pool.
+	batch.buf.Reset()
+	p.bufPool.Put(batch.buf)
+	return metricCnt, uint64(rowCnt)
+}
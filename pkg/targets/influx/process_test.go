@@ -0,0 +1,178 @@
+package influx
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func emptyLog(_ string, _ ...interface{}) (int, error) {
+	return 0, nil
+}
+
+func TestProcessorInit(t *testing.T) {
+	daemonURLs := []string{"url1", "url2"}
+	conf := &SpecificConfig{URLs: daemonURLs, Consistency: "all", APIVersion: 1}
+	dbName := "benchmark"
+	printFn = emptyLog
+	p := &processor{dbName: dbName, conf: conf}
+	p.Init(0, false, false)
+	p.Close(true)
+	if got := p.writer.c.Host; got != daemonURLs[0] {
+		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[0])
+	}
+	if got := p.writer.c.Database; got != dbName {
+		t.Errorf("incorrect database: got %s want %s", got, dbName)
+	}
+
+	p = &processor{dbName: dbName, conf: conf}
+	p.Init(1, false, false)
+	p.Close(true)
+	if got := p.writer.c.Host; got != daemonURLs[1] {
+		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[1])
+	}
+
+	p = &processor{dbName: dbName, conf: conf}
+	p.Init(len(daemonURLs), false, false)
+	p.Close(true)
+	if got := p.writer.c.Host; got != daemonURLs[0] {
+		t.Errorf("incorrect host: got %s want %s", got, daemonURLs[0])
+	}
+
+}
+
+func TestProcessorInitWithHTTPWriterConfig(t *testing.T) {
+	var b bytes.Buffer
+	counter := int64(0)
+	var m sync.Mutex
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		atomic.AddInt64(&counter, 1)
+		m.Lock()
+		defer m.Unlock()
+		return fmt.Fprintf(&b, s, args...)
+	}
+	workerNum := 4
+	p := &processor{}
+	w := NewHTTPWriter(testConf, testConsistency, testHTTPWriter(t, false))
+	p.initWithHTTPWriter(workerNum, w)
+	p.Close(true)
+
+	if got := p.workerID; got != workerNum {
+		t.Errorf("incorrect worker id: got %d want %d", got, workerNum)
+	}
+
+	// Check p was initialized with correct writer given conf
+	err := testWriterMatchesConfig(p.writer, testConf, testConsistency)
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Check that the total backoff was reported on close
+	if got := atomic.LoadInt64(&counter); got != 1 {
+		t.Errorf("printFn called incorrect # of times: got %d want %d", got, 1)
+	}
+	got := string(b.Bytes())
+	if !strings.Contains(got, fmt.Sprintf("worker %d", workerNum)) {
+		t.Errorf("printFn did not contain correct worker number: %s", got)
+	}
+}
+
+func TestProcessorProcessBatch(t *testing.T) {
+	bufPool := &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+		},
+	}
+	printFn = emptyLog
+	f := &factory{bufPool: bufPool}
+	b := f.New().(*batch)
+	pt := data.LoadedPoint{
+		Data: []byte("tag1=tag1val,tag2=tag2val col1=0.0,col2=0.0 140"),
+	}
+	b.Append(pt)
+
+	cases := []struct {
+		doLoad        bool
+		useGzip       bool
+		shouldBackoff bool
+		shouldFatal   bool
+	}{
+		{
+			doLoad:  false,
+			useGzip: false,
+		},
+		{
+			doLoad:  true,
+			useGzip: false,
+		},
+		{
+			doLoad:  true,
+			useGzip: true,
+		},
+		{
+			doLoad:        true,
+			useGzip:       false,
+			shouldBackoff: true,
+		},
+		{
+			doLoad:      true,
+			shouldFatal: true,
+		},
+	}
+
+	for _, c := range cases {
+		var ch chan struct{}
+		fatalCalled := false
+		if c.shouldFatal {
+			fatal = func(format string, args ...interface{}) {
+				fatalCalled = true
+			}
+		} else {
+			fatal = func(format string, args ...interface{}) {
+				t.Errorf("fatal called for case %v unexpectedly\n", c)
+				fmt.Printf(format, args...)
+			}
+			ch = launchHTTPServer()
+		}
+
+		p := &processor{bufPool: bufPool}
+		w := NewHTTPWriter(testConf, testConsistency, testHTTPWriter(t, c.useGzip))
+
+		// If the case should backoff, we tell our dummy server to do so by
+		// modifying the URL params. The writer should retry the request
+		// until it gets a response that is not a backoff (every other
+		// response from the server).
+		if c.shouldBackoff {
+			w.url = fmt.Sprintf("%s&%s=true", w.url, shouldBackoffParam)
+		}
+
+		p.initWithHTTPWriter(0, w)
+		mCnt, rCnt := p.ProcessBatch(b, c.doLoad)
+		if c.shouldFatal {
+			if !fatalCalled {
+				t.Errorf("fatal was not called when it should have been")
+			}
+			continue
+		} else {
+			if mCnt != b.metrics {
+				t.Errorf("process batch returned less metrics than batch: got %d want %d", mCnt, b.metrics)
+			}
+			if rCnt != uint64(b.rows) {
+				t.Errorf("process batch returned less rows than batch: got %d want %d", rCnt, b.rows)
+			}
+			if c.shouldBackoff && p.totalBackoff <= 0 {
+				t.Errorf("backoff not accounted for")
+			}
+			p.Close(true)
+
+			shutdownHTTPServer(ch)
+			time.Sleep(50 * time.Millisecond)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package influx
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// decodeHTTPQuery returns q as the query runner reads it: gob-decoded into a
+// query from the pool.
+func decodeHTTPQuery(t *testing.T, q *query.HTTP) *query.HTTP {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(q); err != nil {
+		t.Fatal(err)
+	}
+	decoded := query.NewHTTP()
+	if err := gob.NewDecoder(&b).Decode(decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestHTTPClientDo(t *testing.T) {
+	cases := []struct {
+		desc        string
+		path        string
+		body        []byte
+		wantURI     string
+		wantType    string
+		wantReqBody string
+	}{
+		{
+			desc:    "influxql",
+			path:    "/query?q=SELECT+1",
+			wantURI: "/query?q=SELECT+1&db=benchmark&chunked=true&chunk_size=100",
+		},
+		{
+			desc:        "flux",
+			path:        "/api/v2/query",
+			body:        []byte(`from(bucket: "benchmark")`),
+			wantURI:     "/api/v2/query?org=my+org",
+			wantType:    "application/vnd.flux",
+			wantReqBody: `from(bucket: "benchmark")`,
+		},
+	}
+	for _, c := range cases {
+		var gotURI, gotType, gotBody string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotURI = r.URL.RequestURI()
+			gotType = r.Header.Get("Content-Type")
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			gotBody = string(body)
+		}))
+		q := decodeHTTPQuery(t, &query.HTTP{
+			HumanLabel: []byte(c.desc),
+			Method:     []byte("POST"),
+			Path:       []byte(c.path),
+			Body:       c.body,
+		})
+		opts := &HTTPClientDoOptions{chunkSize: 100, database: "benchmark", org: "my org"}
+		if _, err := NewHTTPClient(server.URL).Do(q, opts); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+		if gotURI != c.wantURI {
+			t.Errorf("%s: incorrect uri: got %s want %s", c.desc, gotURI, c.wantURI)
+		}
+		if gotType != c.wantType {
+			t.Errorf("%s: incorrect content type: got %q want %q", c.desc, gotType, c.wantType)
+		}
+		if gotBody != c.wantReqBody {
+			t.Errorf("%s: incorrect body: got %q want %q", c.desc, gotBody, c.wantReqBody)
+		}
+		q.Release()
+		server.Close()
+	}
+}
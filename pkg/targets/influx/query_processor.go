@@ -0,0 +1,72 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the InfluxDB query runner.
+type QuerySpecificConfig struct {
+	URLs              []string `yaml:"urls" mapstructure:"urls"`
+	ChunkResponseSize uint64   `yaml:"chunk-response-size" mapstructure:"chunk-response-size"`
+	// Org and Token authenticate against the InfluxDB 2.x API. Org is only
+	// used by Flux queries.
+	Org   string `yaml:"org" mapstructure:"org"`
+	Token string `yaml:"token" mapstructure:"token"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the InfluxDB URLs in conf, round-robin over the workers.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if len(conf.URLs) == 0 {
+		return nil, fmt.Errorf("missing `urls` flag")
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.HTTPPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{conf: conf, runner: runner}
+		},
+	}, nil
+}
+
+type queryProcessor struct {
+	conf   *QuerySpecificConfig
+	runner *query.BenchmarkRunner
+	w      *HTTPClient
+	opts   *HTTPClientDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.opts = &HTTPClientDoOptions{
+		Debug:                p.runner.DebugLevel(),
+		PrettyPrintResponses: p.runner.DoPrintResponses(),
+		chunkSize:            p.conf.ChunkResponseSize,
+		database:             p.runner.DatabaseName(),
+		org:                  p.conf.Org,
+		token:                p.conf.Token,
+	}
+	url := p.conf.URLs[workerNumber%len(p.conf.URLs)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
@@ -0,0 +1,88 @@
+package influx
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
+)
+
+const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
+
+var (
+	newLine = []byte("\n")
+	space   = []byte(" ")
+	comma   = []byte(",")
+)
+
+// fileDataSource reads the lines of the points into its arena, the batches
+// release them once copied.
+type fileDataSource struct {
+	scanner *targetsCommon.LineScanner
+	arena   *data.Arena
+}
+
+func newFileDataSource(br *bufio.Reader) *fileDataSource {
+	return &fileDataSource{scanner: targetsCommon.NewLineScanner(br), arena: data.NewArena(data.DefaultArenaChunkSize)}
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	ok := d.scanner.Scan()
+	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		return data.LoadedPoint{}
+	} else if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	// copied, the scanner reuses its buffer while the point may still be batched
+	line, chunk := d.arena.Alloc(len(d.scanner.Bytes()))
+	copy(line, d.scanner.Bytes())
+	return data.LoadedPoint{Data: line, Chunk: chunk}
+}
+
+// Position returns the number of bytes of the data scanned.
+func (d *fileDataSource) Position() int64 {
+	return d.scanner.Position()
+}
+
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+
+type batch struct {
+	buf     *bytes.Buffer
+	rows    uint
+	metrics uint64
+}
+
+func (b *batch) Len() uint {
+	return b.rows
+}
+
+func (b *batch) Append(item data.LoadedPoint) {
+	that := item.Data.([]byte)
+	b.rows++
+	// Each influx line is format "csv-tags csv-fields timestamp", so we split by space
+	// and then on the middle element, we count the commas to count number of fields added
+	if spaces := bytes.Count(that, space); spaces != 2 {
+		fatal(errNotThreeTuplesFmt, spaces+1)
+		return
+	}
+	fields := that[bytes.IndexByte(that, ' ')+1 : bytes.LastIndexByte(that, ' ')]
+	b.metrics += uint64(bytes.Count(fields, comma) + 1)
+
+	b.buf.Write(that)
+	b.buf.Write(newLine)
+	// the line is copied into the batch
+	item.Chunk.Release()
+}
+
+type factory struct {
+	bufPool *sync.Pool
+}
+
+func (f *factory) New() targets.Batch {
+	return &batch{buf: f.bufPool.Get().(*bytes.Buffer)}
+}
@@ -0,0 +1,68 @@
+package influx
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+)
+
+// SocketWriter writes line protocol to a UDP or TCP listener, such as the UDP
+// service of InfluxDB 1.x or a Telegraf socket_listener. Neither acknowledges
+// writes, so there is no backpressure to back off from and lost points are
+// not reported.
+type SocketWriter struct {
+	network string
+	addr    string
+	// payloadSize is the maximum size of a UDP datagram. Batches are split
+	// at line boundaries so that each datagram only holds whole lines.
+	payloadSize int
+	conn        net.Conn
+}
+
+// NewSocketWriter returns a SocketWriter connected to addr over network, one
+// of TransportUDP or TransportTCP.
+func NewSocketWriter(network, addr string, payloadSize int) (*SocketWriter, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s over %s: %v", addr, network, err)
+	}
+	return &SocketWriter{network: network, addr: addr, payloadSize: payloadSize, conn: conn}, nil
+}
+
+// WriteLineProtocol writes the lines of body. Over UDP, the lines are sent in
+// as few datagrams of at most the payload size as possible; a line longer
+// than the payload size is sent in a datagram of its own.
+func (w *SocketWriter) WriteLineProtocol(body []byte) error {
+	if w.network != TransportUDP {
+		_, err := w.conn.Write(body)
+		return err
+	}
+	for len(body) > 0 {
+		n := datagramSize(body, w.payloadSize)
+		if _, err := w.conn.Write(body[:n]); err != nil {
+			return err
+		}
+		body = body[n:]
+	}
+	return nil
+}
+
+// datagramSize returns the size of the whole lines at the start of body
+// which fit in payloadSize, or the size of the first line if it does not fit.
+func datagramSize(body []byte, payloadSize int) int {
+	if len(body) <= payloadSize {
+		return len(body)
+	}
+	if i := bytes.LastIndexByte(body[:payloadSize], '\n'); i >= 0 {
+		return i + 1
+	}
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		return i + 1
+	}
+	return len(body)
+}
+
+// Close closes the connection.
+func (w *SocketWriter) Close() error {
+	return w.conn.Close()
+}
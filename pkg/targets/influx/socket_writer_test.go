@@ -0,0 +1,93 @@
+package influx
+
+import (
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDatagramSize(t *testing.T) {
+	cases := []struct {
+		desc        string
+		body        string
+		payloadSize int
+		want        int
+	}{
+		{desc: "fits", body: "a 1\nb 2\n", payloadSize: 100, want: 8},
+		{desc: "whole lines", body: "a 1\nb 2\nc 3\n", payloadSize: 9, want: 8},
+		{desc: "exact line boundary", body: "a 1\nb 2\nc 3\n", payloadSize: 8, want: 8},
+		{desc: "long line", body: "abcdef 1\nb 2\n", payloadSize: 4, want: 9},
+		{desc: "long last line", body: "abcdef 1", payloadSize: 4, want: 8},
+	}
+	for _, c := range cases {
+		if got := datagramSize([]byte(c.body), c.payloadSize); got != c.want {
+			t.Errorf("%s: incorrect size: got %d want %d", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestSocketWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	w, err := NewSocketWriter(TransportUDP, conn.LocalAddr().String(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.WriteLineProtocol([]byte("a 1\nb 2\nc 3\nd 4\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"a 1\nb 2\n", "c 3\nd 4\n"}
+	buf := make([]byte, 100)
+	for _, datagram := range want {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != datagram {
+			t.Errorf("incorrect datagram: got %q want %q", got, datagram)
+		}
+	}
+}
+
+func TestSocketWriterTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		b, _ := ioutil.ReadAll(conn)
+		received <- string(b)
+	}()
+
+	w, err := NewSocketWriter(TransportTCP, ln.Addr().String(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := strings.Repeat("cpu,hostname=host_0 usage_user=1 0\n", 100)
+	if err := w.WriteLineProtocol([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; got != body {
+		t.Errorf("incorrect body: got %d bytes want %d", len(got), len(body))
+	}
+}
@@ -29,6 +29,8 @@ func GetTarget(format string) targets.ImplementedTarget {
 		return cassandra.NewTarget()
 	case constants.FormatClickhouse:
 		return clickhouse.NewTarget()
+	case constants.FormatClickhouseNative:
+		return clickhouse.NewNativeTarget()
 	case constants.FormatCrateDB:
 		return crate.NewTarget()
 	case constants.FormatInflux:
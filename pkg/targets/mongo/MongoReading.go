@@ -46,8 +46,29 @@ func (rcv *MongoReading) MutateValue(n float64) bool {
 	return rcv._tab.MutateFloat64Slot(6, n)
 }
 
+func (rcv *MongoReading) TypedValueType() byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return rcv._tab.GetByte(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *MongoReading) MutateTypedValueType(n byte) bool {
+	return rcv._tab.MutateByteSlot(8, n)
+}
+
+func (rcv *MongoReading) TypedValue(obj *flatbuffers.Table) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(10))
+	if o != 0 {
+		rcv._tab.Union(obj, o)
+		return true
+	}
+	return false
+}
+
 func MongoReadingStart(builder *flatbuffers.Builder) {
-	builder.StartObject(2)
+	builder.StartObject(4)
 }
 func MongoReadingAddKey(builder *flatbuffers.Builder, key flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(key), 0)
@@ -55,6 +76,12 @@ func MongoReadingAddKey(builder *flatbuffers.Builder, key flatbuffers.UOffsetT)
 func MongoReadingAddValue(builder *flatbuffers.Builder, value float64) {
 	builder.PrependFloat64Slot(1, value, 0.0)
 }
+func MongoReadingAddTypedValueType(builder *flatbuffers.Builder, typedValueType byte) {
+	builder.PrependByteSlot(2, typedValueType, 0)
+}
+func MongoReadingAddTypedValue(builder *flatbuffers.Builder, typedValue flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(3, flatbuffers.UOffsetT(typedValue), 0)
+}
 func MongoReadingEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
@@ -42,8 +42,29 @@ func (rcv *MongoTag) Value() []byte {
 	return nil
 }
 
+func (rcv *MongoTag) TypedValueType() byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return rcv._tab.GetByte(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *MongoTag) MutateTypedValueType(n byte) bool {
+	return rcv._tab.MutateByteSlot(8, n)
+}
+
+func (rcv *MongoTag) TypedValue(obj *flatbuffers.Table) bool {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(10))
+	if o != 0 {
+		rcv._tab.Union(obj, o)
+		return true
+	}
+	return false
+}
+
 func MongoTagStart(builder *flatbuffers.Builder) {
-	builder.StartObject(2)
+	builder.StartObject(4)
 }
 func MongoTagAddKey(builder *flatbuffers.Builder, key flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(key), 0)
@@ -51,6 +72,12 @@ func MongoTagAddKey(builder *flatbuffers.Builder, key flatbuffers.UOffsetT) {
 func MongoTagAddValue(builder *flatbuffers.Builder, value flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(value), 0)
 }
+func MongoTagAddTypedValueType(builder *flatbuffers.Builder, typedValueType byte) {
+	builder.PrependByteSlot(2, typedValueType, 0)
+}
+func MongoTagAddTypedValue(builder *flatbuffers.Builder, typedValue flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(3, flatbuffers.UOffsetT(typedValue), 0)
+}
 func MongoTagEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
@@ -1,4 +1,4 @@
-package main
+package mongo
 
 import (
 	"context"
@@ -12,53 +12,57 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/targets"
-	tsbsMongo "github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
+// hostnameIndexer sends the points of a host, or of a truck, to the same
+// channel.
 type hostnameIndexer struct {
 	partitions uint
 }
 
 func (i *hostnameIndexer) GetIndex(item data.LoadedPoint) uint {
-	p := item.Data.(*tsbsMongo.MongoPoint)
-	t := &tsbsMongo.MongoTag{}
+	if v, ok := i.AffinityKey(item); ok {
+		h := fnv.New32a()
+		h.Write([]byte(v))
+		return uint(h.Sum32()) % i.partitions
+	}
+	// name tag may be skipped in iot use-case
+	return 0
+}
+
+// AffinityKey returns the hostname, or the truck name, of the point.
+func (i *hostnameIndexer) AffinityKey(item data.LoadedPoint) (string, bool) {
+	p := item.Data.(*MongoPoint)
+	t := &MongoTag{}
 	for j := 0; j < p.TagsLength(); j++ {
 		p.Tags(t, j)
 		key := string(t.Key())
 		if key == "hostname" || key == "name" {
 			// the hostame is the defacto index for devops tags
 			// the truck name is the defacto index for iot tags
-			h := fnv.New32a()
-			h.Write([]byte(string(t.Value())))
-			return uint(h.Sum32()) % i.partitions
+			if v, ok := t.StringValue(); ok {
+				return v, true
+			}
 		}
 	}
-	// name tag may be skipped in iot use-case
-	return 0
-}
-
-// aggBenchmark allows you to run a benchmark using the aggregated document format
-// for Mongo
-type aggBenchmark struct {
-	mongoBenchmark
+	return "", false
 }
 
-func newAggBenchmark(l load.BenchmarkRunner, conf *load.BenchmarkRunnerConfig) *aggBenchmark {
-	// Pre-create the needed empty subdoc for new aggregate docs
-	generateEmptyHourDoc()
-
-	return &aggBenchmark{mongoBenchmark{conf.FileName, l, &dbCreator{}}}
+// metaFieldIndexer sends the points with the same value of the meta field
+// tag to the same channel, for time-series collections.
+type metaFieldIndexer struct {
+	metaField string
 }
 
-func (b *aggBenchmark) GetProcessor() targets.Processor {
-	return &aggProcessor{dbc: b.dbc}
+func (i *metaFieldIndexer) GetIndex(_ data.LoadedPoint) uint {
+	return 0
 }
 
-func (b *aggBenchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
-	return &hostnameIndexer{partitions: maxPartitions}
+// AffinityKey returns the value of the meta field tag of the point.
+func (i *metaFieldIndexer) AffinityKey(item data.LoadedPoint) (string, bool) {
+	return item.Data.(*MongoPoint).TagValue(i.metaField)
 }
 
 // point is a reusable data structure to store a BSON data document for Mongo,
@@ -79,7 +83,10 @@ func generateEmptyHourDoc() {
 
 var pPool = &sync.Pool{New: func() interface{} { return &point{} }}
 
+// aggProcessor loads the events using the aggregated document format.
 type aggProcessor struct {
+	dbName     string
+	conf       *SpecificConfig
 	dbc        *dbCreator
 	collection *mongo.Collection
 
@@ -89,7 +96,7 @@ type aggProcessor struct {
 
 func (p *aggProcessor) Init(_ int, doLoad, _ bool) {
 	if doLoad {
-		p.collection = p.dbc.client.Database(loader.DatabaseName()).Collection(collectionName)
+		p.collection = p.dbc.client.Database(p.dbName).Collection(collectionName)
 	}
 	p.createdDocs = make(map[string]bool)
 	p.createQueue = []interface{}{}
@@ -128,25 +135,30 @@ func (p *aggProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint6
 	eventCnt := uint64(0)
 	for _, event := range batch.arr {
 		tagsSlice := bson.D{}
-		tagsMap := map[string]string{}
-		t := &tsbsMongo.MongoTag{}
+		tagsMap := map[string]interface{}{}
+		hostname := ""
+		t := &MongoTag{}
 		for j := 0; j < event.TagsLength(); j++ {
 			event.Tags(t, j)
-			tagsMap[string(t.Key())] = string(t.Value())
-			tagsSlice = append(tagsSlice, bson.E{string(t.Key()), string(t.Value())})
+			v := t.GoValue()
+			tagsMap[string(t.Key())] = v
+			tagsSlice = append(tagsSlice, bson.E{string(t.Key()), v})
+			if string(t.Key()) == "hostname" {
+				hostname, _ = t.StringValue()
+			}
 		}
 
 		// Determine which document this event belongs too
 		ts := time.Unix(0, event.Timestamp())
 		dateKey := ts.UTC().Format(aggDateFmt)
-		docKey := fmt.Sprintf("day_%s_%s_%s", tagsMap["hostname"], dateKey, string(event.MeasurementName()))
+		docKey := fmt.Sprintf("day_%s_%s_%s", hostname, dateKey, string(event.MeasurementName()))
 
 		// Check that it has been created using a cached map, if not, add
 		// to creation queue
 		_, ok := p.createdDocs[docKey]
 		if !ok {
 			if _, ok := p.createdDocs[docKey]; !ok {
-				if randomFieldOrder {
+				if p.conf.RandomFieldOrder {
 					p.createQueue = append(p.createQueue, bson.M{
 						aggDocID:      docKey,
 						aggKeyID:      dateKey,
@@ -175,13 +187,16 @@ func (p *aggProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint6
 		}
 		x := pPool.Get().(*point)
 		x.Fields = map[string]interface{}{}
-		f := &tsbsMongo.MongoReading{}
+		f := &MongoReading{}
 		for j := 0; j < event.FieldsLength(); j++ {
 			event.Fields(f, j)
-			x.Fields[string(f.Key())] = f.Value()
+			v := f.GoValue()
+			x.Fields[string(f.Key())] = v
+			if v != nil {
+				eventCnt++
+			}
 		}
 		x.Timestamp = ts
-		eventCnt += uint64(len(x.Fields))
 
 		docToEvents[docKey] = append(docToEvents[docKey], x)
 	}
@@ -213,7 +228,7 @@ func (p *aggProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint6
 		}
 
 		// All documents accounted for, finally run the operation
-		opts := options.BulkWrite().SetOrdered(orderedInserts)
+		opts := options.BulkWrite().SetOrdered(p.conf.OrderedInserts)
 		_, err := p.collection.BulkWrite(context.Background(), models, opts)
 		if err != nil {
 			log.Fatalf("Bulk aggregate update err: %s\n", err.Error())
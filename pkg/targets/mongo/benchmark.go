@@ -0,0 +1,178 @@
+package mongo
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+const (
+	collectionName     = "point_data"
+	aggDocID           = "doc_id"
+	aggDateFmt         = "20060102_15" // see Go docs for how we arrive at this time format
+	aggKeyID           = "key_id"
+	aggInsertBatchSize = 500 // found via trial-and-error
+	timestampField     = "time"
+)
+
+// SpecificConfig is the configuration of the Mongo loader.
+type SpecificConfig struct {
+	URL                  string        `yaml:"url" mapstructure:"url"`
+	WriteTimeout         time.Duration `yaml:"write-timeout" mapstructure:"write-timeout"`
+	DocumentPer          bool          `yaml:"document-per-event" mapstructure:"document-per-event"`
+	TimeseriesCollection bool          `yaml:"timeseries-collection" mapstructure:"timeseries-collection"`
+	RetryableWrites      bool          `yaml:"retryable-writes" mapstructure:"retryable-writes"`
+	OrderedInserts       bool          `yaml:"ordered-inserts" mapstructure:"ordered-inserts"`
+	RandomFieldOrder     bool          `yaml:"random-field-order" mapstructure:"random-field-order"`
+	BatchMetaFields      bool          `yaml:"batch-meta-fields" mapstructure:"batch-meta-fields"`
+	CollectionSharded    bool          `yaml:"collection-sharded" mapstructure:"collection-sharded"`
+	NumInitChunks        uint          `yaml:"number-initial-chunks" mapstructure:"number-initial-chunks"`
+	ShardKeySpec         string        `yaml:"shard-key-spec" mapstructure:"shard-key-spec"`
+	BalancerOn           bool          `yaml:"balancer-on" mapstructure:"balancer-on"`
+	PreSplitChunks       uint          `yaml:"pre-split-chunks" mapstructure:"pre-split-chunks"`
+	PreSplitStart        string        `yaml:"pre-split-timestamp-start" mapstructure:"pre-split-timestamp-start"`
+	PreSplitEnd          string        `yaml:"pre-split-timestamp-end" mapstructure:"pre-split-timestamp-end"`
+	PreSplitScale        uint64        `yaml:"pre-split-scale" mapstructure:"pre-split-scale"`
+	PreSplitTagFormat    string        `yaml:"pre-split-tag-format" mapstructure:"pre-split-tag-format"`
+	MetaFieldIndex       string        `yaml:"meta-field-index" mapstructure:"meta-field-index"`
+	Granularity          string        `yaml:"granularity" mapstructure:"granularity"`
+
+	preSplitStart time.Time
+	preSplitEnd   time.Time
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// Validate checks that the options of the config go together, and parses
+// the pre-split time range.
+func (c *SpecificConfig) Validate() error {
+	if !c.DocumentPer && c.TimeseriesCollection {
+		return fmt.Errorf("must set document-per-event=true in order to use timeseries-collection=true")
+	}
+	if !c.TimeseriesCollection && c.BatchMetaFields {
+		return fmt.Errorf("must set document-per-event=true and timeseries-collection=true in order to use batch-meta-fields=true")
+	}
+	if c.CollectionSharded && len(c.ShardKeySpec) == 0 {
+		return fmt.Errorf("must specify a shard key spec in order to use a sharded collection")
+	}
+	if c.PreSplitChunks > 0 {
+		if !c.CollectionSharded {
+			return fmt.Errorf("must set collection-sharded=true in order to use pre-split-chunks")
+		}
+		var err error
+		if c.PreSplitStart != "" {
+			c.preSplitStart, err = time.Parse(time.RFC3339, c.PreSplitStart)
+			if err != nil {
+				return fmt.Errorf("invalid pre-split-timestamp-start: %v", err)
+			}
+		}
+		if c.PreSplitEnd != "" {
+			c.preSplitEnd, err = time.Parse(time.RFC3339, c.PreSplitEnd)
+			if err != nil {
+				return fmt.Errorf("invalid pre-split-timestamp-end: %v", err)
+			}
+		}
+	}
+	if len(c.MetaFieldIndex) == 0 {
+		return fmt.Errorf("must specify a field within metaField to index on")
+	}
+	return nil
+}
+
+// preSplitDefaults sets the pre-split time range and scale which were not
+// given to those of the simulated data.
+func (c *SpecificConfig) preSplitDefaults(timeStart, timeEnd string, scale uint64) {
+	if c.PreSplitStart == "" {
+		c.PreSplitStart = timeStart
+	}
+	if c.PreSplitEnd == "" {
+		c.PreSplitEnd = timeEnd
+	}
+	if c.PreSplitScale == 0 {
+		c.PreSplitScale = scale
+	}
+}
+
+// benchmark loads Mongo with one document per event, or with the events of
+// a host and measurement aggregated per hour.
+type benchmark struct {
+	dbName string
+	conf   *SpecificConfig
+	ds     targets.DataSource
+	dbc    *dbCreator
+}
+
+// NewBenchmark returns the benchmark loading Mongo database dbName with the
+// data of the data source.
+func NewBenchmark(dbName string, conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if sim := dataSourceConfig.Simulator; dataSourceConfig.Type == source.SimulatorDataSourceType && sim != nil {
+		conf.preSplitDefaults(sim.TimeStart, sim.TimeEnd, sim.Scale)
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	ds, err := common.NewDataSource(dataSourceConfig, &Serializer{}, false, func(br *bufio.Reader) (targets.DataSource, error) {
+		return newFileDataSource(br), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !conf.DocumentPer {
+		// Pre-create the needed empty subdoc for new aggregate docs
+		generateEmptyHourDoc()
+	}
+	return &benchmark{
+		dbName: dbName,
+		conf:   conf,
+		ds:     ds,
+		dbc:    &dbCreator{conf: conf},
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	if b.conf.BatchMetaFields {
+		return &metaFieldIndexer{metaField: b.conf.MetaFieldIndex}
+	}
+	if b.conf.DocumentPer {
+		return &targets.ConstantIndexer{}
+	}
+	return &hostnameIndexer{partitions: maxPartitions}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	if b.conf.DocumentPer {
+		return &naiveProcessor{dbName: b.dbName, conf: b.conf, dbc: b.dbc}
+	}
+	return &aggProcessor{dbName: b.dbName, conf: b.conf, dbc: b.dbc}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return b.dbc
+}
+
+// HashWorkers returns whether the loader must hash the workers: with the
+// aggregated documents (document-per-event false), so that the documents of a
+// host are all created by the same worker, and with batch-meta-fields, so that
+// the measurements with the same meta field go to the same worker.
+func (b *benchmark) HashWorkers() bool {
+	return !b.conf.DocumentPer || b.conf.BatchMetaFields
+}
@@ -0,0 +1,186 @@
+package mongo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// fileDataSource reads the flatbuffers of the points into its arena, the
+// batches release them once processed.
+type fileDataSource struct {
+	lenBuf []byte
+	r      *bufio.Reader
+	arena  *data.Arena
+	// position is the number of bytes read
+	position int64
+}
+
+func newFileDataSource(br *bufio.Reader) *fileDataSource {
+	return &fileDataSource{lenBuf: make([]byte, 8), r: br, arena: data.NewArena(data.DefaultArenaChunkSize)}
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	item := &MongoPoint{}
+
+	lenRead, err := d.r.Read(d.lenBuf)
+	if err == io.EOF {
+		return data.LoadedPoint{}
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	// ensure correct len of receiving buffer
+	l := int(binary.LittleEndian.Uint64(d.lenBuf))
+	itemBuf, chunk := d.arena.Alloc(l)
+
+	// read the bytes and init the flatbuffer object
+	totRead := 0
+	for totRead < l {
+		m, err := d.r.Read(itemBuf[totRead:])
+		// (EOF is also fatal)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		totRead += m
+	}
+	if totRead != len(itemBuf) {
+		panic(fmt.Sprintf("reader/writer logic error, %d != %d", totRead, len(itemBuf)))
+	}
+	d.position += int64(lenRead + totRead)
+	n := flatbuffers.GetUOffsetT(itemBuf)
+	item.Init(itemBuf, n)
+
+	return data.LoadedPoint{Data: item, Chunk: chunk}
+}
+
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+// Position returns the number of bytes of the data read.
+func (d *fileDataSource) Position() int64 {
+	return d.position
+}
+
+var batchPool = &sync.Pool{New: func() interface{} { return &batch{} }}
+
+type batch struct {
+	arr []*MongoPoint
+	// chunks hold the flatbuffers of the points
+	chunks []*data.ArenaChunk
+}
+
+func (b *batch) Len() uint {
+	return uint(len(b.arr))
+}
+
+func (b *batch) Append(item data.LoadedPoint) {
+	that := item.Data.(*MongoPoint)
+	b.arr = append(b.arr, that)
+	if item.Chunk != nil {
+		b.chunks = append(b.chunks, item.Chunk)
+	}
+}
+
+// Release releases the flatbuffers of the points and returns the batch to
+// the pool.
+func (b *batch) Release() {
+	for i, c := range b.chunks {
+		c.Release()
+		b.chunks[i] = nil
+	}
+	for i := range b.arr {
+		b.arr[i] = nil
+	}
+	b.arr = b.arr[:0]
+	b.chunks = b.chunks[:0]
+	batchPool.Put(b)
+}
+
+type factory struct{}
+
+func (f *factory) New() targets.Batch {
+	return batchPool.Get().(*batch)
+}
+
+// TagValue returns the value of the tag key of the point as a string, if it
+// has one which is not null.
+func (rcv *MongoPoint) TagValue(key string) (string, bool) {
+	t := &MongoTag{}
+	for j := 0; j < rcv.TagsLength(); j++ {
+		rcv.Tags(t, j)
+		if string(t.Key()) == key {
+			return t.StringValue()
+		}
+	}
+	return "", false
+}
+
+// GoValue returns the value of the tag as a string, int64, float64, bool or
+// nil for null. The tags serialized before the typed values are strings.
+func (rcv *MongoTag) GoValue() interface{} {
+	if rcv.TypedValueType() == MongoValueNONE {
+		return string(rcv.Value())
+	}
+	return unionValue(rcv.TypedValueType(), rcv.TypedValue)
+}
+
+// StringValue returns the value of the tag formatted as a string, unless it
+// is null.
+func (rcv *MongoTag) StringValue() (string, bool) {
+	switch v := rcv.GoValue().(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// GoValue returns the value of the field as an int64, float64, string, bool
+// or nil for null. The fields serialized before the typed values are float64.
+func (rcv *MongoReading) GoValue() interface{} {
+	if rcv.TypedValueType() == MongoValueNONE {
+		return rcv.Value()
+	}
+	return unionValue(rcv.TypedValueType(), rcv.TypedValue)
+}
+
+// unionValue reads the member of the MongoValue union of type valueType.
+func unionValue(valueType MongoValue, member func(obj *flatbuffers.Table) bool) interface{} {
+	var t flatbuffers.Table
+	if !member(&t) {
+		return nil
+	}
+	switch valueType {
+	case MongoValueMongoLong:
+		v := &MongoLong{}
+		v.Init(t.Bytes, t.Pos)
+		return v.Value()
+	case MongoValueMongoDouble:
+		v := &MongoDouble{}
+		v.Init(t.Bytes, t.Pos)
+		return v.Value()
+	case MongoValueMongoString:
+		v := &MongoString{}
+		v.Init(t.Bytes, t.Pos)
+		return string(v.Value())
+	case MongoValueMongoBool:
+		v := &MongoBool{}
+		v.Init(t.Bytes, t.Pos)
+		return v.Value()
+	default:
+		return nil
+	}
+}
@@ -1,10 +1,11 @@
-package main
+package mongo
 
 import (
 	"context"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,16 +13,20 @@ import (
 )
 
 type dbCreator struct {
+	conf   *SpecificConfig
 	client *mongo.Client
+	// loadStart is the time after which chunk migrations are attributed to the load
+	loadStart time.Time
 }
 
 func (d *dbCreator) Init() {
 	var err error
-	opts := options.Client().ApplyURI(daemonURL).SetSocketTimeout(writeTimeout).SetRetryWrites(retryableWrites)
+	opts := options.Client().ApplyURI(d.conf.URL).SetSocketTimeout(d.conf.WriteTimeout).SetRetryWrites(d.conf.RetryableWrites)
 	d.client, err = mongo.Connect(context.Background(), opts)
 	if err != nil {
 		log.Fatal(err)
 	}
+	d.loadStart = time.Now()
 }
 
 func (d *dbCreator) DBExists(dbName string) bool {
@@ -53,11 +58,11 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	createCollCmd := make(bson.D, 0, 4)
 	createCollCmd = append(createCollCmd, bson.E{"create", collectionName})
 
-	if timeseriesCollection {
+	if d.conf.TimeseriesCollection {
 		createCollCmd = append(createCollCmd, bson.E{"timeseries", bson.M{
 			"timeField": timestampField,
 			"metaField": "tags",
-			"granularity": granularity,
+			"granularity": d.conf.Granularity,
 		}})
 	}
 
@@ -70,7 +75,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 		return fmt.Errorf("create collection err: %v", createCollRes.Err().Error())
 	}
 
-	if collectionSharded {
+	if d.conf.CollectionSharded {
 	        // first enable sharding on dbName
 		enableShardingCmd := make(bson.D, 0, 4)
 		enableShardingCmd = append(enableShardingCmd, bson.E{"enableSharding", dbName})
@@ -84,16 +89,16 @@ func (d *dbCreator) CreateDB(dbName string) error {
 		// then shard the collection
 		shardCollCmd := make(bson.D, 0, 4)
 		shardCollCmd = append(shardCollCmd, bson.E{"shardCollection",dbName+"."+collectionName})
-		var shardKey interface{}
+		var shardKey bson.D
 		
-		err := bson.UnmarshalExtJSON([]byte(shardKeySpec), true, &shardKey)
+		err := bson.UnmarshalExtJSON([]byte(d.conf.ShardKeySpec), true, &shardKey)
 		if err != nil {
 		   err = bson.UnmarshalExtJSON([]byte("{\"time\":1}"), true, &shardKey)		       
 		}
 		shardCollCmd = append(shardCollCmd, bson.E{"key", shardKey})
 
-		if numInitChunks > 0 {
-		   	shardCollCmd = append(shardCollCmd, bson.E{"numInitialChunks", numInitChunks})
+		if d.conf.NumInitChunks > 0 {
+		   	shardCollCmd = append(shardCollCmd, bson.E{"numInitialChunks", d.conf.NumInitChunks})
 		}
 	   	shardCollRes := d.client.Database("admin").RunCommand(context.Background(), shardCollCmd)
 	
@@ -101,8 +106,14 @@ func (d *dbCreator) CreateDB(dbName string) error {
 		        return fmt.Errorf("shard collection err: %v", shardCollRes.Err().Error())
 	        }
 
+		if d.conf.PreSplitChunks > 0 {
+			if err := d.preSplit(dbName, shardKey); err != nil {
+				return fmt.Errorf("pre-split err: %v", err)
+			}
+		}
+
 		balancerCmd := make(bson.D, 0, 4)
-		if balancerOn {
+		if d.conf.BalancerOn {
 		        balancerCmd = append(balancerCmd, bson.E{"balancerStart", 1})		
 		} else {
 		        balancerCmd = append(balancerCmd, bson.E{"balancerStop", 1})		
@@ -114,10 +125,10 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	}
 
  	var model []mongo.IndexModel
-	if documentPer {
+	if d.conf.DocumentPer {
 		model = []mongo.IndexModel{
 			{
-				Keys: bson.D{{"tags." + metaFieldIndex, 1}, {"time", -1}},
+				Keys: bson.D{{"tags." + d.conf.MetaFieldIndex, 1}, {"time", -1}},
 			},
 		}
 	} else {
@@ -128,7 +139,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 				Keys: bson.D{{aggDocID, 1}},
 			},
 			{
-				Keys: bson.D{{aggKeyID, 1}, {"measurement", 1}, {"tags." + metaFieldIndex, 1}},
+				Keys: bson.D{{aggKeyID, 1}, {"measurement", 1}, {"tags." + d.conf.MetaFieldIndex, 1}},
 			},
 		}
 	}
@@ -138,9 +149,21 @@ func (d *dbCreator) CreateDB(dbName string) error {
 		return fmt.Errorf("create indexes err: %v", err.Error())
 	}
 
+	d.loadStart = time.Now()
 	return nil
 }
 
+func (d *dbCreator) Report(dbName string) (map[string]interface{}, error) {
+	if !d.conf.CollectionSharded {
+		return nil, nil
+	}
+	dist, err := d.shardDistribution(dbName)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"sharding": dist}, nil
+}
+
 func (d *dbCreator) Close() {
 	serverStatusCmd := make(bson.D, 0, 4)
 	serverStatusCmd = append(serverStatusCmd, bson.E{"serverStatus", 1})
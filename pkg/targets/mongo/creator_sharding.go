@@ -0,0 +1,191 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+)
+
+const bucketsPrefix = "system.buckets."
+
+// shardedNamespace returns the namespace that actually holds the sharded data.
+// Time-series collections are sharded through their underlying buckets collection.
+func shardedNamespace(dbName string, timeseries bool) string {
+	if timeseries {
+		return dbName + "." + bucketsPrefix + collectionName
+	}
+	return dbName + "." + collectionName
+}
+
+// preSplit splits the (empty) sharded collection into pre-split-chunks chunks and
+// distributes them round-robin over the shards in the cluster.
+func (d *dbCreator) preSplit(dbName string, shardKey bson.D) error {
+	points, err := PreSplitPoints(shardKey, d.conf.PreSplitChunks, d.conf.preSplitStart, d.conf.preSplitEnd, d.conf.PreSplitTagFormat, d.conf.PreSplitScale, timestampField, d.conf.TimeseriesCollection)
+	if err != nil {
+		return err
+	}
+	ns := shardedNamespace(dbName, d.conf.TimeseriesCollection)
+	admin := d.client.Database("admin")
+	ctx := context.Background()
+
+	for _, p := range points {
+		res := admin.RunCommand(ctx, bson.D{{"split", ns}, {"middle", p}})
+		if res.Err() != nil {
+			return fmt.Errorf("split at %v: %v", p, res.Err())
+		}
+	}
+
+	var shards struct {
+		Shards []struct {
+			ID string `bson:"_id"`
+		} `bson:"shards"`
+	}
+	if err := admin.RunCommand(ctx, bson.D{{"listShards", 1}}).Decode(&shards); err != nil {
+		return fmt.Errorf("listShards: %v", err)
+	}
+	if len(shards.Shards) == 0 {
+		return fmt.Errorf("no shards found")
+	}
+
+	lower := ShardKeyDoc(shardKey, primitive.MinKey{}, primitive.MinKey{}, timestampField, d.conf.TimeseriesCollection)
+	for i := 0; i <= len(points); i++ {
+		upper := ShardKeyDoc(shardKey, primitive.MaxKey{}, primitive.MaxKey{}, timestampField, d.conf.TimeseriesCollection)
+		if i < len(points) {
+			upper = points[i]
+		}
+		to := shards.Shards[i%len(shards.Shards)].ID
+		res := admin.RunCommand(ctx, bson.D{{"moveChunk", ns}, {"bounds", bson.A{lower, upper}}, {"to", to}})
+		// a chunk that already lives on the target shard is reported as an error
+		// by some server versions, which is harmless here
+		if res.Err() != nil {
+			log.Printf("moveChunk %v to %s: %v", lower, to, res.Err())
+		}
+		lower = upper
+	}
+	return nil
+}
+
+// ShardStats describes how much of the collection is stored on a single shard.
+type ShardStats struct {
+	Chunks    int64 `json:"chunks"`
+	Documents int64 `json:"documents"`
+	SizeBytes int64 `json:"sizeBytes"`
+}
+
+// Migration counts the chunk migrations between two shards.
+type Migration struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int64  `json:"count"`
+}
+
+// ShardDistribution is the sharding part of the results file.
+type ShardDistribution struct {
+	Namespace       string                 `json:"namespace"`
+	Shards          map[string]*ShardStats `json:"shards"`
+	MigrationsTotal int64                  `json:"migrationsTotal"`
+	Migrations      []Migration            `json:"migrations"`
+}
+
+// shardDistribution collects the chunk and document distribution per shard and
+// the chunk migrations committed since the load started. For time-series
+// collections documents are buckets.
+func (d *dbCreator) shardDistribution(dbName string) (*ShardDistribution, error) {
+	ctx := context.Background()
+	ns := shardedNamespace(dbName, d.conf.TimeseriesCollection)
+	config := d.client.Database("config")
+	dist := &ShardDistribution{Namespace: ns, Shards: map[string]*ShardStats{}}
+	shard := func(name string) *ShardStats {
+		if _, ok := dist.Shards[name]; !ok {
+			dist.Shards[name] = &ShardStats{}
+		}
+		return dist.Shards[name]
+	}
+
+	// Newer servers key chunks by collection uuid instead of namespace
+	chunkFilter := bson.A{bson.M{"ns": ns}}
+	var coll struct {
+		UUID primitive.Binary `bson:"uuid"`
+	}
+	if err := config.Collection("collections").FindOne(ctx, bson.M{"_id": ns}).Decode(&coll); err == nil && len(coll.UUID.Data) > 0 {
+		chunkFilter = append(chunkFilter, bson.M{"uuid": coll.UUID})
+	}
+	cur, err := config.Collection("chunks").Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"$or": chunkFilter}},
+		bson.M{"$group": bson.M{"_id": "$shard", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("chunk distribution: %v", err)
+	}
+	var chunks []struct {
+		Shard string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}
+	if err := cur.All(ctx, &chunks); err != nil {
+		return nil, fmt.Errorf("chunk distribution: %v", err)
+	}
+	for _, c := range chunks {
+		shard(c.Shard).Chunks = c.Count
+	}
+
+	collName := strings.TrimPrefix(ns, dbName+".")
+	cur, err = d.client.Database(dbName).Collection(collName).Aggregate(ctx, bson.A{
+		bson.M{"$collStats": bson.M{"storageStats": bson.M{}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("collStats: %v", err)
+	}
+	var stats []struct {
+		Shard        string `bson:"shard"`
+		StorageStats struct {
+			Count int64 `bson:"count"`
+			Size  int64 `bson:"size"`
+		} `bson:"storageStats"`
+	}
+	if err := cur.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("collStats: %v", err)
+	}
+	for _, s := range stats {
+		st := shard(s.Shard)
+		st.Documents = s.StorageStats.Count
+		st.SizeBytes = s.StorageStats.Size
+	}
+
+	cur, err = config.Collection("changelog").Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{
+			"what": "moveChunk.commit",
+			"ns":   ns,
+			"time": bson.M{"$gte": d.loadStart},
+		}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"from": "$details.from", "to": "$details.to"},
+			"count": bson.M{"$sum": 1},
+		}},
+		bson.M{"$sort": bson.D{{"_id.from", 1}, {"_id.to", 1}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("migrations: %v", err)
+	}
+	var moves []struct {
+		Route struct {
+			From string `bson:"from"`
+			To   string `bson:"to"`
+		} `bson:"_id"`
+		Count int64 `bson:"count"`
+	}
+	if err := cur.All(ctx, &moves); err != nil {
+		return nil, fmt.Errorf("migrations: %v", err)
+	}
+	dist.Migrations = make([]Migration, 0, len(moves))
+	for _, m := range moves {
+		dist.Migrations = append(dist.Migrations, Migration{From: m.Route.From, To: m.Route.To, Count: m.Count})
+		dist.MigrationsTotal += m.Count
+	}
+
+	return dist, nil
+}
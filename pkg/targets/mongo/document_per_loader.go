@@ -1,4 +1,4 @@
-package main
+package mongo
 
 import (
 	"context"
@@ -10,34 +10,18 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/targets"
-	tsbsMongo "github.com/timescale/tsbs/pkg/targets/mongo"
 )
 
-// naiveBenchmark allows you to run a benchmark using the naive, one document per
-// event Mongo approach
-type naiveBenchmark struct {
-	mongoBenchmark
-}
-
-func newNaiveBenchmark(l load.BenchmarkRunner, loaderConf *load.BenchmarkRunnerConfig) *naiveBenchmark {
-	return &naiveBenchmark{mongoBenchmark{loaderConf.FileName, l, &dbCreator{}}}
-}
-
-func (b *naiveBenchmark) GetProcessor() targets.Processor {
-	return &naiveProcessor{dbc: b.dbc}
-}
-
-func (b *naiveBenchmark) GetPointIndexer(_ uint) targets.PointIndexer {
-	return &targets.ConstantIndexer{}
-}
-
 type singlePoint map[string]interface{}
 
 var spPool = &sync.Pool{New: func() interface{} { return &singlePoint{} }}
 
+// naiveProcessor loads the events using the naive, one document per event
+// Mongo approach.
 type naiveProcessor struct {
+	dbName     string
+	conf       *SpecificConfig
 	dbc        *dbCreator
 	collection *mongo.Collection
 
@@ -46,7 +30,7 @@ type naiveProcessor struct {
 
 func (p *naiveProcessor) Init(_ int, doLoad, _ bool) {
 	if doLoad {
-		p.collection = p.dbc.client.Database(loader.DatabaseName()).Collection(collectionName)
+		p.collection = p.dbc.client.Database(p.dbName).Collection(collectionName)
 	}
 	p.pvs = []interface{}{}
 }
@@ -62,49 +46,56 @@ func (p *naiveProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uin
 	p.pvs = p.pvs[:len(batch)]
 	var metricCnt uint64
 
-	if randomFieldOrder {
+	if p.conf.RandomFieldOrder {
 		for i, event := range batch {
 			x := spPool.Get().(*singlePoint)
 			(*x)["measurement"] = string(event.MeasurementName())
 			(*x)[timestampField] = time.Unix(0, event.Timestamp())
-			(*x)["tags"] = map[string]string{}
-			f := &tsbsMongo.MongoReading{}
+			tags := map[string]interface{}{}
+			f := &MongoReading{}
 			for j := 0; j < event.FieldsLength(); j++ {
 				event.Fields(f, j)
-				(*x)[string(f.Key())] = f.Value()
+				v := f.GoValue()
+				(*x)[string(f.Key())] = v
+				if v != nil {
+					metricCnt++
+				}
 			}
-			t := &tsbsMongo.MongoTag{}
+			t := &MongoTag{}
 			for j := 0; j < event.TagsLength(); j++ {
 				event.Tags(t, j)
-				(*x)["tags"].(map[string]string)[string(t.Key())] = string(t.Value())
+				tags[string(t.Key())] = t.GoValue()
 			}
+			(*x)["tags"] = tags
 			p.pvs[i] = x
-			metricCnt += uint64(event.FieldsLength())
 		}
 	} else {
 		for i, event := range batch {
 			x := bson.D{}
 			x = append(x, bson.E{"measurement", string(event.MeasurementName())})
 			x = append(x, bson.E{timestampField, time.Unix(0, event.Timestamp())})
-			f := &tsbsMongo.MongoReading{}
+			f := &MongoReading{}
 			for j := 0; j < event.FieldsLength(); j++ {
 				event.Fields(f, j)
-				x = append(x, bson.E{string(f.Key()), f.Value()})
+				v := f.GoValue()
+				x = append(x, bson.E{string(f.Key()), v})
+				if v != nil {
+					metricCnt++
+				}
 			}
-			t := &tsbsMongo.MongoTag{}
+			t := &MongoTag{}
 			tags := bson.D{}
 			for j := 0; j < event.TagsLength(); j++ {
 				event.Tags(t, j)
-				tags = append(tags, bson.E{string(t.Key()), string(t.Value())})
+				tags = append(tags, bson.E{string(t.Key()), t.GoValue()})
 			}
 			x = append(x, bson.E{"tags", tags})
 			p.pvs[i] = x
-			metricCnt += uint64(event.FieldsLength())
 		}
 	}
 
 	if doLoad {
-		opts := options.InsertMany().SetOrdered(orderedInserts)
+		opts := options.InsertMany().SetOrdered(p.conf.OrderedInserts)
 		_, err := p.collection.InsertMany(context.Background(), p.pvs, opts)
 		if err != nil {
 			log.Fatalf("Bulk insert docs err: %s\n", err.Error())
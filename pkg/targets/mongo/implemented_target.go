@@ -7,11 +7,12 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &mongoTarget{}
 }
 
@@ -32,6 +33,15 @@ func (t *mongoTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.Flag
 														"if 0 then do not specifiy any initial chunks and let the system default to 2 per shard")
 	flagSet.String(flagPrefix+"shard-key-spec", "{time:1}", "shard key spec")
 	flagSet.Bool(flagPrefix+"balancer-on", true, "whether to keep shard re-balancer on")
+	flagSet.Uint(flagPrefix+"pre-split-chunks", 0, "number of chunks to pre-split a range sharded collection into, using the generated time range and host set;"+
+		"if 0 then do not pre-split")
+	flagSet.String(flagPrefix+"pre-split-timestamp-start", "", "Beginning timestamp of the generated data (RFC3339), used for pre-splitting on time; "+
+		"defaults to the timestamp-start of a SIMULATOR data source")
+	flagSet.String(flagPrefix+"pre-split-timestamp-end", "", "Ending timestamp of the generated data (RFC3339), used for pre-splitting on time; "+
+		"defaults to the timestamp-end of a SIMULATOR data source")
+	flagSet.Uint64(flagPrefix+"pre-split-scale", 0, "Number of hosts (scale) of the generated data, used for pre-splitting on a meta field; "+
+		"defaults to the scale of a SIMULATOR data source")
+	flagSet.String(flagPrefix+"pre-split-tag-format", "host_%d", "Format of the generated values of the meta-field-index tag, used for pre-splitting on a meta field")
 	flagSet.String(flagPrefix+"meta-field-index", "hostname", "Field name within metaField to index on")
 	flagSet.String(flagPrefix+"granularity", "seconds", "Granularity for time-series collection")
 }
@@ -44,6 +54,23 @@ func (t *mongoTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *mongoTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *mongoTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(targetDB, conf, dataSourceConfig)
+}
+
+func (t *mongoTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"url", "mongodb://localhost:27017", "Mongo URL.")
+	flagSet.Duration(flagPrefix+"read-timeout", 300*time.Second, "Timeout value for individual queries")
+}
+
+func (t *mongoTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
@@ -0,0 +1,103 @@
+package mongo
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/blagojts/viper"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func init() {
+	// needed for deserializing the mongo query from gob
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register(bson.M{})
+	gob.Register(bson.D{})
+	gob.Register(bson.A{})
+	gob.Register([]bson.M{})
+	gob.Register(time.Time{})
+}
+
+// QuerySpecificConfig is the configuration of the Mongo query runner.
+type QuerySpecificConfig struct {
+	URL         string        `yaml:"url" mapstructure:"url"`
+	ReadTimeout time.Duration `yaml:"read-timeout" mapstructure:"read-timeout"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark connects to the Mongo deployment in conf and returns the
+// QueryBenchmark running the aggregation pipelines of runner on it.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	opts := options.Client().ApplyURI(conf.URL).SetSocketTimeout(conf.ReadTimeout)
+	client, err := mongo.Connect(context.Background(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.MongoPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{client: client, runner: runner}
+		},
+		Close: func() {
+			client.Disconnect(context.Background())
+		},
+	}, nil
+}
+
+type queryProcessor struct {
+	client     *mongo.Client
+	runner     *query.BenchmarkRunner
+	collection *mongo.Collection
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.collection = p.client.Database(p.runner.DatabaseName()).Collection("point_data")
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	mq := q.(*query.Mongo)
+	start := time.Now().UnixNano()
+
+	cursor, err := p.collection.Aggregate(context.Background(), mq.Pipeline)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if p.runner.DebugLevel() > 0 {
+		fmt.Println(mq.Pipeline)
+	}
+	cnt := 0
+	for cursor.Next(context.Background()) {
+		if p.runner.DoPrintResponses() {
+			fmt.Printf("ID %d: %v\n", q.GetID(), cursor.Current)
+		}
+		cnt++
+	}
+	if p.runner.DebugLevel() > 0 {
+		fmt.Println(cnt)
+	}
+	err = cursor.Close(context.Background())
+
+	took := time.Now().UnixNano() - start
+	lag := float64(took) / 1e6 // milliseconds
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, err
+}
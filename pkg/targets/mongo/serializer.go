@@ -30,20 +30,7 @@ func (s *Serializer) Serialize(p *data.Point, w io.Writer) (err error) {
 	tagKeys := p.TagKeys()
 	tagValues := p.TagValues()
 	for i := len(tagKeys); i > 0; i-- {
-		switch v := tagValues[i-1].(type) {
-		case string:
-			k := string(tagKeys[i-1])
-			key := b.CreateString(k)
-			val := b.CreateString(v)
-			MongoTagStart(b)
-			MongoTagAddKey(b, key)
-			MongoTagAddValue(b, val)
-			tags = append(tags, MongoTagEnd(b))
-		case nil:
-			continue
-		default:
-			panic("non-string tags not implemented for mongo db")
-		}
+		tags = append(tags, createTag(b, tagKeys[i-1], tagValues[i-1]))
 	}
 	MongoPointStartTagsVector(b, len(tags))
 	for _, t := range tags {
@@ -57,12 +44,7 @@ func (s *Serializer) Serialize(p *data.Point, w io.Writer) (err error) {
 	fieldKeys := p.FieldKeys()
 	fieldValues := p.FieldValues()
 	for i := len(fieldKeys); i > 0; i-- {
-		val := fieldValues[i-1]
-		if val == nil {
-			continue
-		}
-		newField := createField(b, fieldKeys[i-1], val)
-		fields = append(fields, newField)
+		fields = append(fields, createField(b, fieldKeys[i-1], fieldValues[i-1]))
 	}
 	MongoPointStartFieldsVector(b, len(fields))
 	for _, f := range fields {
@@ -101,24 +83,72 @@ func (s *Serializer) Serialize(p *data.Point, w io.Writer) (err error) {
 	return nil
 }
 
+func createTag(b *flatbuffers.Builder, key []byte, val interface{}) flatbuffers.UOffsetT {
+	keyStr := b.CreateString(string(key))
+	valType, valOffset := createValue(b, val)
+	MongoTagStart(b)
+	MongoTagAddKey(b, keyStr)
+	MongoTagAddTypedValueType(b, valType)
+	MongoTagAddTypedValue(b, valOffset)
+	return MongoTagEnd(b)
+}
+
 func createField(b *flatbuffers.Builder, key []byte, val interface{}) flatbuffers.UOffsetT {
 	keyStr := b.CreateString(string(key))
+	valType, valOffset := createValue(b, val)
 	MongoReadingStart(b)
 	MongoReadingAddKey(b, keyStr)
-	prependValue(b, val)
+	MongoReadingAddTypedValueType(b, valType)
+	MongoReadingAddTypedValue(b, valOffset)
 	return MongoReadingEnd(b)
 }
-func prependValue(b *flatbuffers.Builder, value interface{}) {
+
+// createValue writes value as the member of the MongoValue union of its
+// type, so it is loaded with the matching BSON type, and returns the type
+// and the offset of the member.
+func createValue(b *flatbuffers.Builder, value interface{}) (MongoValue, flatbuffers.UOffsetT) {
 	switch val := value.(type) {
+	case nil:
+		MongoNullStart(b)
+		return MongoValueMongoNull, MongoNullEnd(b)
 	case float64:
-		MongoReadingAddValue(b, val)
+		return createDouble(b, val)
 	case float32:
-		MongoReadingAddValue(b, float64(val))
+		return createDouble(b, float64(val))
 	case int:
-		MongoReadingAddValue(b, float64(val))
+		return createLong(b, int64(val))
 	case int64:
-		MongoReadingAddValue(b, float64(val))
+		return createLong(b, val)
+	case int32:
+		return createLong(b, int64(val))
+	case bool:
+		MongoBoolStart(b)
+		MongoBoolAddValue(b, val)
+		return MongoValueMongoBool, MongoBoolEnd(b)
+	case string:
+		return createString(b, val)
+	case []byte:
+		return createString(b, string(val))
 	default:
-		panic(fmt.Sprintf("cannot covert %T to float64", val))
+		panic(fmt.Sprintf("cannot serialize %T for mongo db", val))
 	}
 }
+
+func createDouble(b *flatbuffers.Builder, val float64) (MongoValue, flatbuffers.UOffsetT) {
+	MongoDoubleStart(b)
+	MongoDoubleAddValue(b, val)
+	return MongoValueMongoDouble, MongoDoubleEnd(b)
+}
+
+func createLong(b *flatbuffers.Builder, val int64) (MongoValue, flatbuffers.UOffsetT) {
+	MongoLongStart(b)
+	MongoLongAddValue(b, val)
+	return MongoValueMongoLong, MongoLongEnd(b)
+}
+
+func createString(b *flatbuffers.Builder, val string) (MongoValue, flatbuffers.UOffsetT) {
+	str := b.CreateString(val)
+	MongoStringStart(b)
+	MongoStringAddValue(b, str)
+	return MongoValueMongoString, MongoStringEnd(b)
+}
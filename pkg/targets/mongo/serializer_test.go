@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/targets"
 	"io"
 	"log"
 	"testing"
@@ -64,6 +65,42 @@ func TestMongoSerializerSerialize(t *testing.T) {
 				readingVals: serialize.TestPointMultiField().FieldValues(),
 			},
 		},
+		{
+			desc:       "a Point with a nil tag",
+			inputPoint: serialize.TestPointWithNilTag(),
+			want: output{
+				name:        string(serialize.TestMeasurement),
+				ts:          serialize.TestNow.UnixNano(),
+				tagKeys:     [][]byte{[]byte("hostname")},
+				tagVals:     []interface{}{nil},
+				readingKeys: serialize.TestPointWithNilTag().FieldKeys(),
+				readingVals: serialize.TestPointWithNilTag().FieldValues(),
+			},
+		},
+		{
+			desc:       "a Point with a nil field",
+			inputPoint: serialize.TestPointWithNilField(),
+			want: output{
+				name:        string(serialize.TestMeasurement),
+				ts:          serialize.TestNow.UnixNano(),
+				tagKeys:     [][]byte{},
+				tagVals:     []interface{}{},
+				readingKeys: serialize.TestPointWithNilField().FieldKeys(),
+				readingVals: serialize.TestPointWithNilField().FieldValues(),
+			},
+		},
+		{
+			desc:       "a Point with typed tags and fields",
+			inputPoint: typedPoint(),
+			want: output{
+				name:        string(serialize.TestMeasurement),
+				ts:          serialize.TestNow.UnixNano(),
+				tagKeys:     typedPoint().TagKeys(),
+				tagVals:     typedPoint().TagValues(),
+				readingKeys: typedPoint().FieldKeys(),
+				readingVals: typedPoint().FieldValues(),
+			},
+		},
 		{
 			desc:       "a Point with no tags",
 			inputPoint: serialize.TestPointNoTags(),
@@ -106,9 +143,9 @@ func TestMongoSerializerSerialize(t *testing.T) {
 			if got := string(tag.Key()); got != want {
 				t.Errorf("%s: incorrect tag key %d: got %s want %s", c.desc, i, got, want)
 			}
-			want = c.want.tagVals[i].(string)
-			if got := string(tag.Value()); got != want {
-				t.Errorf("%s: incorrect tag val %d: got %s want %s", c.desc, i, got, want)
+			wantVal := typedValue(c.want.tagVals[i])
+			if got := tag.GoValue(); got != wantVal {
+				t.Errorf("%s: incorrect tag val %d: got %v (%T) want %v (%T)", c.desc, i, got, got, wantVal, wantVal)
 			}
 		}
 
@@ -128,22 +165,141 @@ func TestMongoSerializerSerialize(t *testing.T) {
 				t.Errorf("%s: incorrect reading key %d: got %s want %s", c.desc, i, got, want)
 			}
 
-			var wantVal float64
-			switch x := c.want.readingVals[i].(type) {
-			case int:
-				wantVal = float64(x)
-			case int64:
-				wantVal = float64(x)
-			case float64:
-				wantVal = x
-			}
-			if got := reading.Value(); got != wantVal {
-				t.Errorf("%s: incorrect reading val %d: got %v want %v", c.desc, i, got, wantVal)
+			wantVal := typedValue(c.want.readingVals[i])
+			if got := reading.GoValue(); got != wantVal {
+				t.Errorf("%s: incorrect reading val %d: got %v (%T) want %v (%T)", c.desc, i, got, got, wantVal, wantVal)
 			}
 		}
 	}
 }
 
+// typedPoint is a Point with tags and fields of all the types of MongoValue,
+// like the tags of the trucks of the iot use case.
+func typedPoint() *data.Point {
+	p := &data.Point{}
+	p.SetMeasurementName(serialize.TestMeasurement)
+	p.SetTimestamp(&serialize.TestNow)
+	p.AppendTag([]byte("name"), "truck_0")
+	p.AppendTag([]byte("load_capacity"), float32(1500))
+	p.AppendTag([]byte("fleet"), nil)
+	p.AppendField([]byte("velocity"), int64(42))
+	p.AppendField([]byte("fuel_state"), 0.5)
+	p.AppendField([]byte("status"), "moving")
+	p.AppendField([]byte("driving"), true)
+	p.AppendField([]byte("heading"), nil)
+	return p
+}
+
+// typedValue is the value v is loaded as, with the type of its MongoValue.
+func typedValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case int:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case float32:
+		return float64(x)
+	case []byte:
+		return string(x)
+	}
+	return v
+}
+
+func TestMongoTagLegacyValues(t *testing.T) {
+	// data serialized before the typed values only has the value slots
+	b := flatbuffers.NewBuilder(0)
+	key := b.CreateString("hostname")
+	val := b.CreateString("host_0")
+	MongoTagStart(b)
+	MongoTagAddKey(b, key)
+	MongoTagAddValue(b, val)
+	tag := MongoTagEnd(b)
+	b.Finish(tag)
+	mt := GetRootAsMongoTag(b.FinishedBytes(), 0)
+	if got := mt.GoValue(); got != "host_0" {
+		t.Errorf("incorrect legacy tag value: got %v", got)
+	}
+
+	b = flatbuffers.NewBuilder(0)
+	key = b.CreateString("usage_user")
+	MongoReadingStart(b)
+	MongoReadingAddKey(b, key)
+	MongoReadingAddValue(b, 1.5)
+	reading := MongoReadingEnd(b)
+	b.Finish(reading)
+	mr := GetRootAsMongoReading(b.FinishedBytes(), 0)
+	if got := mr.GoValue(); got != 1.5 {
+		t.Errorf("incorrect legacy reading value: got %v", got)
+	}
+}
+
+func TestMongoPointTagValue(t *testing.T) {
+	b := new(bytes.Buffer)
+	(&Serializer{}).Serialize(typedPoint(), b)
+	mp := deserializeMongo(bufio.NewReader(b))
+	cases := []struct {
+		key    string
+		want   string
+		wantOk bool
+	}{
+		{key: "name", want: "truck_0", wantOk: true},
+		{key: "load_capacity", want: "1500", wantOk: true},
+		{key: "fleet"},
+		{key: "missing"},
+	}
+	for _, c := range cases {
+		got, ok := mp.TagValue(c.key)
+		if got != c.want || ok != c.wantOk {
+			t.Errorf("%s: incorrect tag value: got %q, %v want %q, %v", c.key, got, ok, c.want, c.wantOk)
+		}
+	}
+}
+
+func TestMongoAffinityKeys(t *testing.T) {
+	b := new(bytes.Buffer)
+	(&Serializer{}).Serialize(typedPoint(), b)
+	item := data.NewLoadedPoint(deserializeMongo(bufio.NewReader(b)))
+
+	var indexer targets.AffinityIndexer = &hostnameIndexer{partitions: 2}
+	if got, ok := indexer.AffinityKey(item); got != "truck_0" || !ok {
+		t.Errorf("incorrect hostname key: got %q, %v", got, ok)
+	}
+	indexer = &metaFieldIndexer{metaField: "load_capacity"}
+	if got, ok := indexer.AffinityKey(item); got != "1500" || !ok {
+		t.Errorf("incorrect meta field key: got %q, %v", got, ok)
+	}
+	indexer = &metaFieldIndexer{metaField: "fleet"}
+	if _, ok := indexer.AffinityKey(item); ok {
+		t.Errorf("null meta field has a key")
+	}
+}
+
+func TestMongoFileDataSourceBatchRelease(t *testing.T) {
+	b := new(bytes.Buffer)
+	for i := 0; i < 3; i++ {
+		(&Serializer{}).Serialize(typedPoint(), b)
+	}
+	ds := newFileDataSource(bufio.NewReader(b))
+	batch := (&factory{}).New().(*batch)
+	for item := ds.NextItem(); item.Data != nil; item = ds.NextItem() {
+		if item.Chunk == nil {
+			t.Fatalf("point not allocated from the arena")
+		}
+		batch.Append(item)
+	}
+	if batch.Len() != 3 || len(batch.chunks) != 3 {
+		t.Fatalf("incorrect batch: %d points %d chunks", batch.Len(), len(batch.chunks))
+	}
+	if got, _ := batch.arr[2].TagValue("name"); got != "truck_0" {
+		t.Errorf("incorrect point read from the arena: %q", got)
+	}
+	var released targets.Batch = batch
+	released.(targets.ReleasableBatch).Release()
+	if batch.Len() != 0 || len(batch.chunks) != 0 {
+		t.Errorf("released batch not emptied: %d points %d chunks", batch.Len(), len(batch.chunks))
+	}
+}
+
 func deserializeMongo(r *bufio.Reader) *MongoPoint {
 	item := &MongoPoint{}
 	lenBuf := make([]byte, 8)
@@ -189,7 +345,7 @@ func TestMongoSerializerTypePanic(t *testing.T) {
 		p := &data.Point{}
 		p.SetMeasurementName(serialize.TestMeasurement)
 		p.SetTimestamp(&serialize.TestNow)
-		p.AppendField([]byte("broken"), "a string?")
+		p.AppendField([]byte("broken"), []int{1})
 		ps := &Serializer{}
 		b := new(bytes.Buffer)
 
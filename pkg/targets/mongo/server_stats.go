@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// serverStatusMetrics are the metrics of serverStatus collected during the
+// load, by path. The concurrent transactions are the WiredTiger tickets of
+// MongoDB up to 6.0, and the execution queues those of 7.0 and later. The
+// metrics the server does not report are skipped.
+var serverStatusMetrics = []string{
+	"opcounters.insert",
+	"opcounters.query",
+	"opcounters.update",
+	"opcounters.delete",
+	"opcounters.getmore",
+	"opcounters.command",
+	"connections.current",
+	"mem.resident",
+	"globalLock.currentQueue.readers",
+	"globalLock.currentQueue.writers",
+	"wiredTiger.cache.bytes currently in the cache",
+	"wiredTiger.cache.maximum bytes configured",
+	"wiredTiger.cache.tracked dirty bytes in the cache",
+	"wiredTiger.cache.pages evicted by application threads",
+	"wiredTiger.concurrentTransactions.read.out",
+	"wiredTiger.concurrentTransactions.read.available",
+	"wiredTiger.concurrentTransactions.write.out",
+	"wiredTiger.concurrentTransactions.write.available",
+	"queues.execution.read.out",
+	"queues.execution.read.available",
+	"queues.execution.write.out",
+	"queues.execution.write.available",
+}
+
+// CollectStats returns the operation counters, the WiredTiger cache usage and
+// the read and write tickets of serverStatus.
+func (d *dbCreator) CollectStats(_ string) (map[string]float64, error) {
+	var status bson.Raw
+	err := d.client.Database("admin").RunCommand(context.Background(), bson.D{{"serverStatus", 1}}).Decode(&status)
+	if err != nil {
+		return nil, err
+	}
+	return lookupStats(status, serverStatusMetrics), nil
+}
+
+// lookupStats returns the numeric values of doc at the dotted paths.
+func lookupStats(doc bson.Raw, paths []string) map[string]float64 {
+	stats := make(map[string]float64, len(paths))
+	for _, path := range paths {
+		v, err := doc.LookupErr(strings.Split(path, ".")...)
+		if err != nil {
+			continue
+		}
+		switch v.Type {
+		case bsontype.Int32:
+			stats[path] = float64(v.Int32())
+		case bsontype.Int64:
+			stats[path] = float64(v.Int64())
+		case bsontype.Double:
+			stats[path] = v.Double()
+		}
+	}
+	return stats
+}
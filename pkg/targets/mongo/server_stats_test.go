@@ -0,0 +1,37 @@
+package mongo
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLookupStats(t *testing.T) {
+	doc, err := bson.Marshal(bson.D{
+		{"opcounters", bson.D{{"insert", int64(10)}, {"query", int32(2)}}},
+		{"mem", bson.D{{"resident", 1.5}, {"bits", "64"}}},
+		{"wiredTiger", bson.D{{"cache", bson.D{{"bytes currently in the cache", int64(4096)}}}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := lookupStats(doc, []string{
+		"opcounters.insert",
+		"opcounters.query",
+		"opcounters.delete",
+		"mem.resident",
+		"mem.bits",
+		"wiredTiger.cache.bytes currently in the cache",
+		"queues.execution.write.out",
+	})
+	want := map[string]float64{
+		"opcounters.insert": 10,
+		"opcounters.query":  2,
+		"mem.resident":      1.5,
+		"wiredTiger.cache.bytes currently in the cache": 4096,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect stats: got %v want %v", got, want)
+	}
+}
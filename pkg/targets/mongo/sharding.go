@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	bucketMetaField    = "meta"
+	bucketMinTimeField = "control.min.time"
+)
+
+// bucketKeyField translates a shard key field of the user-facing collection
+// into the matching field of the buckets collection.
+func bucketKeyField(field, timeField string) string {
+	if field == timeField {
+		return bucketMinTimeField
+	}
+	if strings.HasPrefix(field, "tags.") {
+		return bucketMetaField + strings.TrimPrefix(field, "tags")
+	}
+	return field
+}
+
+// PreSplitPoints computes n-1 split points for a range shard key, based on the
+// first field of the key. Time keys are split evenly over [start, end), meta
+// field keys are split over the sorted set of generated tag values.
+func PreSplitPoints(shardKey bson.D, n uint, start, end time.Time, tagFormat string, scale uint64, timeField string, timeseries bool) ([]bson.D, error) {
+	if len(shardKey) == 0 {
+		return nil, fmt.Errorf("empty shard key")
+	}
+	for _, e := range shardKey {
+		if s, ok := e.Value.(string); ok && s == "hashed" {
+			return nil, fmt.Errorf("cannot pre-split hashed shard key field '%s', use number-initial-chunks instead", e.Key)
+		}
+	}
+	if n < 2 {
+		return nil, nil
+	}
+
+	var values []interface{}
+	first := shardKey[0].Key
+	switch {
+	case first == timeField:
+		if start.IsZero() || end.IsZero() {
+			return nil, fmt.Errorf("pre-split-timestamp-start and pre-split-timestamp-end must be set to the time range of the data file")
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("pre-split end time %v is not after start time %v", end, start)
+		}
+		step := end.Sub(start) / time.Duration(n)
+		for i := uint(1); i < n; i++ {
+			values = append(values, start.Add(step*time.Duration(i)).UTC())
+		}
+	case strings.HasPrefix(first, "tags."):
+		if scale == 0 {
+			return nil, fmt.Errorf("pre-split-scale must be set to the scale of the data file")
+		}
+		tags := make([]string, 0, scale)
+		for i := uint64(0); i < scale; i++ {
+			tags = append(tags, fmt.Sprintf(tagFormat, i))
+		}
+		sort.Strings(tags)
+		// splitting at the smallest value would only create an empty chunk
+		prev := tags[0]
+		for i := uint64(1); i < uint64(n); i++ {
+			tag := tags[i*scale/uint64(n)]
+			if tag == prev {
+				continue
+			}
+			values = append(values, tag)
+			prev = tag
+		}
+	default:
+		return nil, fmt.Errorf("cannot pre-split on shard key field '%s', only '%s' and 'tags.*' are supported", first, timeField)
+	}
+
+	points := make([]bson.D, 0, len(values))
+	for _, v := range values {
+		points = append(points, ShardKeyDoc(shardKey, v, primitive.MinKey{}, timeField, timeseries))
+	}
+	return points, nil
+}
+
+// ShardKeyDoc builds a full shard key document with first as the value of the
+// leading field and rest for every other field. For time-series collections the
+// fields are translated to the underlying buckets collection.
+func ShardKeyDoc(shardKey bson.D, first, rest interface{}, timeField string, timeseries bool) bson.D {
+	doc := make(bson.D, 0, len(shardKey))
+	for i, e := range shardKey {
+		field := e.Key
+		if timeseries {
+			field = bucketKeyField(field, timeField)
+		}
+		v := rest
+		if i == 0 {
+			v = first
+		}
+		doc = append(doc, bson.E{field, v})
+	}
+	return doc
+}
@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPreSplitPointsTime(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+	key := bson.D{{"time", 1}}
+
+	points, err := PreSplitPoints(key, 4, start, end, "host_%d", 10, "time", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(points); got != 3 {
+		t.Fatalf("incorrect number of split points: got %d want 3", got)
+	}
+	for i, p := range points {
+		want := start.Add(time.Duration(i+1) * time.Hour)
+		if got := p[0].Value.(time.Time); !got.Equal(want) {
+			t.Errorf("split point %d: got %v want %v", i, got, want)
+		}
+		if p[0].Key != "time" {
+			t.Errorf("split point %d: incorrect key %s", i, p[0].Key)
+		}
+	}
+}
+
+func TestPreSplitPointsTags(t *testing.T) {
+	key := bson.D{{"tags.hostname", 1}, {"time", 1}}
+	points, err := PreSplitPoints(key, 2, time.Time{}, time.Time{}, "host_%d", 10, "time", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(points); got != 1 {
+		t.Fatalf("incorrect number of split points: got %d want 1", got)
+	}
+	// sorted: host_0, host_1, host_2, ..., host_9 -> middle is host_5
+	if got := points[0][0].Value; got != "host_5" {
+		t.Errorf("incorrect split value: got %v want host_5", got)
+	}
+	if _, ok := points[0][1].Value.(primitive.MinKey); !ok {
+		t.Errorf("trailing shard key field should be MinKey, got %T", points[0][1].Value)
+	}
+
+	// more chunks than hosts: duplicate points are dropped
+	points, err = PreSplitPoints(key, 8, time.Time{}, time.Time{}, "host_%d", 2, "time", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(points); got != 1 {
+		t.Errorf("incorrect number of split points for small scale: got %d want 1", got)
+	}
+
+	// time-series collections split the buckets collection
+	points, err = PreSplitPoints(key, 2, time.Time{}, time.Time{}, "host_%d", 10, "time", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if points[0][0].Key != "meta.hostname" || points[0][1].Key != "control.min.time" {
+		t.Errorf("incorrect bucket keys: got %s, %s", points[0][0].Key, points[0][1].Key)
+	}
+}
+
+func TestPreSplitPointsErrors(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		desc string
+		key  bson.D
+		end  time.Time
+	}{
+		{desc: "hashed", key: bson.D{{"tags.hostname", "hashed"}}, end: start.Add(time.Hour)},
+		{desc: "unsupported field", key: bson.D{{"measurement", 1}}, end: start.Add(time.Hour)},
+		{desc: "empty range", key: bson.D{{"time", 1}}, end: start},
+		{desc: "empty key", key: bson.D{}, end: start.Add(time.Hour)},
+		{desc: "no time range", key: bson.D{{"time", 1}}},
+	}
+	for _, c := range cases {
+		if _, err := PreSplitPoints(c.key, 4, start, c.end, "host_%d", 10, "time", false); err == nil {
+			t.Errorf("%s: expected error", c.desc)
+		}
+	}
+}
+
+func TestPreSplitDefaults(t *testing.T) {
+	c := &SpecificConfig{PreSplitEnd: "2020-01-02T00:00:00Z"}
+	c.preSplitDefaults("2020-01-01T00:00:00Z", "2020-01-03T00:00:00Z", 100)
+	if c.PreSplitStart != "2020-01-01T00:00:00Z" || c.PreSplitEnd != "2020-01-02T00:00:00Z" || c.PreSplitScale != 100 {
+		t.Errorf("incorrect defaults: got %s, %s, %d", c.PreSplitStart, c.PreSplitEnd, c.PreSplitScale)
+	}
+}
@@ -1,28 +1,34 @@
 package prometheus
 
 import (
+	"bufio"
 	"log"
 	"sync"
-	"time"
 
 	"github.com/timescale/promscale/pkg/prompb"
 	"github.com/timescale/tsbs/internal/inputs"
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 )
 
 func NewBenchmark(promSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
 	var ds targets.DataSource
 	if dataSourceConfig.Type == source.FileDataSourceType {
-		promIter, err := NewPrometheusIterator(load.GetBufferedReader(dataSourceConfig.File.Location))
+		var err error
+		ds, err = targetsCommon.FileDataSource(dataSourceConfig.File, func(br *bufio.Reader) (targets.DataSource, error) {
+			promIter, err := NewPrometheusIterator(br)
+			if err != nil {
+				return nil, err
+			}
+			return &FileDataSource{iterator: promIter}, nil
+		})
 		if err != nil {
 			log.Printf("could not create prometheus file data source; %v", err)
 			return nil, err
 		}
-		ds = &FileDataSource{iterator: promIter}
 	} else {
 		dataGenerator := &inputs.DataGenerator{}
 		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
@@ -40,6 +46,7 @@ func NewBenchmark(promSpecificConfig *SpecificConfig, dataSourceConfig *source.D
 		dataSource:      ds,
 		batchPool:       batchPool,
 		adapterWriteUrl: promSpecificConfig.AdapterWriteURL,
+		writerConfig:    promSpecificConfig.HTTPWriterConfig,
 	}, nil
 }
 
@@ -78,6 +85,11 @@ func (pd *FileDataSource) Headers() *common.GeneratedDataHeaders {
 	return nil
 }
 
+// Position returns the number of bytes of the data read.
+func (pd *FileDataSource) Position() int64 {
+	return pd.iterator.Position()
+}
+
 // PrometheusProcessor implements load.Processor interface
 type Processor struct {
 	client    *Client
@@ -114,6 +126,7 @@ func (pbf *BatchFactory) New() targets.Batch {
 // Benchmark implements targets.Benchmark interface
 type Benchmark struct {
 	adapterWriteUrl string
+	writerConfig    targetsCommon.HTTPWriterConfig
 	dataSource      targets.DataSource
 	batchPool       *sync.Pool
 	client          *Client
@@ -137,7 +150,7 @@ func (pm *Benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
 func (pm *Benchmark) GetProcessor() targets.Processor {
 	if pm.client == nil {
 		var err error
-		pm.client, err = NewClient(pm.adapterWriteUrl, time.Second*30)
+		pm.client, err = NewClient(pm.adapterWriteUrl, pm.writerConfig)
 		if err != nil {
 			panic(err)
 		}
@@ -1,10 +1,15 @@
 package prometheus
 
-import "github.com/blagojts/viper"
+import (
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
 
 type SpecificConfig struct {
 	AdapterWriteURL string `yaml:"adapter-write-url" mapstructure:"adapter-write-url"`
 	UseCurrentTime  bool   `yaml:"use-current-time" mapstructure:"use-current-time"`
+
+	common.HTTPWriterConfig `yaml:",inline" mapstructure:",squash"`
 }
 
 func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
@@ -5,11 +5,13 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &prometheusTarget{}
 }
 
@@ -35,4 +37,21 @@ func (t *prometheusTarget) Benchmark(_ string, dataSourceConfig *source.DataSour
 func (t *prometheusTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 	flagSet.String(flagPrefix+"adapter-write-url", "http://localhost:9201/write", "Prometheus adapter url to send data to")
 	flagSet.Bool(flagPrefix+"use-current-time", false, "Whether to replace the simulated timestamp with the current timestamp")
+	common.AddHTTPWriterFlags(flagPrefix, flagSet, common.DefaultHTTPWriterConfig)
+}
+
+func (t *prometheusTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(
+		flagPrefix+"urls",
+		"http://localhost:9090",
+		"Comma-separated list of Prometheus HTTP API URLs (any backend serving /api/v1/query_range)",
+	)
+}
+
+func (t *prometheusTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
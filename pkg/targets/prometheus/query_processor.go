@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the Prometheus HTTP API query
+// runner.
+type QuerySpecificConfig struct {
+	URLs []string `yaml:"urls" mapstructure:"urls"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the Prometheus HTTP API URLs in conf, round-robin over the workers.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if len(conf.URLs) == 0 {
+		return nil, fmt.Errorf("missing `urls` flag")
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.HTTPPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{urls: conf.URLs, prettyPrintResponses: runner.DoPrintResponses()}
+		},
+	}, nil
+}
+
+// query.Processor interface implementation
+type queryProcessor struct {
+	urls []string
+	url  string
+
+	prettyPrintResponses bool
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) Init(workerNum int) {
+	p.url = p.urls[workerNum%len(p.urls)]
+}
+
+// query.Processor interface implementation
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.do(hq)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+func (p *queryProcessor) do(q *query.HTTP) (float64, error) {
+	// populate a request with data from the Query:
+	req, err := http.NewRequest(string(q.Method), p.url+string(q.Path), nil)
+	if err != nil {
+		return 0, fmt.Errorf("error while creating request: %s", err)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("query execution error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error while reading response body: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("non-200 statuscode received: %d; Body: %s", resp.StatusCode, string(body))
+	}
+	lag := float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
+
+	// Pretty print JSON responses, if applicable:
+	if p.prettyPrintResponses {
+		var pretty bytes.Buffer
+		prefix := fmt.Sprintf("ID %d: ", q.GetID())
+		if err := json.Indent(&pretty, body, prefix, "  "); err != nil {
+			return lag, err
+		}
+		_, err = fmt.Fprintf(os.Stderr, "%s%s\n", prefix, pretty.Bytes())
+		if err != nil {
+			return lag, err
+		}
+	}
+	return lag, nil
+}
@@ -0,0 +1,73 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestQueryBenchmarkProcessQuery(t *testing.T) {
+	var hits [2]int32
+	var servers []*httptest.Server
+	for i := range hits {
+		i := i
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/query_range" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			atomic.AddInt32(&hits[i], 1)
+			w.Write([]byte(`{"status":"success"}`))
+		}))
+		defer s.Close()
+		servers = append(servers, s)
+	}
+
+	v := viper.New()
+	v.Set("urls", servers[0].URL+","+servers[1].URL)
+	runner := query.NewBenchmarkRunner(query.BenchmarkRunnerConfig{})
+	benchmark, err := NewTarget().QueryBenchmark(runner, v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if benchmark.QueryPool != &query.HTTPPool {
+		t.Errorf("incorrect query pool")
+	}
+
+	for worker := 0; worker < 2; worker++ {
+		p := benchmark.ProcessorCreate()
+		p.Init(worker)
+		q := query.NewHTTP()
+		q.HumanLabel = []byte("label")
+		q.Method = []byte("GET")
+		q.Path = []byte("/api/v1/query_range?query=up")
+		stats, err := p.ProcessQuery(q, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(stats) != 1 {
+			t.Errorf("incorrect stats: %v", stats)
+		}
+
+		q.Path = []byte("/missing")
+		if _, err := p.ProcessQuery(q, false); err == nil {
+			t.Errorf("unexpected lack of error for non-200 status")
+		}
+	}
+	for i, h := range hits {
+		if h != 1 {
+			t.Errorf("server %d got %d queries, want 1", i, h)
+		}
+	}
+}
+
+func TestNewQueryBenchmarkNoURLs(t *testing.T) {
+	runner := query.NewBenchmarkRunner(query.BenchmarkRunnerConfig{})
+	if _, err := NewQueryBenchmark(runner, &QuerySpecificConfig{}); err == nil {
+		t.Errorf("unexpected lack of error")
+	}
+}
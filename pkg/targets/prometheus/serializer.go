@@ -17,6 +17,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/timescale/promscale/pkg/prompb"
 	"github.com/timescale/tsbs/pkg/data"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 )
 
 const serializerVersion uint64 = 1
@@ -142,20 +143,26 @@ func getFloat64(fieldValue interface{}) float64 {
 
 // Iterator iterates over binary data and enables lazy access over protobuf messages
 type Iterator struct {
-	reader    *bufio.Reader
+	reader    *targetsCommon.PositionReader
 	processed uint64 // number of processed protobuf messages
 }
 
 // NewPrometheusIterator creates iterator and reads version information from underlying reader
 func NewPrometheusIterator(reader *bufio.Reader) (*Iterator, error) {
-	version, err := binary.ReadUvarint(reader)
+	pr := targetsCommon.NewPositionReader(reader)
+	version, err := binary.ReadUvarint(pr)
 	if err != nil {
 		return nil, fmt.Errorf("error while reading file version: %v", err)
 	}
 	if _, exists := supportedVersions[version]; !exists {
 		return nil, fmt.Errorf("unsupported version number: %d", version)
 	}
-	return &Iterator{reader: reader}, nil
+	return &Iterator{reader: pr}, nil
+}
+
+// Position returns the number of bytes of the data read, the version included
+func (pi *Iterator) Position() int64 {
+	return pi.reader.Position()
 }
 
 // HasNext returns true if there are more protobuf messages to read
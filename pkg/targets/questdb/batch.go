@@ -0,0 +1,120 @@
+package questdb
+
+import (
+	"bytes"
+	"log"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
+)
+
+const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
+
+var (
+	spaceSep = []byte(" ")
+	commaSep = []byte(",")
+	newLine  = []byte("\n")
+)
+
+// allows for testing
+var fatal = log.Fatalf
+
+type fileDataSource struct {
+	scanner *targetsCommon.LineScanner
+}
+
+func (d *fileDataSource) NextItem() data.LoadedPoint {
+	ok := d.scanner.Scan()
+	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+		return data.LoadedPoint{}
+	} else if !ok {
+		fatal("scan error: %v", d.scanner.Err())
+		return data.LoadedPoint{}
+	}
+	// copied, the scanner reuses its buffer while the point may still be batched
+	return data.NewLoadedPoint(append([]byte(nil), d.scanner.Bytes()...))
+}
+
+// Position returns the number of bytes of the data scanned.
+func (d *fileDataSource) Position() int64 {
+	return d.scanner.Position()
+}
+
+func (d *fileDataSource) Headers() *common.GeneratedDataHeaders { return nil }
+
+func newSimulationDataSource(sim common.Simulator) *simulationDataSource {
+	return &simulationDataSource{
+		simulator: sim,
+		headers:   sim.Headers(),
+	}
+}
+
+// simulationDataSource serializes the points of the simulator to ILP lines,
+// as they would be read from a file.
+type simulationDataSource struct {
+	simulator  common.Simulator
+	headers    *common.GeneratedDataHeaders
+	serializer Serializer
+	buf        bytes.Buffer
+}
+
+func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
+	if d.headers != nil {
+		return d.headers
+	}
+
+	d.headers = d.simulator.Headers()
+	return d.headers
+}
+
+func (d *simulationDataSource) NextItem() data.LoadedPoint {
+	p := data.NewPoint()
+	for !d.simulator.Finished() {
+		if !d.simulator.Next(p) {
+			p.Reset()
+			continue
+		}
+		d.buf.Reset()
+		if err := d.serializer.Serialize(p, &d.buf); err != nil {
+			fatal("serialize error: %v", err)
+			return data.LoadedPoint{}
+		}
+		// points without any field are not serialized
+		if d.buf.Len() == 0 {
+			p.Reset()
+			continue
+		}
+		line := make([]byte, d.buf.Len()-1)
+		copy(line, d.buf.Bytes()) // without the trailing new line
+		return data.NewLoadedPoint(line)
+	}
+	return data.LoadedPoint{}
+}
+
+type batch struct {
+	buf     *bytes.Buffer
+	rows    uint
+	metrics uint64
+}
+
+func (b *batch) Len() uint {
+	return b.rows
+}
+
+func (b *batch) Append(item data.LoadedPoint) {
+	that := item.Data.([]byte)
+	b.rows++
+
+	// Each ILP line is format "csv-tags csv-fields timestamp"
+	if args := bytes.Count(that, spaceSep); args != 2 {
+		fatal(errNotThreeTuplesFmt, args+1)
+		return
+	}
+	fieldsStart := bytes.Index(that, spaceSep) + 1
+	fieldsEnd := bytes.LastIndex(that, spaceSep)
+	b.metrics += uint64(bytes.Count(that[fieldsStart:fieldsEnd], commaSep) + 1)
+
+	b.buf.Write(that)
+	b.buf.Write(newLine)
+}
@@ -0,0 +1,62 @@
+package questdb
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+func TestFileDataSourceNextItem(t *testing.T) {
+	cases := []struct {
+		desc   string
+		input  string
+		result []byte
+	}{
+		{
+			desc:   "correct input",
+			input:  "cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140\n",
+			result: []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140"),
+		},
+		{
+			desc:   "correct input with extra",
+			input:  "cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140\nextra_is_ignored",
+			result: []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140"),
+		},
+	}
+
+	for _, c := range cases {
+		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
+		ds := &fileDataSource{scanner: common.NewLineScanner(br)}
+		p := ds.NextItem()
+		data := p.Data.([]byte)
+		if !bytes.Equal(data, c.result) {
+			t.Errorf("%s: incorrect result: got\n%v\nwant\n%v", c.desc, data, c.result)
+		}
+	}
+}
+
+func TestFileDataSourceNextItemCopies(t *testing.T) {
+	input := "cpu,tag1=a col1=0.0 140\nmem,tag1=b col1=1.0 150\n"
+	br := bufio.NewReader(bytes.NewReader([]byte(input)))
+	ds := &fileDataSource{scanner: common.NewLineScanner(br)}
+	first := ds.NextItem()
+	_ = ds.NextItem()
+	// the point may still be batched when the scanner reads the next line
+	if got, want := string(first.Data.([]byte)), "cpu,tag1=a col1=0.0 140"; got != want {
+		t.Errorf("first point overwritten: got %s want %s", got, want)
+	}
+}
+
+func TestDecodeEOF(t *testing.T) {
+	input := []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140")
+	br := bufio.NewReader(bytes.NewReader([]byte(input)))
+	ds := &fileDataSource{scanner: common.NewLineScanner(br)}
+	_ = ds.NextItem()
+	// nothing left, should be EOF
+	p := ds.NextItem()
+	if p.Data != nil {
+		t.Errorf("expected p to be nil, got %v", p)
+	}
+}
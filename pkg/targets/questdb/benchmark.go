@@ -0,0 +1,144 @@
+package questdb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/internal/inputs"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// ILP transports
+const (
+	TransportTCP  = "tcp"
+	TransportHTTP = "http"
+)
+
+// SpecificConfig is the configuration of the QuestDB loader.
+type SpecificConfig struct {
+	// URL is the REST end point, used to create and drop tables and, with
+	// the http transport, to write ILP.
+	URL       string `yaml:"url" mapstructure:"url"`
+	ILPBindTo string `yaml:"ilp-bind-to" mapstructure:"ilp-bind-to"`
+	Transport string `yaml:"ilp-transport" mapstructure:"ilp-transport"`
+
+	// AutoFlushRows and AutoFlushBytes are the number of rows and bytes a
+	// worker buffers before sending them. 0 rows sends every batch as is,
+	// 0 bytes means no limit.
+	AutoFlushRows  uint `yaml:"auto-flush-rows" mapstructure:"auto-flush-rows"`
+	AutoFlushBytes uint `yaml:"auto-flush-bytes" mapstructure:"auto-flush-bytes"`
+
+	common.HTTPWriterConfig `yaml:",inline" mapstructure:",squash"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// Validate checks the transport of the config.
+func (c *SpecificConfig) Validate() error {
+	switch c.Transport {
+	case TransportTCP:
+		if c.ILPBindTo == "" {
+			return fmt.Errorf("missing `ilp-bind-to` flag")
+		}
+	case TransportHTTP:
+		if c.URL == "" {
+			return fmt.Errorf("missing `url` flag")
+		}
+	default:
+		return fmt.Errorf("invalid ilp-transport '%s': must be one of %s, %s", c.Transport, TransportTCP, TransportHTTP)
+	}
+	return nil
+}
+
+// NewBenchmark returns the Benchmark writing ILP to QuestDB, read from a file
+// or generated by the simulator.
+func NewBenchmark(conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	var ds targets.DataSource
+	if dataSourceConfig.Type == source.FileDataSourceType {
+		var err error
+		ds, err = common.FileDataSource(dataSourceConfig.File, func(br *bufio.Reader) (targets.DataSource, error) {
+			return &fileDataSource{scanner: common.NewLineScanner(br)}, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		dataGenerator := &inputs.DataGenerator{}
+		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
+		if err != nil {
+			return nil, err
+		}
+		ds = newSimulationDataSource(simulator)
+	}
+
+	b := &benchmark{conf: conf, ds: ds}
+	if conf.Transport == TransportHTTP {
+		writer, err := common.NewHTTPWriter(conf.HTTPWriterConfig, nil)
+		if err != nil {
+			return nil, err
+		}
+		b.writer = writer
+	}
+	b.bufPool = &sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4*1024*1024))
+		},
+	}
+	return b, nil
+}
+
+type benchmark struct {
+	conf    *SpecificConfig
+	ds      targets.DataSource
+	writer  *common.HTTPWriter
+	bufPool *sync.Pool
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{bufPool: b.bufPool}
+}
+
+func (b *benchmark) GetPointIndexer(_ uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	p := &processor{conf: b.conf, bufPool: b.bufPool}
+	if b.conf.Transport == TransportHTTP {
+		p.sender = &httpSender{url: writeURL(b.conf.URL), writer: b.writer}
+	} else {
+		p.sender = &tcpSender{addr: b.conf.ILPBindTo}
+	}
+	return p
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{url: b.conf.URL, ds: b.ds}
+}
+
+type factory struct {
+	bufPool *sync.Pool
+}
+
+func (f *factory) New() targets.Batch {
+	return &batch{buf: f.bufPool.Get().(*bytes.Buffer)}
+}
@@ -0,0 +1,83 @@
+package questdb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// headersDataSource is a data source with only headers.
+type headersDataSource struct {
+	headers *common.GeneratedDataHeaders
+}
+
+func (d *headersDataSource) NextItem() data.LoadedPoint            { return data.LoadedPoint{} }
+func (d *headersDataSource) Headers() *common.GeneratedDataHeaders { return d.headers }
+
+// testQuestDB answers SHOW TABLES with tables and records the other queries.
+func testQuestDB(t *testing.T, tables []string, queries *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("query")
+		var resp QueryResponse
+		if q == "SHOW TABLES" {
+			for _, table := range tables {
+				resp.Dataset = append(resp.Dataset, []interface{}{table})
+			}
+			resp.Count = len(tables)
+		} else {
+			*queries = append(*queries, q)
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestDBCreatorRemoveOldDB(t *testing.T) {
+	cases := []struct {
+		desc     string
+		headers  *common.GeneratedDataHeaders
+		existing []string
+		want     []string
+	}{
+		{
+			desc:     "file without headers",
+			existing: []string{"cpu", "mem", "readings", "other"},
+			want:     []string{"DROP TABLE cpu", "DROP TABLE mem", "DROP TABLE readings"},
+		},
+		{
+			desc: "iot simulator",
+			headers: &common.GeneratedDataHeaders{FieldKeys: map[string][]string{
+				"readings":    {"latitude"},
+				"diagnostics": {"fuel_state"},
+			}},
+			existing: []string{"cpu", "diagnostics", "readings"},
+			want:     []string{"DROP TABLE diagnostics", "DROP TABLE readings"},
+		},
+		{
+			desc:     "no tables",
+			headers:  &common.GeneratedDataHeaders{FieldKeys: map[string][]string{"cpu": {"usage_user"}}},
+			existing: []string{"mem"},
+		},
+	}
+	for _, c := range cases {
+		var queries []string
+		server := testQuestDB(t, c.existing, &queries)
+		d := &dbCreator{url: server.URL, ds: &headersDataSource{headers: c.headers}}
+		if got := d.DBExists(""); got != (len(c.want) > 0) {
+			t.Errorf("%s: incorrect DBExists: got %v", c.desc, got)
+		}
+		if err := d.RemoveOldDB(""); err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+		if !reflect.DeepEqual(queries, c.want) {
+			t.Errorf("%s: incorrect queries: got %v want %v", c.desc, queries, c.want)
+		}
+		server.Close()
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &influxTarget{}
 }
 
@@ -19,6 +21,10 @@ type influxTarget struct {
 func (t *influxTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 	flagSet.String(flagPrefix+"url", "http://localhost:9000/", "QuestDB REST end point")
 	flagSet.String(flagPrefix+"ilp-bind-to", "127.0.0.1:9009", "QuestDB influx line protocol TCP ip:port")
+	flagSet.String(flagPrefix+"ilp-transport", TransportTCP, "Transport of the influx line protocol writes, one of: tcp (to ilp-bind-to), http (to the /write end point of url)")
+	flagSet.Uint(flagPrefix+"auto-flush-rows", 0, "Number of rows each worker buffers before writing them. 0 with auto-flush-bytes 0 writes every batch as is")
+	flagSet.Uint(flagPrefix+"auto-flush-bytes", 0, "Number of bytes each worker buffers before writing them. 0 means no limit")
+	common.AddHTTPWriterFlags(flagPrefix, flagSet, common.DefaultHTTPWriterConfig)
 }
 
 func (t *influxTarget) TargetName() string {
@@ -29,6 +35,24 @@ func (t *influxTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *influxTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *influxTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(conf, dataSourceConfig)
+}
+
+func (t *influxTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:9000/", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
+	flagSet.String(flagPrefix+"query-protocol", ProtocolREST, "Protocol queries are sent with, one of: rest (to the /exec end point of urls), pg (PostgreSQL wire protocol with pg-connection)")
+	flagSet.String(flagPrefix+"pg-connection", "host=localhost port=8812 user=admin password=quest dbname=qdb sslmode=disable", "PostgreSQL wire protocol connection string, used with query-protocol pg")
+}
+
+func (t *influxTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
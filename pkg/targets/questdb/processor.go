@@ -0,0 +1,166 @@
+package questdb
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// allows for testing
+var printFn = fmt.Printf
+
+var lineProtocolHeader = http.Header{"Content-Type": {"text/plain"}}
+
+// writeURL returns the ILP over HTTP end point of the REST url.
+func writeURL(url string) string {
+	return strings.TrimSuffix(url, "/") + "/write"
+}
+
+// sender sends ILP to QuestDB. Each worker has its own sender.
+type sender interface {
+	open(workerNum int) error
+	send(b []byte) error
+	close() error
+}
+
+// tcpSender writes ILP over a TCP connection. QuestDB does not acknowledge
+// ILP over TCP, so writes only block when the server does not read fast
+// enough and the TCP window fills up.
+type tcpSender struct {
+	addr string
+	conn *net.TCPConn
+}
+
+func (s *tcpSender) open(_ int) error {
+	tcpAddr, err := net.ResolveTCPAddr("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %v", s.addr, err)
+	}
+	s.conn, err = net.DialTCP("tcp", nil, tcpAddr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %v", s.addr, err)
+	}
+	return nil
+}
+
+func (s *tcpSender) send(b []byte) error {
+	_, err := s.conn.Write(b)
+	return err
+}
+
+func (s *tcpSender) close() error {
+	return s.conn.Close()
+}
+
+// httpSender posts ILP to the /write end point, which acknowledges every
+// request and reports backpressure with its status code.
+type httpSender struct {
+	url    string
+	writer *common.HTTPWriter
+
+	workerNum    int
+	retries      int
+	totalBackoff time.Duration
+}
+
+func (s *httpSender) open(workerNum int) error {
+	s.workerNum = workerNum
+	return nil
+}
+
+func (s *httpSender) send(b []byte) error {
+	stats, err := s.writer.Post(s.url, lineProtocolHeader, b)
+	s.retries += stats.Retries
+	s.totalBackoff += stats.Backoff
+	return err
+}
+
+func (s *httpSender) close() error {
+	if s.retries > 0 {
+		printFn("[worker %d] %d retries, backoffs took a total of %fsec of runtime\n", s.workerNum, s.retries, s.totalBackoff.Seconds())
+	}
+	return nil
+}
+
+// processor sends the batches of a worker over its own connection. Batches
+// are buffered until the auto-flush thresholds are reached.
+type processor struct {
+	conf    *SpecificConfig
+	bufPool *sync.Pool
+	sender  sender
+
+	workerNum   int
+	pending     bytes.Buffer
+	pendingRows uint
+}
+
+func (p *processor) Init(workerNum int, doLoad, _ bool) {
+	p.workerNum = workerNum
+	if !doLoad {
+		return
+	}
+	if err := p.sender.open(workerNum); err != nil {
+		fatal("[worker %d] %v", workerNum, err)
+	}
+}
+
+func (p *processor) Close(doLoad bool) {
+	if !doLoad {
+		return
+	}
+	p.flush()
+	if err := p.sender.close(); err != nil {
+		fatal("[worker %d] error closing connection: %v", p.workerNum, err)
+	}
+}
+
+func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	batch := b.(*batch)
+
+	if doLoad {
+		if p.conf.AutoFlushRows == 0 && p.conf.AutoFlushBytes == 0 {
+			p.send(batch.buf.Bytes())
+		} else {
+			p.pending.Write(batch.buf.Bytes())
+			p.pendingRows += batch.rows
+			if p.shouldFlush() {
+				p.flush()
+			}
+		}
+	}
+
+	metricCnt := batch.metrics
+	rowCnt := batch.rows
+
+	// Return the batch buffer to the pool.
+	batch.buf.Reset()
+	p.bufPool.Put(batch.buf)
+	return metricCnt, uint64(rowCnt)
+}
+
+func (p *processor) shouldFlush() bool {
+	return (p.conf.AutoFlushRows > 0 && p.pendingRows >= p.conf.AutoFlushRows) ||
+		(p.conf.AutoFlushBytes > 0 && uint(p.pending.Len()) >= p.conf.AutoFlushBytes)
+}
+
+func (p *processor) flush() {
+	if p.pending.Len() == 0 {
+		return
+	}
+	p.send(p.pending.Bytes())
+	p.pending.Reset()
+	p.pendingRows = 0
+}
+
+func (p *processor) send(b []byte) {
+	if err := p.sender.send(b); err != nil {
+		fatal("[worker %d] error writing: %v", p.workerNum, err)
+	}
+}
@@ -0,0 +1,217 @@
+package questdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+const testLine = "cpu,hostname=host_0 usage_user=58i,usage_system=2i 1451606400000000000"
+
+// recordingSender records what is sent, as one entry per send.
+type recordingSender struct {
+	sends  []string
+	opened bool
+	closed bool
+}
+
+func (s *recordingSender) open(_ int) error {
+	s.opened = true
+	return nil
+}
+
+func (s *recordingSender) send(b []byte) error {
+	s.sends = append(s.sends, string(b))
+	return nil
+}
+
+func (s *recordingSender) close() error {
+	s.closed = true
+	return nil
+}
+
+func testBufPool() *sync.Pool {
+	return &sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+}
+
+func testBatch(f *factory, rows int) *batch {
+	b := f.New().(*batch)
+	for i := 0; i < rows; i++ {
+		b.Append(data.NewLoadedPoint([]byte(testLine)))
+	}
+	return b
+}
+
+func TestBatchAppend(t *testing.T) {
+	f := &factory{bufPool: testBufPool()}
+	b := testBatch(f, 3)
+	if b.Len() != 3 {
+		t.Errorf("incorrect batch length: got %d want 3", b.Len())
+	}
+	if b.metrics != 6 {
+		t.Errorf("incorrect metric count: got %d want 6", b.metrics)
+	}
+	if got := b.buf.String(); got != testLine+"\n"+testLine+"\n"+testLine+"\n" {
+		t.Errorf("incorrect buffer: %s", got)
+	}
+}
+
+func TestProcessorAutoFlush(t *testing.T) {
+	cases := []struct {
+		desc      string
+		rows      uint
+		bytes     uint
+		batches   int
+		wantSends int
+	}{
+		{desc: "every batch", batches: 4, wantSends: 4},
+		{desc: "rows", rows: 4, batches: 4, wantSends: 2},
+		{desc: "bytes", bytes: uint(3 * (len(testLine) + 1)), batches: 4, wantSends: 2},
+		{desc: "rest on close", rows: 100, batches: 4, wantSends: 1},
+	}
+	for _, c := range cases {
+		f := &factory{bufPool: testBufPool()}
+		s := &recordingSender{}
+		p := &processor{
+			conf:    &SpecificConfig{AutoFlushRows: c.rows, AutoFlushBytes: c.bytes},
+			bufPool: f.bufPool,
+			sender:  s,
+		}
+		p.Init(0, true, false)
+		var metrics, rows uint64
+		for i := 0; i < c.batches; i++ {
+			m, r := p.ProcessBatch(testBatch(f, 2), true)
+			metrics += m
+			rows += r
+		}
+		p.Close(true)
+
+		if !s.opened || !s.closed {
+			t.Errorf("%s: sender not opened and closed", c.desc)
+		}
+		if len(s.sends) != c.wantSends {
+			t.Errorf("%s: incorrect number of sends: got %d want %d", c.desc, len(s.sends), c.wantSends)
+		}
+		total := 0
+		for _, sent := range s.sends {
+			total += len(sent)
+		}
+		if want := c.batches * 2 * (len(testLine) + 1); total != want {
+			t.Errorf("%s: incorrect number of bytes sent: got %d want %d", c.desc, total, want)
+		}
+		if metrics != uint64(c.batches*4) || rows != uint64(c.batches*2) {
+			t.Errorf("%s: incorrect counts: got %d metrics, %d rows", c.desc, metrics, rows)
+		}
+	}
+}
+
+func TestProcessorNoLoad(t *testing.T) {
+	f := &factory{bufPool: testBufPool()}
+	s := &recordingSender{}
+	p := &processor{conf: &SpecificConfig{}, bufPool: f.bufPool, sender: s}
+	p.Init(0, false, false)
+	metrics, rows := p.ProcessBatch(testBatch(f, 2), false)
+	p.Close(false)
+	if s.opened || s.closed || len(s.sends) != 0 {
+		t.Errorf("sender used without loading: %+v", s)
+	}
+	if metrics != 4 || rows != 2 {
+		t.Errorf("incorrect counts: got %d metrics, %d rows", metrics, rows)
+	}
+}
+
+func TestTCPSender(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	received := make(chan []byte)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		b, _ := ioutil.ReadAll(conn)
+		received <- b
+	}()
+
+	s := &tcpSender{addr: l.Addr().String()}
+	if err := s.open(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.send([]byte(testLine + "\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.close(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	select {
+	case b := <-received:
+		if string(b) != testLine+"\n" {
+			t.Errorf("incorrect data received: %q", b)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+}
+
+func TestHTTPSender(t *testing.T) {
+	var gotPath, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		b, _ := ioutil.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	writer, err := common.NewHTTPWriter(common.DefaultHTTPWriterConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &httpSender{url: writeURL(server.URL + "/"), writer: writer}
+	if err := s.open(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.send([]byte(testLine + "\n")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if gotPath != "/write" {
+		t.Errorf("incorrect path: got %s want /write", gotPath)
+	}
+	if gotBody != testLine+"\n" {
+		t.Errorf("incorrect body: %q", gotBody)
+	}
+}
+
+func TestSpecificConfigValidate(t *testing.T) {
+	cases := []struct {
+		desc    string
+		conf    SpecificConfig
+		wantErr bool
+	}{
+		{desc: "tcp", conf: SpecificConfig{Transport: TransportTCP, ILPBindTo: "127.0.0.1:9009"}},
+		{desc: "http", conf: SpecificConfig{Transport: TransportHTTP, URL: "http://localhost:9000/"}},
+		{desc: "tcp without address", conf: SpecificConfig{Transport: TransportTCP}, wantErr: true},
+		{desc: "unknown transport", conf: SpecificConfig{Transport: "udp"}, wantErr: true},
+	}
+	for _, c := range cases {
+		err := c.conf.Validate()
+		if c.wantErr && err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if !c.wantErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package questdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const pgxDriver = "pgx"
+
+// Query protocols
+const (
+	ProtocolREST = "rest"
+	ProtocolPG   = "pg"
+)
+
+// pgQueryProcessor runs the SQL of the queries over the PostgreSQL wire
+// protocol, so the latency does not include the JSON serialization of the
+// REST end point.
+type pgQueryProcessor struct {
+	conf   *QuerySpecificConfig
+	runner *query.BenchmarkRunner
+	db     *sql.DB
+
+	debug         int
+	printResponse bool
+}
+
+func (p *pgQueryProcessor) Init(_ int) {
+	db, err := sql.Open(pgxDriver, p.conf.PGConnection)
+	if err != nil {
+		panic(err)
+	}
+	p.db = db
+	p.debug = p.runner.DebugLevel()
+	p.printResponse = p.runner.DoPrintResponses()
+}
+
+func (p *pgQueryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	sqlQuery := string(hq.RawQuery)
+
+	start := time.Now()
+	rows, err := p.db.Query(sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	var resp []map[string]interface{}
+	if p.printResponse {
+		resp, err = mapRows(rows)
+	} else {
+		// Fetching all the rows to confirm that the query is fully completed.
+		for rows.Next() {
+		}
+		err = rows.Err()
+	}
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+	lag := float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
+
+	if p.debug > 0 {
+		fmt.Printf("debug: %s in %7.2fms -- %s\n", hq.HumanLabel, lag, hq.HumanDescription)
+	}
+	if p.debug > 1 {
+		fmt.Printf("debug:   query: %s\n", sqlQuery)
+	}
+	if p.printResponse {
+		line, err := json.MarshalIndent(map[string]interface{}{
+			"query":   sqlQuery,
+			"results": resp,
+		}, fmt.Sprintf("ID %d: ", q.GetID()), "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Println(string(line) + "\n")
+	}
+
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+func mapRows(r *sql.Rows) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	cols, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	for r.Next() {
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+		if err := r.Scan(values...); err != nil {
+			return nil, fmt.Errorf("error while reading values: %v", err)
+		}
+		row := make(map[string]interface{}, len(cols))
+		for i, column := range cols {
+			row[column] = *values[i].(*interface{})
+		}
+		rows = append(rows, row)
+	}
+	return rows, r.Err()
+}
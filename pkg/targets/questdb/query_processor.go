@@ -0,0 +1,157 @@
+package questdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the QuestDB query runner.
+type QuerySpecificConfig struct {
+	URLs []string `yaml:"urls" mapstructure:"urls"`
+	// Protocol is either rest, sending the queries to the /exec end point
+	// of URLs, or pg, running them over the PostgreSQL wire protocol with
+	// PGConnection.
+	Protocol     string `yaml:"query-protocol" mapstructure:"query-protocol"`
+	PGConnection string `yaml:"pg-connection" mapstructure:"pg-connection"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the QuestDB URLs in conf, round-robin over the workers, or over the
+// PostgreSQL wire protocol. It first adds an index to the hostname column of
+// the cpu table if that table exists.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if conf.Protocol == ProtocolPG {
+		return newPGQueryBenchmark(runner, conf)
+	}
+	if conf.Protocol != "" && conf.Protocol != ProtocolREST {
+		return nil, fmt.Errorf("invalid query-protocol '%s': must be one of %s, %s", conf.Protocol, ProtocolREST, ProtocolPG)
+	}
+	if len(conf.URLs) == 0 {
+		return nil, fmt.Errorf("missing `urls` flag")
+	}
+
+	// Add an index to the hostname column in the cpu table
+	r, err := execQuery(conf.URLs[0], "show columns from cpu")
+	if err == nil && r.Count != 0 {
+		_, err := execQuery(conf.URLs[0], "ALTER TABLE cpu ALTER COLUMN hostname ADD INDEX")
+		if err == nil {
+			fmt.Println("Added index to hostname column of cpu table")
+		}
+	}
+
+	return &targets.QueryBenchmark{
+		QueryPool: &query.HTTPPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{urls: conf.URLs, runner: runner}
+		},
+	}, nil
+}
+
+func newPGQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if conf.PGConnection == "" {
+		return nil, fmt.Errorf("missing `pg-connection` flag")
+	}
+
+	// Add an index to the hostname column in the cpu table
+	db, err := sql.Open(pgxDriver, conf.PGConnection)
+	if err != nil {
+		return nil, err
+	}
+	var table string
+	if db.QueryRow("SELECT table_name FROM tables() WHERE table_name = 'cpu'").Scan(&table) == nil {
+		if _, err := db.Exec("ALTER TABLE cpu ALTER COLUMN hostname ADD INDEX"); err == nil {
+			fmt.Println("Added index to hostname column of cpu table")
+		}
+	}
+	db.Close()
+
+	return &targets.QueryBenchmark{
+		QueryPool: &query.HTTPPool,
+		ProcessorCreate: func() query.Processor {
+			return &pgQueryProcessor{conf: conf, runner: runner}
+		},
+	}, nil
+}
+
+type queryProcessor struct {
+	urls   []string
+	runner *query.BenchmarkRunner
+	w      *HTTPClient
+	opts   *HTTPClientDoOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.opts = &HTTPClientDoOptions{
+		Debug:                p.runner.DebugLevel(),
+		PrettyPrintResponses: p.runner.DoPrintResponses(),
+	}
+	url := p.urls[workerNumber%len(p.urls)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+type QueryResponseColumns struct {
+	Name string
+	Type string
+}
+
+type QueryResponse struct {
+	Query   string
+	Columns []QueryResponseColumns
+	Dataset []interface{}
+	Count   int
+	Error   string
+}
+
+func execQuery(uriRoot string, query string) (QueryResponse, error) {
+	var qr QueryResponse
+	if strings.HasSuffix(uriRoot, "/") {
+		uriRoot = uriRoot[:len(uriRoot)-1]
+	}
+	uriRoot = uriRoot + "/exec?query=" + url.QueryEscape(query)
+	resp, err := http.Get(uriRoot)
+	if err != nil {
+		return qr, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return qr, err
+	}
+	err = json.Unmarshal(body, &qr)
+	if err != nil {
+		return qr, err
+	}
+	if qr.Error != "" {
+		return qr, errors.New(qr.Error)
+	}
+	return qr, nil
+}
@@ -0,0 +1,78 @@
+package siridb
+
+import (
+	"bufio"
+	"log"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
+)
+
+// allows for testing
+var fatal = log.Fatal
+
+// SpecificConfig is the configuration of the SiriDB loader.
+type SpecificConfig struct {
+	DBUser       string `yaml:"dbuser" mapstructure:"dbuser"`
+	DBPass       string `yaml:"dbpass" mapstructure:"dbpass"`
+	Hosts        string `yaml:"hosts" mapstructure:"hosts"`
+	Replica      bool   `yaml:"replica" mapstructure:"replica"`
+	LogBatches   bool   `yaml:"log-batches" mapstructure:"log-batches"`
+	WriteTimeout int    `yaml:"write-timeout" mapstructure:"write-timeout"`
+}
+
+func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
+	var conf SpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+type benchmark struct {
+	dbName string
+	conf   *SpecificConfig
+	ds     targets.DataSource
+}
+
+// NewBenchmark returns the benchmark loading SiriDB database dbName with the
+// data of the data source.
+func NewBenchmark(dbName string, conf *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	ds, err := common.NewDataSource(dataSourceConfig, &Serializer{}, false, func(br *bufio.Reader) (targets.DataSource, error) {
+		return &fileDataSource{
+			buf: make([]byte, 0),
+			len: 0,
+			br:  br,
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &benchmark{
+		dbName: dbName,
+		conf:   conf,
+		ds:     ds,
+	}, nil
+}
+
+func (b *benchmark) GetDataSource() targets.DataSource {
+	return b.ds
+}
+
+func (b *benchmark) GetBatchFactory() targets.BatchFactory {
+	return &factory{}
+}
+
+func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+
+func (b *benchmark) GetProcessor() targets.Processor {
+	return &processor{dbName: b.dbName, conf: b.conf}
+}
+
+func (b *benchmark) GetDBCreator() targets.DBCreator {
+	return &dbCreator{conf: b.conf}
+}
@@ -1,4 +1,4 @@
-package main
+package siridb
 
 import (
 	"errors"
@@ -6,7 +6,7 @@ import (
 	"strconv"
 	"strings"
 
-	siridb "github.com/SiriDB/go-siridb-connector"
+	connector "github.com/SiriDB/go-siridb-connector"
 )
 
 const (
@@ -19,15 +19,16 @@ const (
 )
 
 type dbCreator struct {
-	connection []*siridb.Connection
+	conf       *SpecificConfig
+	connection []*connector.Connection
 	hosts      []string
 	replica    []string
 }
 
 // Init should set up any connection or other setup for talking to the DB, but should NOT create any databases
 func (d *dbCreator) Init() {
-	d.hosts = strings.Split(hosts, ",")
-	d.connection = make([]*siridb.Connection, 0)
+	d.hosts = strings.Split(d.conf.Hosts, ",")
+	d.connection = make([]*connector.Connection, 0)
 	for _, hostport := range d.hosts {
 		x := strings.Split(hostport, ":")
 		host := x[0]
@@ -35,14 +36,14 @@ func (d *dbCreator) Init() {
 		if err != nil {
 			fatal(err)
 		}
-		d.connection = append(d.connection, siridb.NewConnection(host, uint16(port)))
+		d.connection = append(d.connection, connector.NewConnection(host, uint16(port)))
 	}
 }
 
 // DBExists checks if a database with the given name currently exists.
 func (d *dbCreator) DBExists(dbName string) bool {
 	for _, conn := range d.connection {
-		if err := conn.Connect(dbUser, dbPass, dbName); err == nil {
+		if err := conn.Connect(d.conf.DBUser, d.conf.DBPass, dbName); err == nil {
 			return true
 		}
 	}
@@ -64,7 +65,7 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	optionsNewDB["duration_num"] = durationNum
 	optionsNewDB["duration_log"] = durationLog
 
-	if _, err := d.connection[0].Manage(account, password, siridb.AdminNewDatabase, optionsNewDB); err != nil {
+	if _, err := d.connection[0].Manage(account, password, connector.AdminNewDatabase, optionsNewDB); err != nil {
 		return err
 	}
 
@@ -76,15 +77,15 @@ func (d *dbCreator) CreateDB(dbName string) error {
 			fatal(err)
 		}
 
-		if !replica {
+		if !d.conf.Replica {
 			optionsNewPool := make(map[string]interface{})
 			optionsNewPool["dbname"] = dbName
 			optionsNewPool["host"] = host
 			optionsNewPool["port"] = port
-			optionsNewPool["username"] = dbUser
-			optionsNewPool["password"] = dbPass
+			optionsNewPool["username"] = d.conf.DBUser
+			optionsNewPool["password"] = d.conf.DBPass
 
-			if _, err := d.connection[1].Manage(account, password, siridb.AdminNewPool, optionsNewPool); err != nil {
+			if _, err := d.connection[1].Manage(account, password, connector.AdminNewPool, optionsNewPool); err != nil {
 				return err
 			}
 
@@ -93,11 +94,11 @@ func (d *dbCreator) CreateDB(dbName string) error {
 			optionsNewReplica["dbname"] = dbName
 			optionsNewReplica["host"] = host
 			optionsNewReplica["port"] = port
-			optionsNewReplica["username"] = dbUser
-			optionsNewReplica["password"] = dbPass
+			optionsNewReplica["username"] = d.conf.DBUser
+			optionsNewReplica["password"] = d.conf.DBPass
 			optionsNewReplica["pool"] = 0
 
-			if _, err := d.connection[1].Manage(account, password, siridb.AdminNewReplica, optionsNewReplica); err != nil {
+			if _, err := d.connection[1].Manage(account, password, connector.AdminNewReplica, optionsNewReplica); err != nil {
 				return err
 			}
 		}
@@ -5,11 +5,12 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &siriTarget{}
 }
 
@@ -35,6 +36,28 @@ func (t *siriTarget) Serializer() serialize.PointSerializer {
 	return &Serializer{}
 }
 
-func (t *siriTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("not implemented")
+func (t *siriTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	conf, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewBenchmark(targetDB, conf, dataSourceConfig)
+}
+
+func (t *siriTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"dbuser", "iris", "Username to enter SiriDB")
+	flagSet.String(flagPrefix+"dbpass", "siri", "Password to enter SiriDB")
+	flagSet.String(flagPrefix+"hosts", "localhost:9000", "Comma separated list of SiriDB hosts in a cluster.")
+	flagSet.Uint64(flagPrefix+"scale", 8, "Scaling variable (Must be the equal to the scalevar used for data generation).")
+	flagSet.Uint64(flagPrefix+"query-limit", 1000000, "Changes the maximum points which can be returned by a select query.")
+	flagSet.Int(flagPrefix+"write-timeout", 10, "Write timeout.")
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+}
+
+func (t *siriTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
 }
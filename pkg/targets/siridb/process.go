@@ -1,4 +1,4 @@
-package main
+package siridb
 
 import (
 	"fmt"
@@ -7,17 +7,19 @@ import (
 	"strings"
 	"time"
 
-	"github.com/SiriDB/go-siridb-connector"
+	connector "github.com/SiriDB/go-siridb-connector"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/transceptor-technology/go-qpack"
 )
 
 type processor struct {
-	connection *siridb.Connection
+	dbName     string
+	conf       *SpecificConfig
+	connection *connector.Connection
 }
 
 func (p *processor) Init(numWorker int, _, _ bool) {
-	hostlist := strings.Split(hosts, ",")
+	hostlist := strings.Split(p.conf.Hosts, ",")
 	h := hostlist[numWorker%len(hostlist)]
 	x := strings.Split(h, ":")
 	host := x[0]
@@ -25,7 +27,7 @@ func (p *processor) Init(numWorker int, _, _ bool) {
 	if err != nil {
 		fatal(err)
 	}
-	p.connection = siridb.NewConnection(host, uint16(port))
+	p.connection = connector.NewConnection(host, uint16(port))
 }
 
 func (p *processor) Close(doLoad bool) {
@@ -37,7 +39,7 @@ func (p *processor) Close(doLoad bool) {
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rows uint64) {
 	batch := b.(*batch)
 	if doLoad {
-		if err := p.connection.Connect(dbUser, dbPass, loader.DatabaseName()); err != nil {
+		if err := p.connection.Connect(p.conf.DBUser, p.conf.DBPass, p.dbName); err != nil {
 			fatal(err)
 		}
 		series := make([]byte, 0)
@@ -51,10 +53,10 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, row
 			series = append(series, v...)
 		}
 		start := time.Now()
-		if _, err := p.connection.InsertBin(series, uint16(writeTimeout)); err != nil {
+		if _, err := p.connection.InsertBin(series, uint16(p.conf.WriteTimeout)); err != nil {
 			fatal(err)
 		}
-		if logBatches {
+		if p.conf.LogBatches {
 			now := time.Now()
 			took := now.Sub(start)
 			batchSize := batch.batchCnt
@@ -0,0 +1,184 @@
+package siridb
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	connector "github.com/SiriDB/go-siridb-connector"
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the SiriDB query runner.
+type QuerySpecificConfig struct {
+	DBUser       string `yaml:"dbuser" mapstructure:"dbuser"`
+	DBPass       string `yaml:"dbpass" mapstructure:"dbpass"`
+	Hosts        string `yaml:"hosts" mapstructure:"hosts"`
+	Scale        uint64 `yaml:"scale" mapstructure:"scale"`
+	QueryLimit   uint64 `yaml:"query-limit" mapstructure:"query-limit"`
+	WriteTimeout int    `yaml:"write-timeout" mapstructure:"write-timeout"`
+	ShowExplain  bool   `yaml:"show-explain" mapstructure:"show-explain"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark connects to the SiriDB cluster in conf, sets its select
+// points limit and creates the groups used by the queries, and returns the
+// QueryBenchmark sending the queries of runner to it. With ShowExplain set
+// only one query is run.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if conf.ShowExplain {
+		runner.SetLimit(1)
+	}
+
+	hostlist := [][]interface{}{}
+	for _, hostport := range strings.Split(conf.Hosts, ",") {
+		x := strings.Split(hostport, ":")
+		if len(x) != 2 {
+			return nil, fmt.Errorf("invalid SiriDB host '%s', expected host:port", hostport)
+		}
+		port, err := strconv.ParseInt(x[1], 10, 0)
+		if err != nil {
+			return nil, err
+		}
+		hostlist = append(hostlist, []interface{}{x[0], int(port)})
+	}
+
+	client := connector.NewClient(
+		conf.DBUser,           // username
+		conf.DBPass,           // password
+		runner.DatabaseName(), // database
+		hostlist,              // siridb server(s)
+		nil,                   // optional log channel
+	)
+	client.Connect()
+	if err := changeQueryLimit(client, conf); err != nil {
+		client.Close()
+		return nil, err
+	}
+	if err := createGroups(client, conf); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	opts := &queryExecutorOptions{
+		showExplain:   conf.ShowExplain,
+		debug:         runner.DebugLevel() > 0,
+		printResponse: runner.DoPrintResponses(),
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.SiriDBPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{client: client, writeTimeout: uint16(conf.WriteTimeout), opts: opts}
+		},
+		Close: client.Close,
+	}, nil
+}
+
+const errNotConnected = "not even a single server is connected..."
+
+// changeQueryLimit changes the maximum points which can be returned by a select query. The default
+// and recommended value is set to one million points. This value is chosen to
+// prevent a single query for taking to much memory and ensures SiriDB can respond
+// to almost any query in a reasonable amount of time.
+func changeQueryLimit(client *connector.Client, conf *QuerySpecificConfig) error {
+	qry := fmt.Sprintf("alter database set select_points_limit %d", conf.QueryLimit)
+
+	if !client.IsConnected() {
+		return fmt.Errorf(errNotConnected)
+	}
+	_, err := client.Query(qry, uint16(conf.WriteTimeout))
+	return err
+}
+
+// createGroups makes groups representing regular expression to enhance performance
+func createGroups(client *connector.Client, conf *QuerySpecificConfig) error {
+	created := true
+	metrics := devops.GetAllCPUMetrics()
+	siriql := make([]string, 0, 2048)
+	for _, m := range metrics {
+		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s$/", m, m))
+	}
+
+	var n uint64
+	for n = 0; n < conf.Scale; n++ {
+		host := fmt.Sprintf("host_%d", n)
+		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s,.*/", host, host))
+	}
+	siriql = append(siriql, "create group `cpu` for /.*^cpu.*/")
+	for _, qry := range siriql {
+		if !client.IsConnected() {
+			return fmt.Errorf(errNotConnected)
+		}
+		// groups may already exist from an earlier run
+		if _, err := client.Query(qry, uint16(conf.WriteTimeout)); err != nil {
+			created = false
+		}
+	}
+	if created {
+		time.Sleep(6 * time.Second) // because the groups are created in a seperate thread every 2 seconds.
+	}
+	return nil
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+}
+
+type queryProcessor struct {
+	client       *connector.Client
+	writeTimeout uint16
+	opts         *queryExecutorOptions
+}
+
+func (p *queryProcessor) Init(numWorker int) {}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+	tq := q.(*query.SiriDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+
+	var res interface{}
+	var err error
+
+	if p.client.IsConnected() {
+		if res, err = p.client.Query(qry, p.writeTimeout); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		log.Fatal(errNotConnected)
+	}
+
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+
+	if p.opts.printResponse {
+		fmt.Println("\n", res)
+	}
+
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
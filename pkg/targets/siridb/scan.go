@@ -1,4 +1,4 @@
-package main
+package siridb
 
 import (
 	"bufio"
@@ -52,6 +52,8 @@ type fileDataSource struct {
 	buf []byte
 	len uint32
 	br  *bufio.Reader
+	// read is the number of bytes read into buf
+	read int64
 }
 
 func (d *fileDataSource) Read() int {
@@ -65,6 +67,7 @@ func (d *fileDataSource) Read() int {
 	}
 
 	d.len += uint32(n)
+	d.read += int64(n)
 	d.buf = append(d.buf, buf[:n]...)
 	return n
 }
@@ -73,6 +76,12 @@ func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	return nil
 }
 
+// Position returns the number of bytes of the data decoded, without those
+// read ahead in buf.
+func (d *fileDataSource) Position() int64 {
+	return d.read - int64(d.len)
+}
+
 func (d *fileDataSource) NextItem() data.LoadedPoint {
 	if d.len < 8 {
 		if n := d.Read(); n == 0 {
@@ -1,12 +1,15 @@
 package targets
 
 import (
+	"sync"
+
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
 )
 
 type ImplementedTarget interface {
@@ -22,6 +25,39 @@ type ImplementedTarget interface {
 	TargetName() string
 }
 
+// ImplementedQueryTarget is an ImplementedTarget that can also run the queries
+// generated for it by tsbs_generate_queries.
+type ImplementedQueryTarget interface {
+	ImplementedTarget
+	// QueryTargetSpecificFlags adds to the supplied flagSet the target-specific
+	// flags of the query runner, each name prefixed with flagPrefix.
+	QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet)
+	// QueryBenchmark returns the target-specific parts of a query benchmark run
+	// by runner, configured from v which holds the flags defined in
+	// QueryTargetSpecificFlags (without their prefix).
+	QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*QueryBenchmark, error)
+}
+
+// QueryBenchmark holds what a query.BenchmarkRunner needs to run the queries
+// of a particular target.
+type QueryBenchmark struct {
+	// QueryPool is the pool of the query type the target runs
+	QueryPool *sync.Pool
+	// ProcessorCreate creates the query.Processor of each worker
+	ProcessorCreate query.ProcessorCreate
+	// Close, if not nil, releases the resources shared by the processors once
+	// all queries have been run
+	Close func()
+}
+
+// RunQueries runs all queries of runner with the processors of b.
+func (b *QueryBenchmark) RunQueries(runner *query.BenchmarkRunner) {
+	runner.Run(b.QueryPool, b.ProcessorCreate)
+	if b.Close != nil {
+		b.Close()
+	}
+}
+
 // Batch is an aggregate of points for a particular data system.
 // It needs to have a way to measure it's size to make sure
 // it does not get too large and it needs a way to append a point
@@ -30,6 +66,14 @@ type Batch interface {
 	Append(data.LoadedPoint)
 }
 
+// ReleasableBatch is a Batch which reuses its memory, and the memory of its
+// points (see data.Arena). The loader calls Release once the batch is
+// processed, after which neither the batch nor its points are used.
+type ReleasableBatch interface {
+	Batch
+	Release()
+}
+
 // PointIndexer determines the index of the Batch (and subsequently the channel)
 // that a particular point belongs to
 type PointIndexer interface {
@@ -37,6 +81,18 @@ type PointIndexer interface {
 	GetIndex(data.LoadedPoint) uint
 }
 
+// AffinityIndexer is a PointIndexer which knows the series each point
+// belongs to, e.g. its host. The loader sends all the points of a series to
+// the same channel, and so the same worker, instead of the channel GetIndex
+// returns, and can fill a batch per series. It reports how many distinct
+// series the batches contained.
+type AffinityIndexer interface {
+	PointIndexer
+	// AffinityKey returns the key of the series of the point, or false if
+	// it has none, in which case GetIndex places the point
+	AffinityKey(data.LoadedPoint) (string, bool)
+}
+
 // ConstantIndexer always puts the item on a single channel. This is the typical
 // use case where all the workers share the same channel
 type ConstantIndexer struct{}
@@ -71,7 +127,59 @@ type Benchmark interface {
 	GetDBCreator() DBCreator
 }
 
+// HashedWorkersBenchmark is a Benchmark which needs the loader to hash the
+// workers, as with hash-workers, e.g. because a worker keeps the documents of
+// the hosts it loads. The loader hashes the workers of such a Benchmark
+// whatever the runner config says.
+type HashedWorkersBenchmark interface {
+	Benchmark
+	// HashWorkers returns whether each worker must only receive the points
+	// the PointIndexer places on its channel
+	HashWorkers() bool
+}
+
+// DataSource returns the points to load. The points NextItem returns must
+// stay valid after the following calls, the points of several files are read
+// ahead in parallel.
 type DataSource interface {
 	NextItem() data.LoadedPoint
 	Headers() *common.GeneratedDataHeaders
 }
+
+// UnorderedDataSource is a DataSource whose items do not come in the same
+// order on every run, e.g. because it reads several files in parallel. A load
+// from such a DataSource can only be resumed from a checkpoint if it is a
+// SeekableDataSource, as the number of items inserted from the start of the
+// data does not tell which items they were.
+type UnorderedDataSource interface {
+	DataSource
+	// Unordered returns whether the order of the items may change between
+	// runs
+	Unordered() bool
+}
+
+// PositionedDataSource is the DataSource of a target reading a file, which
+// tells how far in the file it read.
+type PositionedDataSource interface {
+	DataSource
+	// Position returns the number of bytes of the data read up to the end
+	// of the last item NextItem returned, the headers included
+	Position() int64
+}
+
+// SeekableDataSource is a DataSource of files which tells how far in each
+// file it returned the items, and can start reading the files from there. A
+// load resumed from a checkpoint then seeks in the files instead of reading
+// again the items inserted before.
+type SeekableDataSource interface {
+	DataSource
+	// Files returns the names of the files
+	Files() []string
+	// Positions appends to positions, for each file, the position in the
+	// data of the file after the last item NextItem returned from it, and
+	// returns the result
+	Positions(positions []int64) []int64
+	// Seek makes NextItem return the items of each file from its position.
+	// It must be called before the first call to NextItem.
+	Seek(positions []int64) error
+}
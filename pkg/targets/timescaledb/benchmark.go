@@ -4,15 +4,23 @@ import (
 	"github.com/timescale/tsbs/internal/inputs"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 )
 
 const pgxDriver = "pgx"
 const pqDriver = "postgres"
 
 func NewBenchmark(dbName string, opts *LoadingOptions, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
 	var ds targets.DataSource
 	if dataSourceConfig.Type == source.FileDataSourceType {
-		ds = newFileDataSource(dataSourceConfig.File.Location)
+		var err error
+		ds, err = common.FileDataSource(dataSourceConfig.File, newFileDataSource)
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		dataGenerator := &inputs.DataGenerator{}
 		simulator, err := dataGenerator.CreateSimulator(dataSourceConfig.Simulator)
@@ -26,6 +34,13 @@ func NewBenchmark(dbName string, opts *LoadingOptions, dataSourceConfig *source.
 		opts:   opts,
 		ds:     ds,
 		dbName: dbName,
+		dbc: &dbCreator{
+			opts:    opts,
+			connDB:  opts.ConnDB,
+			ds:      ds,
+			driver:  getDriver(opts.ForceTextFormat),
+			connStr: opts.GetConnectString(dbName),
+		},
 	}, nil
 }
 
@@ -33,6 +48,8 @@ type benchmark struct {
 	opts   *LoadingOptions
 	ds     targets.DataSource
 	dbName string
+	// the same creator runs the post load steps and reports on them
+	dbc *dbCreator
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
@@ -55,13 +72,7 @@ func (b *benchmark) GetProcessor() targets.Processor {
 }
 
 func (b *benchmark) GetDBCreator() targets.DBCreator {
-	return &dbCreator{
-		opts:    b.opts,
-		connDB:  b.opts.ConnDB,
-		ds:      b.ds,
-		driver:  getDriver(b.opts.ForceTextFormat),
-		connStr: b.opts.GetConnectString(b.dbName),
-	}
+	return b.dbc
 }
 
 func getDriver(forceTextFormat bool) string {
@@ -30,6 +30,7 @@ type dbCreator struct {
 	connStr string
 	connDB  string
 	opts    *LoadingOptions
+	stats   postLoadStats
 }
 
 func (d *dbCreator) Init() {
@@ -103,6 +104,9 @@ func (d *dbCreator) PostCreateDB(dbName string) error {
 		fieldDefs, indexDefs := d.getFieldAndIndexDefinitions(tableName, columns)
 		if d.opts.CreateMetricsTable {
 			d.createTableAndIndexes(dbBench, tableName, fieldDefs, indexDefs)
+			if d.opts.UseHypertable {
+				d.setUpHypertablePolicies(dbBench, tableName, columns)
+			}
 		} else {
 			// If not creating table, wait for another client to set it up
 			i := 0
@@ -165,15 +169,7 @@ func (d *dbCreator) getFieldAndIndexDefinitions(tableName string, columns []stri
 // createTableAndIndexes takes a list of field and index definitions for a given tableName and constructs
 // the necessary table, index, and potential hypertable based on the user's settings
 func (d *dbCreator) createTableAndIndexes(dbBench *sql.DB, tableName string, fieldDefs []string, indexDefs []string) {
-	// We default to the tags_id column unless users are creating the
-	// name/hostname column in the time-series table for multi-node
-	// testing. For distributed queries, pushdown of JOINs is not yet
-	// supported.
-	var partitionColumn string = "tags_id"
-
-	if d.opts.InTableTag {
-		partitionColumn = tableCols[tagsKey][0]
-	}
+	partitionColumn := d.partitionColumn()
 
 	MustExec(dbBench, fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))
 	MustExec(dbBench, fmt.Sprintf("CREATE TABLE %s (time timestamptz, tags_id integer, %s, additional_tags JSONB DEFAULT NULL)", tableName, strings.Join(fieldDefs, ",")))
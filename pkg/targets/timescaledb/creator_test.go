@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log"
 	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets/common"
 )
 
 func TestDBCreatorInit(t *testing.T) {
@@ -52,7 +55,7 @@ func TestDBCreatorInit(t *testing.T) {
 	}
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewBufferString(buf))
-		dbc := &dbCreator{ds: &fileDataSource{scanner: bufio.NewScanner(br)}, connStr: c.connStr, connDB: c.connDB}
+		dbc := &dbCreator{ds: &fileDataSource{scanner: common.NewLineScanner(br)}, connStr: c.connStr, connDB: c.connDB}
 		dbc.initConnectString()
 		if got := dbc.connStr; got != c.want {
 			t.Errorf("%s: incorrect connstr: got %s want %s", c.desc, got, c.want)
@@ -254,3 +257,84 @@ func TestGenerateTagsTableQueryPanicOnWrongType(t *testing.T) {
 
 	t.Fatalf("test should have stopped at this point")
 }
+
+func TestHypertablePolicyQueries(t *testing.T) {
+	cases := []struct {
+		desc string
+		opts *LoadingOptions
+		want []string
+	}{
+		{
+			desc: "no policies",
+			opts: &LoadingOptions{UseHypertable: true},
+		},
+		{
+			desc: "compression with defaults",
+			opts: &LoadingOptions{UseHypertable: true, UseCompression: true, CompressAfter: 24 * time.Hour},
+			want: []string{
+				"ALTER TABLE cpu SET (timescaledb.compress, timescaledb.compress_segmentby = 'tags_id', timescaledb.compress_orderby = 'time DESC')",
+				"SELECT add_compression_policy('cpu', INTERVAL '86400 seconds')",
+			},
+		},
+		{
+			desc: "compression segmented by the in-table tag",
+			opts: &LoadingOptions{UseHypertable: true, UseCompression: true, InTableTag: true, CompressOrderBy: "time"},
+			want: []string{
+				"ALTER TABLE cpu SET (timescaledb.compress, timescaledb.compress_segmentby = 'hostname', timescaledb.compress_orderby = 'time')",
+			},
+		},
+		{
+			desc: "caggs and retention",
+			opts: &LoadingOptions{UseHypertable: true, CaggIntervals: []string{"1m"}, CaggTables: []string{"cpu"}, CaggMaterializedOnly: true, RetentionPeriod: time.Hour},
+			want: []string{
+				"CREATE MATERIALIZED VIEW cpu_1m WITH (timescaledb.continuous, timescaledb.materialized_only = true) AS " +
+					"SELECT time_bucket(INTERVAL '60 seconds', time) AS bucket, tags_id, max(usage_user) AS max_usage_user, avg(usage_user) AS avg_usage_user " +
+					"FROM cpu GROUP BY bucket, tags_id WITH NO DATA",
+				"SELECT add_retention_policy('cpu', INTERVAL '3600 seconds')",
+			},
+		},
+		{
+			desc: "caggs of other tables only",
+			opts: &LoadingOptions{UseHypertable: true, CaggIntervals: []string{"1m"}, CaggTables: []string{"mem"}},
+		},
+	}
+	tableCols[tagsKey] = []string{"hostname"}
+	for _, c := range cases {
+		dbc := &dbCreator{opts: c.opts}
+		got := dbc.hypertablePolicyQueries("cpu", []string{"usage_user"})
+		if len(got) != len(c.want) {
+			t.Errorf("%s: incorrect number of queries: got %d want %d", c.desc, len(got), len(c.want))
+			continue
+		}
+		for i, q := range got {
+			if q != c.want[i] {
+				t.Errorf("%s: incorrect query:\ngot\n%s\nwant\n%s", c.desc, q, c.want[i])
+			}
+		}
+	}
+}
+
+func TestLoadingOptionsValidate(t *testing.T) {
+	cases := []struct {
+		desc      string
+		opts      LoadingOptions
+		shouldErr bool
+	}{
+		{desc: "defaults", opts: LoadingOptions{}},
+		{desc: "compression", opts: LoadingOptions{UseHypertable: true, UseCompression: true, CompressAfterLoad: true}},
+		{desc: "compression without hypertable", opts: LoadingOptions{UseCompression: true}, shouldErr: true},
+		{desc: "caggs without hypertable", opts: LoadingOptions{CaggIntervals: []string{"1m"}}, shouldErr: true},
+		{desc: "retention without hypertable", opts: LoadingOptions{RetentionPeriod: time.Hour}, shouldErr: true},
+		{desc: "compress after load without compression", opts: LoadingOptions{UseHypertable: true, CompressAfterLoad: true}, shouldErr: true},
+		{desc: "invalid cagg interval", opts: LoadingOptions{UseHypertable: true, CaggIntervals: []string{"hourly"}}, shouldErr: true},
+		{desc: "cagg interval too short", opts: LoadingOptions{UseHypertable: true, CaggIntervals: []string{"10ms"}}, shouldErr: true},
+	}
+	for _, c := range cases {
+		err := c.opts.Validate()
+		if c.shouldErr && err == nil {
+			t.Errorf("%s: expected an error", c.desc)
+		} else if !c.shouldErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+	}
+}
@@ -4,19 +4,18 @@ import (
 	"bufio"
 	"strings"
 
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 )
 
-func newFileDataSource(fileName string) targets.DataSource {
-	br := load.GetBufferedReader(fileName)
-	return &fileDataSource{scanner: bufio.NewScanner(br)}
+func newFileDataSource(br *bufio.Reader) (targets.DataSource, error) {
+	return &fileDataSource{scanner: targetsCommon.NewLineScanner(br)}, nil
 }
 
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targetsCommon.LineScanner
 	headers *common.GeneratedDataHeaders
 }
 
@@ -113,3 +112,8 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		row:        newPoint,
 	})
 }
+
+// Position returns the number of bytes of the data scanned.
+func (d *fileDataSource) Position() int64 {
+	return d.scanner.Position()
+}
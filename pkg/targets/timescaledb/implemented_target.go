@@ -7,11 +7,12 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &timescaleTarget{}
 }
 
@@ -64,8 +65,38 @@ func (t *timescaleTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.
 
 	flagSet.String(flagPrefix+"write-profile", "", "File to output CPU/memory profile to")
 	flagSet.String(flagPrefix+"write-replication-stats", "", "File to output replication stats to")
+	flagSet.Bool(flagPrefix+"use-compression", false, "Whether to enable compression on the hypertables")
+	flagSet.String(flagPrefix+"compress-segmentby", "", "Columns to segment the compressed data by (defaults to the partition column, e.g. tags_id)")
+	flagSet.String(flagPrefix+"compress-orderby", "time DESC", "Columns to order the compressed data by")
+	flagSet.Duration(flagPrefix+"compress-after", 0, "Age of the chunks the compression policy compresses, e.g. 24h (0 for no compression policy)")
+	flagSet.Bool(flagPrefix+"compress-after-load", false, "Whether to compress all chunks once the load has finished")
+	flagSet.StringSlice(flagPrefix+"cagg-intervals", nil, "Comma-separated bucket intervals of the continuous aggregates of the cagg-tables, e.g. 1m,1h (the caggs are named <table>_<interval>)")
+	flagSet.StringSlice(flagPrefix+"cagg-tables", []string{"cpu"}, "Comma-separated tables to create continuous aggregates of")
+	flagSet.Bool(flagPrefix+"cagg-materialized-only", true, "Whether the continuous aggregates only return materialized data (no real-time aggregation)")
+	flagSet.Duration(flagPrefix+"retention-period", 0, "Age of the chunks the retention policy drops, e.g. 720h (0 for no retention policy)")
+
 	flagSet.Bool(flagPrefix+"create-metrics-table", true, "Drops existing and creates new metrics table. Can be used for both regular and hypertable")
 
 	flagSet.Bool(flagPrefix+"use-insert", false, "Provides the option to test data inserts with batched INSERT commands rather than the preferred COPY function")
 	flagSet.Bool(flagPrefix+"force-text-format", false, "Send/receive data in text format")
 }
+
+func (t *timescaleTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"postgres", "sslmode=disable",
+		"String of additional PostgreSQL connection parameters, e.g., 'sslmode=disable'. Parameters for host and database will be ignored.")
+	flagSet.String(flagPrefix+"hosts", "localhost", "Comma separated list of PostgreSQL hosts (pass multiple values for sharding reads on a multi-node setup)")
+	flagSet.String(flagPrefix+"user", "postgres", "User to connect to PostgreSQL as")
+	flagSet.String(flagPrefix+"pass", "", "Password for the user connecting to PostgreSQL (leave blank if not password protected)")
+	flagSet.String(flagPrefix+"port", "5432", "Which port to connect to on the database host")
+
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+	flagSet.Bool(flagPrefix+"force-text-format", false, "Send/receive data in text format")
+}
+
+func (t *timescaleTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
+}
@@ -0,0 +1,206 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// postLoadStats are the durations and results of the work done on the
+// loaded data once all workers are done.
+type postLoadStats struct {
+	caggRefresh     time.Duration
+	compression     time.Duration
+	compressed      int64
+	beforeBytes     int64
+	afterBytes      int64
+	compressionDone bool
+	caggsRefreshed  bool
+}
+
+// setUpHypertablePolicies enables the compression of hypertable tableName,
+// and creates its compression and retention policies and its continuous
+// aggregates, as set in the options.
+func (d *dbCreator) setUpHypertablePolicies(dbBench *sql.DB, tableName string, columns []string) {
+	for _, query := range d.hypertablePolicyQueries(tableName, columns) {
+		MustExec(dbBench, query)
+	}
+}
+
+// hypertablePolicyQueries returns the statements setting up the compression,
+// the continuous aggregates and the retention of hypertable tableName.
+func (d *dbCreator) hypertablePolicyQueries(tableName string, columns []string) []string {
+	var queries []string
+	if d.opts.UseCompression {
+		segmentBy := d.opts.CompressSegmentBy
+		if segmentBy == "" {
+			segmentBy = d.partitionColumn()
+		}
+		orderBy := d.opts.CompressOrderBy
+		if orderBy == "" {
+			orderBy = "time DESC"
+		}
+		queries = append(queries, fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress, timescaledb.compress_segmentby = '%s', timescaledb.compress_orderby = '%s')",
+			tableName, segmentBy, orderBy))
+		if d.opts.CompressAfter > 0 {
+			queries = append(queries, fmt.Sprintf("SELECT add_compression_policy('%s', %s)", tableName, intervalLiteral(d.opts.CompressAfter)))
+		}
+	}
+	if d.hasCaggs(tableName) {
+		for _, interval := range d.opts.CaggIntervals {
+			queries = append(queries, d.caggQuery(tableName, interval, columns))
+		}
+	}
+	if d.opts.RetentionPeriod > 0 {
+		queries = append(queries, fmt.Sprintf("SELECT add_retention_policy('%s', %s)", tableName, intervalLiteral(d.opts.RetentionPeriod)))
+	}
+	return queries
+}
+
+// partitionColumn is the column the hypertables are partitioned on, besides
+// time.
+func (d *dbCreator) partitionColumn() string {
+	// We default to the tags_id column unless users are creating the
+	// name/hostname column in the time-series table for multi-node
+	// testing. For distributed queries, pushdown of JOINs is not yet
+	// supported.
+	if d.opts.InTableTag {
+		return tableCols[tagsKey][0]
+	}
+	return "tags_id"
+}
+
+// hasCaggs tells whether continuous aggregates are created for tableName.
+func (d *dbCreator) hasCaggs(tableName string) bool {
+	for _, t := range d.opts.CaggTables {
+		if t == tableName {
+			return true
+		}
+	}
+	return false
+}
+
+// caggName is the name of the continuous aggregate of tableName per
+// interval, e.g. cpu_1m.
+func caggName(tableName, interval string) string {
+	return fmt.Sprintf("%s_%s", tableName, interval)
+}
+
+// caggQuery builds the continuous aggregate rolling tableName up per
+// interval and per partition column, with the max and the avg of each
+// column, e.g. max_usage_user and avg_usage_user. It is created empty, and
+// refreshed once the data is loaded.
+func (d *dbCreator) caggQuery(tableName, interval string, columns []string) string {
+	// validated by the options
+	bucket, _ := time.ParseDuration(interval)
+	groupBy := []string{"tags_id"}
+	if d.opts.InTableTag {
+		groupBy = append(groupBy, tableCols[tagsKey][0])
+	}
+	var aggs []string
+	for _, c := range columns {
+		if len(c) == 0 {
+			continue
+		}
+		aggs = append(aggs, fmt.Sprintf("max(%[1]s) AS max_%[1]s, avg(%[1]s) AS avg_%[1]s", c))
+	}
+	return fmt.Sprintf("CREATE MATERIALIZED VIEW %s WITH (timescaledb.continuous, timescaledb.materialized_only = %t) AS "+
+		"SELECT time_bucket(%s, time) AS bucket, %s, %s FROM %s GROUP BY bucket, %s WITH NO DATA",
+		caggName(tableName, interval), d.opts.CaggMaterializedOnly,
+		intervalLiteral(bucket), strings.Join(groupBy, ", "), strings.Join(aggs, ", "), tableName, strings.Join(groupBy, ", "))
+}
+
+// intervalLiteral returns d as a PostgreSQL interval.
+func intervalLiteral(d time.Duration) string {
+	return fmt.Sprintf("INTERVAL '%d seconds'", int64(d/time.Second))
+}
+
+// PostLoad refreshes the continuous aggregates and compresses all the
+// chunks, if set in the options, once all the data is loaded.
+func (d *dbCreator) PostLoad(dbName string) error {
+	if !d.opts.CompressAfterLoad && len(d.opts.CaggIntervals) == 0 {
+		return nil
+	}
+	dbBench := MustConnect(d.driver, d.opts.GetConnectString(dbName))
+	defer dbBench.Close()
+
+	tables := d.hypertables()
+	if len(d.opts.CaggIntervals) > 0 {
+		start := time.Now()
+		for _, tableName := range tables {
+			if !d.hasCaggs(tableName) {
+				continue
+			}
+			for _, interval := range d.opts.CaggIntervals {
+				if _, err := dbBench.Exec(fmt.Sprintf("CALL refresh_continuous_aggregate('%s', NULL, NULL)", caggName(tableName, interval))); err != nil {
+					return fmt.Errorf("could not refresh the continuous aggregate of %s per %s: %v", tableName, interval, err)
+				}
+			}
+		}
+		d.stats.caggRefresh = time.Since(start)
+		d.stats.caggsRefreshed = true
+		fmt.Printf("Refreshed continuous aggregates in %v\n", d.stats.caggRefresh)
+	}
+
+	if d.opts.CompressAfterLoad {
+		start := time.Now()
+		for _, tableName := range tables {
+			var n int64
+			err := dbBench.QueryRow(fmt.Sprintf("SELECT count(compress_chunk(c, if_not_compressed => true)) FROM show_chunks('%s') c", tableName)).Scan(&n)
+			if err != nil {
+				return fmt.Errorf("could not compress the chunks of %s: %v", tableName, err)
+			}
+			d.stats.compressed += n
+		}
+		d.stats.compression = time.Since(start)
+		d.stats.compressionDone = true
+		fmt.Printf("Compressed %d chunks in %v\n", d.stats.compressed, d.stats.compression)
+
+		for _, tableName := range tables {
+			var before, after int64
+			err := dbBench.QueryRow(fmt.Sprintf("SELECT coalesce(sum(before_compression_total_bytes), 0)::bigint, coalesce(sum(after_compression_total_bytes), 0)::bigint FROM hypertable_compression_stats('%s')", tableName)).Scan(&before, &after)
+			if err != nil {
+				return fmt.Errorf("could not get the compression stats of %s: %v", tableName, err)
+			}
+			d.stats.beforeBytes += before
+			d.stats.afterBytes += after
+		}
+	}
+	return nil
+}
+
+// hypertables returns the names of the metrics tables, in order.
+func (d *dbCreator) hypertables() []string {
+	var tables []string
+	for tableName := range d.ds.Headers().FieldKeys {
+		tables = append(tables, tableName)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// Report returns the time spent refreshing the continuous aggregates and
+// compressing the chunks after the load, and the sizes before and after
+// compression.
+func (d *dbCreator) Report(dbName string) (map[string]interface{}, error) {
+	report := make(map[string]interface{})
+	if d.stats.caggsRefreshed {
+		report["caggRefresh"] = map[string]interface{}{
+			"durationMillis": d.stats.caggRefresh.Milliseconds(),
+		}
+	}
+	if d.stats.compressionDone {
+		report["compression"] = map[string]interface{}{
+			"durationMillis": d.stats.compression.Milliseconds(),
+			"chunks":         d.stats.compressed,
+			"beforeBytes":    d.stats.beforeBytes,
+			"afterBytes":     d.stats.afterBytes,
+		}
+	}
+	if len(report) == 0 {
+		return nil, nil
+	}
+	return report, nil
+}
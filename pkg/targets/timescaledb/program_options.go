@@ -35,12 +35,41 @@ type LoadingOptions struct {
 	ProfileFile          string `yaml:"write-profile" mapstructure:"write-profile"`
 	ReplicationStatsFile string `yaml:"write-replication-stats" mapstructure:"write-replication-stats"`
 
+	UseCompression    bool          `yaml:"use-compression" mapstructure:"use-compression"`
+	CompressSegmentBy string        `yaml:"compress-segmentby" mapstructure:"compress-segmentby"`
+	CompressOrderBy   string        `yaml:"compress-orderby" mapstructure:"compress-orderby"`
+	CompressAfter     time.Duration `yaml:"compress-after" mapstructure:"compress-after"`
+	CompressAfterLoad bool          `yaml:"compress-after-load" mapstructure:"compress-after-load"`
+
+	CaggIntervals        []string `yaml:"cagg-intervals" mapstructure:"cagg-intervals"`
+	CaggTables           []string `yaml:"cagg-tables" mapstructure:"cagg-tables"`
+	CaggMaterializedOnly bool     `yaml:"cagg-materialized-only" mapstructure:"cagg-materialized-only"`
+
+	RetentionPeriod time.Duration `yaml:"retention-period" mapstructure:"retention-period"`
+
 	CreateMetricsTable bool     `yaml:"create-metrics-table" mapstructure:"create-metrics-table"`
 	ForceTextFormat    bool     `yaml:"force-text-format" mapstructure:"force-text-format"`
 	TagColumnTypes     []string `yaml:",omitempty" mapstructure:",omitempty"`
 	UseInsert          bool     `yaml:"use-insert" mapstructure:"use-insert"`
 }
 
+// Validate checks that the compression, continuous aggregate and retention
+// options go together.
+func (o *LoadingOptions) Validate() error {
+	if !o.UseHypertable && (o.UseCompression || len(o.CaggIntervals) > 0 || o.RetentionPeriod > 0) {
+		return fmt.Errorf("must set use-hypertable=true in order to use compression, continuous aggregates or retention")
+	}
+	if !o.UseCompression && (o.CompressAfter > 0 || o.CompressAfterLoad) {
+		return fmt.Errorf("must set use-compression=true in order to use compress-after or compress-after-load")
+	}
+	for _, interval := range o.CaggIntervals {
+		if d, err := time.ParseDuration(interval); err != nil || d < time.Second {
+			return fmt.Errorf("invalid cagg interval %q, expected a duration of at least 1s like 1m or 1h", interval)
+		}
+	}
+	return nil
+}
+
 func (o *LoadingOptions) GetConnectString(dbName string) string {
 	// User might be passing in host=hostname the connect string out of habit which may override the
 	// multi host configuration. Same for dbname= and user=. This sanitizes that.
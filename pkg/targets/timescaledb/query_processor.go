@@ -0,0 +1,192 @@
+package timescaledb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/pkg/errors"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// QuerySpecificConfig is the configuration of the TimescaleDB query runner.
+type QuerySpecificConfig struct {
+	Postgres        string   `yaml:"postgres" mapstructure:"postgres"`
+	Hosts           []string `yaml:"hosts" mapstructure:"hosts"`
+	User            string   `yaml:"user" mapstructure:"user"`
+	Pass            string   `yaml:"pass" mapstructure:"pass"`
+	Port            string   `yaml:"port" mapstructure:"port"`
+	ShowExplain     bool     `yaml:"show-explain" mapstructure:"show-explain"`
+	ForceTextFormat bool     `yaml:"force-text-format" mapstructure:"force-text-format"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// GetConnectString returns the connection string of a query worker.
+//
+// If we're running queries against multiple nodes we need to balance the queries
+// across replicas. Each worker is assigned a sequence number -- we'll use that
+// to evenly distribute hosts to worker connections
+func (c *QuerySpecificConfig) GetConnectString(dbName string, workerNumber int) string {
+	// User might be passing in host=hostname the connect string out of habit which may override the
+	// multi host configuration. Same for dbname= and user=. This sanitizes that.
+	re := regexp.MustCompile(`(host|dbname|user)=\S*\b`)
+	connectString := re.ReplaceAllString(c.Postgres, "")
+
+	// Round robin the host/worker assignment by assigning a host based on workerNumber % totalNumberOfHosts
+	host := c.Hosts[workerNumber%len(c.Hosts)]
+	connectString = fmt.Sprintf("host=%s dbname=%s user=%s %s", host, dbName, c.User, connectString)
+
+	// For optional parameters, ensure they exist then interpolate them into the connectString
+	if len(c.Port) > 0 {
+		connectString = fmt.Sprintf("%s port=%s", connectString, c.Port)
+	}
+	if len(c.Pass) > 0 {
+		connectString = fmt.Sprintf("%s password=%s", connectString, c.Pass)
+	}
+	if c.ForceTextFormat {
+		connectString = fmt.Sprintf("%s disable_prepared_binary_result=yes binary_parameters=no", connectString)
+	}
+
+	return connectString
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the TimescaleDB hosts in conf, round-robin over the workers. With
+// ShowExplain set only one query is run.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	if len(conf.Hosts) == 0 {
+		return nil, fmt.Errorf("missing `hosts` flag")
+	}
+	if conf.ShowExplain {
+		runner.SetLimit(1)
+	}
+	return &targets.QueryBenchmark{
+		QueryPool: &query.TimescaleDBPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{conf: conf, runner: runner}
+		},
+	}, nil
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(rows *sql.Rows, q *query.TimescaleDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = mapRows(rows)
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(r *sql.Rows) []map[string]interface{} {
+	rows := []map[string]interface{}{}
+	cols, _ := r.Columns()
+	for r.Next() {
+		row := make(map[string]interface{})
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+
+		err := r.Scan(values...)
+		if err != nil {
+			panic(errors.Wrap(err, "error while reading values"))
+		}
+
+		for i, column := range cols {
+			row[column] = *values[i].(*interface{})
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+}
+
+type queryProcessor struct {
+	conf   *QuerySpecificConfig
+	runner *query.BenchmarkRunner
+	db     *sql.DB
+	opts   *queryExecutorOptions
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	db, err := sql.Open(getDriver(p.conf.ForceTextFormat), p.conf.GetConnectString(p.runner.DatabaseName(), workerNumber))
+	if err != nil {
+		panic(err)
+	}
+	p.db = db
+	p.opts = &queryExecutorOptions{
+		showExplain:   p.conf.ShowExplain,
+		debug:         p.runner.DebugLevel() > 0,
+		printResponse: p.runner.DoPrintResponses(),
+	}
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+	tq := q.(*query.TimescaleDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.showExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.db.Query(qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+	if p.opts.showExplain {
+		text := ""
+		for rows.Next() {
+			var s string
+			if err2 := rows.Scan(&s); err2 != nil {
+				panic(err2)
+			}
+			text += s + "\n"
+		}
+		fmt.Printf("%s\n\n%s\n-----\n\n", qry, text)
+	} else if p.opts.printResponse {
+		prettyPrintResponse(rows, tq)
+	}
+	// Fetching all the rows to confirm that the query is fully completed.
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
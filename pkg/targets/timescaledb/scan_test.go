@@ -10,6 +10,7 @@ import (
 
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 )
 
 func TestHostnameIndexer(t *testing.T) {
@@ -133,7 +134,7 @@ func TestDecode(t *testing.T) {
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
 		dataSource := &fileDataSource{
-			scanner: bufio.NewScanner(br),
+			scanner: targetsCommon.NewLineScanner(br),
 			headers: &common.GeneratedDataHeaders{},
 		}
 		if c.shouldFatal {
@@ -166,7 +167,7 @@ func TestDecode(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("tags,tag1text,tag2text\ncpu,140,0.0,0.0\n")
 	br := bufio.NewReader(bytes.NewReader(input))
-	decoder := &fileDataSource{headers: &common.GeneratedDataHeaders{}, scanner: bufio.NewScanner(br)}
+	decoder := &fileDataSource{headers: &common.GeneratedDataHeaders{}, scanner: targetsCommon.NewLineScanner(br)}
 	_ = decoder.NextItem()
 	// nothing left, should be EOF
 	p := decoder.NextItem()
@@ -227,7 +228,7 @@ func TestFileDataSourceHeaders(t *testing.T) {
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
 		ds := &fileDataSource{
-			scanner: bufio.NewScanner(br),
+			scanner: targetsCommon.NewLineScanner(br),
 		}
 
 		if c.shouldFatal {
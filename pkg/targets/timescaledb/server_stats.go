@@ -0,0 +1,75 @@
+package timescaledb
+
+import (
+	"database/sql"
+)
+
+// serverStatsQueries return a row of the metrics collected during the load,
+// named after their prefix and columns.
+var serverStatsQueries = []struct {
+	prefix string
+	query  string
+}{
+	{
+		prefix: "pg_stat_database.",
+		query: `SELECT numbackends, xact_commit, xact_rollback, blks_read, blks_hit,
+			tup_inserted, temp_files, temp_bytes, deadlocks
+			FROM pg_stat_database WHERE datname = $1`,
+	},
+	{
+		prefix: "pg_stat_activity.",
+		query: `SELECT count(*) FILTER (WHERE state = 'active') AS active,
+			count(*) FILTER (WHERE state = 'idle in transaction') AS idle_in_transaction,
+			count(*) FILTER (WHERE wait_event_type = 'Lock') AS waiting_on_lock
+			FROM pg_stat_activity WHERE datname = $1`,
+	},
+}
+
+// CollectStats returns the transaction, block and tuple counters of the
+// database from pg_stat_database, and its active and waiting connections
+// from pg_stat_activity.
+func (d *dbCreator) CollectStats(dbName string) (map[string]float64, error) {
+	db, err := sql.Open(d.driver, d.opts.GetConnectString(dbName))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	stats := make(map[string]float64)
+	for _, q := range serverStatsQueries {
+		if err = queryStats(db, stats, q.prefix, q.query, dbName); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// queryStats adds the columns of the row of query to stats, prefixing their
+// names with prefix.
+func queryStats(db *sql.DB, stats map[string]float64, prefix, query string, args ...interface{}) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return rows.Err()
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	values := make([]sql.NullFloat64, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err = rows.Scan(dest...); err != nil {
+		return err
+	}
+	for i, column := range columns {
+		if values[i].Valid {
+			stats[prefix+column] = values[i].Float64
+		}
+	}
+	return nil
+}
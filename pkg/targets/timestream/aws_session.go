@@ -9,7 +9,10 @@ import (
 	"time"
 )
 
-func OpenAWSSession(awsRegion *string, timeout time.Duration) (*session.Session, error) {
+// OpenAWSSession opens a session to the Timestream service of awsRegion. A
+// non-empty endpoint overrides the endpoint of the service, e.g. to use a
+// local stand-in of Timestream, and disables the endpoint discovery.
+func OpenAWSSession(awsRegion *string, endpoint string, timeout time.Duration) (*session.Session, error) {
 	tr := &http.Transport{
 		ResponseHeaderTimeout: 20 * time.Second,
 		// Using DefaultTransport values for other parameters: https://golang.org/pkg/net/http/#RoundTripper
@@ -27,8 +30,12 @@ func OpenAWSSession(awsRegion *string, timeout time.Duration) (*session.Session,
 		panic("could not configure http transport: " + err.Error())
 
 	}
-	return session.NewSession(&aws.Config{
+	config := &aws.Config{
 		Region:     awsRegion,
 		MaxRetries: aws.Int(10),
-		HTTPClient: &http.Client{Transport: tr}})
+		HTTPClient: &http.Client{Transport: tr}}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+	return session.NewSession(config)
 }
@@ -6,7 +6,6 @@ import (
 	"github.com/aws/aws-sdk-go/service/timestreamwrite"
 	"github.com/pkg/errors"
 	"github.com/timescale/tsbs/internal/inputs"
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -53,10 +52,18 @@ func (b benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
 }
 
 func (b benchmark) GetProcessor() targets.Processor {
-	awsSession, err := OpenAWSSession(&b.config.AwsRegion, time.Minute)
+	awsSession, err := OpenAWSSession(&b.config.AwsRegion, b.config.Endpoint, time.Minute)
 	if err != nil {
 		panic("could not open aws session")
 	}
+	if b.config.UseMultiMeasureRecords {
+		return &multiMeasureProcessor{
+			dbName:       b.targetDb,
+			batchPool:    b.batchFactory.pool,
+			headers:      b.ds.Headers(),
+			writeService: timestreamwrite.New(awsSession),
+		}
+	}
 	if b.config.UseCommonAttributes {
 		return &commonDimensionsProcessor{
 			dbName:       b.targetDb,
@@ -75,7 +82,7 @@ func (b benchmark) GetProcessor() targets.Processor {
 }
 
 func (b benchmark) GetDBCreator() targets.DBCreator {
-	awsSession, err := OpenAWSSession(&b.config.AwsRegion, time.Minute)
+	awsSession, err := OpenAWSSession(&b.config.AwsRegion, b.config.Endpoint, time.Minute)
 	if err != nil {
 		panic("could not open aws session")
 	}
@@ -84,16 +91,18 @@ func (b benchmark) GetDBCreator() targets.DBCreator {
 		writeSvc:                           timestreamwrite.New(awsSession),
 		magneticStoreRetentionPeriodInDays: b.config.MagStoreRetentionInDays,
 		memoryRetentionPeriodInHours:       b.config.MemStoreRetentionInHours,
+		enableMagneticStoreWrites:          b.config.EnableMagStoreWrites,
 	}
 }
 
 func initDataSource(config *source.DataSourceConfig, useCurrentTs bool) (targets.DataSource, error) {
 	if config.Type == source.FileDataSourceType {
-		br := load.GetBufferedReader(config.File.Location)
-		return &fileDataSource{
-			scanner:      bufio.NewScanner(br),
-			useCurrentTs: useCurrentTs,
-		}, nil
+		return common.FileDataSource(config.File, func(br *bufio.Reader) (targets.DataSource, error) {
+			return &fileDataSource{
+				scanner:      common.NewLineScanner(br),
+				useCurrentTs: useCurrentTs,
+			}, nil
+		})
 	} else if config.Type == source.SimulatorDataSourceType {
 		dataGenerator := &inputs.DataGenerator{}
 		simulator, err := dataGenerator.CreateSimulator(config.Simulator)
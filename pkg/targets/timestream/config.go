@@ -1,17 +1,29 @@
 package timestream
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 )
 
+const (
+	// bounds of the retention periods of the memory and the magnetic stores
+	maxMemStoreRetentionInHours = 8766
+	maxMagStoreRetentionInDays  = 73000
+)
+
 type SpecificConfig struct {
 	UseCommonAttributes      bool   `yaml:"use-common-attributes" mapstructure:"use-common-attributes"`
+	UseMultiMeasureRecords   bool   `yaml:"use-multi-measure-records" mapstructure:"use-multi-measure-records"`
 	AwsRegion                string `yaml:"aws-region" mapstructure:"aws-region"`
+	Endpoint                 string `yaml:"endpoint" mapstructure:"endpoint"`
 	HashProperty             string `yaml:"hash-property" mapstructure:"hash-property"`
 	UseCurrentTime           bool   `yaml:"use-current-time" mapstructure:"use-current-time"`
 	MagStoreRetentionInDays  int64  `yaml:"mag-store-retention-in-days" mapstructure:"mag-store-retention-in-days"`
 	MemStoreRetentionInHours int64  `yaml:"mem-store-retention-in-hours" mapstructure:"mem-store-retention-in-hours"`
+	EnableMagStoreWrites     bool   `yaml:"enable-mag-store-writes" mapstructure:"enable-mag-store-writes"`
 }
 
 func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
@@ -19,6 +31,36 @@ func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
 	if err := v.Unmarshal(&conf); err != nil {
 		return nil, err
 	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// Validate checks that the retention periods are within the bounds
+// Timestream accepts.
+func (c *SpecificConfig) Validate() error {
+	if c.MemStoreRetentionInHours < 1 || c.MemStoreRetentionInHours > maxMemStoreRetentionInHours {
+		return fmt.Errorf("mem-store-retention-in-hours must be between 1 and %d, got %d", maxMemStoreRetentionInHours, c.MemStoreRetentionInHours)
+	}
+	if c.MagStoreRetentionInDays < 1 || c.MagStoreRetentionInDays > maxMagStoreRetentionInDays {
+		return fmt.Errorf("mag-store-retention-in-days must be between 1 and %d, got %d", maxMagStoreRetentionInDays, c.MagStoreRetentionInDays)
+	}
+	return nil
+}
+
+// QuerySpecificConfig is the configuration of the Timestream query runner.
+type QuerySpecificConfig struct {
+	AwsRegion    string        `yaml:"aws-region" mapstructure:"aws-region"`
+	Endpoint     string        `yaml:"endpoint" mapstructure:"endpoint"`
+	QueryTimeout time.Duration `yaml:"query-timeout" mapstructure:"query-timeout"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
 	return &conf, nil
 }
 
@@ -28,7 +70,17 @@ func targetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 		true,
 		"Timestream client makes write requests with common attributes. "+
 			"If false, each value is written as a separate Record and a request of 100 records at once is sent")
+	flagSet.Bool(
+		flagPrefix+"use-multi-measure-records",
+		false,
+		"Write each point as a single multi-measure record named after its table, with a measure per field. "+
+			"Takes precedence over use-common-attributes")
 	flagSet.String(flagPrefix+"aws-region", "us-east-1", "AWS region where the db is located")
+	flagSet.String(
+		flagPrefix+"endpoint",
+		"",
+		"Endpoint of the Timestream write service, e.g. http://localhost:8000 for a local stand-in. "+
+			"Empty discovers the endpoint of the aws-region")
 	flagSet.String(
 		flagPrefix+"hash-property",
 		"hostname",
@@ -39,7 +91,7 @@ func targetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 		false,
 		"Use the local current timestamp when generating the records to load")
 	flagSet.Int64(
-		"mag-store-retention-in-days",
+		flagPrefix+"mag-store-retention-in-days",
 		180,
 		"The duration for which data must be stored in the magnetic store",
 	)
@@ -47,4 +99,14 @@ func targetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 		flagPrefix+"mem-store-retention-in-hours",
 		12,
 		"The duration for which data must be stored in the memory store")
+	flagSet.Bool(
+		flagPrefix+"enable-mag-store-writes",
+		false,
+		"Accept writes of records older than the memory store retention into the magnetic store")
+}
+
+func queryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"aws-region", "us-east-1", "Region where the database is")
+	flagSet.String(flagPrefix+"endpoint", "", "Endpoint of the Timestream query service, e.g. for a local stand-in. Empty discovers the endpoint of the aws-region")
+	flagSet.Duration(flagPrefix+"query-timeout", time.Minute, "Configuration for aws sdk client to timeout after")
 }
@@ -0,0 +1,30 @@
+package timestream
+
+import "testing"
+
+func TestSpecificConfigValidate(t *testing.T) {
+	cases := []struct {
+		desc      string
+		memHours  int64
+		magDays   int64
+		expectErr bool
+	}{
+		{desc: "within the bounds", memHours: 6, magDays: 1},
+		{desc: "upper bounds", memHours: maxMemStoreRetentionInHours, magDays: maxMagStoreRetentionInDays},
+		{desc: "no memory retention", memHours: 0, magDays: 1, expectErr: true},
+		{desc: "negative memory retention", memHours: -1, magDays: 1, expectErr: true},
+		{desc: "memory retention too long", memHours: maxMemStoreRetentionInHours + 1, magDays: 1, expectErr: true},
+		{desc: "no magnetic retention", memHours: 6, magDays: 0, expectErr: true},
+		{desc: "negative magnetic retention", memHours: 6, magDays: -1, expectErr: true},
+		{desc: "magnetic retention too long", memHours: 6, magDays: maxMagStoreRetentionInDays + 1, expectErr: true},
+	}
+	for _, c := range cases {
+		conf := &SpecificConfig{MemStoreRetentionInHours: c.memHours, MagStoreRetentionInDays: c.magDays}
+		err := conf.Validate()
+		if c.expectErr && err == nil {
+			t.Errorf("%s: expected an error", c.desc)
+		} else if !c.expectErr && err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package timestream
 
 import (
 	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/timestreamwrite"
 	"github.com/pkg/errors"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -19,6 +20,7 @@ type dbCreator struct {
 	ds                                 targets.DataSource
 	memoryRetentionPeriodInHours       int64
 	magneticStoreRetentionPeriodInDays int64
+	enableMagneticStoreWrites          bool
 }
 
 func (d *dbCreator) Init() {
@@ -86,18 +88,26 @@ func (d *dbCreator) PostCreateDB(dbName string) error {
 	for tableName := range headers.FieldKeys {
 		requiredTables = append(requiredTables, tableName)
 		createTableInput := &timestreamwrite.CreateTableInput{
-			DatabaseName: &dbName,
-			RetentionProperties: &timestreamwrite.RetentionProperties{
-				MagneticStoreRetentionPeriodInDays: &d.magneticStoreRetentionPeriodInDays,
-				MemoryStoreRetentionPeriodInHours:  &d.memoryRetentionPeriodInHours,
-			},
-			TableName: &tableName,
+			DatabaseName:                 &dbName,
+			RetentionProperties:          d.retentionProperties(),
+			MagneticStoreWriteProperties: d.magneticStoreWriteProperties(),
+			TableName:                    &tableName,
 		}
 		_, err := d.writeSvc.CreateTable(createTableInput)
-		if _, ok := err.(*timestreamwrite.ConflictException); !ok {
+		if err == nil {
+			continue
+		} else if _, ok := err.(*timestreamwrite.ConflictException); !ok {
 			return errors.Wrap(err, "could not create table '"+tableName+"': ")
-		} else {
-			log.Println("Table " + tableName + " exists, skipping create")
+		}
+		log.Println("Table " + tableName + " exists, updating its retention")
+		updateTableInput := &timestreamwrite.UpdateTableInput{
+			DatabaseName:                 &dbName,
+			RetentionProperties:          d.retentionProperties(),
+			MagneticStoreWriteProperties: d.magneticStoreWriteProperties(),
+			TableName:                    &tableName,
+		}
+		if _, err := d.writeSvc.UpdateTable(updateTableInput); err != nil {
+			return errors.Wrap(err, "could not update table '"+tableName+"': ")
 		}
 	}
 
@@ -108,6 +118,24 @@ func (d *dbCreator) PostCreateDB(dbName string) error {
 	return nil
 }
 
+// retentionProperties are the retention periods of the memory and the
+// magnetic stores of the tables.
+func (d *dbCreator) retentionProperties() *timestreamwrite.RetentionProperties {
+	return &timestreamwrite.RetentionProperties{
+		MagneticStoreRetentionPeriodInDays: aws.Int64(d.magneticStoreRetentionPeriodInDays),
+		MemoryStoreRetentionPeriodInHours:  aws.Int64(d.memoryRetentionPeriodInHours),
+	}
+}
+
+// magneticStoreWriteProperties tells whether the tables accept the records
+// older than the retention of the memory store, written to the magnetic
+// store.
+func (d *dbCreator) magneticStoreWriteProperties() *timestreamwrite.MagneticStoreWriteProperties {
+	return &timestreamwrite.MagneticStoreWriteProperties{
+		EnableMagneticStoreWrites: aws.Bool(d.enableMagneticStoreWrites),
+	}
+}
+
 func (d *dbCreator) waitForTables(dbName string, requiredTables []string) error {
 	numAttempts := 0
 	for {
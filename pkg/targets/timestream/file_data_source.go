@@ -1,10 +1,10 @@
 package timestream
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 	"log"
 	"strconv"
 	"strings"
@@ -17,7 +17,7 @@ const (
 
 type fileDataSource struct {
 	_headers     *common.GeneratedDataHeaders
-	scanner      *bufio.Scanner
+	scanner      *targetsCommon.LineScanner
 	useCurrentTs bool
 }
 
@@ -121,6 +121,11 @@ func (f *fileDataSource) NextItem() data.LoadedPoint {
 	return data.NewLoadedPoint(&newPoint)
 }
 
+// Position returns the number of bytes of the data scanned.
+func (f *fileDataSource) Position() int64 {
+	return f.scanner.Position()
+}
+
 func (f *fileDataSource) prepareTimestamp(pointTs string) string {
 	if !f.useCurrentTs {
 		return pointTs
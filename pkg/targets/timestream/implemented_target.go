@@ -6,13 +6,14 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 type implementedTarget struct{}
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return implementedTarget{}
 }
 
@@ -35,3 +36,15 @@ func (i implementedTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag
 func (i implementedTarget) TargetName() string {
 	return constants.FormatTimestream
 }
+
+func (i implementedTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	queryTargetSpecificFlags(flagPrefix, flagSet)
+}
+
+func (i implementedTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	queryConfig, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create query benchmark")
+	}
+	return NewQueryBenchmark(runner, queryConfig), nil
+}
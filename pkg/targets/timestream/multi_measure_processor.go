@@ -0,0 +1,104 @@
+package timestream
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+	"github.com/pkg/errors"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+	"log"
+	"sync"
+)
+
+// multiMeasureProcessor writes each point as a single multi-measure record,
+// named after the table of the point, with a measure per field. The records
+// are sent maxRecordsPerWriteRequest at a time.
+type multiMeasureProcessor struct {
+	dbName       string
+	batchPool    *sync.Pool
+	headers      *common.GeneratedDataHeaders
+	writeService *timestreamwrite.TimestreamWrite
+}
+
+func (p *multiMeasureProcessor) Init(_ int, _, _ bool) {}
+
+func (p *multiMeasureProcessor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rowCount uint64) {
+	var timestreamBatch batch
+	timestreamBatch = *b.(*batch)
+	for table, rows := range timestreamBatch.rows {
+		rowCount += uint64(len(rows))
+		if doLoad {
+			newMetricCount, err := p.writeBatch(table, rows)
+			if err != nil {
+				log.Fatal("could not write to table: " + err.Error())
+			}
+			metricCount += newMetricCount
+		}
+	}
+	timestreamBatch.reset()
+	p.batchPool.Put(b)
+	return metricCount, rowCount
+}
+
+func (p *multiMeasureProcessor) writeBatch(table string, rows []deserializedPoint) (numMetrics uint64, err error) {
+	fieldKeys := p.headers.FieldKeys[table]
+	records := make([]*timestreamwrite.Record, 0, maxRecordsPerWriteRequest)
+	for i := range rows {
+		record, numMeasures := createMultiMeasureRecord(table, &rows[i], fieldKeys)
+		if numMeasures == 0 {
+			// a record requires at least one measure
+			continue
+		}
+		records = append(records, record)
+		numMetrics += uint64(numMeasures)
+		if len(records) == maxRecordsPerWriteRequest {
+			if err := p.write(table, records); err != nil {
+				return 0, err
+			}
+			records = make([]*timestreamwrite.Record, 0, maxRecordsPerWriteRequest)
+		}
+	}
+	if len(records) > 0 {
+		if err := p.write(table, records); err != nil {
+			return 0, err
+		}
+	}
+	return numMetrics, nil
+}
+
+func (p *multiMeasureProcessor) write(table string, records []*timestreamwrite.Record) error {
+	writeRecordsInput := &timestreamwrite.WriteRecordsInput{
+		DatabaseName: &p.dbName,
+		TableName:    &table,
+		Records:      records,
+	}
+	if _, err := p.writeService.WriteRecords(writeRecordsInput); err != nil {
+		return errors.Wrap(err, "could not write records to db")
+	}
+	return nil
+}
+
+// createMultiMeasureRecord converts a point to a record of type MULTI named
+// measureName, with the non-null fields of the point as its measures. It
+// returns the record and its number of measures.
+func createMultiMeasureRecord(measureName string, point *deserializedPoint, fieldKeys []string) (*timestreamwrite.Record, int) {
+	measures := make([]*timestreamwrite.MeasureValue, 0, len(point.fields))
+	for i, fieldVal := range point.fields {
+		if fieldVal == nil {
+			continue
+		}
+		measures = append(measures, &timestreamwrite.MeasureValue{
+			Name:  aws.String(fieldKeys[i]),
+			Type:  aws.String(timestreamwrite.MeasureValueTypeDouble),
+			Value: fieldVal,
+		})
+	}
+	record := &timestreamwrite.Record{}
+	record.SetDimensions(createDimensions(point.tagKeys, point.tags))
+	record.SetMeasureName(measureName)
+	record.SetMeasureValueType(timestreamwrite.MeasureValueTypeMulti)
+	record.SetMeasureValues(measures)
+	record.SetTime(point.timeUnixNano)
+	record.SetTimeUnit(timestreamwrite.TimeUnitNanoseconds)
+	return record, len(measures)
+}
@@ -0,0 +1,63 @@
+package timestream
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+func TestCreateMultiMeasureRecord(t *testing.T) {
+	fieldKeys := []string{"usage_user", "usage_system", "usage_idle"}
+	cases := []struct {
+		desc     string
+		fields   []*string
+		measures map[string]string
+	}{
+		{
+			desc:     "all fields",
+			fields:   []*string{aws.String("1"), aws.String("2"), aws.String("3")},
+			measures: map[string]string{"usage_user": "1", "usage_system": "2", "usage_idle": "3"},
+		},
+		{
+			desc:     "null fields",
+			fields:   []*string{nil, aws.String("2"), nil},
+			measures: map[string]string{"usage_system": "2"},
+		},
+		{
+			desc:     "only null fields",
+			fields:   []*string{nil, nil, nil},
+			measures: map[string]string{},
+		},
+	}
+	for _, c := range cases {
+		point := &deserializedPoint{
+			timeUnixNano: "1451606400000000000",
+			table:        "cpu",
+			tagKeys:      []string{"hostname"},
+			tags:         []string{"host_0"},
+			fields:       c.fields,
+		}
+		record, numMeasures := createMultiMeasureRecord("cpu", point, fieldKeys)
+		if numMeasures != len(c.measures) || len(record.MeasureValues) != numMeasures {
+			t.Errorf("%s: incorrect number of measures: got %d and %d values want %d", c.desc, numMeasures, len(record.MeasureValues), len(c.measures))
+		}
+		for _, m := range record.MeasureValues {
+			if want, ok := c.measures[*m.Name]; !ok || *m.Value != want {
+				t.Errorf("%s: incorrect measure %s: got %s want %s", c.desc, *m.Name, *m.Value, want)
+			}
+			if *m.Type != timestreamwrite.MeasureValueTypeDouble {
+				t.Errorf("%s: incorrect type of measure %s: %s", c.desc, *m.Name, *m.Type)
+			}
+		}
+		if *record.MeasureName != "cpu" || *record.MeasureValueType != timestreamwrite.MeasureValueTypeMulti {
+			t.Errorf("%s: incorrect record measure: %s of type %s", c.desc, *record.MeasureName, *record.MeasureValueType)
+		}
+		if *record.Time != point.timeUnixNano || *record.TimeUnit != timestreamwrite.TimeUnitNanoseconds {
+			t.Errorf("%s: incorrect record time: %s %s", c.desc, *record.Time, *record.TimeUnit)
+		}
+		if len(record.Dimensions) != 1 || *record.Dimensions[0].Name != "hostname" || *record.Dimensions[0].Value != "host_0" {
+			t.Errorf("%s: incorrect dimensions: %v", c.desc, record.Dimensions)
+		}
+	}
+}
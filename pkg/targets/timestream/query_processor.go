@@ -0,0 +1,118 @@
+package timestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to Timestream, each worker with its own AWS session.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) *targets.QueryBenchmark {
+	return &targets.QueryBenchmark{
+		QueryPool: &query.TimestreamPool,
+		ProcessorCreate: func() query.Processor {
+			return &queryProcessor{conf: conf, runner: runner}
+		},
+	}
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(qry string, page *timestreamquery.QueryOutput, pageNum int) {
+	resp := make(map[string]interface{})
+	resp["query"] = qry
+	resp["results"] = mapRows(page)
+	resp["page"] = pageNum
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(page *timestreamquery.QueryOutput) []map[string]string {
+	var rows []map[string]string
+	cols := page.ColumnInfo
+	for _, row := range page.Rows {
+		rowAsMap := make(map[string]string)
+		for i, val := range row.Data {
+			colName := cols[i].Name
+			rowAsMap[*colName] = val.String()
+		}
+
+		rows = append(rows, rowAsMap)
+	}
+	return rows
+}
+
+type queryExecutorOptions struct {
+	debug         bool
+	printResponse bool
+}
+
+type queryProcessor struct {
+	conf     *QuerySpecificConfig
+	runner   *query.BenchmarkRunner
+	_opts    *queryExecutorOptions
+	_readSvc *timestreamquery.TimestreamQuery
+}
+
+func (p *queryProcessor) Init(_ int) {
+	awsSession, err := OpenAWSSession(&p.conf.AwsRegion, p.conf.Endpoint, p.conf.QueryTimeout)
+	if err != nil {
+		panic("could not open aws session")
+	}
+	p._readSvc = timestreamquery.New(awsSession)
+	p._opts = &queryExecutorOptions{
+		debug:         p.runner.DebugLevel() > 0,
+		printResponse: p.runner.DoPrintResponses(),
+	}
+}
+
+func (p *queryProcessor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+	tq := q.(*query.Timestream)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+
+	if p._opts.debug {
+		fmt.Println(qry)
+	}
+
+	queryInput := &timestreamquery.QueryInput{
+		QueryString: &qry,
+	}
+	totalRows := 0
+	pageNum := 1
+	err := p._readSvc.QueryPages(queryInput,
+		func(page *timestreamquery.QueryOutput, lastPage bool) bool {
+			// process query response
+			// making sure all the returned data is read
+			totalRows += len(page.Rows)
+			if p._opts.printResponse {
+				prettyPrintResponse(qry, page, pageNum)
+			}
+			pageNum++
+			// return true to continue to next page
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if p._opts.debug {
+		fmt.Printf("Total rows: %d\n", totalRows)
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
@@ -5,14 +5,16 @@ import (
 	"bytes"
 	"errors"
 	"github.com/blagojts/viper"
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"sync"
 )
 
 type SpecificConfig struct {
 	ServerURLs []string `yaml:"urls" mapstructure:"urls"`
+
+	common.HTTPWriterConfig `yaml:",inline" mapstructure:",squash"`
 }
 
 func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
@@ -27,6 +29,7 @@ func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
 type benchmark struct {
 	serverURLs []string
 	dataSource targets.DataSource
+	writer     *common.HTTPWriter
 }
 
 func NewBenchmark(vmSpecificConfig *SpecificConfig, dataSourceConfig *source.DataSourceConfig) (targets.Benchmark, error) {
@@ -34,12 +37,20 @@ func NewBenchmark(vmSpecificConfig *SpecificConfig, dataSourceConfig *source.Dat
 		return nil, errors.New("only FILE data source type is supported for VictoriaMetrics")
 	}
 
-	br := load.GetBufferedReader(dataSourceConfig.File.Location)
+	writer, err := common.NewHTTPWriter(vmSpecificConfig.HTTPWriterConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := common.FileDataSource(dataSourceConfig.File, func(br *bufio.Reader) (targets.DataSource, error) {
+		return &fileDataSource{scanner: common.NewLineScanner(br)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &benchmark{
-		dataSource: &fileDataSource{
-			scanner: bufio.NewScanner(br),
-		},
+		dataSource: ds,
 		serverURLs: vmSpecificConfig.ServerURLs,
+		writer:     writer,
 	}, nil
 }
 
@@ -61,7 +72,7 @@ func (b *benchmark) GetPointIndexer(maxPartitions uint) targets.PointIndexer {
 }
 
 func (b *benchmark) GetProcessor() targets.Processor {
-	return &processor{vmURLs: b.serverURLs}
+	return &processor{vmURLs: b.serverURLs, writer: b.writer}
 }
 
 func (b *benchmark) GetDBCreator() targets.DBCreator {
@@ -4,11 +4,12 @@ import (
 	"bufio"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	targetsCommon "github.com/timescale/tsbs/pkg/targets/common"
 	"log"
 )
 
 type fileDataSource struct {
-	scanner *bufio.Scanner
+	scanner *targetsCommon.LineScanner
 }
 
 func (f fileDataSource) NextItem() data.LoadedPoint {
@@ -18,7 +19,13 @@ func (f fileDataSource) NextItem() data.LoadedPoint {
 	} else if !ok {
 		log.Fatalf("scan error: %v", f.scanner.Err())
 	}
-	return data.NewLoadedPoint(f.scanner.Bytes())
+	// copied, the scanner reuses its buffer while the point may still be batched
+	return data.NewLoadedPoint(append([]byte(nil), f.scanner.Bytes()...))
+}
+
+// Position returns the number of bytes of the data scanned.
+func (f fileDataSource) Position() int64 {
+	return f.scanner.Position()
 }
 
 func (f fileDataSource) Headers() *common.GeneratedDataHeaders {
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"sync"
 	"testing"
 )
@@ -68,7 +69,7 @@ func TestDecode(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		decoder := &fileDataSource{scanner: bufio.NewScanner(br)}
+		decoder := &fileDataSource{scanner: common.NewLineScanner(br)}
 		p := decoder.NextItem()
 		dataBytes := p.Data.([]byte)
 		if !bytes.Equal(dataBytes, c.result) {
@@ -80,7 +81,7 @@ func TestDecode(t *testing.T) {
 func TestDecodeEOF(t *testing.T) {
 	input := []byte("cpu,tag1=tag1text,tag2=tag2text col1=0.0,col2=0.0 140")
 	br := bufio.NewReader(bytes.NewReader(input))
-	decoder := &fileDataSource{scanner: bufio.NewScanner(br)}
+	decoder := &fileDataSource{scanner: common.NewLineScanner(br)}
 	_ = decoder.NextItem()
 	// nothing left, should be EOF
 	p := decoder.NextItem()
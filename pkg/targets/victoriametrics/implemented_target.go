@@ -5,12 +5,14 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/influx"
 )
 
-func NewTarget() targets.ImplementedTarget {
+func NewTarget() targets.ImplementedQueryTarget {
 	return &vmTarget{}
 }
 
@@ -36,8 +38,26 @@ func (vm vmTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet
 		"http://localhost:8428/write",
 		"Comma-separated list of VictoriaMetrics ingestion URLs(single-node or VMInsert)",
 	)
+	common.AddHTTPWriterFlags(flagPrefix, flagSet, common.DefaultHTTPWriterConfig)
+	common.AddContentEncodingFlag(flagPrefix, flagSet, common.EncodingNone)
 }
 
 func (vm vmTarget) TargetName() string {
 	return constants.FormatVictoriaMetrics
 }
+
+func (vm vmTarget) QueryTargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(
+		flagPrefix+"urls",
+		"http://localhost:8428",
+		"Comma-separated list of VictoriaMetrics query URLs(single-node or VMSelect)",
+	)
+}
+
+func (vm vmTarget) QueryBenchmark(runner *query.BenchmarkRunner, v *viper.Viper) (*targets.QueryBenchmark, error) {
+	conf, err := parseQuerySpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	return NewQueryBenchmark(runner, conf)
+}
@@ -1,16 +1,18 @@
 package victoriametrics
 
 import (
-	"bytes"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"log"
 	"net/http"
-	"time"
 )
 
+var lineProtocolHeader = http.Header{"Content-Type": {"text/plain"}}
+
 type processor struct {
 	url    string
 	vmURLs []string
+	writer *common.HTTPWriter
 }
 
 func (p *processor) Init(workerNum int, doLoad, hashWorkers bool) {
@@ -27,22 +29,13 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, row
 }
 
 func (p *processor) do(b *batch) (uint64, uint64) {
-	for {
-		r := bytes.NewReader(b.buf.Bytes())
-		req, err := http.NewRequest("POST", p.url, r)
-		if err != nil {
-			log.Fatalf("error while creating new request: %s", err)
-		}
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Fatalf("error while executing request: %s", err)
-		}
-		resp.Body.Close()
-		if resp.StatusCode == http.StatusNoContent {
-			b.buf.Reset()
-			return b.metrics, b.rows
-		}
-		log.Printf("server returned HTTP status %d. Retrying", resp.StatusCode)
-		time.Sleep(time.Millisecond * 10)
+	stats, err := p.writer.Post(p.url, lineProtocolHeader, b.buf.Bytes())
+	if err != nil {
+		log.Fatalf("error while writing batch: %s", err)
+	}
+	if stats.Retries > 0 {
+		log.Printf("batch written after %d retries, backoff took %.02fsec", stats.Retries, stats.Backoff.Seconds())
 	}
+	b.buf.Reset()
+	return b.metrics, b.rows
 }
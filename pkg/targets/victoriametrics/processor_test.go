@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets/common"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -54,6 +55,10 @@ func TestProcessorProcessBatch(t *testing.T) {
 	}}
 	vm := startFakeVMServer(t)
 	vmURLs := []string{vm.server.URL}
+	writer, err := common.NewHTTPWriter(common.DefaultHTTPWriterConfig, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
 	for _, tc := range testCases {
 		name := fmt.Sprintf("%dmetrics %drows %dpoints load %v",
 			tc.metrics, tc.rows, len(tc.points), tc.doLoad)
@@ -65,7 +70,7 @@ func TestProcessorProcessBatch(t *testing.T) {
 				})
 			}
 
-			p := &processor{vmURLs: vmURLs}
+			p := &processor{vmURLs: vmURLs, writer: writer}
 			const ignored = false
 			p.Init(1, ignored, ignored)
 			callsBefore := vm.getCalls()
@@ -0,0 +1,28 @@
+package victoriametrics
+
+import (
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/prometheus"
+)
+
+// QuerySpecificConfig is the configuration of the VictoriaMetrics query runner.
+type QuerySpecificConfig struct {
+	URLs []string `yaml:"urls" mapstructure:"urls"`
+}
+
+func parseQuerySpecificConfig(v *viper.Viper) (*QuerySpecificConfig, error) {
+	var conf QuerySpecificConfig
+	if err := v.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
+
+// NewQueryBenchmark returns the QueryBenchmark sending the queries of runner
+// to the VictoriaMetrics URLs in conf, round-robin over the workers.
+// VictoriaMetrics implements the Prometheus querying API.
+func NewQueryBenchmark(runner *query.BenchmarkRunner, conf *QuerySpecificConfig) (*targets.QueryBenchmark, error) {
+	return prometheus.NewQueryBenchmark(runner, &prometheus.QuerySpecificConfig{URLs: conf.URLs})
+}
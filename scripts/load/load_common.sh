@@ -19,8 +19,8 @@ REPORTING_PERIOD=${REPORTING_PERIOD:-10s}
 
 DO_CREATE_DB=${DO_CREATE_DB:-true}
 
-# Ensure data file is in place
-if [ ! -f ${DATA_FILE} ]; then
+# Ensure data file is in place, DATA_FILE may be a glob pattern of several files
+if ! ls ${DATA_FILE} > /dev/null 2>&1; then
    echo "Cannot find data file ${DATA_FILE}"
    exit -1
 fi
@@ -31,7 +31,8 @@ RANDOM_FIELD_ORDER=${RANDOM_FIELD_ORDER:-false}
 BATCH_META_FIELDS=${BATCH_META_FIELDS:-true}
 GRANULARITY=${GRANULARITY:-"seconds"}
 
-cat ${DATA_FILE} | gunzip | $EXE_FILE_NAME \
+$EXE_FILE_NAME \
+                                --file="${DATA_FILE}" \
                                 --url=${MONGO_URL} \
                                 --db-name=${DATABASE_NAME} \
                                 --url=${MONGO_URL} \
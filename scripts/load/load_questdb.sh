@@ -28,7 +28,8 @@ done
 # Remove previous table
 curl -X GET http://${DATABASE_HOST}:${DATABASE_PORT}/exec?query=drop%20table%20cpu
 # Load new data
-cat ${DATA_FILE} | gunzip | $EXE_FILE_NAME \
+$EXE_FILE_NAME \
+                                --file="${DATA_FILE}" \
                                 --workers=${NUM_WORKERS} \
                                 --batch-size=${BATCH_SIZE} \
                                 --reporting-period=${REPORTING_PERIOD} \
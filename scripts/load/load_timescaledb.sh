@@ -38,7 +38,8 @@ while ! pg_isready -h ${DATABASE_HOST} -p ${DATABASE_PORT}; do
     sleep 1
 done
 
-cat ${DATA_FILE} | gunzip | $EXE_FILE_NAME \
+$EXE_FILE_NAME \
+                                --file="${DATA_FILE}" \
                                 --postgres="sslmode=disable" \
                                 --db-name=${DATABASE_NAME} \
                                 --host=${DATABASE_HOST} \
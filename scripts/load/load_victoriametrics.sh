@@ -16,5 +16,6 @@ EXE_DIR=${EXE_DIR:-$(dirname $0)}
 source ${EXE_DIR}/load_common.sh
 
 # Load data
-cat ${DATA_FILE} | gunzip | $EXE_FILE_NAME \
+$EXE_FILE_NAME \
+                                --file="${DATA_FILE}" \
                                 --urls=http://${DATABASE_HOST}:${DATABASE_PORT}/${DATABASE_PATH}